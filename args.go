@@ -0,0 +1,124 @@
+package di
+
+import (
+	"reflect"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// WithArgs binds parameters of the constructor function to values when calling [WithService].
+//
+// The bound parameters are passed the values, and the remaining parameters are resolved from the [Container]
+// as usual. This avoids writing a closure just to pass a connection string or port to a constructor function.
+//
+// Each value is bound to the first parameter that is not already bound and that the value is assignable to.
+// Use [ArgAt] to bind a value to a parameter by position instead, like when there are several parameters
+// of the same type. The values are checked when the Container is created,
+// and bound parameters are not dependencies for [WithDependencyValidation] or the [Manifest].
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithService(NewServer, // NewServer(*slog.Logger, addr string, port int) *Server
+//			di.WithArgs("localhost", 8080),
+//		),
+//		di.WithService(NewReplica, // NewReplica(primary string, replica string) *Replica
+//			di.WithArgs(di.ArgAt(1, "db-2"), di.ArgAt(0, "db-1")),
+//		),
+//	)
+//
+// This option will return an error if the service is a value service,
+// a value is nil without [ArgAt], or a value can't be bound to any parameter.
+func WithArgs(values ...any) ServiceOption {
+	return serviceOption(func(s *service) error {
+		if s.IsValue() {
+			return errors.New("WithArgs: not supported for value service")
+		}
+
+		deps := s.Dependencies()
+		if s.args == nil {
+			s.args = make([]reflect.Value, len(deps))
+		}
+
+		// Bind arguments by position first, so they aren't taken by arguments matched by type
+		for _, v := range values {
+			if pos, ok := v.(positionalArg); ok {
+				if err := s.bindArg(pos.index, pos.value); err != nil {
+					return errors.Wrapf(err, "WithArgs: ArgAt %d", pos.index)
+				}
+			}
+		}
+
+		for i, v := range values {
+			if _, ok := v.(positionalArg); ok {
+				continue
+			}
+			if v == nil {
+				return errors.Errorf("WithArgs: argument %d is nil; use di.ArgAt", i)
+			}
+
+			index := -1
+			for j, dep := range deps {
+				if s.args[j].IsValid() || dep.Type == typeContext || dep.Type == typeScope {
+					continue
+				}
+				if reflect.TypeOf(v).AssignableTo(dep.Type) {
+					index = j
+					break
+				}
+			}
+			if index < 0 {
+				return errors.Errorf("WithArgs: argument %d: no parameter for type %T", i, v)
+			}
+
+			if err := s.bindArg(index, v); err != nil {
+				return errors.Wrapf(err, "WithArgs: argument %d", i)
+			}
+		}
+
+		return nil
+	})
+}
+
+// ArgAt binds a value to the parameter of the constructor function at index i when passed to [WithArgs].
+//
+// If the value is nil, the zero value of the parameter type is passed.
+func ArgAt(i int, value any) any {
+	return positionalArg{index: i, value: value}
+}
+
+type positionalArg struct {
+	value any
+	index int
+}
+
+// bindArg binds the parameter at index i to the value.
+func (s *service) bindArg(i int, value any) error {
+	if i < 0 || i >= len(s.args) {
+		return errors.New("parameter not found")
+	}
+	if s.args[i].IsValid() {
+		return errors.New("parameter already bound")
+	}
+
+	t := s.deps[i].Type
+	arg := reflect.New(t).Elem()
+	if value != nil {
+		v := reflect.ValueOf(value)
+		if !v.Type().AssignableTo(t) {
+			return errors.Errorf("%s is not assignable to %s", v.Type(), t)
+		}
+		arg.Set(v)
+	}
+
+	s.args[i] = arg
+	return nil
+}
+
+// arg returns the value bound to the parameter at index i, or an invalid Value if it's not bound.
+func (s *service) arg(i int) reflect.Value {
+	if i < len(s.args) {
+		return s.args[i]
+	}
+	return reflect.Value{}
+}
@@ -0,0 +1,181 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type server struct {
+	a    testtypes.InterfaceA
+	host string
+	port int
+}
+
+func newServer(a testtypes.InterfaceA, host string, port int) *server {
+	return &server{a: a, host: host, port: port}
+}
+
+type replica struct {
+	primary string
+	replica string
+}
+
+func newReplica(primary, r string) *replica {
+	return &replica{primary: primary, replica: r}
+}
+
+func Test_WithArgs(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("by type", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(newServer, di.WithArgs(8080, "localhost")),
+			di.WithDependencyValidation(),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[*server](ctx, c)
+		require.NoError(t, err)
+		assert.NotNil(t, got.a)
+		assert.Equal(t, "localhost", got.host)
+		assert.Equal(t, 8080, got.port)
+	})
+
+	t.Run("same type in order", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(newReplica, di.WithArgs("db-1", "db-2")),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[*replica](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, &replica{primary: "db-1", replica: "db-2"}, got)
+	})
+
+	t.Run("ArgAt", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(newReplica, di.WithArgs("db-1", di.ArgAt(0, "db-0"))),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[*replica](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, &replica{primary: "db-0", replica: "db-1"}, got)
+	})
+
+	t.Run("ArgAt nil", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(newServer, di.WithArgs(di.ArgAt(0, nil), "localhost", 8080)),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[*server](ctx, c)
+		require.NoError(t, err)
+		assert.Nil(t, got.a)
+	})
+
+	t.Run("assignable to interface", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceB, di.WithArgs(&testtypes.StructA{})),
+			di.WithDependencyValidation(),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceB](ctx, c)
+		require.NoError(t, err)
+	})
+
+	t.Run("remaining dependency not registered", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(newServer, di.WithArgs("localhost", 8080)),
+			di.WithDependencyValidation(),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithDependencyValidation: "+
+			"service func(testtypes.InterfaceA, string, int) *di_test.server: "+
+			"dependency testtypes.InterfaceA: service not registered")
+	})
+
+	t.Run("manifest", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(newServer, di.WithArgs("localhost", 8080)),
+		)
+		require.NoError(t, err)
+
+		var deps []string
+		for _, s := range c.Manifest().Services {
+			if s.Type == "*di_test.server" {
+				deps = s.Dependencies
+			}
+		}
+		assert.Equal(t, []string{"testtypes.InterfaceA"}, deps)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			name string
+			want string
+			opts []any
+		}{
+			{
+				name: "nil",
+				opts: []any{nil},
+				want: "WithArgs: argument 0 is nil; use di.ArgAt",
+			},
+			{
+				name: "no parameter",
+				opts: []any{1.5},
+				want: "WithArgs: argument 0: no parameter for type float64",
+			},
+			{
+				name: "too many",
+				opts: []any{8080, 8081},
+				want: "WithArgs: argument 1: no parameter for type int",
+			},
+			{
+				name: "ArgAt out of range",
+				opts: []any{di.ArgAt(3, "x")},
+				want: "WithArgs: ArgAt 3: parameter not found",
+			},
+			{
+				name: "ArgAt not assignable",
+				opts: []any{di.ArgAt(2, "x")},
+				want: "WithArgs: ArgAt 2: string is not assignable to int",
+			},
+			{
+				name: "ArgAt already bound",
+				opts: []any{di.ArgAt(1, "x"), di.ArgAt(1, "y")},
+				want: "WithArgs: ArgAt 1: parameter already bound",
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				c, err := di.NewContainer(
+					di.WithService(newServer, di.WithArgs(tt.opts...)),
+				)
+				testutils.LogError(t, err)
+				assert.Nil(t, c)
+				assert.EqualError(t, err, "di.NewContainer: WithService "+
+					"func(testtypes.InterfaceA, string, int) *di_test.server: "+tt.want)
+			})
+		}
+	})
+
+	t.Run("value service", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(&testtypes.StructA{}, di.WithArgs("x")),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService *testtypes.StructA: WithArgs: not supported for value service")
+	})
+}
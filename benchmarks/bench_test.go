@@ -0,0 +1,266 @@
+package benchmarks
+
+import (
+	"context"
+	"testing"
+
+	"github.com/samber/do/v2"
+	"github.com/sectrean/di-kit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+)
+
+// Test_Graph checks that each library wires the same graph, so the benchmarks compare the same work.
+func Test_Graph(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("di-kit", func(t *testing.T) {
+		c, err := newDIKit()
+		require.NoError(t, err)
+
+		s, err := di.Resolve[*Server](ctx, c)
+		require.NoError(t, err)
+		assert.True(t, s.Wired())
+
+		h, err := diKitScope(ctx, c)
+		require.NoError(t, err)
+		assert.Same(t, s.orders, h.orders)
+
+		require.NoError(t, c.Close(ctx))
+		assert.True(t, s.users.repo.db.closed)
+	})
+
+	t.Run("dig", func(t *testing.T) {
+		c, err := newDig()
+		require.NoError(t, err)
+
+		s, err := digResolve(c)
+		require.NoError(t, err)
+		assert.True(t, s.Wired())
+
+		h, err := digScope(c)
+		require.NoError(t, err)
+		assert.Same(t, s.orders, h.orders)
+	})
+
+	t.Run("fx", func(t *testing.T) {
+		var s *Server
+		app, err := newFx(fx.Populate(&s), fxClosers())
+		require.NoError(t, err)
+		assert.True(t, s.Wired())
+
+		require.NoError(t, app.Start(ctx))
+		require.NoError(t, app.Stop(ctx))
+		assert.True(t, s.users.repo.db.closed)
+	})
+
+	t.Run("do", func(t *testing.T) {
+		i := newDo()
+
+		s, err := do.Invoke[*Server](i)
+		require.NoError(t, err)
+		assert.True(t, s.Wired())
+
+		h, err := doScope(i)
+		require.NoError(t, err)
+		assert.Same(t, s.orders, h.orders)
+
+		_ = i.Shutdown()
+		assert.True(t, s.users.repo.db.closed)
+	})
+}
+
+func Benchmark_Build(b *testing.B) {
+	b.Run("di-kit", func(b *testing.B) {
+		for range b.N {
+			_, err := newDIKit()
+			require.NoError(b, err)
+		}
+	})
+
+	b.Run("dig", func(b *testing.B) {
+		for range b.N {
+			_, err := newDig()
+			require.NoError(b, err)
+		}
+	})
+
+	b.Run("fx", func(b *testing.B) {
+		for range b.N {
+			_, err := newFx()
+			require.NoError(b, err)
+		}
+	})
+
+	b.Run("do", func(b *testing.B) {
+		for range b.N {
+			_ = newDo()
+		}
+	})
+}
+
+func Benchmark_ResolveCold(b *testing.B) {
+	ctx := context.Background()
+
+	b.Run("di-kit", func(b *testing.B) {
+		for range b.N {
+			c, err := newDIKit()
+			require.NoError(b, err)
+
+			_, err = di.Resolve[*Server](ctx, c)
+			require.NoError(b, err)
+		}
+	})
+
+	b.Run("dig", func(b *testing.B) {
+		for range b.N {
+			c, err := newDig()
+			require.NoError(b, err)
+
+			_, err = digResolve(c)
+			require.NoError(b, err)
+		}
+	})
+
+	b.Run("fx", func(b *testing.B) {
+		for range b.N {
+			var s *Server
+			_, err := newFx(fx.Populate(&s))
+			require.NoError(b, err)
+		}
+	})
+
+	b.Run("do", func(b *testing.B) {
+		for range b.N {
+			_, err := do.Invoke[*Server](newDo())
+			require.NoError(b, err)
+		}
+	})
+}
+
+func Benchmark_ResolveWarm(b *testing.B) {
+	ctx := context.Background()
+
+	b.Run("di-kit", func(b *testing.B) {
+		c, err := newDIKit()
+		require.NoError(b, err)
+		_, err = di.Resolve[*Server](ctx, c)
+		require.NoError(b, err)
+
+		b.ResetTimer()
+		for range b.N {
+			_, err = di.Resolve[*Server](ctx, c)
+			require.NoError(b, err)
+		}
+	})
+
+	b.Run("dig", func(b *testing.B) {
+		c, err := newDig()
+		require.NoError(b, err)
+		_, err = digResolve(c)
+		require.NoError(b, err)
+
+		b.ResetTimer()
+		for range b.N {
+			_, err = digResolve(c)
+			require.NoError(b, err)
+		}
+	})
+
+	b.Run("fx", func(b *testing.B) {
+		b.Skip("fx only resolves services when the application is created")
+	})
+
+	b.Run("do", func(b *testing.B) {
+		i := newDo()
+		_, err := do.Invoke[*Server](i)
+		require.NoError(b, err)
+
+		b.ResetTimer()
+		for range b.N {
+			_, err = do.Invoke[*Server](i)
+			require.NoError(b, err)
+		}
+	})
+}
+
+func Benchmark_Scope(b *testing.B) {
+	ctx := context.Background()
+
+	b.Run("di-kit", func(b *testing.B) {
+		c, err := newDIKit()
+		require.NoError(b, err)
+
+		b.ResetTimer()
+		for range b.N {
+			_, err = diKitScope(ctx, c)
+			require.NoError(b, err)
+		}
+	})
+
+	b.Run("dig", func(b *testing.B) {
+		c, err := newDig()
+		require.NoError(b, err)
+
+		b.ResetTimer()
+		for range b.N {
+			_, err = digScope(c)
+			require.NoError(b, err)
+		}
+	})
+
+	b.Run("fx", func(b *testing.B) {
+		b.Skip("fx does not support child scopes")
+	})
+
+	b.Run("do", func(b *testing.B) {
+		i := newDo()
+
+		b.ResetTimer()
+		for range b.N {
+			_, err := doScope(i)
+			require.NoError(b, err)
+		}
+	})
+}
+
+func Benchmark_Close(b *testing.B) {
+	ctx := context.Background()
+
+	b.Run("di-kit", func(b *testing.B) {
+		for range b.N {
+			c, err := newDIKit()
+			require.NoError(b, err)
+			_, err = di.Resolve[*Server](ctx, c)
+			require.NoError(b, err)
+
+			require.NoError(b, c.Close(ctx))
+		}
+	})
+
+	b.Run("dig", func(b *testing.B) {
+		b.Skip("dig does not close services")
+	})
+
+	b.Run("fx", func(b *testing.B) {
+		for range b.N {
+			var s *Server
+			app, err := newFx(fx.Populate(&s), fxClosers())
+			require.NoError(b, err)
+
+			require.NoError(b, app.Start(ctx))
+			require.NoError(b, app.Stop(ctx))
+		}
+	})
+
+	b.Run("do", func(b *testing.B) {
+		for range b.N {
+			i := newDo()
+			_, err := do.Invoke[*Server](i)
+			require.NoError(b, err)
+
+			_ = i.Shutdown()
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package benchmarks
+
+import (
+	"go.uber.org/dig"
+)
+
+func newDig() (*dig.Container, error) {
+	c := dig.New()
+	for _, fn := range Constructors {
+		if err := c.Provide(fn); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func digResolve(c *dig.Container) (*Server, error) {
+	var s *Server
+	err := c.Invoke(func(server *Server) {
+		s = server
+	})
+
+	return s, err
+}
+
+func digScope(c *dig.Container) (*Handler, error) {
+	scope := c.Scope("request")
+	if err := scope.Provide(NewRequest); err != nil {
+		return nil, err
+	}
+	if err := scope.Provide(NewHandler); err != nil {
+		return nil, err
+	}
+
+	var h *Handler
+	err := scope.Invoke(func(handler *Handler) {
+		h = handler
+	})
+
+	return h, err
+}
@@ -0,0 +1,33 @@
+package benchmarks
+
+import (
+	"context"
+
+	"github.com/sectrean/di-kit"
+)
+
+func newDIKit() (*di.Container, error) {
+	opts := make([]di.ContainerOption, 0, len(Constructors))
+	for _, fn := range Constructors {
+		opts = append(opts, di.WithService(fn))
+	}
+
+	return di.NewContainer(opts...)
+}
+
+func diKitScope(ctx context.Context, c *di.Container) (*Handler, error) {
+	scope, err := c.NewScope(
+		di.WithService(NewRequest),
+		di.WithService(NewHandler),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := di.Resolve[*Handler](ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return h, scope.Close(ctx)
+}
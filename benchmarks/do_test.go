@@ -0,0 +1,64 @@
+package benchmarks
+
+import (
+	"github.com/samber/do/v2"
+)
+
+func newDo() *do.RootScope {
+	i := do.New()
+	do.Provide(i, func(do.Injector) (*Config, error) {
+		return NewConfig(), nil
+	})
+	do.Provide(i, func(i do.Injector) (*Logger, error) {
+		return NewLogger(do.MustInvoke[*Config](i)), nil
+	})
+	do.Provide(i, func(i do.Injector) (*DB, error) {
+		return NewDB(do.MustInvoke[*Config](i), do.MustInvoke[*Logger](i)), nil
+	})
+	do.Provide(i, func(i do.Injector) (*Cache, error) {
+		return NewCache(do.MustInvoke[*Logger](i)), nil
+	})
+	do.Provide(i, func(i do.Injector) (*UserRepo, error) {
+		return NewUserRepo(do.MustInvoke[*DB](i), do.MustInvoke[*Cache](i)), nil
+	})
+	do.Provide(i, func(i do.Injector) (*OrderRepo, error) {
+		return NewOrderRepo(do.MustInvoke[*DB](i), do.MustInvoke[*Cache](i)), nil
+	})
+	do.Provide(i, func(i do.Injector) (*UserService, error) {
+		return NewUserService(do.MustInvoke[*UserRepo](i), do.MustInvoke[*Logger](i)), nil
+	})
+	do.Provide(i, func(i do.Injector) (*OrderService, error) {
+		return NewOrderService(
+			do.MustInvoke[*OrderRepo](i),
+			do.MustInvoke[*UserService](i),
+			do.MustInvoke[*Logger](i),
+		), nil
+	})
+	do.Provide(i, func(i do.Injector) (*Server, error) {
+		return NewServer(
+			do.MustInvoke[*UserService](i),
+			do.MustInvoke[*OrderService](i),
+			do.MustInvoke[*Logger](i),
+		), nil
+	})
+
+	return i
+}
+
+func doScope(i *do.RootScope) (*Handler, error) {
+	scope := i.Scope("request")
+	do.Provide(scope, func(i do.Injector) (*Request, error) {
+		return NewRequest(do.MustInvoke[*Logger](i)), nil
+	})
+	do.Provide(scope, func(i do.Injector) (*Handler, error) {
+		return NewHandler(do.MustInvoke[*Request](i), do.MustInvoke[*OrderService](i)), nil
+	})
+
+	h, err := do.Invoke[*Handler](scope)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = scope.Shutdown()
+	return h, nil
+}
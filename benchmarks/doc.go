@@ -0,0 +1,27 @@
+/*
+Package benchmarks compares the performance of di-kit with [dig], [fx], and [do]
+on the same synthetic graph of services.
+
+It is a separate module, so the libraries it compares against are not dependencies of di-kit.
+Run the benchmarks from this directory:
+
+	go mod tidy
+	go test -bench=. -benchmem -count=10 | tee new.txt
+	benchstat new.txt
+
+Each benchmark has a sub-benchmark for each library, so the results can be compared side by side:
+
+  - Benchmark_Build creates a container with the graph registered.
+  - Benchmark_ResolveCold creates a container and resolves the root service, creating every service.
+  - Benchmark_ResolveWarm resolves the root service from a container where it was already created.
+  - Benchmark_Scope creates a child scope, resolves a service registered with the scope, and closes the scope.
+  - Benchmark_Close creates a container, resolves the root service, and closes the container.
+
+Libraries without an equivalent feature skip the sub-benchmark, like fx, which has no child scopes
+and only resolves services when the application is created.
+
+[dig]: https://pkg.go.dev/go.uber.org/dig
+[fx]: https://pkg.go.dev/go.uber.org/fx
+[do]: https://pkg.go.dev/github.com/samber/do/v2
+*/
+package benchmarks
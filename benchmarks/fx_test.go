@@ -0,0 +1,27 @@
+package benchmarks
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+)
+
+// newFx creates an application with the graph registered.
+// The services are only created if opts include an option that needs them, like fx.Populate.
+func newFx(opts ...fx.Option) (*fx.App, error) {
+	app := fx.New(
+		fx.NopLogger,
+		fx.Provide(Constructors...),
+		fx.Options(opts...),
+	)
+
+	return app, app.Err()
+}
+
+// fxClosers registers lifecycle hooks to close the services, like the other libraries do automatically.
+func fxClosers() fx.Option {
+	return fx.Invoke(func(lc fx.Lifecycle, db *DB, cache *Cache) {
+		lc.Append(fx.Hook{OnStop: func(context.Context) error { return cache.Close() }})
+		lc.Append(fx.Hook{OnStop: func(context.Context) error { return db.Close() }})
+	})
+}
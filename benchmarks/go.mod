@@ -0,0 +1,23 @@
+module github.com/sectrean/di-kit/benchmarks
+
+go 1.25.10
+
+require (
+	github.com/samber/do/v2 v2.0.0
+	github.com/sectrean/di-kit v0.0.0
+	github.com/stretchr/testify v1.11.1
+	go.uber.org/dig v1.18.0
+	go.uber.org/fx v1.23.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
+	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
+	github.com/samber/go-type-to-string v1.8.0 // indirect
+	go.uber.org/multierr v1.11.0 // indirect
+	go.uber.org/zap v1.26.0 // indirect
+	golang.org/x/sys v0.44.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/sectrean/di-kit => ../
@@ -0,0 +1,156 @@
+package benchmarks
+
+// The graph has a shared configuration and logger, two infrastructure services that need to be closed,
+// two repositories, two services, and a server at the root:
+//
+//	Server -> UserService, OrderService, Logger
+//	OrderService -> OrderRepo, UserService, Logger
+//	UserService -> UserRepo, Logger
+//	OrderRepo, UserRepo -> DB, Cache
+//	DB -> Config, Logger
+//	Cache -> Logger
+//	Logger -> Config
+//
+// Request and Handler are created for each request scope:
+//
+//	Handler -> Request, OrderService
+//	Request -> Logger
+
+type Config struct {
+	DSN string
+}
+
+func NewConfig() *Config {
+	return &Config{DSN: "postgres://localhost/bench"}
+}
+
+type Logger struct {
+	cfg *Config
+}
+
+func NewLogger(cfg *Config) *Logger {
+	return &Logger{cfg: cfg}
+}
+
+type DB struct {
+	cfg    *Config
+	log    *Logger
+	closed bool
+}
+
+func NewDB(cfg *Config, log *Logger) *DB {
+	return &DB{cfg: cfg, log: log}
+}
+
+func (db *DB) Close() error {
+	db.closed = true
+	return nil
+}
+
+// Shutdown is called by samber/do instead of Close.
+func (db *DB) Shutdown() error {
+	return db.Close()
+}
+
+type Cache struct {
+	log    *Logger
+	closed bool
+}
+
+func NewCache(log *Logger) *Cache {
+	return &Cache{log: log}
+}
+
+func (c *Cache) Close() error {
+	c.closed = true
+	return nil
+}
+
+// Shutdown is called by samber/do instead of Close.
+func (c *Cache) Shutdown() error {
+	return c.Close()
+}
+
+type UserRepo struct {
+	db    *DB
+	cache *Cache
+}
+
+func NewUserRepo(db *DB, cache *Cache) *UserRepo {
+	return &UserRepo{db: db, cache: cache}
+}
+
+type OrderRepo struct {
+	db    *DB
+	cache *Cache
+}
+
+func NewOrderRepo(db *DB, cache *Cache) *OrderRepo {
+	return &OrderRepo{db: db, cache: cache}
+}
+
+type UserService struct {
+	repo *UserRepo
+	log  *Logger
+}
+
+func NewUserService(repo *UserRepo, log *Logger) *UserService {
+	return &UserService{repo: repo, log: log}
+}
+
+type OrderService struct {
+	repo  *OrderRepo
+	users *UserService
+	log   *Logger
+}
+
+func NewOrderService(repo *OrderRepo, users *UserService, log *Logger) *OrderService {
+	return &OrderService{repo: repo, users: users, log: log}
+}
+
+type Server struct {
+	users  *UserService
+	orders *OrderService
+	log    *Logger
+}
+
+func NewServer(users *UserService, orders *OrderService, log *Logger) *Server {
+	return &Server{users: users, orders: orders, log: log}
+}
+
+// Wired returns true if every service in the graph was injected.
+func (s *Server) Wired() bool {
+	return s != nil && s.log != nil && s.log.cfg != nil &&
+		s.users != nil && s.users.repo != nil && s.users.repo.db != nil && s.users.repo.cache != nil &&
+		s.orders != nil && s.orders.repo != nil && s.orders.users == s.users
+}
+
+type Request struct {
+	log *Logger
+}
+
+func NewRequest(log *Logger) *Request {
+	return &Request{log: log}
+}
+
+type Handler struct {
+	req    *Request
+	orders *OrderService
+}
+
+func NewHandler(req *Request, orders *OrderService) *Handler {
+	return &Handler{req: req, orders: orders}
+}
+
+// Constructors are the constructor functions of the singleton services, in dependency order.
+var Constructors = []any{
+	NewConfig,
+	NewLogger,
+	NewDB,
+	NewCache,
+	NewUserRepo,
+	NewOrderRepo,
+	NewUserService,
+	NewOrderService,
+	NewServer,
+}
@@ -0,0 +1,57 @@
+package di
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// Bind registers type *Interface* as an alias for the service registered as type *Impl*
+// when calling [NewContainer] or [Container.NewScope].
+//
+// Resolving *Interface* resolves *Impl* and returns the same instance,
+// so the lifetime and closing of the instance are determined by the registration of *Impl*.
+// This separates how a service is created from which interfaces it satisfies,
+// and lets modules bind interfaces without registering constructor functions again.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithService(storage.NewDBStore), // NewDBStore() *storage.DBStore
+//		di.Bind[storage.Store, *storage.DBStore](),
+//	)
+//
+// Available options:
+//   - [WithTag] specifies a tag for *Interface*.
+//   - [WithTagged] specifies the tag *Impl* is registered with.
+//   - [Replace] replaces services registered earlier as *Interface*.
+//
+// This option will return an error if *Impl* does not implement *Interface*.
+func Bind[Interface, Impl any](opts ...ServiceOption) ContainerOption {
+	return containerOption(func(c *Container) error {
+		ifaceType := reflect.TypeFor[Interface]()
+		implType := reflect.TypeFor[Impl]()
+		if !implType.AssignableTo(ifaceType) {
+			return errors.Errorf("di.Bind %s, %s: %s does not implement %s", ifaceType, implType, implType, ifaceType)
+		}
+
+		b := NewServiceBuilder(ifaceType).
+			Dependency(implType, nil).
+			Factory(func(_ context.Context, deps []any) (any, error) {
+				return deps[0], nil
+			}).
+			Lifetime(Transient).
+			Options(IgnoreCloser()).
+			Options(opts...)
+		b.name = "di.Bind " + ifaceType.String() + ", " + implType.String()
+
+		s, err := b.newService(c)
+		if err != nil {
+			return errors.Wrapf(err, "di.Bind %s, %s", ifaceType, implType)
+		}
+
+		c.register(s)
+		return nil
+	})
+}
@@ -0,0 +1,101 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type closerService interface {
+	Close(ctx context.Context) error
+}
+
+func Test_Bind(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("same instance", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewStructAPtr),
+			di.Bind[testtypes.InterfaceA, *testtypes.StructA](),
+		)
+		require.NoError(t, err)
+
+		a, err := di.Resolve[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Same(t, di.MustResolve[*testtypes.StructA](ctx, c), a)
+	})
+
+	t.Run("closed once", func(t *testing.T) {
+		var closed []string
+		c, err := di.NewContainer(
+			di.WithService(func() *closeRecorder {
+				return &closeRecorder{closed: &closed, name: "impl"}
+			}),
+			di.Bind[closerService, *closeRecorder](),
+		)
+		require.NoError(t, err)
+
+		_ = di.MustResolve[closerService](ctx, c)
+		_ = di.MustResolve[closerService](ctx, c)
+
+		err = c.Close(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"impl"}, closed)
+	})
+
+	t.Run("tags", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewStructAPtr, di.WithTag("impl")),
+			di.Bind[testtypes.InterfaceA, *testtypes.StructA](
+				di.WithTag("iface"),
+				di.WithTagged[*testtypes.StructA]("impl"),
+			),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, c, di.WithTag("iface"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("re-bind", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(testtypes.NewStructAPtr),
+			di.Bind[testtypes.InterfaceA, *testtypes.StructA](di.Replace()),
+		)
+		require.NoError(t, err)
+
+		all, err := di.Resolve[[]testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		require.Len(t, all, 1)
+		assert.Same(t, di.MustResolve[*testtypes.StructA](ctx, c), all[0])
+	})
+
+	t.Run("not registered", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.Bind[testtypes.InterfaceA, *testtypes.StructA](),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve testtypes.InterfaceA: "+
+			"dependency *testtypes.StructA: service not registered")
+	})
+
+	t.Run("does not implement", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.Bind[testtypes.InterfaceB, *testtypes.StructA](),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: di.Bind testtypes.InterfaceB, *testtypes.StructA: "+
+			"*testtypes.StructA does not implement testtypes.InterfaceB")
+	})
+}
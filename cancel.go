@@ -0,0 +1,64 @@
+package di
+
+import (
+	"time"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// WithoutCancel specifies that a service is created with a context that is not canceled
+// when the context passed to Resolve is canceled, when calling [WithService].
+//
+// By default, if the context is canceled while a [Singleton] or [Scoped] service is being created
+// and the constructor function returns an error, the error is not cached.
+// The next call to Resolve will call the constructor function again.
+// Other goroutines waiting for the service are not affected by the canceled context.
+//
+// With this option, the constructor function and its dependencies are called with [context.WithoutCancel],
+// so the service is created and cached even if the caller stops waiting.
+// The context values are still available to the constructor function.
+// Use this for expensive services that should not be created more than once.
+//
+// This option will return an error for a value service.
+func WithoutCancel() ServiceOption {
+	return serviceOption(func(s *service) error {
+		if s.IsValue() {
+			return errors.New("WithoutCancel: not supported for value service")
+		}
+
+		s.withoutCancel = true
+		return nil
+	})
+}
+
+// WithColdStartTimeout specifies that a [Singleton] or [Scoped] service is created with its own timeout,
+// instead of the deadline of the context passed to Resolve, when calling [WithService].
+//
+// When the service is created, the constructor function and its dependencies are called with a context
+// that is not canceled when the caller's context is, and that times out after d.
+// This gives the first resolve of an expensive service a dedicated budget, even when it is triggered
+// by a request with a short deadline. The caller waits until the service is created or d elapses.
+// Resolving the service after it is created returns immediately, and still honors the caller's context.
+//
+// If the constructor function returns an error after d elapses, the error is not cached.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithService(search.NewIndex, di.WithColdStartTimeout(30*time.Second)),
+//	)
+//
+// This option will return an error for a value service, a [Transient] service, or if d is not positive.
+func WithColdStartTimeout(d time.Duration) ServiceOption {
+	return serviceOption(func(s *service) error {
+		if s.IsValue() {
+			return errors.Errorf("WithColdStartTimeout %s: not supported for value service", d)
+		}
+		if d <= 0 {
+			return errors.Errorf("WithColdStartTimeout %s: must be positive", d)
+		}
+
+		s.coldStartTimeout = d
+		return nil
+	})
+}
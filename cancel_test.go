@@ -0,0 +1,231 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingConstructor returns a constructor function that signals when it starts,
+// then waits for the context to be canceled or for release to be closed.
+func blockingConstructor(started chan<- struct{}, release <-chan struct{}) func(context.Context) (*testtypes.StructA, error) {
+	return func(ctx context.Context) (*testtypes.StructA, error) {
+		started <- struct{}{}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-release:
+			return &testtypes.StructA{}, nil
+		}
+	}
+}
+
+func Test_ContextCanceledDuringConstruction(t *testing.T) {
+	t.Run("error not cached", func(t *testing.T) {
+		started := make(chan struct{}, 2)
+		release := make(chan struct{})
+
+		c, err := di.NewContainer(
+			di.WithService(blockingConstructor(started, release)),
+		)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-started
+			cancel()
+		}()
+
+		_, err = di.Resolve[*testtypes.StructA](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve *testtypes.StructA: context canceled")
+
+		// The next caller creates the service
+		close(release)
+		a, err := di.Resolve[*testtypes.StructA](context.Background(), c)
+		assert.NoError(t, err)
+		assert.NotNil(t, a)
+
+		a2, err := di.Resolve[*testtypes.StructA](context.Background(), c)
+		assert.NoError(t, err)
+		assert.Same(t, a, a2)
+	})
+
+	t.Run("waiter not poisoned", func(t *testing.T) {
+		started := make(chan struct{}, 2)
+		release := make(chan struct{})
+
+		c, err := di.NewContainer(
+			di.WithService(blockingConstructor(started, release)),
+		)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		errs := make(chan error, 1)
+		go func() {
+			_, err := di.Resolve[*testtypes.StructA](ctx, c)
+			errs <- err
+		}()
+		<-started
+
+		// Wait for the service while the first caller is creating it
+		waiter := make(chan *testtypes.StructA, 1)
+		go func() {
+			a, err := di.Resolve[*testtypes.StructA](context.Background(), c)
+			assert.NoError(t, err)
+			waiter <- a
+		}()
+
+		cancel()
+		require.ErrorIs(t, <-errs, context.Canceled)
+
+		// The waiter calls the constructor function again
+		<-started
+		close(release)
+		assert.NotNil(t, <-waiter)
+	})
+
+	t.Run("WithoutCancel", func(t *testing.T) {
+		started := make(chan struct{}, 1)
+		release := make(chan struct{})
+
+		c, err := di.NewContainer(
+			di.WithService(blockingConstructor(started, release), di.WithoutCancel()),
+		)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-started
+			cancel()
+			close(release)
+		}()
+
+		a, err := di.Resolve[*testtypes.StructA](ctx, c)
+		assert.NoError(t, err)
+		assert.NotNil(t, a)
+
+		a2, err := di.Resolve[*testtypes.StructA](context.Background(), c)
+		assert.NoError(t, err)
+		assert.Same(t, a, a2)
+	})
+
+	t.Run("WithoutCancel context values", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func(ctx context.Context) testtypes.InterfaceA {
+				assert.Equal(t, "value", testutils.TestValue(ctx))
+				assert.NoError(t, ctx.Err())
+				return &testtypes.StructA{}
+			}, di.WithoutCancel()),
+		)
+		require.NoError(t, err)
+
+		ctx := testutils.ContextWithTestValue(context.Background(), "value")
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, c)
+		assert.NoError(t, err)
+	})
+
+	t.Run("WithoutCancel value service", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(&testtypes.StructA{}, di.WithoutCancel()),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService *testtypes.StructA: WithoutCancel: not supported for value service")
+	})
+}
+
+func Test_WithColdStartTimeout(t *testing.T) {
+	t.Run("caller deadline", func(t *testing.T) {
+		started := make(chan struct{}, 1)
+		release := make(chan struct{})
+
+		c, err := di.NewContainer(
+			di.WithService(blockingConstructor(started, release), di.WithColdStartTimeout(time.Minute)),
+		)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		go func() {
+			<-started
+			<-ctx.Done()
+			close(release)
+		}()
+
+		a, err := di.Resolve[*testtypes.StructA](ctx, c)
+		assert.NoError(t, err)
+		assert.NotNil(t, a)
+
+		// Warm resolves still honor the caller's context
+		_, err = di.Resolve[*testtypes.StructA](ctx, c)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		started := make(chan struct{}, 2)
+		release := make(chan struct{})
+
+		c, err := di.NewContainer(
+			di.WithService(blockingConstructor(started, release), di.WithColdStartTimeout(time.Millisecond)),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*testtypes.StructA](context.Background(), c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve *testtypes.StructA: context deadline exceeded")
+
+		// The error is not cached
+		close(release)
+		a, err := di.Resolve[*testtypes.StructA](context.Background(), c)
+		assert.NoError(t, err)
+		assert.NotNil(t, a)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		tests := map[string]struct {
+			wantErr string
+			opts    []di.ContainerOption
+		}{
+			"Transient": {
+				opts: []di.ContainerOption{
+					di.WithService(testtypes.NewInterfaceA, di.Transient, di.WithColdStartTimeout(time.Second)),
+				},
+				wantErr: "di.NewContainer: WithService func() testtypes.InterfaceA: " +
+					"WithColdStartTimeout 1s: service must be Singleton or Scoped",
+			},
+			"value service": {
+				opts: []di.ContainerOption{
+					di.WithService(&testtypes.StructA{}, di.WithColdStartTimeout(time.Second)),
+				},
+				wantErr: "di.NewContainer: WithService *testtypes.StructA: " +
+					"WithColdStartTimeout 1s: not supported for value service",
+			},
+			"not positive": {
+				opts: []di.ContainerOption{
+					di.WithService(testtypes.NewInterfaceA, di.WithColdStartTimeout(0)),
+				},
+				wantErr: "di.NewContainer: WithService func() testtypes.InterfaceA: " +
+					"WithColdStartTimeout 0s: must be positive",
+			},
+		}
+
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				c, err := di.NewContainer(tt.opts...)
+				testutils.LogError(t, err)
+
+				assert.Nil(t, c)
+				assert.EqualError(t, err, tt.wantErr)
+			})
+		}
+	})
+}
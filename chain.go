@@ -0,0 +1,86 @@
+package di
+
+import (
+	"reflect"
+	"slices"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// Chain registers a service of type *Service* composed from all other services registered as *Service*
+// when calling [NewContainer] or [Container.NewScope].
+//
+// This is the most common use of slice services, like composing HTTP middleware.
+// When the service is resolved, the other services registered as *Service* are resolved
+// and passed to combine, sorted by [WithOrder] and then by registration order.
+// The composed service is not included in the slice, and is resolved instead of the other services
+// when resolving a single *Service*, regardless of the order they were registered in.
+// Resolving []*Service* still returns the other services, without the composed service.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithService(NewLoggingMiddleware, di.WithOrder(1)),
+//		di.WithService(NewAuthMiddleware, di.WithOrder(2)),
+//		di.Chain(func(mws []Middleware) Middleware {
+//			return func(next http.Handler) http.Handler {
+//				for _, mw := range slices.Backward(mws) {
+//					next = mw(next)
+//				}
+//				return next
+//			}
+//		}),
+//	)
+//
+// All [ServiceOption]s supported by [WithService] are available.
+// Resolving the service returns an error if no other services are registered as *Service*.
+//
+// This option will return an error if combine is nil.
+func Chain[Service any](combine func([]Service) Service, opts ...ServiceOption) ContainerOption {
+	t := reflect.TypeFor[Service]()
+
+	return containerOption(func(c *Container) error {
+		if combine == nil {
+			return errors.Errorf("Chain %s: combine is nil", t)
+		}
+
+		fn := func(elems []Service) Service {
+			return combine(elems)
+		}
+
+		s, err := newService(c, reflect.ValueOf(fn), false, opts...)
+		if err != nil {
+			return errors.Wrapf(err, "Chain %s", t)
+		}
+
+		s.name = "di.Chain " + t.String()
+		s.chain = true
+		c.register(s)
+		return nil
+	})
+}
+
+// lastService returns the service resolved for a key from the registered services.
+// This is the service registered with [Chain], if any, then the service registered with [WithPrimary], if any,
+// or the last service registered, or the first with [DuplicateFirstWins].
+func (c *Container) lastService(svcs []*service) *service {
+	if i := slices.IndexFunc(svcs, (*service).IsChain); i >= 0 {
+		return svcs[i]
+	}
+	if i := slices.IndexFunc(svcs, (*service).IsPrimary); i >= 0 {
+		return svcs[i]
+	}
+	if c.duplicatePolicy == DuplicateFirstWins {
+		// Overriding the built-in services is not a duplicate
+		if i := slices.IndexFunc(svcs, func(s *service) bool { return !s.builtin }); i >= 0 {
+			return svcs[i]
+		}
+	}
+
+	return svcs[len(svcs)-1]
+}
+
+// IsChain returns true if the service was registered with [Chain].
+func (s *service) IsChain() bool {
+	return s.chain
+}
@@ -0,0 +1,124 @@
+package di_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type step func(string) string
+
+func appendStep(s string) step {
+	return func(in string) string { return in + s }
+}
+
+func combineSteps(steps []step) step {
+	return func(in string) string {
+		for _, s := range steps {
+			in = s(in)
+		}
+		return in
+	}
+}
+
+func Test_Chain(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("registration order", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithDeclaredService(appendStep("a")),
+			di.WithDeclaredService(appendStep("b")),
+			di.Chain(combineSteps),
+			di.WithDependencyValidation(),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[step](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, "-ab", got("-"))
+	})
+
+	t.Run("WithOrder", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithDeclaredService(appendStep("a"), di.WithOrder(2)),
+			di.WithDeclaredService(appendStep("b")),
+			di.WithDeclaredService(appendStep("c"), di.WithOrder(-1)),
+			di.WithDeclaredService(appendStep("d"), di.WithOrder(2)),
+			di.Chain(combineSteps),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[step](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, "-cbad", got("-"))
+	})
+
+	t.Run("registered first", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.Chain(combineSteps),
+			di.WithDeclaredService(appendStep("a")),
+			di.WithDeclaredService(appendStep("b")),
+			di.WithStrictResolve(),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[step](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, "-ab", got("-"))
+	})
+
+	t.Run("dependency", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithDeclaredService(appendStep("a")),
+			di.Chain(combineSteps),
+			di.WithService(func(s step) *strings.Builder {
+				b := &strings.Builder{}
+				b.WriteString(s("-"))
+				return b
+			}),
+			di.WithDependencyValidation(),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[*strings.Builder](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, "-a", got.String())
+	})
+
+	t.Run("slice excludes chain", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithDeclaredService(appendStep("a")),
+			di.Chain(combineSteps),
+		)
+		require.NoError(t, err)
+
+		got, err := di.ResolveAll[step](ctx, c)
+		require.NoError(t, err)
+		assert.Len(t, got, 1)
+	})
+
+	t.Run("no services", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.Chain(combineSteps),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[step](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve di_test.step: dependency []di_test.step: service not registered")
+	})
+
+	t.Run("combine nil", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.Chain[step](nil),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: Chain di_test.step: combine is nil")
+	})
+}
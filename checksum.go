@@ -0,0 +1,94 @@
+package di
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// Checksum returns a checksum of the wiring of the services described by the Manifest.
+//
+// Only the type, tag, lifetime, and dependencies of each service are included, in the order they are registered.
+// The checksum changes whenever the wiring changes, so it can be embedded in generated code
+// to detect that the code needs to be regenerated. Renaming a constructor function,
+// or changing a service's name, metadata, or module, doesn't change it.
+// See [WithWiringChecksum].
+func (m Manifest) Checksum() string {
+	wiring := make([]checksumService, len(m.Services))
+	for i := range m.Services {
+		s := &m.Services[i]
+		wiring[i] = checksumService{
+			Type:         s.Type,
+			Tag:          s.Tag,
+			Lifetime:     s.Lifetime,
+			Dependencies: s.Dependencies,
+		}
+	}
+
+	// Marshaling can't fail, since it only contains strings
+	data, _ := json.Marshal(wiring)
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// checksumService is the part of a [ManifestService] included in [Manifest.Checksum].
+type checksumService struct {
+	Type         string   `json:"type"`
+	Tag          string   `json:"tag,omitempty"`
+	Lifetime     string   `json:"lifetime"`
+	Dependencies []string `json:"dependencies,omitempty"`
+}
+
+// WithWiringChecksum checks that the services registered with the Container match checksum
+// when calling [NewContainer] or [Container.NewScope].
+//
+// The checksum is compared with [Manifest.Checksum] after all other options are applied,
+// including services registered with parent Containers.
+// Code generators can embed the checksum of the wiring they were generated from,
+// so the Container fails fast instead of running with generated code that has drifted.
+//
+// When applied in a [NamedModule], only the services registered by the module and its nested modules
+// are checked, so a generated module can check its own wiring regardless of the other services
+// registered with the Container.
+//
+// Example:
+//
+//	// Code generated by a wiring tool. DO NOT EDIT.
+//	const wiringChecksum = "5f2b..."
+//
+//	c, err := di.NewContainer(
+//		app.Dependencies,
+//		di.WithWiringChecksum(wiringChecksum),
+//	)
+//
+// This option will return an error if the checksum does not match.
+func WithWiringChecksum(checksum string) ContainerOption {
+	return WithOptionOrder(OrderValidation, containerOption(func(c *Container) error {
+		m := c.Manifest()
+		if c.module != "" {
+			m.Services = moduleServices(m.Services, c.module)
+		}
+
+		if got := m.Checksum(); got != checksum {
+			return errors.Errorf("WithWiringChecksum: registered services have changed; "+
+				"regenerate code with checksum %s (was %s)", got, checksum)
+		}
+
+		return nil
+	}))
+}
+
+// moduleServices returns the services registered by the module with the path or its nested modules.
+func moduleServices(svcs []ManifestService, path string) []ManifestService {
+	var filtered []ManifestService
+	for i := range svcs {
+		if m := svcs[i].Module; m == path || strings.HasPrefix(m, path+"/") {
+			filtered = append(filtered, svcs[i])
+		}
+	}
+	return filtered
+}
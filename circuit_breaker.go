@@ -0,0 +1,131 @@
+package di
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// WithCircuitBreaker configures a circuit breaker for a function service when calling [WithService].
+//
+// After the constructor function returns an error *threshold* times in a row, the circuit breaker opens.
+// While it is open, resolving the service fails fast with a [*CircuitOpenError] without calling the
+// constructor function or resolving its dependencies.
+// After the cooldown has passed, the circuit breaker is half-open, and admits a single trial call
+// to the constructor function. Other calls fail fast until the trial call finishes.
+// If it succeeds, the circuit breaker is closed. If it fails, the circuit breaker opens again.
+//
+// This is useful for [Transient] or [Scoped] services that depend on a downstream system
+// that may be unavailable, so the system is not called on every request.
+// (Errors for [Singleton] services are already cached by the Container.)
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithService(client.Connect, // Connect(context.Context) (*client.Client, error)
+//			di.Scoped,
+//			di.WithCircuitBreaker(5, 30*time.Second),
+//		),
+//	)
+//
+// This option will return an error if threshold or cooldown is not positive, or the service is a value service.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) ServiceOption {
+	return serviceOption(func(s *service) error {
+		if threshold <= 0 {
+			return errors.Errorf("WithCircuitBreaker: threshold %d must be positive", threshold)
+		}
+		if cooldown <= 0 {
+			return errors.Errorf("WithCircuitBreaker: cooldown %s must be positive", cooldown)
+		}
+		if s.IsValue() {
+			return errors.New("WithCircuitBreaker: not supported for value service")
+		}
+
+		s.breaker = &circuitBreaker{
+			threshold: threshold,
+			cooldown:  cooldown,
+		}
+		return nil
+	})
+}
+
+// CircuitOpenError is returned when resolving a service with an open circuit breaker.
+//
+// See [WithCircuitBreaker] for more information.
+type CircuitOpenError struct {
+	// Until is the time the circuit breaker will allow a trial call to create the service.
+	// It is in the past if a trial call is already in progress.
+	Until time.Time
+	// Err is the last error returned by the constructor function.
+	Err error
+}
+
+func (e *CircuitOpenError) Error() string {
+	return "circuit breaker open: " + e.Err.Error()
+}
+
+func (e *CircuitOpenError) Unwrap() error {
+	return e.Err
+}
+
+type circuitBreaker struct {
+	openUntil time.Time
+	lastErr   error
+	cooldown  time.Duration
+	failures  int
+	threshold int
+	trial     bool
+	mu        sync.Mutex
+}
+
+// Allow returns an error if the circuit breaker is open,
+// or it is half-open and a trial call is already in progress.
+//
+// It returns true if the caller is admitted as the trial call, and must call EndTrial when it's done.
+func (b *circuitBreaker) Allow(now time.Time) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return false, nil
+	}
+
+	if b.trial || now.Before(b.openUntil) {
+		return false, &CircuitOpenError{
+			Until: b.openUntil,
+			Err:   b.lastErr,
+		}
+	}
+
+	// Half-open: admit a single trial call
+	b.trial = true
+	return true, nil
+}
+
+// EndTrial allows another trial call if the circuit breaker is still half-open,
+// like when the trial call failed before calling the constructor function.
+func (b *circuitBreaker) EndTrial() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+}
+
+// Record the result of calling the constructor function at now.
+func (b *circuitBreaker) Record(err error, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil {
+		b.failures = 0
+		b.lastErr = nil
+		return
+	}
+
+	b.failures++
+	b.lastErr = err
+	if b.failures >= b.threshold {
+		b.openUntil = now.Add(b.cooldown)
+	}
+}
@@ -0,0 +1,216 @@
+package di_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/ditest"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithCircuitBreaker(t *testing.T) {
+	t.Run("opens after threshold", func(t *testing.T) {
+		calls := 0
+		c, err := di.NewContainer(
+			di.WithService(func() (*testtypes.StructA, error) {
+				calls++
+				return nil, errors.New("connection refused")
+			}, di.Transient, di.WithCircuitBreaker(2, time.Hour)),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		for range 2 {
+			_, err = di.Resolve[*testtypes.StructA](ctx, c)
+			assert.EqualError(t, err, "di.Container.Resolve *testtypes.StructA: connection refused")
+		}
+
+		_, err = di.Resolve[*testtypes.StructA](ctx, c)
+		testutils.LogError(t, err)
+
+		assert.EqualError(t, err, "di.Container.Resolve *testtypes.StructA: circuit breaker open: connection refused")
+		assert.Equal(t, 2, calls)
+
+		var openErr *di.CircuitOpenError
+		require.ErrorAs(t, err, &openErr)
+		assert.True(t, openErr.Until.After(time.Now()))
+	})
+
+	t.Run("dependencies not resolved while open", func(t *testing.T) {
+		depCalls := 0
+		c, err := di.NewContainer(
+			di.WithService(func() testtypes.InterfaceA {
+				depCalls++
+				return &testtypes.StructA{}
+			}, di.Transient),
+			di.WithService(func(testtypes.InterfaceA) (testtypes.InterfaceB, error) {
+				return nil, errors.New("connection refused")
+			}, di.Transient, di.WithCircuitBreaker(1, time.Hour)),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		_, err = di.Resolve[testtypes.InterfaceB](ctx, c)
+		assert.Error(t, err)
+		_, err = di.Resolve[testtypes.InterfaceB](ctx, c)
+		assert.ErrorAs(t, err, new(*di.CircuitOpenError))
+
+		assert.Equal(t, 1, depCalls)
+	})
+
+	t.Run("closes after cooldown", func(t *testing.T) {
+		fail := true
+		clock := ditest.NewFakeClock(time.Now())
+		c, err := di.NewContainer(
+			ditest.WithClock(clock),
+			di.WithService(func() (*testtypes.StructA, error) {
+				if fail {
+					return nil, errors.New("connection refused")
+				}
+				return &testtypes.StructA{}, nil
+			}, di.Transient, di.WithCircuitBreaker(1, time.Minute)),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		_, err = di.Resolve[*testtypes.StructA](ctx, c)
+		assert.Error(t, err)
+
+		clock.Advance(time.Minute)
+		fail = false
+
+		got, err := di.Resolve[*testtypes.StructA](ctx, c)
+		assert.NotNil(t, got)
+		assert.NoError(t, err)
+	})
+
+	t.Run("half-open admits one trial call", func(t *testing.T) {
+		fail := true
+		trialStarted := make(chan struct{})
+		finishTrial := make(chan struct{})
+		var once sync.Once
+		clock := ditest.NewFakeClock(time.Now())
+		c, err := di.NewContainer(
+			ditest.WithClock(clock),
+			di.WithService(func() (*testtypes.StructA, error) {
+				if fail {
+					return nil, errors.New("connection refused")
+				}
+				once.Do(func() {
+					close(trialStarted)
+					<-finishTrial
+				})
+				return &testtypes.StructA{}, nil
+			}, di.Transient, di.WithCircuitBreaker(1, time.Minute)),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		_, err = di.Resolve[*testtypes.StructA](ctx, c)
+		assert.Error(t, err)
+
+		clock.Advance(time.Minute)
+		fail = false
+
+		done := make(chan error)
+		go func() {
+			_, resolveErr := di.Resolve[*testtypes.StructA](ctx, c)
+			done <- resolveErr
+		}()
+		<-trialStarted
+
+		_, err = di.Resolve[*testtypes.StructA](ctx, c)
+		testutils.LogError(t, err)
+		assert.ErrorAs(t, err, new(*di.CircuitOpenError))
+
+		close(finishTrial)
+		require.NoError(t, <-done)
+
+		_, err = di.Resolve[*testtypes.StructA](ctx, c)
+		assert.NoError(t, err)
+	})
+
+	t.Run("trial call dependency error", func(t *testing.T) {
+		depFail := false
+		calls := 0
+		clock := ditest.NewFakeClock(time.Now())
+		c, err := di.NewContainer(
+			ditest.WithClock(clock),
+			di.WithService(func() (testtypes.InterfaceA, error) {
+				if depFail {
+					return nil, errors.New("dependency error")
+				}
+				return &testtypes.StructA{}, nil
+			}, di.Transient),
+			di.WithService(func(testtypes.InterfaceA) (testtypes.InterfaceB, error) {
+				calls++
+				if calls == 1 {
+					return nil, errors.New("connection refused")
+				}
+				return &testtypes.StructB{}, nil
+			}, di.Transient, di.WithCircuitBreaker(1, time.Minute)),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		_, err = di.Resolve[testtypes.InterfaceB](ctx, c)
+		assert.Error(t, err)
+
+		clock.Advance(time.Minute)
+
+		// The trial call fails before the constructor function is called
+		depFail = true
+		_, err = di.Resolve[testtypes.InterfaceB](ctx, c)
+		assert.NotErrorAs(t, err, new(*di.CircuitOpenError))
+
+		// The next call is admitted as a trial call
+		depFail = false
+		_, err = di.Resolve[testtypes.InterfaceB](ctx, c)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("success resets failures", func(t *testing.T) {
+		calls := 0
+		c, err := di.NewContainer(
+			di.WithService(func() (*testtypes.StructA, error) {
+				calls++
+				if calls%2 == 1 {
+					return nil, errors.New("connection refused")
+				}
+				return &testtypes.StructA{}, nil
+			}, di.Transient, di.WithCircuitBreaker(2, time.Hour)),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		for range 4 {
+			_, err = di.Resolve[*testtypes.StructA](ctx, c)
+			assert.NotErrorAs(t, err, new(*di.CircuitOpenError))
+		}
+		assert.Equal(t, 4, calls)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.WithCircuitBreaker(0, time.Second)),
+			di.WithService(testtypes.NewInterfaceB, di.WithCircuitBreaker(1, 0)),
+			di.WithService(&testtypes.StructA{}, di.WithCircuitBreaker(1, time.Second)),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func() testtypes.InterfaceA: "+
+			"WithCircuitBreaker: threshold 0 must be positive\n"+
+			"WithService func(testtypes.InterfaceA) testtypes.InterfaceB: "+
+			"WithCircuitBreaker: cooldown 0s must be positive\n"+
+			"WithService *testtypes.StructA: WithCircuitBreaker: not supported for value service")
+	})
+}
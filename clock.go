@@ -0,0 +1,47 @@
+package di
+
+import (
+	"context"
+	"time"
+)
+
+// Clock provides the current time.
+//
+// A Clock is registered with every [Container] by default, using the system clock.
+// Services should depend on Clock instead of calling [time.Now] directly,
+// so tests can control the current time. See [ditest.FakeClock].
+//
+// The Container also uses the Clock for the expiry of [WithMemo] and [SingletonPer] instances,
+// and the cooldown of [WithCircuitBreaker].
+//
+// Register another implementation to override the default:
+//
+//	di.WithServiceOverride(clock, di.As[di.Clock]())
+//
+// [ditest.FakeClock]: https://pkg.go.dev/github.com/sectrean/di-kit/ditest#FakeClock
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+var _ Clock = systemClock{}
+
+// clock returns the Clock registered with c, used when resolving the service for key.
+// The system clock is used if a Clock can't be resolved, or the service is the Clock.
+func (c *Container) clock(ctx context.Context, key serviceKey, visitor resolveVisitor) Clock {
+	clockKey := serviceKey{Type: typeClock}
+	if key == clockKey {
+		return systemClock{}
+	}
+
+	val, err := resolveLastKey(ctx, c, clockKey, visitor)
+	if clock, ok := val.(Clock); ok && err == nil {
+		return clock
+	}
+
+	return systemClock{}
+}
@@ -0,0 +1,72 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/ditest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fixedClock struct {
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time { return c.now }
+
+func Test_Clock(t *testing.T) {
+	t.Run("registered by default", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		ditest.AssertContains[di.Clock](t, c)
+
+		clock, err := di.Resolve[di.Clock](context.Background(), c)
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), clock.Now(), time.Second)
+	})
+
+	t.Run("override", func(t *testing.T) {
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		c, err := di.NewContainer(
+			di.WithService(fixedClock{now: now}, di.As[di.Clock]()),
+		)
+		require.NoError(t, err)
+
+		clock, err := di.Resolve[di.Clock](context.Background(), c)
+		require.NoError(t, err)
+		assert.Equal(t, now, clock.Now())
+	})
+
+	t.Run("override in child scope", func(t *testing.T) {
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		scope, err := c.NewScope(
+			di.WithService(fixedClock{now: now}, di.As[di.Clock]()),
+		)
+		require.NoError(t, err)
+
+		clock, err := di.Resolve[di.Clock](context.Background(), scope)
+		require.NoError(t, err)
+		assert.Equal(t, now, clock.Now())
+	})
+}
+
+func Test_Rand(t *testing.T) {
+	c, err := di.NewContainer()
+	require.NoError(t, err)
+
+	r, err := di.Resolve[di.Rand](context.Background(), c)
+	require.NoError(t, err)
+
+	n := r.IntN(10)
+	assert.GreaterOrEqual(t, n, 0)
+	assert.Less(t, n, 10)
+}
@@ -0,0 +1,151 @@
+package di
+
+import (
+	"maps"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// WithShuffledClose closes services in a random order when calling [Container.Close],
+// instead of the reverse order they were created.
+//
+// Each service is still closed before the services it depends on.
+// Services that depend on [Scope] are treated as if they depend on every service created before them,
+// since the services they resolve are not known.
+//
+// This is intended for tests, to find code that depends on the order unrelated services are closed.
+// Child scopes inherit this option from the parent Container. See [ditest.WithChaos].
+//
+// This option will return an error if r is nil.
+//
+// [ditest.WithChaos]: https://pkg.go.dev/github.com/sectrean/di-kit/ditest#WithChaos
+func WithShuffledClose(r Rand) ContainerOption {
+	return containerOption(func(c *Container) error {
+		if r == nil {
+			return errors.New("WithShuffledClose: r is nil")
+		}
+
+		c.closeRand = r
+		return nil
+	})
+}
+
+// appendCloser adds the Closer for an instance of svc.
+// The caller must hold a lock on closersMu, unless the Container is being created.
+func (c *Container) appendCloser(closer Closer, svc *service) {
+	c.closers = append(c.closers, closer)
+	c.closerSvcs = append(c.closerSvcs, svc)
+}
+
+// shuffledCloseOrder returns the indexes of the closers in a random order,
+// where each closer comes before the closers of the services it depends on.
+func (c *Container) shuffledCloseOrder() []int {
+	n := len(c.closers)
+
+	// Find the services each closed service depends on, directly or indirectly
+	deps, scopeDeps, ok := c.closeDependencies()
+	if !ok {
+		// Keep the default order if the dependencies have a cycle
+		order := make([]int, n)
+		for i := range order {
+			order[i] = n - 1 - i
+		}
+		return order
+	}
+
+	// dependsOn returns true if closer i must be closed before closer j
+	dependsOn := func(i, j int) bool {
+		svc := c.closerSvcs[i]
+		if svc == c.closerSvcs[j] {
+			return false
+		}
+		if scopeDeps[svc] {
+			return true
+		}
+		_, ok := deps[svc][c.closerSvcs[j]]
+		return ok
+	}
+
+	// Count the closers that must be closed before each closer.
+	// A service can only depend on services created before it.
+	waiting := make([]int, n)
+	for i := range n {
+		for j := range i {
+			if dependsOn(i, j) {
+				waiting[j]++
+			}
+		}
+	}
+
+	var ready []int
+	for i := range n {
+		if waiting[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	order := make([]int, 0, n)
+	for len(ready) > 0 {
+		pick := c.closeRand.IntN(len(ready))
+		i := ready[pick]
+		ready[pick] = ready[len(ready)-1]
+		ready = ready[:len(ready)-1]
+
+		order = append(order, i)
+		for j := range i {
+			if dependsOn(i, j) {
+				waiting[j]--
+				if waiting[j] == 0 {
+					ready = append(ready, j)
+				}
+			}
+		}
+	}
+
+	return order
+}
+
+// closeDependencies returns the services each closed service depends on, directly or indirectly,
+// and whether it or one of its dependencies depends on [Scope].
+// It returns false if the dependencies have a cycle.
+func (c *Container) closeDependencies() (
+	deps map[*service]map[*service]struct{},
+	scopeDeps map[*service]bool,
+	ok bool,
+) {
+	// Sort so each service comes after its dependencies
+	sorter := newTopoSorter()
+	for _, svc := range c.closerSvcs {
+		if err := sorter.Visit(c, svc); err != nil {
+			return nil, nil, false
+		}
+	}
+
+	deps = make(map[*service]map[*service]struct{}, len(sorter.order))
+	scopeDeps = make(map[*service]bool, len(sorter.order))
+	for _, svc := range sorter.order {
+		reachable := make(map[*service]struct{})
+		scopeDep := svc.dependsOnScope()
+
+		for _, depSvc := range svc.resolvedFrom(c).serviceDependencies(svc) {
+			reachable[depSvc] = struct{}{}
+			maps.Copy(reachable, deps[depSvc])
+			scopeDep = scopeDep || scopeDeps[depSvc]
+		}
+
+		deps[svc] = reachable
+		scopeDeps[svc] = scopeDep
+	}
+
+	return deps, scopeDeps, true
+}
+
+// dependsOnScope returns true if svc has a [Scope] dependency that is not bound with [WithArgs].
+func (s *service) dependsOnScope() bool {
+	for i, dep := range s.Dependencies() {
+		if dep.Type == typeScope && !s.arg(i).IsValid() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package di_test
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	shuffleA struct{ closeRecorder }
+	shuffleB struct{ closeRecorder }
+	shuffleC struct{ closeRecorder }
+	shuffleD struct{ closeRecorder }
+)
+
+func Test_WithShuffledClose(t *testing.T) {
+	ctx := context.Background()
+
+	closeOrder := func(t *testing.T, seed uint64, opts ...di.ContainerOption) []string {
+		t.Helper()
+
+		var closed []string
+		c, err := di.NewContainer(append([]di.ContainerOption{
+			di.WithService(func() shuffleA {
+				return shuffleA{closeRecorder{closed: &closed, name: "A"}}
+			}),
+			di.WithService(func(shuffleA) shuffleB {
+				return shuffleB{closeRecorder{closed: &closed, name: "B"}}
+			}),
+			di.WithService(func() shuffleC {
+				return shuffleC{closeRecorder{closed: &closed, name: "C"}}
+			}),
+			di.WithService(func(di.Scope) shuffleD {
+				return shuffleD{closeRecorder{closed: &closed, name: "D"}}
+			}),
+			di.WithShuffledClose(rand.New(rand.NewPCG(seed, seed))),
+		}, opts...)...)
+		require.NoError(t, err)
+
+		_ = di.MustResolve[shuffleB](ctx, c)
+		_ = di.MustResolve[shuffleC](ctx, c)
+		_ = di.MustResolve[shuffleD](ctx, c)
+
+		err = c.Close(ctx)
+		require.NoError(t, err)
+
+		return closed
+	}
+
+	t.Run("dependencies closed last", func(t *testing.T) {
+		orders := make(map[string]bool)
+		for seed := range uint64(20) {
+			closed := closeOrder(t, seed)
+			require.Len(t, closed, 4)
+
+			// D depends on Scope, so it is closed first
+			assert.Equal(t, "D", closed[0])
+			assert.Less(t, slices.Index(closed, "B"), slices.Index(closed, "A"))
+
+			orders[closed[1]+closed[2]+closed[3]] = true
+		}
+
+		assert.Greater(t, len(orders), 1)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithShuffledClose(nil),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithShuffledClose: r is nil")
+	})
+}
@@ -3,6 +3,7 @@ package di
 import (
 	"context"
 	"reflect"
+	"time"
 
 	"github.com/sectrean/di-kit/internal/errors"
 )
@@ -27,6 +28,7 @@ import (
 // the code that creates them is responsible for closing them.
 // Use the [UseCloser] option to automatically close a value service when the [Container] is closed.
 //
+// Use the [AutoCloser] option to close a service with a Shutdown or Stop method.
 // Use the [UseCloseFunc] option to specify a custom function to close a service.
 type Closer interface {
 	// Close resources owned by the service.
@@ -36,6 +38,7 @@ type Closer interface {
 // IgnoreCloser configures the [Container] to ignore if the service has a Close method when closing the Container.
 //
 // Use this option if a function service has a Close method, but you don't want the Container to call it.
+// A cleanup function returned by the constructor function is still called.
 // See [Closer] for more information.
 func IgnoreCloser() ServiceOption {
 	return serviceOption(func(s *service) error {
@@ -55,10 +58,36 @@ func UseCloser() ServiceOption {
 	})
 }
 
+// AutoCloser configures the [Container] to close the service with a Shutdown or Stop method
+// when the Container is closed, as well as a Close method.
+//
+// This saves using [UseCloseFunc] for types like [net/http.Server], grpc.Server, and background workers.
+// The first method found is used, in this order:
+//
+//	Shutdown(context.Context) error
+//	Shutdown(context.Context)
+//	Shutdown() error
+//	Shutdown()
+//	Close signatures supported by [Closer]
+//	Stop(context.Context) error
+//	Stop(context.Context)
+//	Stop() error
+//	Stop()
+//
+// Shutdown is preferred over Close, since types like [net/http.Server] use Close to stop immediately.
+// This option can be used with function or value services.
+// See [Closer] for more information.
+func AutoCloser() ServiceOption {
+	return serviceOption(func(s *service) error {
+		s.closerFactory = getAutoCloser
+		return nil
+	})
+}
+
 // UseCloseFunc configures a custom function to call to close the service when the [Container] is closed.
 //
-// This is useful if a service has a method called Shutdown or Stop instead of Close that should be
-// used to close the service.
+// This is useful if a service needs to be closed some other way than calling a method.
+// Use [AutoCloser] if the service has a Shutdown or Stop method.
 //
 // Example:
 //
@@ -85,6 +114,125 @@ func UseCloseFunc[Service any](f func(context.Context, Service) error) ServiceOp
 	})
 }
 
+// DefaultCloseGracePeriod is a reasonable grace period to use with [CloseWithGrace].
+const DefaultCloseGracePeriod = 5 * time.Second
+
+// CloseWithGrace closes c with a context that is not canceled when ctx is canceled,
+// but has a deadline of grace from now.
+//
+// This is useful for closing a request or job scope after the request has been canceled,
+// when cleanup must still run, but not indefinitely. Values from ctx are preserved.
+//
+// If grace is not positive, the context passed to Close has no deadline.
+//
+// Example:
+//
+//	defer func() {
+//		err := di.CloseWithGrace(ctx, scope, di.DefaultCloseGracePeriod)
+//		// ...
+//	}()
+func CloseWithGrace(ctx context.Context, c Closer, grace time.Duration) error {
+	ctx = context.WithoutCancel(ctx)
+
+	if grace > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, grace)
+		defer cancel()
+	}
+
+	return c.Close(ctx)
+}
+
+// CloseOnDone closes c with [CloseWithGrace] and [DefaultCloseGracePeriod] when ctx is done.
+//
+// This is useful for adapters where a scope lives as long as a context, but there is no single
+// place to close it, like a server-sent events stream, a streaming RPC, or a long poll.
+// c is closed in its own goroutine using [context.AfterFunc]. If ctx is already done, c is closed immediately.
+//
+// If closing returns an error, errHandler is called with the error. errHandler may be nil.
+//
+// The returned stop function stops c from being closed when ctx is done.
+// It returns false if c has already started closing, or if stop has already been called.
+//
+// Example:
+//
+//	scope, err := c.NewScope()
+//	if err != nil {
+//		return err
+//	}
+//	di.CloseOnDone(stream.Context(), scope, func(err error) {
+//		logger.Error("closing stream scope", "error", err)
+//	})
+func CloseOnDone(ctx context.Context, c Closer, errHandler func(error)) (stop func() bool) {
+	return context.AfterFunc(ctx, func() {
+		err := CloseWithGrace(ctx, c, DefaultCloseGracePeriod)
+		if err != nil && errHandler != nil {
+			errHandler(err)
+		}
+	})
+}
+
+// WithCloserContext sets a function that returns the base context passed to each [Closer]
+// when calling [NewContainer] or [Container.NewScope].
+//
+// By default, closers receive the context passed to [Container.Close], and instances evicted
+// from a [SingletonPer] cache are closed in the background with the context passed to Resolve, without cancellation.
+// Use this option to give every closer the same context, with values like a logger or trace span.
+//
+// When the Container is closed, the context returned by f is canceled if the context passed to Close is canceled.
+// Deadlines and values from the context passed to Close are not passed along.
+// Instances closed in the background receive the context returned by f as-is.
+//
+// Child scopes inherit this option from the parent Container.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithCloserContext(func() context.Context {
+//			return log.WithLogger(context.Background(), shutdownLogger)
+//		}),
+//	)
+//
+// This option will return an error if f is nil.
+func WithCloserContext(f func() context.Context) ContainerOption {
+	return containerOption(func(c *Container) error {
+		if f == nil {
+			return errors.New("WithCloserContext: f is nil")
+		}
+
+		c.closerCtx = f
+		return nil
+	})
+}
+
+// closeContext returns the context passed to closers when the Container is closed with ctx.
+// The returned function must be called when closing is done.
+func (c *Container) closeContext(ctx context.Context) (closeCtx context.Context, done func()) {
+	if c.closerCtx == nil {
+		return ctx, func() {}
+	}
+
+	closeCtx, cancel := context.WithCancelCause(c.closerCtx())
+	stop := context.AfterFunc(ctx, func() {
+		cancel(context.Cause(ctx))
+	})
+
+	return closeCtx, func() {
+		stop()
+		cancel(nil)
+	}
+}
+
+// backgroundCloseContext returns the context passed to closers called in the background
+// while resolving a service with ctx.
+func (c *Container) backgroundCloseContext(ctx context.Context) context.Context {
+	if c.closerCtx == nil {
+		return context.WithoutCancel(ctx)
+	}
+
+	return c.closerCtx()
+}
+
 // getCloser returns the Closer interface if the given value implements it,
 // or any of the compatible Close function signatures.
 func getCloser(val any) Closer {
@@ -103,6 +251,54 @@ func getCloser(val any) Closer {
 	}
 }
 
+// getAutoCloser returns a Closer for the value's Shutdown, Close, or Stop method, in that order.
+func getAutoCloser(val any) Closer {
+	switch c := val.(type) {
+	case interface{ Shutdown(context.Context) error }:
+		return closeFunc(c.Shutdown)
+	case interface{ Shutdown(context.Context) }:
+		return closeFunc(func(ctx context.Context) error {
+			c.Shutdown(ctx)
+			return nil
+		})
+	case interface{ Shutdown() error }:
+		return closeFunc(func(context.Context) error {
+			return c.Shutdown()
+		})
+	case interface{ Shutdown() }:
+		return closeFunc(func(context.Context) error {
+			c.Shutdown()
+			return nil
+		})
+	}
+
+	if closer := getCloser(val); closer != nil {
+		return closer
+	}
+
+	switch c := val.(type) {
+	case interface{ Stop(context.Context) error }:
+		return closeFunc(c.Stop)
+	case interface{ Stop(context.Context) }:
+		return closeFunc(func(ctx context.Context) error {
+			c.Stop(ctx)
+			return nil
+		})
+	case interface{ Stop() error }:
+		return closeFunc(func(context.Context) error {
+			return c.Stop()
+		})
+	case interface{ Stop() }:
+		return closeFunc(func(context.Context) error {
+			c.Stop()
+			return nil
+		})
+
+	default:
+		return nil
+	}
+}
+
 type closerWithContextNoError interface {
 	Close(ctx context.Context)
 }
@@ -141,6 +337,11 @@ func (w closerNoContextWithErrorWrapper) Close(context.Context) error {
 	return w.c.Close()
 }
 
+// noCloser is the closerFactory for a service that is only closed by a cleanup function.
+func noCloser(any) Closer {
+	return nil
+}
+
 type closeFunc func(context.Context) error
 
 func (f closeFunc) Close(ctx context.Context) error {
@@ -0,0 +1,493 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/mocks"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CloseWithGrace(t *testing.T) {
+	t.Run("canceled context", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() testtypes.InterfaceA {
+				a := mocks.NewInterfaceAMock(t)
+				a.EXPECT().
+					Close(mock.Anything).
+					RunAndReturn(func(ctx context.Context) error {
+						assert.NoError(t, ctx.Err())
+						assert.Equal(t, "value", testutils.TestValue(ctx))
+
+						deadline, ok := ctx.Deadline()
+						assert.True(t, ok)
+						assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+						return nil
+					})
+				return a
+			}),
+		)
+		require.NoError(t, err)
+
+		ctx := testutils.ContextWithTestValue(context.Background(), "value")
+		_ = di.MustResolve[testtypes.InterfaceA](ctx, c)
+
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		err = di.CloseWithGrace(ctx, c, time.Minute)
+		assert.NoError(t, err)
+	})
+
+	t.Run("no grace period", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() testtypes.InterfaceA {
+				a := mocks.NewInterfaceAMock(t)
+				a.EXPECT().
+					Close(mock.Anything).
+					RunAndReturn(func(ctx context.Context) error {
+						_, ok := ctx.Deadline()
+						assert.False(t, ok)
+						return errors.New("close error")
+					})
+				return a
+			}),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		_ = di.MustResolve[testtypes.InterfaceA](ctx, c)
+
+		err = di.CloseWithGrace(ctx, c, 0)
+		assert.EqualError(t, err, "di.Container.Close: close error")
+	})
+}
+
+func Test_CloseOnDone(t *testing.T) {
+	t.Run("context canceled", func(t *testing.T) {
+		closed := make(chan struct{})
+		c, err := di.NewContainer(
+			di.WithService(func() testtypes.InterfaceA {
+				a := mocks.NewInterfaceAMock(t)
+				a.EXPECT().
+					Close(mock.Anything).
+					RunAndReturn(func(ctx context.Context) error {
+						assert.NoError(t, ctx.Err())
+						assert.Equal(t, "value", testutils.TestValue(ctx))
+
+						_, ok := ctx.Deadline()
+						assert.True(t, ok)
+
+						close(closed)
+						return nil
+					})
+				return a
+			}),
+		)
+		require.NoError(t, err)
+
+		ctx := testutils.ContextWithTestValue(context.Background(), "value")
+		ctx, cancel := context.WithCancel(ctx)
+		_ = di.MustResolve[testtypes.InterfaceA](ctx, c)
+
+		di.CloseOnDone(ctx, c, func(err error) {
+			assert.Fail(t, "unexpected error", err)
+		})
+		cancel()
+
+		select {
+		case <-closed:
+		case <-time.After(time.Second):
+			assert.Fail(t, "container not closed")
+		}
+	})
+
+	t.Run("close error", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() testtypes.InterfaceA {
+				a := mocks.NewInterfaceAMock(t)
+				a.EXPECT().
+					Close(mock.Anything).
+					Return(errors.New("close error"))
+				return a
+			}),
+		)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		_ = di.MustResolve[testtypes.InterfaceA](ctx, c)
+
+		errs := make(chan error, 1)
+		di.CloseOnDone(ctx, c, func(err error) {
+			errs <- err
+		})
+		cancel()
+
+		select {
+		case err := <-errs:
+			testutils.LogError(t, err)
+			assert.EqualError(t, err, "di.Container.Close: close error")
+		case <-time.After(time.Second):
+			assert.Fail(t, "error handler not called")
+		}
+	})
+
+	t.Run("nil error handler", func(t *testing.T) {
+		closed := make(chan struct{})
+		c, err := di.NewContainer(
+			di.WithService(func() testtypes.InterfaceA {
+				a := mocks.NewInterfaceAMock(t)
+				a.EXPECT().
+					Close(mock.Anything).
+					RunAndReturn(func(context.Context) error {
+						close(closed)
+						return errors.New("close error")
+					})
+				return a
+			}),
+		)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		_ = di.MustResolve[testtypes.InterfaceA](ctx, c)
+
+		di.CloseOnDone(ctx, c, nil)
+		cancel()
+
+		select {
+		case <-closed:
+		case <-time.After(time.Second):
+			assert.Fail(t, "container not closed")
+		}
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() testtypes.InterfaceA {
+				return mocks.NewInterfaceAMock(t)
+			}),
+		)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		_ = di.MustResolve[testtypes.InterfaceA](ctx, c)
+
+		stop := di.CloseOnDone(ctx, c, nil)
+		assert.True(t, stop())
+		assert.False(t, stop())
+		cancel()
+
+		_, err = di.Resolve[testtypes.InterfaceA](context.Background(), c)
+		assert.NoError(t, err)
+	})
+}
+
+type shutdowner struct {
+	calls []string
+}
+
+func (s *shutdowner) Shutdown(context.Context) error {
+	s.calls = append(s.calls, "Shutdown")
+	return nil
+}
+
+func (s *shutdowner) Close() error {
+	s.calls = append(s.calls, "Close")
+	return nil
+}
+
+type stopper struct {
+	calls []string
+}
+
+func (s *stopper) Stop() {
+	s.calls = append(s.calls, "Stop")
+}
+
+type stopperWithError struct{}
+
+func (stopperWithError) Stop(context.Context) error {
+	return errors.New("stop error")
+}
+
+func Test_AutoCloser(t *testing.T) {
+	t.Run("Shutdown preferred over Close", func(t *testing.T) {
+		s := &shutdowner{}
+		c, err := di.NewContainer(
+			di.WithService(func() *shutdowner { return s }, di.AutoCloser()),
+		)
+		require.NoError(t, err)
+
+		_ = di.MustResolve[*shutdowner](context.Background(), c)
+
+		err = c.Close(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Shutdown"}, s.calls)
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		s := &stopper{}
+		c, err := di.NewContainer(
+			di.WithService(s, di.AutoCloser()),
+		)
+		require.NoError(t, err)
+
+		err = c.Close(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Stop"}, s.calls)
+	})
+
+	t.Run("Stop error", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(stopperWithError{}, di.AutoCloser()),
+		)
+		require.NoError(t, err)
+
+		err = c.Close(context.Background())
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Close: stop error")
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() testtypes.InterfaceA {
+				a := mocks.NewInterfaceAMock(t)
+				a.EXPECT().Close(mock.Anything).Return(nil)
+				return a
+			}, di.AutoCloser()),
+		)
+		require.NoError(t, err)
+
+		_ = di.MustResolve[testtypes.InterfaceA](context.Background(), c)
+
+		err = c.Close(context.Background())
+		assert.NoError(t, err)
+	})
+
+	t.Run("no method", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(&testtypes.StructA{}, di.AutoCloser()),
+		)
+		require.NoError(t, err)
+
+		err = c.Close(context.Background())
+		assert.NoError(t, err)
+	})
+}
+
+func Test_WithCloserContext(t *testing.T) {
+	closerCtx := func() context.Context {
+		return testutils.ContextWithTestValue(context.Background(), "closer")
+	}
+
+	t.Run("Close", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithCloserContext(closerCtx),
+			di.WithService(func() testtypes.InterfaceA {
+				a := mocks.NewInterfaceAMock(t)
+				a.EXPECT().
+					Close(mock.Anything).
+					RunAndReturn(func(ctx context.Context) error {
+						assert.Equal(t, "closer", testutils.TestValue(ctx))
+						assert.NoError(t, ctx.Err())
+						return nil
+					})
+				return a
+			}),
+		)
+		require.NoError(t, err)
+
+		_ = di.MustResolve[testtypes.InterfaceA](context.Background(), c)
+
+		ctx := testutils.ContextWithTestValue(context.Background(), "close")
+		err = c.Close(ctx)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Close canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		c, err := di.NewContainer(
+			di.WithCloserContext(closerCtx),
+			di.WithService(func() testtypes.InterfaceA {
+				a := mocks.NewInterfaceAMock(t)
+				a.EXPECT().
+					Close(mock.Anything).
+					RunAndReturn(func(closeCtx context.Context) error {
+						assert.Equal(t, "closer", testutils.TestValue(closeCtx))
+						<-closeCtx.Done()
+						return closeCtx.Err()
+					})
+				return a
+			}),
+		)
+		require.NoError(t, err)
+
+		_ = di.MustResolve[testtypes.InterfaceA](context.Background(), c)
+
+		err = c.Close(ctx)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Close: context canceled")
+	})
+
+	t.Run("child scope inherits", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithCloserContext(closerCtx),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope(
+			di.WithService(func() testtypes.InterfaceA {
+				a := mocks.NewInterfaceAMock(t)
+				a.EXPECT().
+					Close(mock.Anything).
+					RunAndReturn(func(ctx context.Context) error {
+						assert.Equal(t, "closer", testutils.TestValue(ctx))
+						return nil
+					})
+				return a
+			}),
+		)
+		require.NoError(t, err)
+
+		_ = di.MustResolve[testtypes.InterfaceA](context.Background(), scope)
+
+		err = scope.Close(context.Background())
+		assert.NoError(t, err)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithCloserContext(nil),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithCloserContext: f is nil")
+	})
+}
+
+func Test_CleanupFunc(t *testing.T) {
+	ctx := context.Background()
+
+	newRecorder := func(closed *[]string, name string) func() (closeRecorder, func(), error) {
+		return func() (closeRecorder, func(), error) {
+			r := closeRecorder{closed: closed, name: name}
+			return r, func() { *closed = append(*closed, name+" cleanup") }, nil
+		}
+	}
+
+	t.Run("called in reverse order", func(t *testing.T) {
+		var closed []string
+		c, err := di.NewContainer(
+			di.WithService(newRecorder(&closed, "first"), di.WithTag("first")),
+			di.WithService(newRecorder(&closed, "second"), di.WithTag("second")),
+		)
+		require.NoError(t, err)
+
+		_ = di.MustResolve[closeRecorder](ctx, c, di.WithTag("first"))
+		_ = di.MustResolve[closeRecorder](ctx, c, di.WithTag("second"))
+
+		err = c.Close(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"second cleanup", "first cleanup"}, closed)
+	})
+
+	t.Run("not resolved", func(t *testing.T) {
+		var closed []string
+		c, err := di.NewContainer(
+			di.WithService(newRecorder(&closed, "first")),
+		)
+		require.NoError(t, err)
+
+		err = c.Close(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, closed)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		cleanups := 0
+		c, err := di.NewContainer(
+			di.WithService(func() (testtypes.InterfaceA, func(), error) {
+				return nil, func() { cleanups++ }, errors.New("constructor error")
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve testtypes.InterfaceA: constructor error")
+
+		err = c.Close(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, cleanups)
+	})
+
+	t.Run("nil cleanup", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() (testtypes.InterfaceA, func(), error) {
+				return testtypes.StructA{}, nil, nil
+			}),
+		)
+		require.NoError(t, err)
+
+		_ = di.MustResolve[testtypes.InterfaceA](ctx, c)
+
+		err = c.Close(ctx)
+		require.NoError(t, err)
+	})
+
+	t.Run("IgnoreCloser", func(t *testing.T) {
+		var closed []string
+		c, err := di.NewContainer(
+			di.WithService(newRecorder(&closed, "first"), di.UseCloser(), di.IgnoreCloser()),
+		)
+		require.NoError(t, err)
+
+		_ = di.MustResolve[closeRecorder](ctx, c)
+
+		// The Close method is ignored, but the cleanup function is called
+		err = c.Close(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"first cleanup"}, closed)
+	})
+
+	t.Run("UseCloser", func(t *testing.T) {
+		var closed []string
+		c, err := di.NewContainer(
+			di.WithService(newRecorder(&closed, "first"), di.UseCloser()),
+		)
+		require.NoError(t, err)
+
+		_ = di.MustResolve[closeRecorder](ctx, c)
+
+		err = c.Close(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"first", "first cleanup"}, closed)
+	})
+
+	t.Run("Scoped", func(t *testing.T) {
+		var closed []string
+		c, err := di.NewContainer(
+			di.WithService(newRecorder(&closed, "scoped"), di.Scoped),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope()
+		require.NoError(t, err)
+
+		_ = di.MustResolve[closeRecorder](ctx, scope)
+
+		err = scope.Close(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"scoped cleanup"}, closed)
+	})
+}
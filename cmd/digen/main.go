@@ -0,0 +1,70 @@
+// Command digen generates di-kit registrations from Go source code.
+//
+// Usage:
+//
+//	digen -autobind [-o file] [-var name] [packages]
+//
+// With -autobind, digen writes a file to the package in the current directory, declaring a di.NamedModule
+// with a di.Bind option for each exported interface with exactly one exported implementation,
+// and a di.WithWiringChecksum option with the checksum of the bindings.
+// By default, all packages in the current module are scanned.
+//
+// Example:
+//
+//	//go:generate go run github.com/sectrean/di-kit/cmd/digen -autobind
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sectrean/di-kit/internal/codegen"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	fs := flag.NewFlagSet("digen", flag.ContinueOnError)
+	autobind := fs.Bool("autobind", false, "generate di.Bind options for interfaces with exactly one implementation")
+	out := fs.String("o", "autobind_gen.go", "output file")
+	varName := fs.String("var", "AutoBindings", "name of the generated di.Module variable")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if !*autobind {
+		fmt.Fprintln(os.Stderr, "digen: no mode specified; use -autobind")
+		fs.Usage()
+		return 2
+	}
+
+	src, err := codegen.Autobind(".", *varName, fs.Args()...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "digen:", err)
+		return 1
+	}
+
+	if err := writeFile(*out, src); err != nil {
+		fmt.Fprintln(os.Stderr, "digen:", err)
+		return 1
+	}
+
+	return 0
+}
+
+// writeFile writes src to a new or truncated file, with the default permissions for the umask.
+func writeFile(name string, src []byte) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(src)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
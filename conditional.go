@@ -0,0 +1,50 @@
+package di
+
+import (
+	"context"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// WithServiceIf registers a service like [WithService], only if cond is true.
+//
+// This allows a module to include registrations enabled by feature flags or build configuration.
+//
+// Example:
+//
+//	var Dependencies = di.Module{
+//		di.WithService(storage.NewDBStore, di.As[storage.Store]()),
+//		di.WithServiceIf(config.CacheEnabled, cache.NewCachedStore, di.As[storage.Store]()),
+//	}
+//
+// The service is not validated if cond is false.
+func WithServiceIf(cond bool, funcOrValue any, opts ...ServiceOption) ContainerOption {
+	if !cond {
+		return Module{}
+	}
+
+	return WithService(funcOrValue, opts...)
+}
+
+// WithServiceWhen registers a service like [WithService], only if cond returns true.
+//
+// The function is called once when the option is applied during [NewContainer] or [Container.NewScope],
+// with [context.Background]. Use this when the condition is only known at runtime, like a feature flag.
+//
+// Example:
+//
+//	di.WithServiceWhen(flags.Enabled("new-search"), search.NewIndex)
+//
+// This option will return an error if cond is nil.
+func WithServiceWhen(cond func(ctx context.Context) bool, funcOrValue any, opts ...ServiceOption) ContainerOption {
+	return containerOption(func(c *Container) error {
+		if cond == nil {
+			return errors.New("WithServiceWhen: cond is nil")
+		}
+		if !cond(context.Background()) {
+			return nil
+		}
+
+		return WithService(funcOrValue, opts...).applyContainer(c)
+	})
+}
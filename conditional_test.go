@@ -0,0 +1,79 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithServiceIf(t *testing.T) {
+	t.Run("true", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithServiceIf(true, testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+		assert.True(t, c.Contains(testtypes.TypeInterfaceA))
+	})
+
+	t.Run("false", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithServiceIf(false, testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+		assert.False(t, c.Contains(testtypes.TypeInterfaceA))
+	})
+
+	t.Run("false not validated", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithServiceIf(false, nil),
+		)
+		require.NoError(t, err)
+		assert.NotNil(t, c)
+	})
+}
+
+func Test_WithServiceWhen(t *testing.T) {
+	t.Run("true", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithServiceWhen(func(ctx context.Context) bool {
+				assert.NotNil(t, ctx)
+				return true
+			}, testtypes.NewInterfaceA, di.WithTag("tag")),
+		)
+		require.NoError(t, err)
+		assert.True(t, c.Contains(testtypes.TypeInterfaceA, di.WithTag("tag")))
+	})
+
+	t.Run("false", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithServiceWhen(func(context.Context) bool { return false }, testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+		assert.False(t, c.Contains(testtypes.TypeInterfaceA))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithServiceWhen(func(context.Context) bool { return true }, nil),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService: funcOrValue is nil")
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithServiceWhen(nil, testtypes.NewInterfaceA),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithServiceWhen: cond is nil")
+	})
+}
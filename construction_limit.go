@@ -0,0 +1,53 @@
+package di
+
+import (
+	"context"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// WithMaxConcurrentConstructions limits the number of concurrent calls to the constructor function
+// of a [Transient] or [Scoped] service when calling [WithService].
+//
+// This is useful for services whose constructor functions call a rate-limited backend.
+// Resolving the service waits until another call has returned, or the context is canceled.
+// The limit applies to the service across all scopes.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithService(auth.ExchangeToken, // ExchangeToken(context.Context, *auth.Client) (auth.Token, error)
+//			di.Transient,
+//			di.WithMaxConcurrentConstructions(4),
+//		),
+//	)
+//
+// This option will return an error if n is not positive, or the service is not [Transient] or [Scoped].
+func WithMaxConcurrentConstructions(n int) ServiceOption {
+	return serviceOption(func(s *service) error {
+		if n <= 0 {
+			return errors.Errorf("WithMaxConcurrentConstructions %d: limit must be positive", n)
+		}
+
+		s.constructions = make(constructionLimit, n)
+		return nil
+	})
+}
+
+// constructionLimit is a semaphore for calls to a constructor function.
+type constructionLimit chan struct{}
+
+// Acquire waits for a slot to call the constructor function, or the context to be canceled.
+func (l constructionLimit) Acquire(ctx context.Context) error {
+	select {
+	case l <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Release the slot acquired with Acquire.
+func (l constructionLimit) Release() {
+	<-l
+}
@@ -0,0 +1,168 @@
+package di_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithMaxConcurrentConstructions(t *testing.T) {
+	t.Run("limits concurrent calls", func(t *testing.T) {
+		var running, maxRunning atomic.Int32
+
+		c, err := di.NewContainer(
+			di.WithService(func() *testtypes.StructA {
+				n := running.Add(1)
+				defer running.Add(-1)
+
+				for {
+					m := maxRunning.Load()
+					if n <= m || maxRunning.CompareAndSwap(m, n) {
+						break
+					}
+				}
+
+				time.Sleep(time.Millisecond)
+				return &testtypes.StructA{}
+			},
+				di.Transient,
+				di.WithMaxConcurrentConstructions(2),
+			),
+		)
+		require.NoError(t, err)
+
+		testutils.RunParallel(20, func(int) {
+			_, resolveErr := di.Resolve[*testtypes.StructA](context.Background(), c)
+			assert.NoError(t, resolveErr)
+		})
+
+		assert.LessOrEqual(t, maxRunning.Load(), int32(2))
+	})
+
+	t.Run("context canceled while waiting", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		var once sync.Once
+
+		c, err := di.NewContainer(
+			di.WithService(func() *testtypes.StructA {
+				once.Do(func() { close(started) })
+				<-release
+				return &testtypes.StructA{}
+			},
+				di.Transient,
+				di.WithMaxConcurrentConstructions(1),
+			),
+		)
+		require.NoError(t, err)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, resolveErr := di.Resolve[*testtypes.StructA](context.Background(), c)
+			assert.NoError(t, resolveErr)
+		}()
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err = di.Resolve[*testtypes.StructA](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve *testtypes.StructA: context deadline exceeded")
+
+		close(release)
+		<-done
+	})
+
+	t.Run("Scoped", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.Scoped, di.WithMaxConcurrentConstructions(1)),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope()
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](context.Background(), scope)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Scoped waits without locking the scope", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		var once sync.Once
+
+		c, err := di.NewContainer(
+			di.WithService(func() testtypes.InterfaceA {
+				once.Do(func() {
+					close(started)
+					<-release
+				})
+				return &testtypes.StructA{}
+			}, di.Scoped, di.WithMaxConcurrentConstructions(1)),
+			di.WithService(func() testtypes.InterfaceC { return testtypes.StructC{} }, di.Scoped),
+		)
+		require.NoError(t, err)
+
+		scope1, err := c.NewScope()
+		require.NoError(t, err)
+		scope2, err := c.NewScope()
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		done := make(chan struct{}, 2)
+		go func() {
+			_, resolveErr := di.Resolve[testtypes.InterfaceA](ctx, scope1)
+			assert.NoError(t, resolveErr)
+			done <- struct{}{}
+		}()
+		<-started
+
+		// Waits for the slot held by scope1
+		go func() {
+			_, resolveErr := di.Resolve[testtypes.InterfaceA](ctx, scope2)
+			assert.NoError(t, resolveErr)
+			done <- struct{}{}
+		}()
+		time.Sleep(5 * time.Millisecond)
+
+		// Other services can still be created in scope2
+		_, err = di.Resolve[testtypes.InterfaceC](ctx, scope2)
+		assert.NoError(t, err)
+
+		close(release)
+		<-done
+		<-done
+	})
+
+	t.Run("Singleton", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.WithMaxConcurrentConstructions(1)),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func() testtypes.InterfaceA: "+
+			"WithMaxConcurrentConstructions 1: service must be Transient or Scoped")
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.Transient, di.WithMaxConcurrentConstructions(0)),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func() testtypes.InterfaceA: "+
+			"WithMaxConcurrentConstructions 0: limit must be positive")
+	})
+}
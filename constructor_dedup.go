@@ -0,0 +1,77 @@
+package di
+
+import (
+	"reflect"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// WithConstructorDedup skips registering a constructor function that is already registered,
+// when calling [NewContainer] or [Container.NewScope].
+//
+// By default, registering the same constructor function twice creates two services,
+// so a Singleton is created twice and a slice includes it twice. This happens when modules
+// are composed in a diamond shape, where two modules both include a shared module.
+// With this option, a function service is not registered if the same function is already registered
+// with the Container with the same types, tags, and lifetime, so both registrations resolve to the same instance.
+//
+// This applies to the services registered after this option, which are compared with all the services
+// registered earlier. Pass it before the modules, or put it at the start of a shared Module.
+// It does not apply to value services, services registered with [Replace],
+// or services registered with a parent Container.
+//
+// Only top-level functions are compared. Closures and method values are never skipped,
+// since two closures created from the same function literal, like in a loop,
+// can capture different values, and two method values can have different receivers.
+//
+// Example:
+//
+//	var Common = di.Module{
+//		di.WithService(NewLogger),
+//	}
+//
+//	c, err := di.NewContainer(
+//		di.WithConstructorDedup(),
+//		di.Module{Common, di.WithService(NewDB)},
+//		di.Module{Common, di.WithService(NewCache)}, // NewLogger is only registered once
+//	)
+func WithConstructorDedup() ContainerOption {
+	return containerOption(func(c *Container) error {
+		c.constructorDedup = true
+		return nil
+	})
+}
+
+// isDuplicateConstructor returns true if the function of s is already registered with the Container
+// with the same keys and lifetime.
+func (c *Container) isDuplicateConstructor(s *service) bool {
+	if !c.constructorDedup || s.IsValue() || s.replace || s.name != "" || !isTopLevelFunc(s.v) {
+		return false
+	}
+
+	keys := s.Keys()
+	ptr := s.v.Pointer()
+	return slices.ContainsFunc(c.registered, func(r *service) bool {
+		return !r.IsValue() && r.name == "" &&
+			r.v.Pointer() == ptr &&
+			r.lifetime == s.lifetime &&
+			slices.Equal(r.Keys(), keys)
+	})
+}
+
+// closureName matches the names the compiler gives to function literals, like "pkg.Func.func1".
+var closureName = regexp.MustCompile(`\.func\d+(\.|$)`)
+
+// isTopLevelFunc returns true if fn is a top-level function or method expression,
+// and not a closure or method value, which share their code with other functions that capture different values.
+func isTopLevelFunc(fn reflect.Value) bool {
+	f := runtime.FuncForPC(fn.Pointer())
+	if f == nil {
+		return false
+	}
+
+	name := f.Name()
+	return !strings.HasSuffix(name, "-fm") && !closureName.MatchString(name)
+}
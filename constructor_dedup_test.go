@@ -0,0 +1,156 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithConstructorDedup(t *testing.T) {
+	ctx := context.Background()
+
+	common := di.Module{
+		di.WithService(testtypes.NewInterfaceA),
+	}
+
+	t.Run("diamond modules", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithConstructorDedup(),
+			di.Module{common, di.WithService(testtypes.NewInterfaceB)},
+			di.Module{common, di.WithService(testtypes.NewInterfaceC)},
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceC](ctx, c)
+		require.NoError(t, err)
+
+		all, err := di.ResolveAll[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Len(t, all, 1)
+	})
+
+	t.Run("closures", func(t *testing.T) {
+		var opts []di.ContainerOption
+		for _, tag := range []string{"a", "b", "c"} {
+			opts = append(opts, di.WithService(func() testtypes.InterfaceA {
+				return testtypes.StructA{Tag: tag}
+			}))
+		}
+
+		c, err := di.NewContainer(
+			di.WithConstructorDedup(),
+			di.Module(opts),
+		)
+		require.NoError(t, err)
+
+		all, err := di.ResolveAll[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []testtypes.InterfaceA{
+			testtypes.StructA{Tag: "a"},
+			testtypes.StructA{Tag: "b"},
+			testtypes.StructA{Tag: "c"},
+		}, all)
+	})
+
+	t.Run("method values", func(t *testing.T) {
+		f1 := &testtypes.Factory{}
+		f2 := &testtypes.Factory{}
+		c, err := di.NewContainer(
+			di.WithConstructorDedup(),
+			di.WithService(f1.NewStructA),
+			di.WithService(f2.NewStructA),
+		)
+		require.NoError(t, err)
+
+		all, err := di.ResolveAll[*testtypes.StructA](ctx, c)
+		require.NoError(t, err)
+		assert.Len(t, all, 2)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		c, err := di.NewContainer(
+			common,
+			common,
+		)
+		require.NoError(t, err)
+
+		all, err := di.ResolveAll[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Len(t, all, 2)
+	})
+
+	t.Run("registered before option", func(t *testing.T) {
+		c, err := di.NewContainer(
+			common,
+			di.WithConstructorDedup(),
+			common,
+		)
+		require.NoError(t, err)
+
+		all, err := di.ResolveAll[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Len(t, all, 1)
+	})
+
+	t.Run("option last", func(t *testing.T) {
+		c, err := di.NewContainer(
+			common,
+			common,
+			di.WithConstructorDedup(),
+		)
+		require.NoError(t, err)
+
+		all, err := di.ResolveAll[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Len(t, all, 2)
+	})
+
+	t.Run("different options", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithConstructorDedup(),
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(testtypes.NewInterfaceA, di.Transient),
+			di.WithService(testtypes.NewInterfaceA, di.WithTag("a")),
+		)
+		require.NoError(t, err)
+
+		all, err := di.ResolveAll[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Len(t, all, 2)
+	})
+
+	t.Run("value services", func(t *testing.T) {
+		a := &testtypes.StructA{}
+		c, err := di.NewContainer(
+			di.WithConstructorDedup(),
+			di.WithService(a),
+			di.WithService(a),
+		)
+		require.NoError(t, err)
+
+		all, err := di.ResolveAll[*testtypes.StructA](ctx, c)
+		require.NoError(t, err)
+		assert.Len(t, all, 2)
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithConstructorDedup(),
+			common,
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope(
+			common,
+		)
+		require.NoError(t, err)
+
+		all, err := di.ResolveAll[testtypes.InterfaceA](ctx, scope)
+		require.NoError(t, err)
+		assert.Len(t, all, 2)
+	})
+}
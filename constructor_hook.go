@@ -0,0 +1,111 @@
+package di
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// ConstructorHook is called before a service constructor function is called.
+//
+// If it returns an error, the constructor function is not called,
+// and the error is returned as if the constructor function returned it.
+// The error is not cached for a [Singleton] or [Scoped] service, so the next Resolve calls the hooks again.
+type ConstructorHook = func(ctx context.Context, svc ServiceInfo) error
+
+// WithConstructorHook registers a function that is called before each service constructor function
+// when calling [NewContainer] or [Container.NewScope].
+//
+// Child scopes inherit the hooks of the parent Container.
+// Hooks are called in the order they are registered, and may be called concurrently from multiple goroutines.
+// The time spent in hooks is included in the Duration of [Resolved] and [ConstructorFailed] events.
+//
+// This can be used by tests to slow down or fail constructor functions, see [ditest.WithChaos].
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithConstructorHook(func(ctx context.Context, svc di.ServiceInfo) error {
+//			logger.DebugContext(ctx, "creating service", "service", svc)
+//			return nil
+//		}),
+//		// ...
+//	)
+//
+// [ditest.WithChaos]: https://pkg.go.dev/github.com/sectrean/di-kit/ditest#WithChaos
+func WithConstructorHook(h ConstructorHook) ContainerOption {
+	return containerOption(func(c *Container) error {
+		if h != nil {
+			c.constructorHooks = append(c.constructorHooks, h)
+		}
+		return nil
+	})
+}
+
+// construct calls the hooks, then the constructor function and start functions for svc, and records the instance created.
+func (c *Container) construct(
+	ctx context.Context,
+	svc *service,
+	key serviceKey,
+	deps []reflect.Value,
+) (val any, cleanup func(), err error) {
+	err = c.callConstructorHooks(ctx, svc, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	val, cleanup, err = svc.New(deps)
+	if err == nil {
+		err = c.initInstance(ctx, svc, val, cleanup)
+		if err != nil {
+			val, cleanup = nil, nil
+		}
+	}
+
+	return val, cleanup, svc.wrapNamed(err)
+}
+
+// callConstructorHooks calls the hooks before an instance of svc is created.
+func (c *Container) callConstructorHooks(ctx context.Context, svc *service, key serviceKey) error {
+	if len(c.constructorHooks) == 0 {
+		return nil
+	}
+
+	info := svc.Info(key)
+	for _, h := range c.constructorHooks {
+		if err := h(ctx, info); err != nil {
+			return hookError{err}
+		}
+	}
+	return nil
+}
+
+// initInstance calls the start functions for a new instance of svc, and records the instance created.
+// The instance is closed if a start function fails.
+func (c *Container) initInstance(ctx context.Context, svc *service, val any, cleanup func()) error {
+	if len(svc.startFuncs) > 0 {
+		if err := svc.start(ctx, val, cleanup); err != nil {
+			return err
+		}
+	}
+	if c.memoryStats != nil {
+		c.memoryStats.Record(svc, val)
+	}
+
+	return nil
+}
+
+// hookError is an error returned by a [ConstructorHook].
+type hookError struct {
+	err error
+}
+
+func (e hookError) Error() string { return e.err.Error() }
+func (e hookError) Unwrap() error { return e.err }
+
+// isHookError returns true if err was returned by a [ConstructorHook].
+func isHookError(err error) bool {
+	var hookErr hookError
+	return errors.As(err, &hookErr)
+}
@@ -0,0 +1,111 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithConstructorHook(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("called before constructor", func(t *testing.T) {
+		var called []string
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(testtypes.NewInterfaceB, di.Scoped),
+			di.WithService(testtypes.StructC{}, di.As[testtypes.InterfaceC]()),
+			di.WithConstructorHook(func(_ context.Context, svc di.ServiceInfo) error {
+				called = append(called, svc.String())
+				return nil
+			}),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope()
+		require.NoError(t, err)
+
+		_ = di.MustResolve[testtypes.InterfaceB](ctx, scope)
+		_ = di.MustResolve[testtypes.InterfaceB](ctx, scope)
+		_ = di.MustResolve[testtypes.InterfaceC](ctx, scope)
+
+		assert.Equal(t, []string{"testtypes.InterfaceA", "testtypes.InterfaceB"}, called)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		calls := 0
+		c, err := di.NewContainer(
+			di.WithService(func() testtypes.InterfaceA {
+				calls++
+				return testtypes.StructA{}
+			}, di.Transient),
+			di.WithConstructorHook(func(context.Context, di.ServiceInfo) error {
+				return errors.New("hook error")
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, c)
+		testutils.LogError(t, err)
+
+		assert.EqualError(t, err, "di.Container.Resolve testtypes.InterfaceA: hook error")
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("error not cached", func(t *testing.T) {
+		fail := true
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithConstructorHook(func(context.Context, di.ServiceInfo) error {
+				if fail {
+					return errors.New("hook error")
+				}
+				return nil
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve testtypes.InterfaceA: hook error")
+
+		fail = false
+		a, err := di.Resolve[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.NotNil(t, a)
+	})
+
+	t.Run("SingletonPer", func(t *testing.T) {
+		var called []string
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			di.WithService(testtypes.NewInterfaceA, di.SingletonPer(func(context.Context) any {
+				return "key"
+			})),
+			di.WithConstructorHook(func(_ context.Context, svc di.ServiceInfo) error {
+				called = append(called, svc.String())
+				return nil
+			}),
+		)
+		require.NoError(t, err)
+
+		_ = di.MustResolve[testtypes.InterfaceA](ctx, c)
+		_ = di.MustResolve[testtypes.InterfaceA](ctx, c)
+
+		assert.Equal(t, []string{"testtypes.InterfaceA"}, called)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithConstructorHook(nil),
+		)
+		require.NoError(t, err)
+		assert.NotNil(t, c)
+	})
+}
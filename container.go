@@ -4,48 +4,139 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/sectrean/di-kit/internal/errors"
 )
 
 // Container is a dependency injection container.
 // It is used to resolve services by first resolving their dependencies.
+//
+// A Container is safe for concurrent use. See [Container.AssertImmutable] for more information.
 type Container struct {
-	parent     *Container
-	services   map[serviceKey][]*service
-	resolved   map[*service]resolveResult
-	closers    []Closer
-	resolvedMu sync.RWMutex
-	closedMu   sync.RWMutex
-	closersMu  sync.Mutex
-	closed     bool
-	validate   bool
+	parent              *Container
+	services            map[serviceKey][]*service
+	replaced            map[serviceKey]struct{}
+	resolved            map[*service]resolveResult
+	memos               map[*service]memoResult
+	invoked             map[any]*invokeOnceResult
+	warmCache           WarmCache
+	closers             []Closer
+	closerSvcs          []*service
+	resolveOpts         []ResolveOption
+	registered          []*service
+	eventHandlers       []EventHandler
+	constructorHooks    []ConstructorHook
+	orderedOpts         []orderedOption
+	closerCtx           func() context.Context
+	module              string
+	newInstanceStore    func() InstanceStore
+	instanceStore       InstanceStore
+	closeRand           Rand
+	lockStats           *lockStats
+	memoryStats         *memoryStats
+	modules             []string
+	inheritFilters      []func(reflect.Type, any) bool
+	resolvePolicies     []ResolvePolicy
+	experiments         []Experiment
+	sealedRegistrations int
+	optionOrder         OptionOrder
+	resolvedMu          sync.RWMutex
+	closedMu            sync.RWMutex
+	closersMu           sync.Mutex
+	memosMu             sync.Mutex
+	invokedMu           sync.Mutex
+	sealedMutations     atomic.Int64
+	sealed              atomic.Bool
+	closing             atomic.Bool
+	duplicatePolicy     DuplicatePolicy
+	closed              bool
+	validate            bool
+	requireScope        bool
+	strictResolve       bool
+	tagFallback         bool
+	sliceDedup          bool
+	constructorDedup    bool
+	decoratorsDisabled  bool
+	inheritFiltered     bool
 }
 
 var _ Scope = (*Container)(nil)
 
+// ContainerInterface is the interface of a [Container] used by adapters like dihttp to create child scopes.
+//
+// It allows a fake Container to be used in tests. A fake can return an error from NewChildScope,
+// or return another fake to control how services are resolved from each child scope.
+type ContainerInterface interface {
+	Scope
+	Closer
+
+	// NewChildScope creates a new child scope.
+	//
+	// See [Container.NewChildScope] for more information.
+	NewChildScope(opts ...ContainerOption) (ContainerInterface, error)
+}
+
+var _ ContainerInterface = (*Container)(nil)
+
 // NewContainer creates a new [Container] with the provided options.
 //
+// A [Clock] and [Rand] are registered by default and can be overridden.
+//
 // Available options:
 //   - [WithService] registers a service with a value or constructor function.
+//   - [WithDeclaredService] registers a value service as its declared type.
+//   - [WithValue] and [WithConstructor] register a value or constructor function as type *Service*.
+//   - [WithValuesFrom] registers the fields of a struct as value services.
 //   - [WithModule] registers services from a module.
 //   - [WithDependencyValidation] validates service dependencies.
+//   - [WithRequireScope] requires services to be resolved from a child scope.
+//   - [WithDefaultResolveOptions] sets options used for every call to Resolve.
+//   - [WithStrictResolve] returns an error when resolving a type with multiple services registered.
+//   - [WithCloserContext] sets the base context passed to closers.
+//   - [WithLockStats] records lock contention stats.
+//   - [WithOptionOrder] applies options after services are registered.
+//   - [WithInstanceStore] sets a custom store for created services.
 func NewContainer(opts ...ContainerOption) (*Container, error) {
-	c := &Container{
-		services: make(map[serviceKey][]*service),
-		resolved: make(map[*service]resolveResult),
+	c, err := newContainer(opts, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "di.NewContainer")
 	}
 
-	err := c.applyOptions(opts)
+	return c, nil
+}
+
+// newContainer creates a new root Container with the options.
+// If validate is false, [WithDependencyValidation] is skipped.
+func newContainer(opts []ContainerOption, validate bool) (*Container, error) {
+	c := newUnsealedContainer()
+
+	err := c.applyOptions(opts, validate)
 	if err != nil {
-		return nil, errors.Wrap(err, "di.NewContainer")
+		return nil, err
 	}
+	c.seal()
+	c.emitCreated()
+	c.startPrewarm()
 
 	return c, nil
 }
 
+// newUnsealedContainer creates a new root Container with the default services registered.
+func newUnsealedContainer() *Container {
+	c := &Container{
+		services: make(map[serviceKey][]*service),
+		resolved: make(map[*service]resolveResult),
+	}
+	c.registerDefaults()
+
+	return c
+}
+
 // ContainerOption is used to configure a new [Container] when calling [NewContainer]
 // or [Container.NewScope].
 type ContainerOption interface {
@@ -55,10 +146,31 @@ type ContainerOption interface {
 type containerOption func(*Container) error
 
 func (o containerOption) applyContainer(c *Container) error {
+	// Every option that sets a field of the Container is a containerOption
+	c.assertMutable("option applied")
 	return o(c)
 }
 
-func (c *Container) applyOptions(opts []ContainerOption) error {
+// WithError returns err when calling [NewContainer] or [Container.NewScope].
+//
+// Packages that provide their own options can use this to report invalid arguments
+// like the options in this package do, instead of panicking. It does nothing if err is nil.
+//
+// Example:
+//
+//	func WithQueue(name string) di.ContainerOption {
+//		if name == "" {
+//			return di.WithError(errors.New("WithQueue: name is empty"))
+//		}
+//		return di.WithService(func() *Queue { return NewQueue(name) })
+//	}
+func WithError(err error) ContainerOption {
+	return containerOption(func(*Container) error {
+		return err
+	})
+}
+
+func (c *Container) applyOptions(opts []ContainerOption, validate bool) error {
 	err := applyOptions(opts, func(o ContainerOption) error {
 		return o.applyContainer(c)
 	})
@@ -66,7 +178,32 @@ func (c *Container) applyOptions(opts []ContainerOption) error {
 		return err
 	}
 
-	if c.validate {
+	err = c.applyOrderedOptions()
+	if err != nil {
+		return err
+	}
+
+	err = c.checkExperiments()
+	if err != nil {
+		return err
+	}
+
+	err = c.checkPrimaries()
+	if err != nil {
+		return err
+	}
+
+	err = c.checkDuplicates()
+	if err != nil {
+		return err
+	}
+
+	err = c.checkPrewarm()
+	if err != nil {
+		return err
+	}
+
+	if c.validate && validate {
 		err := c.validateDependencies()
 		if err != nil {
 			return errors.Wrap(err, "WithDependencyValidation")
@@ -76,42 +213,60 @@ func (c *Container) applyOptions(opts []ContainerOption) error {
 	return nil
 }
 
+// registerDefaults registers the built-in services that can be overridden.
+func (c *Container) registerDefaults() {
+	c.register(&service{
+		scope:    c,
+		v:        reflect.ValueOf(systemClock{}),
+		t:        typeClock,
+		lifetime: Singleton,
+		value:    true,
+		builtin:  true,
+	})
+	c.register(&service{
+		scope:    c,
+		v:        reflect.ValueOf(globalRand{}),
+		t:        typeRand,
+		lifetime: Singleton,
+		value:    true,
+		builtin:  true,
+	})
+}
+
 func (c *Container) register(s *service) {
+	c.assertMutable("service registered")
+	if c.isDuplicateConstructor(s) {
+		return
+	}
+	if s.replace {
+		c.replaceServices(s)
+	}
+	c.registered = append(c.registered, s)
+	s.module = c.module
+	s.decorator = c.optionOrder >= OrderDecorator && c.optionOrder < OrderValidation
+
 	if c.services == nil {
 		c.services = make(map[serviceKey][]*service)
 	}
 
-	if len(s.Assignables()) == 0 {
-		c.registerType(s.Type(), s)
-	} else {
-		for _, assignable := range s.Assignables() {
-			c.registerType(assignable, s)
+	// This doesn't de-duplicate tags, so if someone registers duplicate tags, that's on them
+	depth := c.depth()
+	s.regs = make(map[serviceKey]registration)
+	for _, key := range s.Keys() {
+		index := len(c.services[key])
+		s.regs[key] = registration{
+			ID:    newServiceID(s, key, depth, index),
+			Depth: depth,
+			Index: index,
 		}
+		c.services[key] = append(c.services[key], s)
 	}
 
 	// Add closers for value services
 	// We don't need to take locks here because this is only called when creating a new Container
 	if s.IsValue() {
-		if closer := s.CloserFor(s.Value()); closer != nil {
-			c.closers = append(c.closers, closer)
-		}
-	}
-}
-
-func (c *Container) registerType(t reflect.Type, s *service) {
-	if len(s.Tags()) == 0 {
-		key := serviceKey{
-			Type: t,
-		}
-		c.services[key] = append(c.services[key], s)
-	} else {
-		// This doesn't de-duplicate tags, so if someone registers duplicate tags, that's on them
-		for _, tag := range s.Tags() {
-			key := serviceKey{
-				Type: t,
-				Tag:  tag,
-			}
-			c.services[key] = append(c.services[key], s)
+		if closer := s.CloserFor(s.Value(), nil); closer != nil {
+			c.appendCloser(closer, s)
 		}
 	}
 }
@@ -130,6 +285,55 @@ func WithDependencyValidation() ContainerOption {
 	})
 }
 
+// WithRequireScope prevents services from being resolved directly from the root [Container]
+// when calling [NewContainer].
+//
+// [Container.Resolve] on the root Container will return an error, so all services must be
+// resolved from a child scope created with [Container.NewScope], such as a request or job scope.
+// Singleton services are still created and cached by the root Container when resolved from a child scope.
+//
+// [Invoke] can still be called with the root Container to start the application.
+//
+// This option will return an error if used with [Container.NewScope].
+func WithRequireScope() ContainerOption {
+	return containerOption(func(c *Container) error {
+		if c.parent != nil {
+			return errors.New("WithRequireScope: not supported for child scope")
+		}
+
+		c.requireScope = true
+		return nil
+	})
+}
+
+// WithDefaultResolveOptions sets [ResolveOption]s used every time a service is resolved with
+// [Container.Resolve], checked with [Container.Contains], or injected with [Invoke], when calling [NewContainer] or [Container.NewScope].
+//
+// Options passed to Resolve or Contains are applied after the default options, so they take precedence.
+// Child scopes inherit the default options from the parent Container, and options set on the
+// child scope are applied after the inherited options.
+//
+// Default options do not apply to resolving dependencies of services. Use [WithTagged] for dependencies.
+func WithDefaultResolveOptions(opts ...ResolveOption) ContainerOption {
+	return containerOption(func(c *Container) error {
+		c.resolveOpts = append(c.resolveOpts, opts...)
+		return nil
+	})
+}
+
+// serviceKeyFor returns the service key for the type with the default options and provided options applied.
+func (c *Container) serviceKeyFor(t reflect.Type, opts []ResolveOption) serviceKey {
+	key := serviceKey{Type: t}
+	for _, opt := range c.resolveOpts {
+		key = opt.applyServiceKey(key)
+	}
+	for _, opt := range opts {
+		key = opt.applyServiceKey(key)
+	}
+
+	return key
+}
+
 func (c *Container) validateDependencies() error {
 	var errs []error
 	svcProblems := make(map[*service]string)
@@ -185,31 +389,35 @@ func (c *Container) validateService(svc *service, svcProblems map[*service]strin
 	defer visitor.Leave(svc)
 
 	var problems []string
-	for _, depKey := range deps {
-		if depKey.Type == typeContext || depKey.Type == typeScope {
+	variadic := svc.Func().Type().IsVariadic()
+	for i, depKey := range deps {
+		if svc.arg(i).IsValid() {
 			continue
 		}
 
-		if isUnnamedSliceType(depKey.Type) {
-			if svc.Func().Type().IsVariadic() {
-				// If the service is variadic, registration is optional
-				continue
+		if isInType(depKey.Type) {
+			// Validate each field of a parameter object
+			fields, _ := inFields(depKey.Type)
+			for _, f := range fields {
+				if prob := c.validateDependency(f.key, f.optional, svcProblems, visitor); prob != "" {
+					problems = append(problems, prob)
+				}
 			}
-
-			// Check that the element type is registered
-			depKey.Type = depKey.Type.Elem()
+			continue
 		}
 
-		depSvc := c.lookupService(depKey)
-		if depSvc == nil {
-			prob := fmt.Sprintf("dependency %s: service not registered", depKey)
-			problems = append(problems, prob)
-			continue
+		if n := minCount(svc.minCounts, i); n > 0 {
+			elemKey := serviceKey{Type: depKey.Type.Elem(), Tag: depKey.Tag}
+			if count := c.countServices(elemKey); count < n {
+				problems = append(problems, fmt.Sprintf("dependency %s: RequireCount %d: found %d services", depKey, n, count))
+				continue
+			}
 		}
 
-		prob := c.validateService(depSvc, svcProblems, visitor)
-		if prob != "" {
-			problems = append(problems, fmt.Sprintf("dependency %s: %s", depKey, prob))
+		// If the service is variadic, registration is optional
+		optional := (variadic && i == len(deps)-1) || isZeroDefault(svc.zeroDeps, i)
+		if prob := c.validateDependency(depKey, optional, svcProblems, visitor); prob != "" {
+			problems = append(problems, prob)
 		}
 	}
 
@@ -222,15 +430,84 @@ func (c *Container) validateService(svc *service, svcProblems map[*service]strin
 	return ""
 }
 
+// validateDependency returns a problem with the dependency, if any.
+func (c *Container) validateDependency(
+	depKey serviceKey,
+	optional bool,
+	svcProblems map[*service]string,
+	visitor resolveVisitor,
+) string {
+	if depKey.Type == typeContext || depKey.Type == typeScope {
+		return ""
+	}
+
+	if elemType, ok := optionalElem(depKey.Type); ok {
+		// Registration is optional, but a registered service is validated
+		depKey.Type = elemType
+		optional = true
+	}
+
+	var depSvc *service
+	switch {
+	case isUnnamedSliceType(depKey.Type):
+		if optional {
+			return ""
+		}
+
+		// Check that the element type is registered
+		depKey.Type = depKey.Type.Elem()
+		if svcs := c.sliceServices(depKey); len(svcs) > 0 {
+			depSvc = svcs[len(svcs)-1]
+		}
+	case isStringMapType(depKey.Type):
+		if optional {
+			return ""
+		}
+
+		// Check that a service is registered with a string tag
+		if keys := c.stringMapKeys(depKey.Type); len(keys) > 0 {
+			depSvc = c.lookupService(c.resolvableKey(keys[len(keys)-1]))
+		}
+	default:
+		depSvc = c.lookupService(c.resolvableKey(depKey))
+	}
+
+	if depSvc == nil && optional {
+		return ""
+	}
+	if depSvc == nil {
+		return fmt.Sprintf("dependency %s: service not registered", depKey)
+	}
+
+	prob := c.validateService(depSvc, svcProblems, visitor)
+	if prob != "" {
+		return fmt.Sprintf("dependency %s: %s", depKey, prob)
+	}
+
+	return ""
+}
+
 func (c *Container) lookupService(key serviceKey) *service {
+	svcs := c.lookupServices(key)
+	if len(svcs) == 0 {
+		return nil
+	}
+
+	// Return the service resolved for this key
+	return c.lastService(svcs)
+}
+
+// lookupServices returns the services registered for the key with the nearest scope that has any.
+func (c *Container) lookupServices(key serviceKey) []*service {
 	for scope := c; scope != nil; scope = scope.parent {
-		svcs, ok := scope.services[key]
-		if !ok {
-			continue
+		if svcs, ok := scope.services[key]; ok && c.visible(scope, key) {
+			if c.decoratorsDisabled {
+				if svcs = withoutDecorators(svcs); len(svcs) == 0 {
+					continue
+				}
+			}
+			return svcs
 		}
-
-		// Return the last registered service for this key
-		return svcs[len(svcs)-1]
 	}
 
 	return nil
@@ -249,7 +526,12 @@ func (c *Container) lookupService(key serviceKey) *service {
 //   - [WithService] registers a service with a value or a function.
 //   - [WithModule] registers services from a module.
 //   - [WithDependencyValidation] validates service dependencies.
+//   - [WithOptionOrder] applies options after services are registered.
 func (c *Container) NewScope(opts ...ContainerOption) (*Container, error) {
+	if c.closing.Load() {
+		return nil, errors.Wrap(ErrContainerClosing, "di.Container.NewScope")
+	}
+
 	c.closedMu.RLock()
 	defer c.closedMu.RUnlock()
 
@@ -258,14 +540,52 @@ func (c *Container) NewScope(opts ...ContainerOption) (*Container, error) {
 	}
 
 	scope := &Container{
-		parent:   c,
-		resolved: make(map[*service]resolveResult),
+		parent:             c,
+		resolved:           make(map[*service]resolveResult),
+		resolveOpts:        slices.Clip(c.resolveOpts),
+		eventHandlers:      slices.Clip(c.eventHandlers),
+		constructorHooks:   slices.Clip(c.constructorHooks),
+		resolvePolicies:    slices.Clip(c.resolvePolicies),
+		experiments:        slices.Clip(c.experiments),
+		strictResolve:      c.strictResolve,
+		tagFallback:        c.tagFallback,
+		sliceDedup:         c.sliceDedup,
+		decoratorsDisabled: c.decoratorsDisabled,
+		inheritFiltered:    c.inheritFiltered,
+		duplicatePolicy:    c.duplicatePolicy,
+		closerCtx:          c.closerCtx,
+		closeRand:          c.closeRand,
+	}
+	if c.lockStats != nil {
+		scope.lockStats = &lockStats{}
+	}
+	if c.memoryStats != nil {
+		scope.memoryStats = &memoryStats{sizer: c.memoryStats.sizer}
+	}
+	if c.newInstanceStore != nil {
+		scope.newInstanceStore = c.newInstanceStore
+		scope.instanceStore = c.newInstanceStore()
 	}
 
-	err := scope.applyOptions(opts)
+	err := scope.applyOptions(opts, true)
 	if err != nil {
 		return nil, errors.Wrap(err, "di.Container.NewScope")
 	}
+	scope.seal()
+	scope.emitCreated()
+	scope.startPrewarm()
+
+	return scope, nil
+}
+
+// NewChildScope creates a new child scope, like [Container.NewScope], and returns it as a [ContainerInterface].
+//
+// This implements [ContainerInterface] for adapters like dihttp. Use NewScope to get a [*Container].
+func (c *Container) NewChildScope(opts ...ContainerOption) (ContainerInterface, error) {
+	scope, err := c.NewScope(opts...)
+	if err != nil {
+		return nil, err
+	}
 
 	return scope, nil
 }
@@ -275,23 +595,48 @@ func (c *Container) NewScope(opts ...ContainerOption) (*Container, error) {
 // Available options:
 //   - [WithTag] specifies a key associated with the service.
 func (c *Container) Contains(t reflect.Type, opts ...ResolveOption) bool {
+	if isStringMapType(t) {
+		return len(c.stringMapKeys(t)) > 0
+	}
+
 	// Check if the type is a slice, look for the element type
-	if isUnnamedSliceType(t) {
+	slice := isUnnamedSliceType(t)
+	if slice {
 		t = t.Elem()
 	}
 
-	key := serviceKey{Type: t}
-	for _, opt := range opts {
-		key = opt.applyServiceKey(key)
+	key := c.serviceKeyFor(t, opts)
+	if !slice {
+		key = c.resolvableKey(key)
 	}
 
-	for scope := c; scope != nil; scope = scope.parent {
-		if _, found := scope.services[key]; found {
-			return true
-		}
+	return len(c.lookupServices(key)) > 0
+}
+
+// Lookup returns the [ServiceInfo] for the service that would be resolved for the given [reflect.Type].
+//
+// Unlike [Container.Contains], this reports where the service is registered.
+// [ServiceInfo.Depth] is the scope level of the Container the service is registered with,
+// and [ServiceInfo.Index] is the position of the registration with that Container.
+// This is useful for debugging services registered with a child scope that shadow a parent Container.
+//
+// It returns false if no service is registered for the type.
+// For a slice type, it returns the last service registered as the element type.
+//
+// Available options:
+//   - [WithTag] specifies a key associated with the service.
+func (c *Container) Lookup(t reflect.Type, opts ...ResolveOption) (ServiceInfo, bool) {
+	if isUnnamedSliceType(t) {
+		t = t.Elem()
 	}
 
-	return false
+	key := c.resolvableKey(c.serviceKeyFor(t, opts))
+	svc := c.lookupService(key)
+	if svc == nil {
+		return ServiceInfo{}, false
+	}
+
+	return svc.Info(key), true
 }
 
 // ResolveOption can be used when calling [Resolve], [MustResolve],
@@ -303,31 +648,54 @@ type ResolveOption interface {
 
 // Resolve a service of the given [reflect.Type].
 //
-// This will return an error under the following conditions:
-//   - The container has been closed
+// This will return a [*ResolveError] under the following conditions:
+//   - The container has been closed, or is closing ([ErrContainerClosing])
 //   - The type is not registered with the container
 //   - The type cannot be resolved due to unregistered dependencies
 //   - A dependency cycle is detected
 //   - A service's constructor function returns an error
+//   - The container is the root container and [WithRequireScope] is used
 //
 // Available options:
 //   - [WithTag] specifies a key associated with the service.
 func (c *Container) Resolve(ctx context.Context, t reflect.Type, opts ...ResolveOption) (any, error) {
-	key := serviceKey{Type: t}
-	for _, opt := range opts {
-		key = opt.applyServiceKey(key)
+	key := c.serviceKeyFor(t, opts)
+
+	if c.requireScope {
+		return nil, newResolveError(c, key, errScopeRequired)
+	}
+
+	return c.resolve(ctx, key)
+}
+
+// resolve a service by key without checking [WithRequireScope].
+func (c *Container) resolve(ctx context.Context, key serviceKey) (any, error) {
+	return c.resolveWith(ctx, key, false)
+}
+
+// resolveWith resolves a service by key. If last is true, [WithStrictResolve] is ignored for the service.
+func (c *Container) resolveWith(ctx context.Context, key serviceKey, last bool) (any, error) {
+	// Don't wait for the Container to finish closing
+	if c.closing.Load() {
+		return nil, newResolveError(c, key, ErrContainerClosing)
 	}
 
 	c.closedMu.RLock()
 	defer c.closedMu.RUnlock()
 
 	if c.closed {
-		return nil, errors.Wrapf(errContainerClosed, "di.Container.Resolve %s", key)
+		return nil, newResolveError(c, key, errContainerClosed)
 	}
 
-	val, err := resolveKey(ctx, c, key, make(resolveVisitor), false)
+	var val any
+	var err error
+	if last && !isUnnamedSliceType(key.Type) && !isStringMapType(key.Type) {
+		val, err = resolveLastKey(ctx, c, key, make(resolveVisitor))
+	} else {
+		val, err = resolveKey(ctx, c, key, make(resolveVisitor), false)
+	}
 	if err != nil {
-		return val, errors.Wrapf(err, "di.Container.Resolve %s", key)
+		return val, newResolveError(c, key, err)
 	}
 
 	return val, nil
@@ -343,12 +711,43 @@ func resolveKey(
 	if isUnnamedSliceType(key.Type) {
 		return resolveSliceKey(ctx, scope, key, visitor, optional)
 	}
+	if isStringMapType(key.Type) {
+		return resolveStringMapKey(ctx, scope, key, visitor, optional)
+	}
+	if elemType, ok := optionalElem(key.Type); ok {
+		return resolveOptionalKey(ctx, scope, key, elemType, visitor)
+	}
+	if isInType(key.Type) {
+		return resolveInKey(ctx, scope, key, visitor)
+	}
 
 	// Look up the service
+	key = scope.resolvableKey(key)
+	svcs := scope.lookupServices(key)
+	if len(svcs) == 0 {
+		// If the service is not found, return an error
+		return nil, errServiceNotRegistered
+	}
+
+	if scope.strictResolve {
+		if err := scope.checkAmbiguous(svcs); err != nil {
+			return nil, err
+		}
+	}
+
+	return resolveService(ctx, scope, key, scope.lastService(svcs), visitor)
+}
+
+// resolveLastKey resolves the last service registered for the key, ignoring [WithStrictResolve].
+func resolveLastKey(
+	ctx context.Context,
+	scope *Container,
+	key serviceKey,
+	visitor resolveVisitor,
+) (any, error) {
+	key = scope.resolvableKey(key)
 	svc := scope.lookupService(key)
 	if svc == nil {
-		// If the service is not found, return an error
-		// TODO: Support optional dependencies?
 		return nil, errServiceNotRegistered
 	}
 
@@ -370,16 +769,22 @@ func resolveSliceKey(
 	}
 	found := false
 
-	for s := scope; s != nil; s = s.parent {
-		for _, svc := range s.services[elemKey] {
-			val, err := resolveService(ctx, scope, elemKey, svc, visitor)
-			if err != nil {
-				return nil, err
-			}
+	var dedup sliceDedup
+	if scope.sliceDedup {
+		dedup = make(sliceDedup)
+	}
 
-			sliceVal = reflect.Append(sliceVal, safeReflectValue(elemType, val))
-			found = true
+	for _, svc := range scope.sliceServices(elemKey) {
+		val, err := resolveService(ctx, scope, elemKey, svc, visitor)
+		if err != nil {
+			return nil, err
 		}
+		if dedup.Seen(val) {
+			continue
+		}
+
+		sliceVal = reflect.Append(sliceVal, safeReflectValue(elemType, val))
+		found = true
 	}
 
 	if !found && !optional {
@@ -397,30 +802,77 @@ func resolveService(
 	svc *service,
 	visitor resolveVisitor,
 ) (val any, err error) {
+	err = scope.checkResolvePolicies(ctx, svc, key)
+	if err != nil {
+		return nil, err
+	}
+	scope.emitDeprecated(svc, key)
+
 	if svc.IsValue() {
 		// Value services are always resolved, so we can return the value directly.
-		return svc.Value(), nil
+		return svc.Instance(), nil
 	}
 
 	// Check context for errors
 	if ctx.Err() != nil {
 		return nil, ctx.Err()
 	}
+	if svc.withoutCancel {
+		ctx = context.WithoutCancel(ctx)
+	}
 
-	// For singleton services, use the scope the service is registered with.
+	// For singleton services and shared custom lifetimes, use the scope the service is registered with.
 	// Otherwise, use the current scope.
 	lifetime := svc.Lifetime()
-	if lifetime == Singleton {
+	custom := svc.custom
+	if lifetime == Singleton || (custom != nil && custom.Shared()) {
+		if scope != svc.Scope() {
+			// Make sure the parent Container isn't closed while the service is being created
+			parent := svc.Scope()
+			if parent.closing.Load() {
+				return nil, ErrContainerClosing
+			}
+
+			parent.closedMu.RLock()
+			defer parent.closedMu.RUnlock()
+
+			if parent.closed {
+				return nil, errContainerClosed
+			}
+		}
+
 		scope = svc.Scope()
 	} else if lifetime == Scoped && scope == svc.Scope() {
 		return nil, errors.New("scoped service must be resolved from a child scope")
 	}
 
-	// For Singleton or Scoped services, we store the result.
-	// See if this service has already been resolved.
-	if lifetime != Transient {
-		scope.resolvedMu.RLock()
-		res, exists := scope.resolved[svc]
+	// Expiry and cooldowns use the Clock registered with the scope, so tests can control them
+	var clock Clock
+	if svc.MemoTTL() > 0 || svc.Breaker() != nil || svc.Keyed() != nil {
+		clock = scope.clock(ctx, key, visitor)
+	}
+
+	// Keyed singletons are cached by the key from the context.
+	keyed := svc.Keyed()
+	var cacheKey any
+	if keyed != nil {
+		cacheKey, err = keyed.Key(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		keyed.mu.Lock()
+		cached, exists := keyed.Load(cacheKey, clock.Now())
+		keyed.mu.Unlock()
+
+		if exists {
+			return cached, nil
+		}
+	} else if lifetime != Transient {
+		// For Singleton or Scoped services, we store the result.
+		// See if this service has already been resolved.
+		scope.rlockResolved()
+		res, exists := scope.loadResolved(svc)
 		scope.resolvedMu.RUnlock()
 
 		if exists {
@@ -428,12 +880,71 @@ func resolveService(
 		}
 	}
 
+	// Services with a custom lifetime decide when to create a new instance
+	if custom != nil {
+		if cached, ok := custom.Load(ctx, scope); ok {
+			return cached, nil
+		}
+
+		defer func() {
+			if err == nil {
+				custom.Store(ctx, scope, val)
+			}
+		}()
+	}
+
+	// Transient services may have instances created ahead of time
+	if pool := svc.prewarm; pool != nil && prewarmingFor(ctx, svc) == nil {
+		defer pool.Fill(svc.Scope(), key, svc)
+
+		if pooled, ok := pool.Take(scope, svc); ok {
+			return pooled, nil
+		}
+	}
+
+	// Transient services may be memoized for a duration
+	if svc.MemoTTL() > 0 {
+		if memo, ok := scope.loadMemo(svc, clock.Now()); ok {
+			return memo, nil
+		}
+
+		defer func() {
+			if err == nil {
+				scope.storeMemo(svc, val, clock.Now())
+			}
+		}()
+	}
+
 	// Throw an error if we've already visited this service
 	if !visitor.Enter(svc) {
 		return nil, errDependencyCycle
 	}
 	defer visitor.Leave(svc)
 
+	// Fail fast if the circuit breaker is open
+	breaker := svc.Breaker()
+	if breaker != nil {
+		trial, openErr := breaker.Allow(clock.Now())
+		if openErr != nil {
+			return nil, openErr
+		}
+		if trial {
+			defer breaker.EndTrial()
+		}
+	}
+
+	// The first resolve may take longer than the caller's context allows
+	if d := svc.coldStartTimeout; d > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.WithoutCancel(ctx), d)
+		defer cancel()
+	}
+
+	// Singletons imported from a warm cache don't need their dependencies
+	if res, ok := scope.resolveWarm(ctx, svc, key); ok {
+		return res.Val, res.Err
+	}
+
 	// Recursively resolve dependencies
 	var depVals []reflect.Value
 
@@ -441,6 +952,12 @@ func resolveService(
 	if len(deps) > 0 {
 		depVals = make([]reflect.Value, len(deps))
 		for i, depKey := range deps {
+			// Parameters bound with WithArgs are not resolved
+			if arg := svc.arg(i); arg.IsValid() {
+				depVals[i] = arg
+				continue
+			}
+
 			var depVal any
 			var depErr error
 
@@ -461,37 +978,78 @@ func resolveService(
 					// we treat it as optional.
 					optional = true
 				}
+				if isZeroDefault(svc.zeroDeps, i) && !scope.isRegistered(depKey) {
+					// Inject the zero value
+					break
+				}
 
 				// Recursive call
 				depVal, depErr = resolveKey(ctx, scope, depKey, visitor, optional)
 			}
 
+			if n := minCount(svc.minCounts, i); n > 0 && depErr == nil {
+				depErr = checkCount(n, depVal)
+			}
+
 			if depErr != nil {
 				// Stop at the first error
-				return nil, errors.Wrapf(depErr, "dependency %s", depKey)
+				return nil, &dependencyError{Key: depKey, Err: depErr}
 			}
 			depVals[i] = safeReflectValue(depKey.Type, depVal)
 		}
 	}
 
+	// Emit an event after the constructor function is called and any locks are released
+	var start time.Time
+	defer func() {
+		if !start.IsZero() {
+			scope.emitConstructed(svc, key, time.Since(start), err)
+		}
+	}()
+
+	if keyed != nil {
+		start = time.Now()
+		return keyed.Create(ctx, scope, svc, cacheKey, depVals, clock)
+	}
+
+	// Wait for a slot if concurrent calls to the constructor function are limited.
+	// Wait before locking, so other services can be resolved from the scope in the meantime.
+	if limit := svc.constructions; limit != nil {
+		err = limit.Acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer limit.Release()
+	}
+
 	if svc.Lifetime() != Transient {
 		// We need to lock before we create the service to make sure we don't create it twice
-		scope.resolvedMu.Lock()
+		scope.lockResolved()
 		defer scope.resolvedMu.Unlock()
 
 		// Check if another goroutine resolved the service since the last check
-		if res, exists := scope.resolved[svc]; exists {
+		if res, exists := scope.loadResolved(svc); exists {
 			return res.Val, res.Err
 		}
 
 		defer func() {
+			// Don't store an error if the context was canceled during construction,
+			// or a constructor hook failed. Another caller may still be able to create the service.
+			if err != nil && (ctx.Err() != nil || isHookError(err)) {
+				return
+			}
+
 			// Store the result
-			scope.resolved[svc] = resolveResult{val, err}
+			scope.storeResolved(svc, resolveResult{val, err})
 		}()
 	}
 
 	// Create the service
-	val, err = svc.New(depVals)
+	start = time.Now()
+	val, cleanup, err := scope.construct(ctx, svc, key, depVals)
+	if breaker != nil {
+		breaker.Record(err, clock.Now())
+	}
 
 	// Skip the rest if there was an error
 	if err != nil {
@@ -499,9 +1057,15 @@ func resolveService(
 	}
 
 	// Add Closer for the service
-	if closer := svc.CloserFor(val); closer != nil {
-		scope.closersMu.Lock()
-		scope.closers = append(scope.closers, closer)
+	if closer := svc.CloserFor(val, cleanup); closer != nil {
+		// An instance created ahead of time is closed by the scope that takes it
+		if p := prewarmingFor(ctx, svc); p != nil {
+			p.Closer = closer
+			return val, nil
+		}
+
+		scope.lockClosers()
+		scope.appendCloser(closer, svc)
 		scope.closersMu.Unlock()
 	}
 
@@ -518,35 +1082,57 @@ func resolveService(
 //
 // Close will return an error if called more than once.
 func (c *Container) Close(ctx context.Context) error {
+	// New calls to Resolve and NewScope fail fast while in-flight calls finish
+	c.closing.Store(true)
+
 	c.closedMu.Lock()
 	defer c.closedMu.Unlock()
+	defer c.closing.Store(false)
 
 	if c.closed {
 		return errors.Wrap(errContainerClosed, "di.Container.Close: closed already")
 	}
 	c.closed = true
 
+	closeCtx, done := c.closeContext(ctx)
+	defer done()
+
 	// Close services in LIFO order
 	// This is important because of dependencies
 	var errs []error
-	for i := len(c.closers) - 1; i >= 0; i-- {
-		err := c.closers[i].Close(ctx)
-		if err != nil {
-			errs = append(errs, err)
+	if c.closeRand != nil {
+		for _, i := range c.shuffledCloseOrder() {
+			if err := c.closers[i].Close(closeCtx); err != nil {
+				errs = append(errs, c.closerSvcs[i].wrapNamed(err))
+			}
+		}
+	} else {
+		for i := len(c.closers) - 1; i >= 0; i-- {
+			err := c.closers[i].Close(closeCtx)
+			if err != nil {
+				errs = append(errs, c.closerSvcs[i].wrapNamed(err))
+			}
 		}
 	}
 
-	if err := errors.Join(errs...); err != nil {
-		return errors.Wrap(err, "di.Container.Close")
-	}
+	err := errors.Wrap(errors.Join(errs...), "di.Container.Close")
+	c.emitClosed(err)
 
-	return nil
+	return err
 }
 
+// ErrContainerClosing is returned when resolving a service or creating a child scope
+// from a [Container] after [Container.Close] has been called, but before it has returned.
+//
+// Close waits for services that are already being created, so closers never run
+// while a constructor function is running.
+var ErrContainerClosing = errors.New("container closing")
+
 var (
 	errServiceNotRegistered = errors.New("service not registered")
 	errDependencyCycle      = errors.New("dependency cycle detected")
 	errContainerClosed      = errors.New("container closed")
+	errScopeRequired        = errors.New("must be resolved from a child scope")
 )
 
 type resolveResult struct {
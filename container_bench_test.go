@@ -40,6 +40,30 @@ func Benchmark_NewContainer(b *testing.B) {
 			_, _ = di.NewContainer(optsOneServiceValue...)
 		}
 	})
+
+	optsValidated := []di.ContainerOption{
+		di.WithService(testtypes.NewInterfaceAStruct),
+		di.WithService(testtypes.NewInterfaceBStruct),
+		di.WithService(testtypes.NewInterfaceCStruct),
+		di.WithService(testtypes.NewInterfaceDStruct),
+		di.WithDependencyValidation(),
+	}
+
+	b.Run("func service four validated", func(b *testing.B) {
+		for range b.N {
+			_, _ = di.NewContainer(optsValidated...)
+		}
+	})
+
+	b.Run("func service four validated Template", func(b *testing.B) {
+		tmpl, err := di.NewTemplate(optsValidated...)
+		require.NoError(b, err)
+		b.ResetTimer()
+
+		for range b.N {
+			_, _ = tmpl.New()
+		}
+	})
 }
 
 func Benchmark_Container_NewScope(b *testing.B) {
@@ -287,6 +311,23 @@ func Benchmark_Container_Resolve(b *testing.B) {
 			_, _ = c.Resolve(ctx, testtypes.TypeInterfaceB)
 		}
 	})
+
+	b.Run("transient Register with transient dep", func(b *testing.B) {
+		ctx := context.Background()
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceAStruct, di.Transient),
+			di.Register[testtypes.InterfaceB](func(a testtypes.InterfaceA) (testtypes.InterfaceB, error) {
+				return testtypes.NewInterfaceBStruct(a), nil
+			}, di.Transient),
+		)
+		require.NoError(b, err)
+
+		b.ResetTimer()
+
+		for range b.N {
+			_, _ = c.Resolve(ctx, testtypes.TypeInterfaceB)
+		}
+	})
 }
 
 func newParent(b *testing.B) *di.Container {
@@ -0,0 +1,380 @@
+package di
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// DefaultContainerSetLimit is the maximum number of Containers kept by a [ContainerSet].
+const DefaultContainerSetLimit = 128
+
+// maxEvictErrors is the maximum number of errors from closing evicted Containers
+// kept by a [ContainerSet] until it is closed. It also caps the errors from closing
+// evicted instances kept by a [SingletonPer] cache.
+const maxEvictErrors = 100
+
+// ContainerSet manages a root [Container] for each key, like one Container per tenant.
+//
+// Containers are created the first time they are requested with [ContainerSet.Get],
+// using the options returned by the template function for the key.
+// Up to [DefaultContainerSetLimit] Containers are kept. Use [WithContainerLimit] to change the limit.
+// When the limit is reached, the least recently used Container is evicted and closed asynchronously.
+// Use [ContainerSet.Acquire] to keep a Container from being closed while it is being used.
+// A Container returned by [ContainerSet.Get] that is evicted while it is being used will be closed,
+// so keep the limit above the number of keys that are used at the same time.
+//
+// A ContainerSet is safe for concurrent use.
+type ContainerSet[K comparable] struct {
+	template  func(key K) []ContainerOption
+	entries   map[K]*list.Element
+	order     *list.List
+	evictErrs []error
+	evicting  sync.WaitGroup
+	limit     int
+	dropped   int
+	mu        sync.Mutex
+	closed    bool
+}
+
+// ContainerSetOption is used to configure a [ContainerSet] when calling [NewContainerSet].
+type ContainerSetOption interface {
+	applyContainerSet(*containerSetConfig) error
+}
+
+type containerSetConfig struct {
+	limit int
+}
+
+type containerSetOption func(*containerSetConfig) error
+
+func (o containerSetOption) applyContainerSet(c *containerSetConfig) error {
+	return o(c)
+}
+
+// WithContainerLimit sets the maximum number of Containers kept by a [ContainerSet] when calling [NewContainerSet].
+//
+// This option will return an error if the limit is not positive.
+func WithContainerLimit(n int) ContainerSetOption {
+	return containerSetOption(func(c *containerSetConfig) error {
+		if n <= 0 {
+			return errors.Errorf("WithContainerLimit %d: limit must be positive", n)
+		}
+
+		c.limit = n
+		return nil
+	})
+}
+
+// NewContainerSet creates a new [ContainerSet] that creates a Container for each key
+// with the options returned by template.
+//
+// Example:
+//
+//	tenants, err := di.NewContainerSet(func(tenantID string) []di.ContainerOption {
+//		return []di.ContainerOption{
+//			app.Dependencies,
+//			di.WithService(tenant.Config{ID: tenantID}),
+//		}
+//	}, di.WithContainerLimit(1000))
+//	...
+//
+//	c, err := tenants.Get(ctx, tenantID)
+//
+// This will return an error if template is nil, or an option returns an error.
+func NewContainerSet[K comparable](
+	template func(key K) []ContainerOption,
+	opts ...ContainerSetOption,
+) (*ContainerSet[K], error) {
+	if template == nil {
+		return nil, errors.New("di.NewContainerSet: template is nil")
+	}
+
+	cfg := containerSetConfig{limit: DefaultContainerSetLimit}
+	err := applyOptions(opts, func(o ContainerSetOption) error {
+		return o.applyContainerSet(&cfg)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "di.NewContainerSet")
+	}
+
+	return &ContainerSet[K]{
+		template: template,
+		entries:  make(map[K]*list.Element),
+		order:    list.New(),
+		limit:    cfg.limit,
+	}, nil
+}
+
+type containerSetEntry[K comparable] struct {
+	key   K
+	err   error
+	c     *Container
+	ready chan struct{}
+	// idle is closed when the entry is retired and has no leases.
+	idle chan struct{}
+
+	// leases is the number of callers using the Container. The lock must be held.
+	leases int
+	// retired is true when the entry has been removed from the set. The lock must be held.
+	retired bool
+}
+
+// Get returns the Container for the key, creating it if needed.
+//
+// If another goroutine is creating the Container for the key, Get waits for it,
+// or returns an error if ctx is canceled first.
+// Errors returned from creating the Container are not kept, so the next call to Get tries again.
+// If the template function panics, the panic is returned as an error.
+func (s *ContainerSet[K]) Get(ctx context.Context, key K) (*Container, error) {
+	c, release, err := s.acquire(ctx, key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "di.ContainerSet.Get %v", key)
+	}
+	release()
+
+	return c, nil
+}
+
+// Acquire returns the Container for the key like [ContainerSet.Get], along with a function to release it.
+//
+// The Container is not closed while it is acquired, even if it is evicted or removed from the set.
+// It is closed when the last caller releases it. Call release when done using the Container.
+// Calling release more than once has no effect.
+//
+// Example:
+//
+//	c, release, err := tenants.Acquire(ctx, tenantID)
+//	if err != nil {
+//		return err
+//	}
+//	defer release()
+func (s *ContainerSet[K]) Acquire(ctx context.Context, key K) (c *Container, release func(), err error) {
+	c, release, err = s.acquire(ctx, key)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "di.ContainerSet.Acquire %v", key)
+	}
+
+	return c, release, nil
+}
+
+func (s *ContainerSet[K]) acquire(ctx context.Context, key K) (*Container, func(), error) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil, nil, errContainerClosed
+	}
+
+	if elem, ok := s.entries[key]; ok {
+		s.order.MoveToFront(elem)
+		entry := elem.Value.(*containerSetEntry[K])
+		entry.leases++
+		s.mu.Unlock()
+
+		release := s.releaser(entry)
+		select {
+		case <-entry.ready:
+		case <-ctx.Done():
+			release()
+			return nil, nil, ctx.Err()
+		}
+
+		if entry.err != nil {
+			release()
+			return nil, nil, entry.err
+		}
+		return entry.c, release, nil
+	}
+
+	entry := &containerSetEntry[K]{
+		key:    key,
+		ready:  make(chan struct{}),
+		idle:   make(chan struct{}),
+		leases: 1,
+	}
+	elem := s.order.PushFront(entry)
+	s.entries[key] = elem
+	evicted := s.evictLocked()
+	s.mu.Unlock()
+
+	release := s.releaser(entry)
+	s.evict(ctx, evicted)
+
+	entry.c, entry.err = s.newContainer(key)
+	close(entry.ready)
+
+	if entry.err != nil {
+		s.mu.Lock()
+		if s.entries[key] == elem {
+			s.order.Remove(elem)
+			delete(s.entries, key)
+			s.retireLocked(entry)
+		}
+		s.mu.Unlock()
+		release()
+
+		return nil, nil, entry.err
+	}
+
+	return entry.c, release, nil
+}
+
+// newContainer creates the Container for the key, returning an error if the template function panics.
+func (s *ContainerSet[K]) newContainer(key K) (c *Container, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			c, err = nil, errors.Errorf("template function for key %v panicked: %v", key, r)
+		}
+	}()
+
+	return NewContainer(s.template(key)...)
+}
+
+// releaser returns a function that releases a lease on the entry once.
+func (s *ContainerSet[K]) releaser(entry *containerSetEntry[K]) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			entry.leases--
+			if entry.leases == 0 && entry.retired {
+				close(entry.idle)
+			}
+			s.mu.Unlock()
+		})
+	}
+}
+
+// retireLocked marks the entry as removed from the set, so it is closed when it has no leases.
+// The lock must be held by the caller.
+func (s *ContainerSet[K]) retireLocked(entry *containerSetEntry[K]) {
+	entry.retired = true
+	if entry.leases == 0 {
+		close(entry.idle)
+	}
+}
+
+// Remove closes and removes the Container for the key, if there is one.
+//
+// If the Container is acquired, Remove waits for it to be released, or until ctx is done.
+func (s *ContainerSet[K]) Remove(ctx context.Context, key K) error {
+	s.mu.Lock()
+	elem, ok := s.entries[key]
+	if ok {
+		s.order.Remove(elem)
+		delete(s.entries, key)
+		s.retireLocked(elem.Value.(*containerSetEntry[K]))
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	return errors.Wrapf(closeEntry(ctx, elem.Value.(*containerSetEntry[K])), "di.ContainerSet.Remove %v", key)
+}
+
+// Len returns the number of Containers in the set.
+func (s *ContainerSet[K]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.order.Len()
+}
+
+// Close closes all Containers in the set.
+//
+// Acquired Containers are closed after they are released, or when ctx is done.
+// Errors returned from closing evicted Containers are also returned.
+// Get will return an error if called after the set has been closed.
+func (s *ContainerSet[K]) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return errors.Wrap(errContainerClosed, "di.ContainerSet.Close: closed already")
+	}
+	s.closed = true
+
+	entries := make([]*containerSetEntry[K], 0, s.order.Len())
+	for elem := s.order.Front(); elem != nil; elem = elem.Next() {
+		entry := elem.Value.(*containerSetEntry[K])
+		s.retireLocked(entry)
+		entries = append(entries, entry)
+	}
+	s.entries = make(map[K]*list.Element)
+	s.order.Init()
+	s.mu.Unlock()
+
+	var errs []error
+	for _, entry := range entries {
+		if err := closeEntry(ctx, entry); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	s.evicting.Wait()
+	s.mu.Lock()
+	errs = append(errs, s.evictErrs...)
+	if s.dropped > 0 {
+		errs = append(errs, errors.Errorf("%d more errors closing evicted Containers", s.dropped))
+	}
+	s.evictErrs = nil
+	s.dropped = 0
+	s.mu.Unlock()
+
+	return errors.Wrap(errors.Join(errs...), "di.ContainerSet.Close")
+}
+
+// evictLocked removes the least recently used entries over the limit.
+// The caller must hold the lock, and call evict with the entries.
+func (s *ContainerSet[K]) evictLocked() []*containerSetEntry[K] {
+	evicted := make([]*containerSetEntry[K], 0, max(s.order.Len()-s.limit, 0))
+	for s.order.Len() > s.limit {
+		entry := s.order.Remove(s.order.Back()).(*containerSetEntry[K])
+		delete(s.entries, entry.key)
+		s.retireLocked(entry)
+		evicted = append(evicted, entry)
+	}
+
+	// Add while holding the lock, so Close waits for these entries
+	s.evicting.Add(len(evicted))
+	return evicted
+}
+
+// evict closes evicted Containers in the background and keeps any errors to be returned by Close.
+func (s *ContainerSet[K]) evict(ctx context.Context, evicted []*containerSetEntry[K]) {
+	ctx = context.WithoutCancel(ctx)
+	for _, entry := range evicted {
+		go func() {
+			defer s.evicting.Done()
+
+			if err := closeEntry(ctx, entry); err != nil {
+				s.mu.Lock()
+				if len(s.evictErrs) < maxEvictErrors {
+					s.evictErrs = append(s.evictErrs, err)
+				} else {
+					s.dropped++
+				}
+				s.mu.Unlock()
+			}
+		}()
+	}
+}
+
+// closeEntry closes the Container for a retired entry after it is created and released,
+// or after ctx is done.
+func closeEntry[K comparable](ctx context.Context, entry *containerSetEntry[K]) error {
+	<-entry.ready
+	if entry.c == nil {
+		return nil
+	}
+
+	select {
+	case <-entry.idle:
+	case <-ctx.Done():
+	}
+
+	return entry.c.Close(ctx)
+}
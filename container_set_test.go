@@ -0,0 +1,289 @@
+package di_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tenantConfig struct {
+	ID string
+}
+
+type tenantCloser struct {
+	close func(context.Context) error
+}
+
+func (c *tenantCloser) Close(ctx context.Context) error {
+	return c.close(ctx)
+}
+
+func Test_ContainerSet(t *testing.T) {
+	ctx := context.Background()
+
+	tenantTemplate := func(closed *[]string, mu *sync.Mutex) func(string) []di.ContainerOption {
+		return func(id string) []di.ContainerOption {
+			return []di.ContainerOption{
+				di.WithService(&tenantConfig{ID: id}),
+				di.WithService(&tenantCloser{close: func(context.Context) error {
+					mu.Lock()
+					defer mu.Unlock()
+					*closed = append(*closed, id)
+					return nil
+				}}, di.UseCloser()),
+			}
+		}
+	}
+
+	t.Run("Get", func(t *testing.T) {
+		var closed []string
+		var mu sync.Mutex
+		set, err := di.NewContainerSet(tenantTemplate(&closed, &mu))
+		require.NoError(t, err)
+
+		c1, err := set.Get(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, "a", di.MustResolve[*tenantConfig](ctx, c1).ID)
+
+		c2, err := set.Get(ctx, "a")
+		require.NoError(t, err)
+		assert.Same(t, c1, c2)
+
+		c3, err := set.Get(ctx, "b")
+		require.NoError(t, err)
+		assert.NotSame(t, c1, c3)
+		assert.Equal(t, 2, set.Len())
+
+		err = set.Close(ctx)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"a", "b"}, closed)
+	})
+
+	t.Run("evicts least recently used", func(t *testing.T) {
+		var closed []string
+		var mu sync.Mutex
+		set, err := di.NewContainerSet(tenantTemplate(&closed, &mu), di.WithContainerLimit(2))
+		require.NoError(t, err)
+
+		a, _ := set.Get(ctx, "a")
+		_, _ = set.Get(ctx, "b")
+		_, _ = set.Get(ctx, "a")
+		_, _ = set.Get(ctx, "c")
+		assert.Equal(t, 2, set.Len())
+
+		a2, err := set.Get(ctx, "a")
+		require.NoError(t, err)
+		assert.Same(t, a, a2)
+
+		err = set.Close(ctx)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"a", "b", "c"}, closed)
+	})
+
+	t.Run("evict error returned from Close", func(t *testing.T) {
+		set, err := di.NewContainerSet(func(id string) []di.ContainerOption {
+			return []di.ContainerOption{
+				di.WithService(&tenantCloser{close: func(context.Context) error {
+					return errors.Errorf("close error %s", id)
+				}}, di.UseCloser()),
+			}
+		}, di.WithContainerLimit(1))
+		require.NoError(t, err)
+
+		_, _ = set.Get(ctx, "a")
+		_, _ = set.Get(ctx, "b")
+
+		err = set.Close(ctx)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.ContainerSet.Close: di.Container.Close: close error b\n"+
+			"di.Container.Close: close error a")
+	})
+
+	t.Run("evict errors capped", func(t *testing.T) {
+		set, err := di.NewContainerSet(func(id string) []di.ContainerOption {
+			return []di.ContainerOption{
+				di.WithService(&tenantCloser{close: func(context.Context) error {
+					return errors.Errorf("close error %s", id)
+				}}, di.UseCloser()),
+			}
+		}, di.WithContainerLimit(1))
+		require.NoError(t, err)
+
+		for i := range 103 {
+			_, _ = set.Get(ctx, fmt.Sprint(i))
+		}
+
+		err = set.Close(ctx)
+		require.Error(t, err)
+		assert.Len(t, strings.Split(err.Error(), "\n"), 102)
+		assert.Contains(t, err.Error(), "\n2 more errors closing evicted Containers")
+	})
+
+	t.Run("Acquire", func(t *testing.T) {
+		var closed []string
+		var mu sync.Mutex
+		set, err := di.NewContainerSet(tenantTemplate(&closed, &mu), di.WithContainerLimit(1))
+		require.NoError(t, err)
+
+		a, release, err := set.Acquire(ctx, "a")
+		require.NoError(t, err)
+
+		// Evict a while it is acquired
+		_, err = set.Get(ctx, "b")
+		require.NoError(t, err)
+
+		mu.Lock()
+		assert.Empty(t, closed)
+		mu.Unlock()
+		assert.Equal(t, "a", di.MustResolve[*tenantConfig](ctx, a).ID)
+
+		release()
+		release()
+
+		err = set.Close(ctx)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"a", "b"}, closed)
+	})
+
+	t.Run("Remove acquired", func(t *testing.T) {
+		var closed []string
+		var mu sync.Mutex
+		set, err := di.NewContainerSet(tenantTemplate(&closed, &mu))
+		require.NoError(t, err)
+
+		_, release, err := set.Acquire(ctx, "a")
+		require.NoError(t, err)
+
+		removed := make(chan error)
+		go func() {
+			removed <- set.Remove(ctx, "a")
+		}()
+
+		select {
+		case <-removed:
+			assert.Fail(t, "Remove should wait for release")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		release()
+		require.NoError(t, <-removed)
+		assert.Equal(t, []string{"a"}, closed)
+	})
+
+	t.Run("template panics", func(t *testing.T) {
+		set, err := di.NewContainerSet(func(string) []di.ContainerOption {
+			panic("template panic")
+		})
+		require.NoError(t, err)
+
+		_, err = set.Get(ctx, "a")
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.ContainerSet.Get a: template function for key a panicked: template panic")
+
+		// Other callers are not blocked
+		_, _, err = set.Acquire(ctx, "a")
+		assert.EqualError(t, err, "di.ContainerSet.Acquire a: template function for key a panicked: template panic")
+
+		err = set.Close(ctx)
+		require.NoError(t, err)
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		var closed []string
+		var mu sync.Mutex
+		set, err := di.NewContainerSet(tenantTemplate(&closed, &mu))
+		require.NoError(t, err)
+
+		c1, _ := set.Get(ctx, "a")
+		err = set.Remove(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a"}, closed)
+		assert.Equal(t, 0, set.Len())
+
+		err = set.Remove(ctx, "a")
+		require.NoError(t, err)
+
+		c2, err := set.Get(ctx, "a")
+		require.NoError(t, err)
+		assert.NotSame(t, c1, c2)
+	})
+
+	t.Run("error not kept", func(t *testing.T) {
+		calls := 0
+		set, err := di.NewContainerSet(func(string) []di.ContainerOption {
+			calls++
+			if calls == 1 {
+				return []di.ContainerOption{di.WithService(nil)}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		c, err := set.Get(ctx, "a")
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.ContainerSet.Get a: di.NewContainer: WithService: funcOrValue is nil")
+		assert.Equal(t, 0, set.Len())
+
+		c, err = set.Get(ctx, "a")
+		require.NoError(t, err)
+		assert.NotNil(t, c)
+	})
+
+	t.Run("concurrent Get", func(t *testing.T) {
+		set, err := di.NewContainerSet(func(string) []di.ContainerOption { return nil })
+		require.NoError(t, err)
+
+		results := make([]*di.Container, 10)
+		var wg sync.WaitGroup
+		for i := range results {
+			wg.Go(func() {
+				results[i], _ = set.Get(ctx, "a")
+			})
+		}
+		wg.Wait()
+
+		for _, c := range results {
+			assert.Same(t, results[0], c)
+		}
+	})
+
+	t.Run("Get after Close", func(t *testing.T) {
+		set, err := di.NewContainerSet(func(string) []di.ContainerOption { return nil })
+		require.NoError(t, err)
+
+		err = set.Close(ctx)
+		require.NoError(t, err)
+
+		c, err := set.Get(ctx, "a")
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.ContainerSet.Get a: container closed")
+
+		err = set.Close(ctx)
+		assert.EqualError(t, err, "di.ContainerSet.Close: closed already: container closed")
+	})
+
+	t.Run("nil template", func(t *testing.T) {
+		set, err := di.NewContainerSet[string](nil)
+		testutils.LogError(t, err)
+		assert.Nil(t, set)
+		assert.EqualError(t, err, "di.NewContainerSet: template is nil")
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		set, err := di.NewContainerSet(func(string) []di.ContainerOption { return nil }, di.WithContainerLimit(0))
+		testutils.LogError(t, err)
+		assert.Nil(t, set)
+		assert.EqualError(t, err, "di.NewContainerSet: WithContainerLimit 0: limit must be positive")
+	})
+}
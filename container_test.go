@@ -16,9 +16,17 @@ import (
 	"github.com/sectrean/di-kit/internal/testtypes"
 	"github.com/sectrean/di-kit/internal/testutils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
+type (
+	routes []string
+	limits map[string]int
+	topics chan string
+	hook   func()
+)
+
 // TODO: Add tests for the following:
 // - more tests around the resolve locking
 
@@ -46,7 +54,7 @@ func Test_NewContainer(t *testing.T) {
 		testutils.LogError(t, err)
 
 		assert.Nil(t, c)
-		assert.EqualError(t, err, "di.NewContainer: WithService int: invalid service type")
+		assert.EqualError(t, err, "di.NewContainer: WithService int: invalid service type; use a named type or a pointer to a named type")
 	})
 
 	t.Run("WithService interface nil", func(t *testing.T) {
@@ -78,7 +86,7 @@ func Test_NewContainer(t *testing.T) {
 		testutils.LogError(t, err)
 
 		assert.Nil(t, c)
-		assert.EqualError(t, err, "di.NewContainer: WithService di.Lifetime: invalid service type")
+		assert.EqualError(t, err, "di.NewContainer: WithService di.Lifetime: invalid service type; types from package di are reserved")
 	})
 
 	t.Run("WithService invalid type map", func(t *testing.T) {
@@ -88,7 +96,62 @@ func Test_NewContainer(t *testing.T) {
 		testutils.LogError(t, err)
 
 		assert.Nil(t, c)
-		assert.EqualError(t, err, "di.NewContainer: WithService map[string]int: invalid service type")
+		assert.EqualError(t, err, "di.NewContainer: WithService map[string]int: invalid service type; use a named type or a pointer to a named type")
+	})
+
+	t.Run("WithService named slice, map, and chan types", func(t *testing.T) {
+		ctx := context.Background()
+		c, err := di.NewContainer(
+			di.WithService(routes{"/a", "/b"}),
+			di.WithService(limits{"a": 1}),
+			di.WithService(func() topics { return make(topics, 1) }),
+			di.WithService(func(r routes, l limits, t topics) *testtypes.StructA {
+				return &testtypes.StructA{}
+			}),
+			di.WithDependencyValidation(),
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, routes{"/a", "/b"}, di.MustResolve[routes](ctx, c))
+		assert.Equal(t, limits{"a": 1}, di.MustResolve[limits](ctx, c))
+		assert.NotNil(t, di.MustResolve[topics](ctx, c))
+		assert.NotNil(t, di.MustResolve[*testtypes.StructA](ctx, c))
+
+		all, err := di.ResolveAll[routes](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, []routes{{"/a", "/b"}}, all)
+	})
+
+	t.Run("WithService named slice and map nil", func(t *testing.T) {
+		ctx := context.Background()
+		c, err := di.NewContainer(
+			di.WithService(routes(nil)),
+			di.WithService(limits(nil)),
+		)
+		require.NoError(t, err)
+
+		assert.Empty(t, di.MustResolve[routes](ctx, c))
+		assert.Empty(t, di.MustResolve[limits](ctx, c))
+	})
+
+	t.Run("WithService named chan nil", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(topics(nil)),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService: funcOrValue is nil")
+	})
+
+	t.Run("WithValue named func nil", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithValue(hook(nil)),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithValue di_test.hook: value is nil")
 	})
 
 	t.Run("WithService invalid type *int", func(t *testing.T) {
@@ -98,7 +161,8 @@ func Test_NewContainer(t *testing.T) {
 		testutils.LogError(t, err)
 
 		assert.Nil(t, c)
-		assert.EqualError(t, err, "di.NewContainer: WithService func() *int: invalid service type")
+		assert.EqualError(t, err, "di.NewContainer: WithService func() *int: return type *int: invalid service type; "+
+			"use a named type or a pointer to a named type")
 	})
 
 	t.Run("WithService func returns unnamed func", func(t *testing.T) {
@@ -108,7 +172,9 @@ func Test_NewContainer(t *testing.T) {
 		testutils.LogError(t, err)
 
 		assert.Nil(t, c)
-		assert.EqualError(t, err, "di.NewContainer: WithService func() func(http.Handler) http.Handler: invalid service type")
+		assert.EqualError(t, err, "di.NewContainer: WithService func() func(http.Handler) http.Handler: "+
+			"return type func(http.Handler) http.Handler: invalid service type; "+
+			"use a named type or a pointer to a named type")
 	})
 
 	t.Run("WithService invalid dependency type", func(t *testing.T) {
@@ -118,7 +184,8 @@ func Test_NewContainer(t *testing.T) {
 		testutils.LogError(t, err)
 
 		assert.Nil(t, c)
-		assert.EqualError(t, err, "di.NewContainer: WithService func(int) testtypes.InterfaceA: invalid dependency type int")
+		assert.EqualError(t, err, "di.NewContainer: WithService func(int) testtypes.InterfaceA: parameter 0: invalid dependency type int; "+
+			"use a named type or a pointer to a named type")
 	})
 
 	t.Run("WithService invalid dependency types", func(t *testing.T) {
@@ -128,8 +195,9 @@ func Test_NewContainer(t *testing.T) {
 		testutils.LogError(t, err)
 
 		assert.Nil(t, c)
-		assert.EqualError(t, err, "di.NewContainer: WithService func(int, di.Lifetime) testtypes.InterfaceA: invalid dependency type int\n"+
-			"invalid dependency type di.Lifetime")
+		assert.EqualError(t, err, "di.NewContainer: WithService func(int, di.Lifetime) testtypes.InterfaceA: parameter 0: invalid dependency type int; "+
+			"use a named type or a pointer to a named type\n"+
+			"parameter 1: invalid dependency type di.Lifetime; types from package di are reserved")
 	})
 
 	t.Run("WithService As not assignable", func(t *testing.T) {
@@ -149,7 +217,8 @@ func Test_NewContainer(t *testing.T) {
 		testutils.LogError(t, err)
 
 		assert.Nil(t, c)
-		assert.EqualError(t, err, "di.NewContainer: WithService testtypes.CustomMap: As map[string]interface {}: invalid service type")
+		assert.EqualError(t, err, "di.NewContainer: WithService testtypes.CustomMap: As map[string]interface {}: invalid service type;"+
+			" use a named type or a pointer to a named type")
 	})
 
 	t.Run("WithService SingletonLifetime value service", func(t *testing.T) {
@@ -212,7 +281,46 @@ func Test_NewContainer(t *testing.T) {
 
 		assert.Nil(t, c)
 		assert.EqualError(t, err,
-			"di.NewContainer: WithService func() (testtypes.InterfaceA, testtypes.InterfaceB): function must return Service or (Service, error)")
+			"di.NewContainer: WithService func() (testtypes.InterfaceA, testtypes.InterfaceB): "+
+				"function must return Service, (Service, error), or (Service, func(), error); return 1 has type testtypes.InterfaceB, expected error")
+	})
+
+	t.Run("WithService unsupported func signature three return values", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() (testtypes.InterfaceA, testtypes.InterfaceB, error) { return nil, nil, nil }),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err,
+			"di.NewContainer: WithService func() (testtypes.InterfaceA, testtypes.InterfaceB, error): "+
+				"function must return Service, (Service, error), or (Service, func(), error); "+
+				"return 1 has type testtypes.InterfaceB, expected func()")
+	})
+
+	t.Run("WithService unsupported func signature cleanup without error", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() (testtypes.InterfaceA, func(), testtypes.InterfaceB) { return nil, nil, nil }),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err,
+			"di.NewContainer: WithService func() (testtypes.InterfaceA, func(), testtypes.InterfaceB): "+
+				"function must return Service, (Service, error), or (Service, func(), error); "+
+				"return 2 has type testtypes.InterfaceB, expected error")
+	})
+
+	t.Run("WithService too many return values", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() (testtypes.InterfaceA, func(), error, error) { return nil, nil, nil, nil }),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err,
+			"di.NewContainer: WithService func() (testtypes.InterfaceA, func(), error, error): "+
+				"function must return Service, (Service, error), or (Service, func(), error); function has 4 return values")
 	})
 
 	t.Run("WithService invalid type error", func(t *testing.T) {
@@ -222,7 +330,8 @@ func Test_NewContainer(t *testing.T) {
 		testutils.LogError(t, err)
 
 		assert.Nil(t, c)
-		assert.EqualError(t, err, "di.NewContainer: WithService func() error: invalid service type")
+		assert.EqualError(t, err, "di.NewContainer: WithService func() error: return type error: invalid service type; "+
+			"error is reserved and can only be used as a dependency")
 	})
 
 	t.Run("WithService invalid type context.Context", func(t *testing.T) {
@@ -232,7 +341,8 @@ func Test_NewContainer(t *testing.T) {
 		testutils.LogError(t, err)
 
 		assert.Nil(t, c)
-		assert.EqualError(t, err, "di.NewContainer: WithService func() context.Context: invalid service type")
+		assert.EqualError(t, err, "di.NewContainer: WithService func() context.Context: return type context.Context: invalid service type; "+
+			"context.Context is reserved and can only be used as a dependency")
 	})
 
 	t.Run("WithService invalid basic types", func(t *testing.T) {
@@ -243,8 +353,8 @@ func Test_NewContainer(t *testing.T) {
 		testutils.LogError(t, err)
 
 		assert.Nil(t, c)
-		assert.EqualError(t, err, "di.NewContainer: WithService []int: invalid service type\n"+
-			"WithService map[string]int: invalid service type",
+		assert.EqualError(t, err, "di.NewContainer: WithService []int: invalid service type; use a named type or a pointer to a named type\n"+
+			"WithService map[string]int: invalid service type; use a named type or a pointer to a named type",
 		)
 	})
 
@@ -256,7 +366,8 @@ func Test_NewContainer(t *testing.T) {
 		testutils.LogError(t, err)
 
 		assert.Nil(t, c)
-		assert.EqualError(t, err, "di.NewContainer: WithService []testtypes.InterfaceA: invalid service type\n"+
+		assert.EqualError(t, err, "di.NewContainer: WithService []testtypes.InterfaceA: invalid service type; "+
+			"register each element as testtypes.InterfaceA, and depend on []testtypes.InterfaceA to resolve all of them\n"+
 			"WithService func() testtypes.InterfaceA: As testtypes.InterfaceB: type testtypes.InterfaceA not assignable to testtypes.InterfaceB",
 		)
 	})
@@ -272,12 +383,49 @@ func Test_NewContainer(t *testing.T) {
 		testutils.LogError(t, err)
 
 		assert.Nil(t, c)
-		assert.EqualError(t, err, "di.NewContainer: WithService []testtypes.InterfaceA: invalid service type\n"+
+		assert.EqualError(t, err, "di.NewContainer: WithService []testtypes.InterfaceA: invalid service type; "+
+			"register each element as testtypes.InterfaceA, and depend on []testtypes.InterfaceA to resolve all of them\n"+
 			"WithService func() testtypes.InterfaceA: As testtypes.InterfaceB: type testtypes.InterfaceA not assignable to testtypes.InterfaceB\n"+
 			"WithTagged *testtypes.StructB: parameter not found",
 		)
 	})
 
+	t.Run("multiple errors unwrap", func(t *testing.T) {
+		_, err := di.NewContainer(
+			di.WithService([]testtypes.InterfaceA{}),
+			di.WithService(testtypes.NewInterfaceA, di.As[testtypes.InterfaceB]()),
+		)
+		require.Error(t, err)
+
+		multi, ok := err.(interface{ Unwrap() []error })
+		require.True(t, ok)
+
+		errs := multi.Unwrap()
+		require.Len(t, errs, 2)
+		assert.EqualError(t, errs[0], "WithService []testtypes.InterfaceA: invalid service type; "+
+			"register each element as testtypes.InterfaceA, and depend on []testtypes.InterfaceA to resolve all of them")
+		assert.EqualError(t, errs[1], "WithService func() testtypes.InterfaceA: As testtypes.InterfaceB: "+
+			"type testtypes.InterfaceA not assignable to testtypes.InterfaceB")
+	})
+
+	t.Run("WithDependencyValidation multiple errors unwrap", func(t *testing.T) {
+		_, err := di.NewContainer(
+			di.WithService(func(testtypes.InterfaceC) testtypes.InterfaceA { return nil }),
+			di.WithService(func(testtypes.InterfaceD) testtypes.InterfaceB { return nil }),
+			di.WithDependencyValidation(),
+		)
+		require.Error(t, err)
+
+		multi, ok := err.(interface{ Unwrap() []error })
+		require.True(t, ok)
+
+		errs := multi.Unwrap()
+		require.Len(t, errs, 2)
+		for _, err := range errs {
+			assert.ErrorContains(t, err, "service not registered")
+		}
+	})
+
 	t.Run("Module", func(t *testing.T) {
 		c, err := di.NewContainer(
 			di.Module{
@@ -290,6 +438,48 @@ func Test_NewContainer(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("nested modules", func(t *testing.T) {
+		inner := di.Module{
+			di.WithService(testtypes.NewInterfaceA),
+		}
+		middle := di.Module{
+			di.WithModule(inner),
+			di.WithService(testtypes.NewInterfaceB),
+		}
+		outer := di.Module{
+			middle,
+			di.WithModule(di.Module{
+				di.WithService(testtypes.NewInterfaceC),
+			}),
+		}
+
+		c, err := di.NewContainer(
+			di.WithModule(outer),
+			di.WithDependencyValidation(),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceC](context.Background(), c)
+		assert.NoError(t, err)
+	})
+
+	t.Run("nested module errors", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.Module{
+				di.Module{
+					di.Module{
+						di.WithService(nil),
+					},
+				},
+				di.WithService(testtypes.NewInterfaceA),
+			},
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService: funcOrValue is nil")
+	})
+
 	t.Run("WithModule WithService nil", func(t *testing.T) {
 		c, err := di.NewContainer(
 			di.WithModule([]di.ContainerOption{
@@ -400,6 +590,83 @@ func Test_NewContainer(t *testing.T) {
 		assert.NotNil(t, c)
 		assert.NoError(t, err)
 	})
+
+	t.Run("WithRequireScope", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(testtypes.NewInterfaceB, di.Scoped),
+			di.WithRequireScope(),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve testtypes.InterfaceA: must be resolved from a child scope")
+
+		scope, err := c.NewScope()
+		require.NoError(t, err)
+
+		a1, err := di.Resolve[testtypes.InterfaceA](ctx, scope)
+		assert.NotNil(t, a1)
+		assert.NoError(t, err)
+
+		b, err := di.Resolve[testtypes.InterfaceB](ctx, scope)
+		assert.NotNil(t, b)
+		assert.NoError(t, err)
+
+		// Singletons are still shared between scopes
+		scope2, err := c.NewScope()
+		require.NoError(t, err)
+
+		a2, err := di.Resolve[testtypes.InterfaceA](ctx, scope2)
+		assert.Same(t, a1, a2)
+		assert.NoError(t, err)
+
+		// Invoke is allowed on the root container
+		err = di.Invoke(ctx, c, func(a testtypes.InterfaceA) {
+			assert.Same(t, a1, a)
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("WithRequireScope child scope", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		scope, err := c.NewScope(
+			di.WithRequireScope(),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, scope)
+		assert.EqualError(t, err, "di.Container.NewScope: WithRequireScope: not supported for child scope")
+	})
+}
+
+func Test_Container_NewChildScope(t *testing.T) {
+	t.Run("Container", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewChildScope()
+		require.NoError(t, err)
+		assert.IsType(t, &di.Container{}, scope)
+		ditest.AssertContains[testtypes.InterfaceA](t, scope)
+	})
+
+	t.Run("closed Container", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+		require.NoError(t, c.Close(context.Background()))
+
+		scope, err := c.NewChildScope()
+		testutils.LogError(t, err)
+		assert.Nil(t, scope)
+		assert.Error(t, err)
+	})
 }
 
 func Test_Container_NewScope(t *testing.T) {
@@ -449,7 +716,7 @@ func Test_Container_NewScope(t *testing.T) {
 		testutils.LogError(t, err)
 
 		assert.Nil(t, scope)
-		assert.EqualError(t, err, "di.Container.NewScope: WithService di.Lifetime: invalid service type")
+		assert.EqualError(t, err, "di.Container.NewScope: WithService di.Lifetime: invalid service type; types from package di are reserved")
 	})
 
 	t.Run("parent closed", func(t *testing.T) {
@@ -607,6 +874,77 @@ func Test_Container_Contains(t *testing.T) {
 	})
 }
 
+func Test_Container_Lookup(t *testing.T) {
+	t.Run("service not registered", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		info, ok := c.Lookup(reflect.TypeFor[testtypes.InterfaceA]())
+		assert.False(t, ok)
+		assert.Zero(t, info)
+	})
+
+	t.Run("root Container", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(testtypes.NewInterfaceA, di.WithTag("tag")),
+			di.WithService(testtypes.NewInterfaceA, di.WithTag("tag")),
+		)
+		require.NoError(t, err)
+
+		info, ok := c.Lookup(reflect.TypeFor[testtypes.InterfaceA]())
+		require.True(t, ok)
+		assert.Equal(t, reflect.TypeFor[testtypes.InterfaceA](), info.Type)
+		assert.Nil(t, info.Tag)
+		assert.NotEmpty(t, info.ID)
+		assert.Equal(t, 0, info.Depth)
+		assert.Equal(t, 0, info.Index)
+
+		info, ok = c.Lookup(reflect.TypeFor[testtypes.InterfaceA](), di.WithTag("tag"))
+		require.True(t, ok)
+		assert.Equal(t, "tag", info.Tag)
+		assert.Equal(t, 0, info.Depth)
+		assert.Equal(t, 1, info.Index)
+	})
+
+	t.Run("shadowed by child scope", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(testtypes.NewInterfaceB),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope()
+		require.NoError(t, err)
+
+		child, err := scope.NewScope(
+			di.WithService(testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+
+		info, ok := child.Lookup(reflect.TypeFor[testtypes.InterfaceA]())
+		require.True(t, ok)
+		assert.Equal(t, 2, info.Depth)
+
+		info, ok = child.Lookup(reflect.TypeFor[testtypes.InterfaceB]())
+		require.True(t, ok)
+		assert.Equal(t, 0, info.Depth)
+	})
+
+	t.Run("slice", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+
+		info, ok := c.Lookup(reflect.TypeFor[[]testtypes.InterfaceA]())
+		require.True(t, ok)
+		assert.Equal(t, reflect.TypeFor[testtypes.InterfaceA](), info.Type)
+		assert.Equal(t, 1, info.Index)
+	})
+}
+
 func Test_Container_Resolve(t *testing.T) {
 	t.Run("value service", func(t *testing.T) {
 		c, err := di.NewContainer(
@@ -1954,6 +2292,120 @@ func Test_Container_Close(t *testing.T) {
 		assert.EqualError(t, err, "di.Container.Close: closed already: container closed")
 	})
 
+	t.Run("Resolve while closing", func(t *testing.T) {
+		var c *di.Container
+		var resolveErr, scopeErr error
+
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceB),
+			di.WithService(func() testtypes.InterfaceA {
+				a := mocks.NewInterfaceAMock(t)
+				a.EXPECT().
+					Close(mock.Anything).
+					RunAndReturn(func(ctx context.Context) error {
+						_, resolveErr = di.Resolve[testtypes.InterfaceB](ctx, c)
+						_, scopeErr = c.NewScope()
+						return nil
+					})
+				return a
+			}),
+		)
+		require.NoError(t, err)
+
+		_ = di.MustResolve[testtypes.InterfaceA](context.Background(), c)
+
+		err = c.Close(context.Background())
+		require.NoError(t, err)
+
+		testutils.LogError(t, resolveErr)
+		require.ErrorIs(t, resolveErr, di.ErrContainerClosing)
+		assert.EqualError(t, resolveErr, "di.Container.Resolve testtypes.InterfaceB: container closing")
+		assert.EqualError(t, scopeErr, "di.Container.NewScope: container closing")
+
+		// After Close returns, the Container is closed
+		_, err = di.Resolve[testtypes.InterfaceB](context.Background(), c)
+		assert.EqualError(t, err, "di.Container.Resolve testtypes.InterfaceB: container closed")
+	})
+
+	t.Run("child scope Resolve parent singleton while closing", func(t *testing.T) {
+		var scope *di.Container
+		var resolveErr error
+
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceB),
+			di.WithService(func() testtypes.InterfaceA {
+				a := mocks.NewInterfaceAMock(t)
+				a.EXPECT().
+					Close(mock.Anything).
+					RunAndReturn(func(ctx context.Context) error {
+						_, resolveErr = di.Resolve[testtypes.InterfaceB](ctx, scope)
+						return nil
+					})
+				return a
+			}),
+		)
+		require.NoError(t, err)
+
+		scope, err = c.NewScope()
+		require.NoError(t, err)
+
+		_ = di.MustResolve[testtypes.InterfaceA](context.Background(), scope)
+
+		err = c.Close(context.Background())
+		require.NoError(t, err)
+
+		testutils.LogError(t, resolveErr)
+		assert.EqualError(t, resolveErr, "di.Container.Resolve testtypes.InterfaceB: container closing")
+	})
+
+	t.Run("waits for parent singleton constructor", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		var events []string
+		var mu sync.Mutex
+		record := func(e string) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, e)
+		}
+
+		c, err := di.NewContainer(
+			di.WithService(func() *testtypes.StructA {
+				close(started)
+				<-release
+				record("constructed")
+				return &testtypes.StructA{}
+			}, di.UseCloseFunc(func(context.Context, *testtypes.StructA) error {
+				record("closed")
+				return nil
+			})),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope()
+		require.NoError(t, err)
+
+		resolved := make(chan struct{})
+		go func() {
+			defer close(resolved)
+			_, err := di.Resolve[*testtypes.StructA](context.Background(), scope)
+			assert.NoError(t, err)
+		}()
+		<-started
+
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			assert.NoError(t, c.Close(context.Background()))
+		}()
+
+		close(release)
+		<-resolved
+		<-closed
+
+		assert.Equal(t, []string{"constructed", "closed"}, events)
+	})
+
 	t.Run("all close funcs", func(t *testing.T) {
 		ctx := testutils.ContextWithTestValue(context.Background(), "value")
 
@@ -2293,3 +2745,346 @@ func Test_Container_Close(t *testing.T) {
 		}
 	})
 }
+
+func Test_WithError(t *testing.T) {
+	t.Run("error", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithError(errors.New("WithQueue: name is empty")),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithQueue: name is empty")
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithError(nil),
+		)
+		assert.NotNil(t, c)
+		assert.NoError(t, err)
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		scope, err := c.NewScope(
+			di.WithError(errors.New("WithQueue: name is empty")),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, scope)
+		assert.EqualError(t, err, "di.Container.NewScope: WithQueue: name is empty")
+	})
+}
+
+func Test_WithDefaultResolveOptions(t *testing.T) {
+	a := &testtypes.StructA{Tag: "default"}
+	aTagged := &testtypes.StructA{Tag: "tagged"}
+	aOther := &testtypes.StructA{Tag: "other"}
+
+	newContainer := func(t *testing.T, opts ...di.ContainerOption) *di.Container {
+		c, err := di.NewContainer(append([]di.ContainerOption{
+			di.WithService(a),
+			di.WithService(aTagged, di.WithTag("tagged")),
+			di.WithService(aOther, di.WithTag("other")),
+		}, opts...)...)
+		require.NoError(t, err)
+		return c
+	}
+
+	t.Run("Resolve", func(t *testing.T) {
+		c := newContainer(t, di.WithDefaultResolveOptions(di.WithTag("tagged")))
+
+		ctx := context.Background()
+		got, err := di.Resolve[*testtypes.StructA](ctx, c)
+		assert.Same(t, aTagged, got)
+		assert.NoError(t, err)
+
+		// Options passed to Resolve take precedence
+		got, err = di.Resolve[*testtypes.StructA](ctx, c, di.WithTag("other"))
+		assert.Same(t, aOther, got)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Contains", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(a),
+			di.WithDefaultResolveOptions(di.WithTag("tagged")),
+		)
+		require.NoError(t, err)
+
+		assert.False(t, c.Contains(reflect.TypeFor[*testtypes.StructA]()))
+		assert.True(t, c.Contains(reflect.TypeFor[*testtypes.StructA](), di.WithTag(nil)))
+	})
+
+	t.Run("Invoke", func(t *testing.T) {
+		c := newContainer(t, di.WithDefaultResolveOptions(di.WithTag("tagged")))
+
+		err := di.Invoke(context.Background(), c, func(got *testtypes.StructA) {
+			assert.Same(t, aTagged, got)
+		})
+		assert.NoError(t, err)
+
+		err = di.Invoke(context.Background(), c, func(got *testtypes.StructA) {
+			assert.Same(t, aOther, got)
+		}, di.WithTagged[*testtypes.StructA]("other"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("not applied to dependencies", func(t *testing.T) {
+		var dep *testtypes.StructA
+		c := newContainer(t,
+			di.WithService(func(a *testtypes.StructA) *testtypes.StructB {
+				dep = a
+				return &testtypes.StructB{}
+			}, di.WithTag("tagged")),
+			di.WithDefaultResolveOptions(di.WithTag("tagged")),
+		)
+
+		_, err := di.Resolve[*testtypes.StructB](context.Background(), c)
+		assert.NoError(t, err)
+		assert.Same(t, a, dep)
+	})
+
+	t.Run("inherited by child scope", func(t *testing.T) {
+		c := newContainer(t, di.WithDefaultResolveOptions(di.WithTag("tagged")))
+
+		scope, err := c.NewScope()
+		require.NoError(t, err)
+
+		got, err := di.Resolve[*testtypes.StructA](context.Background(), scope)
+		assert.Same(t, aTagged, got)
+		assert.NoError(t, err)
+
+		scope2, err := c.NewScope(di.WithDefaultResolveOptions(di.WithTag("other")))
+		require.NoError(t, err)
+
+		got, err = di.Resolve[*testtypes.StructA](context.Background(), scope2)
+		assert.Same(t, aOther, got)
+		assert.NoError(t, err)
+
+		// The parent is not affected by the child scope
+		got, err = di.Resolve[*testtypes.StructA](context.Background(), c)
+		assert.Same(t, aTagged, got)
+		assert.NoError(t, err)
+	})
+}
+
+func Test_WithDeclaredService(t *testing.T) {
+	t.Run("interface", func(t *testing.T) {
+		var a testtypes.InterfaceA = &testtypes.StructA{}
+
+		c, err := di.NewContainer(
+			di.WithDeclaredService(a),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[testtypes.InterfaceA](context.Background(), c)
+		assert.NoError(t, err)
+		assert.Same(t, a, got)
+
+		assert.False(t, c.Contains(reflect.TypeFor[*testtypes.StructA]()))
+	})
+
+	t.Run("As", func(t *testing.T) {
+		var a testtypes.InterfaceA = &testtypes.StructA{}
+
+		c, err := di.NewContainer(
+			di.WithDeclaredService(a, di.As[*testtypes.StructA]()),
+		)
+		require.NoError(t, err)
+
+		assert.True(t, c.Contains(reflect.TypeFor[testtypes.InterfaceA]()))
+		assert.True(t, c.Contains(reflect.TypeFor[*testtypes.StructA]()))
+	})
+
+	t.Run("func type", func(t *testing.T) {
+		called := false
+		mw := testtypes.HTTPMiddleware(func(h http.Handler) http.Handler {
+			called = true
+			return h
+		})
+
+		c, err := di.NewContainer(
+			di.WithDeclaredService(mw),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[testtypes.HTTPMiddleware](context.Background(), c)
+		require.NoError(t, err)
+
+		got(nil)
+		assert.True(t, called)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithDeclaredService[testtypes.InterfaceA](nil),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithDeclaredService testtypes.InterfaceA: value is nil")
+	})
+
+	t.Run("interface holding nil pointer", func(t *testing.T) {
+		var a *testtypes.StructA
+
+		c, err := di.NewContainer(
+			di.WithDeclaredService[testtypes.InterfaceA](a),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithDeclaredService testtypes.InterfaceA: value is nil")
+	})
+
+	t.Run("invalid option", func(t *testing.T) {
+		var a testtypes.InterfaceA = &testtypes.StructA{}
+
+		c, err := di.NewContainer(
+			di.WithDeclaredService(a, di.Transient),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithDeclaredService testtypes.InterfaceA: "+
+			"Lifetime Transient: invalid lifetime for value service")
+	})
+}
+
+func Test_WithValue(t *testing.T) {
+	t.Run("declared type", func(t *testing.T) {
+		a := &testtypes.StructA{}
+
+		c, err := di.NewContainer(
+			di.WithValue[testtypes.InterfaceA](a),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[testtypes.InterfaceA](context.Background(), c)
+		assert.NoError(t, err)
+		assert.Same(t, a, got)
+
+		assert.False(t, c.Contains(reflect.TypeFor[*testtypes.StructA]()))
+	})
+
+	t.Run("interface holding nil pointer", func(t *testing.T) {
+		var a *testtypes.StructA
+
+		c, err := di.NewContainer(
+			di.WithValue[testtypes.InterfaceA](a),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithValue testtypes.InterfaceA: value is nil")
+	})
+
+	t.Run("invalid option", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithValue(&testtypes.StructA{}, di.Transient),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithValue *testtypes.StructA: "+
+			"Lifetime Transient: invalid lifetime for value service")
+	})
+}
+
+func Test_WithConstructor(t *testing.T) {
+	t.Run("registered as type", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithConstructor[testtypes.InterfaceA](testtypes.NewStructAPtr),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[testtypes.InterfaceA](context.Background(), c)
+		require.NoError(t, err)
+		assert.Equal(t, &testtypes.StructA{}, got)
+
+		assert.False(t, c.Contains(reflect.TypeFor[*testtypes.StructA]()))
+	})
+
+	t.Run("options", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithConstructor[*testtypes.StructA](testtypes.NewStructAPtr, di.Transient),
+		)
+		require.NoError(t, err)
+
+		got1 := di.MustResolve[*testtypes.StructA](context.Background(), c)
+		got2 := di.MustResolve[*testtypes.StructA](context.Background(), c)
+		assert.NotSame(t, got1, got2)
+	})
+
+	t.Run("value", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithConstructor[*testtypes.StructA](testtypes.NewStructAPtr()),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithConstructor *testtypes.StructA: "+
+			"*testtypes.StructA is not a function")
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithConstructor[*testtypes.StructA](nil),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithConstructor *testtypes.StructA: function is nil")
+	})
+
+	t.Run("wrong return type", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithConstructor[testtypes.InterfaceB](testtypes.NewStructAPtr),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithConstructor testtypes.InterfaceB: "+
+			"function returns *testtypes.StructA")
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithConstructor[*testtypes.StructA](func() {}),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithConstructor *testtypes.StructA: "+
+			"function must return Service, (Service, error), or (Service, func(), error); function has no return values")
+	})
+}
+
+func Test_ValidateServiceType(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		assert.NoError(t, di.ValidateServiceType(reflect.TypeFor[testtypes.InterfaceA]()))
+		assert.NoError(t, di.ValidateServiceType(reflect.TypeFor[*testtypes.StructA]()))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		err := di.ValidateServiceType(reflect.TypeFor[map[string]any]())
+		testutils.LogError(t, err)
+
+		assert.EqualError(t, err, "invalid service type map[string]interface {}; "+
+			"use a named type or a pointer to a named type")
+	})
+
+	t.Run("context", func(t *testing.T) {
+		err := di.ValidateServiceType(reflect.TypeFor[context.Context]())
+		assert.Error(t, err)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		err := di.ValidateServiceType(nil)
+		assert.EqualError(t, err, "service type is nil")
+	})
+}
@@ -0,0 +1,35 @@
+package di
+
+import "context"
+
+// WithContextValue registers a service of type *Service* that is extracted from the [context.Context]
+// when it is resolved.
+//
+// This can be used to expose values stored on the context, like authentication claims or a locale,
+// as services that can be resolved from any scope, or used as dependencies.
+//
+// The extract function is called each time the service is resolved, with the context passed to
+// [Container.Resolve]. The service is always [Transient] and is not closed by the Container.
+// If extract returns an error, it will be returned when the service is resolved.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithContextValue(auth.ClaimsFromContext), // ClaimsFromContext(context.Context) (*auth.Claims, error)
+//		di.WithService(NewRequestService, di.Transient), // NewRequestService(*auth.Claims) *RequestService
+//	)
+//
+// Available options:
+//   - [As] overrides the type a service is registered as.
+//   - [WithTag] specifies a tag differentiate between services of the same type.
+func WithContextValue[Service any](
+	extract func(context.Context) (Service, error),
+	opts ...ServiceOption,
+) ContainerOption {
+	serviceOpts := make([]ServiceOption, 0, len(opts)+2)
+	serviceOpts = append(serviceOpts, IgnoreCloser())
+	serviceOpts = append(serviceOpts, opts...)
+	serviceOpts = append(serviceOpts, Transient)
+
+	return WithService(extract, serviceOpts...)
+}
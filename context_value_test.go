@@ -0,0 +1,101 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/mocks"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func structAFromContext(ctx context.Context) (*testtypes.StructA, error) {
+	a, ok := testutils.TestValue(ctx).(*testtypes.StructA)
+	if !ok {
+		return nil, errors.New("value not found on context")
+	}
+	return a, nil
+}
+
+func Test_WithContextValue(t *testing.T) {
+	t.Run("resolve", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithContextValue(structAFromContext),
+		)
+		require.NoError(t, err)
+
+		a1 := &testtypes.StructA{Tag: 1}
+		a2 := &testtypes.StructA{Tag: 2}
+
+		ctx1 := testutils.ContextWithTestValue(context.Background(), a1)
+		got1, err := di.Resolve[*testtypes.StructA](ctx1, c)
+		assert.Same(t, a1, got1)
+		assert.NoError(t, err)
+
+		ctx2 := testutils.ContextWithTestValue(context.Background(), a2)
+		got2, err := di.Resolve[*testtypes.StructA](ctx2, c)
+		assert.Same(t, a2, got2)
+		assert.NoError(t, err)
+	})
+
+	t.Run("dependency from child scope", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithContextValue(structAFromContext, di.As[testtypes.InterfaceA]()),
+			di.WithService(testtypes.NewInterfaceB, di.Scoped),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope()
+		require.NoError(t, err)
+
+		a := &testtypes.StructA{Tag: 1}
+		ctx := testutils.ContextWithTestValue(context.Background(), a)
+
+		got, err := di.Resolve[testtypes.InterfaceB](ctx, scope)
+		assert.NotNil(t, got)
+		assert.NoError(t, err)
+	})
+
+	t.Run("extract error", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithContextValue(structAFromContext),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[*testtypes.StructA](context.Background(), c)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, got)
+		assert.EqualError(t, err, "di.Container.Resolve *testtypes.StructA: value not found on context")
+	})
+
+	t.Run("not closed", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithContextValue(func(context.Context) (testtypes.InterfaceA, error) {
+				// The mock will fail the test if Close is called
+				return mocks.NewInterfaceAMock(t), nil
+			}),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		_ = di.MustResolve[testtypes.InterfaceA](ctx, c)
+
+		err = c.Close(ctx)
+		assert.NoError(t, err)
+	})
+
+	t.Run("extract nil", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithContextValue[*testtypes.StructA](nil),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService: funcOrValue is nil")
+	})
+}
@@ -0,0 +1,76 @@
+package di
+
+import (
+	"context"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// CustomLifetime decides when a service is created, for lifetimes other than [Singleton],
+// [Transient], and [Scoped].
+//
+// This can be used to implement pooled, TTL, or keyed lifetimes, without changes to the [Container].
+// Use [WithCustomLifetime] to register a service with a custom lifetime.
+//
+// Each time the service is resolved, Load is called to get a cached instance.
+// If Load returns false, the constructor function is called and the new instance is passed to Store.
+// Load and Store may be called concurrently from multiple goroutines, and
+// the constructor function may be called more than once if Load is called concurrently.
+type CustomLifetime interface {
+	// Shared reports whether instances are owned by the Container the service is registered with,
+	// like a [Singleton]. Otherwise, instances are owned by the scope the service is resolved from.
+	//
+	// The owner is passed to Load and Store, and closes the instances the Container creates.
+	Shared() bool
+
+	// Load returns a cached instance of the service for the owner and the context passed to Resolve.
+	Load(ctx context.Context, owner *Container) (val any, ok bool)
+
+	// Store is called after the constructor function creates a new instance without an error.
+	Store(ctx context.Context, owner *Container, val any)
+}
+
+// WithCustomLifetime specifies a [CustomLifetime] for the service when calling [WithService].
+//
+// The built-in lifetimes are faster, and should be used when possible.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithService(NewToken, // NewToken(context.Context) (*Token, error)
+//			di.WithCustomLifetime(&ttlLifetime{ttl: time.Minute}),
+//		),
+//	)
+//
+// This option will return an error if the lifetime is nil or the service is a value service.
+// It cannot be used with a [Lifetime], even [Transient] or [Singleton], in any order,
+// or with [SingletonPer], [WithMemo], or [WithPrewarm].
+func WithCustomLifetime(l CustomLifetime) ServiceOption {
+	return serviceOption(func(s *service) error {
+		if l == nil {
+			return errors.New("WithCustomLifetime: lifetime is nil")
+		}
+		if s.IsValue() {
+			return errors.New("WithCustomLifetime: not supported for value service")
+		}
+
+		s.custom = l
+		return nil
+	})
+}
+
+// validateCustomLifetime returns an error if the custom lifetime is used with other options that cache instances.
+func (s *service) validateCustomLifetime() error {
+	switch {
+	case s.custom == nil:
+		return nil
+	case s.keyed != nil:
+		return errors.New("WithCustomLifetime: cannot be used with SingletonPer")
+	case s.memoTTL > 0:
+		return errors.New("WithCustomLifetime: cannot be used with WithMemo")
+	case s.prewarm != nil:
+		return errors.New("WithCustomLifetime: cannot be used with WithPrewarm")
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,183 @@
+package di_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// perOwnerLifetime caches one instance per owner Container.
+type perOwnerLifetime struct {
+	vals   map[*di.Container]any
+	mu     sync.Mutex
+	shared bool
+}
+
+func (l *perOwnerLifetime) Shared() bool { return l.shared }
+
+func (l *perOwnerLifetime) Load(_ context.Context, owner *di.Container) (any, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	val, ok := l.vals[owner]
+	return val, ok
+}
+
+func (l *perOwnerLifetime) Store(_ context.Context, owner *di.Container, val any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.vals == nil {
+		l.vals = make(map[*di.Container]any)
+	}
+	l.vals[owner] = val
+}
+
+func Test_WithCustomLifetime(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("per scope", func(t *testing.T) {
+		lifetime := &perOwnerLifetime{}
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewStructAPtr, di.WithCustomLifetime(lifetime)),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope()
+		require.NoError(t, err)
+
+		a1 := di.MustResolve[*testtypes.StructA](ctx, c)
+		a2 := di.MustResolve[*testtypes.StructA](ctx, c)
+		a3 := di.MustResolve[*testtypes.StructA](ctx, scope)
+
+		assert.Same(t, a1, a2)
+		assert.NotSame(t, a1, a3)
+		assert.Same(t, a1, lifetime.vals[c])
+		assert.Same(t, a3, lifetime.vals[scope])
+	})
+
+	t.Run("shared", func(t *testing.T) {
+		lifetime := &perOwnerLifetime{shared: true}
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewStructAPtr, di.WithCustomLifetime(lifetime)),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope()
+		require.NoError(t, err)
+
+		a1 := di.MustResolve[*testtypes.StructA](ctx, scope)
+		a2 := di.MustResolve[*testtypes.StructA](ctx, c)
+
+		assert.Same(t, a1, a2)
+		assert.Len(t, lifetime.vals, 1)
+	})
+
+	t.Run("closed by owner", func(t *testing.T) {
+		closed := 0
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA,
+				di.WithCustomLifetime(&perOwnerLifetime{}),
+				di.UseCloseFunc(func(context.Context, testtypes.InterfaceA) error {
+					closed++
+					return nil
+				}),
+			),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope()
+		require.NoError(t, err)
+
+		_ = di.MustResolve[testtypes.InterfaceA](ctx, scope)
+		_ = di.MustResolve[testtypes.InterfaceA](ctx, scope)
+
+		require.NoError(t, scope.Close(ctx))
+		assert.Equal(t, 1, closed)
+
+		require.NoError(t, c.Close(ctx))
+		assert.Equal(t, 1, closed)
+	})
+
+	t.Run("error not stored", func(t *testing.T) {
+		lifetime := &perOwnerLifetime{}
+		c, err := di.NewContainer(
+			di.WithService(func() (testtypes.InterfaceA, error) {
+				return nil, errors.New("constructor error")
+			}, di.WithCustomLifetime(lifetime)),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, c)
+		assert.EqualError(t, err, "di.Container.Resolve testtypes.InterfaceA: constructor error")
+		assert.Empty(t, lifetime.vals)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.WithCustomLifetime(nil)),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func() testtypes.InterfaceA: WithCustomLifetime: lifetime is nil")
+	})
+
+	t.Run("value service", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(&testtypes.StructA{}, di.WithCustomLifetime(&perOwnerLifetime{})),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService *testtypes.StructA: WithCustomLifetime: not supported for value service")
+	})
+
+	t.Run("with Lifetime", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.WithCustomLifetime(&perOwnerLifetime{}), di.Scoped),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func() testtypes.InterfaceA: WithCustomLifetime: cannot be used with Scoped")
+	})
+
+	t.Run("with Lifetime before", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.Singleton, di.WithCustomLifetime(&perOwnerLifetime{})),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func() testtypes.InterfaceA: WithCustomLifetime: cannot be used with Singleton")
+	})
+
+	t.Run("with Transient", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.WithCustomLifetime(&perOwnerLifetime{}), di.Transient),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func() testtypes.InterfaceA: WithCustomLifetime: cannot be used with Transient")
+	})
+
+	t.Run("with WithMemo", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.WithCustomLifetime(&perOwnerLifetime{}), di.WithMemo(time.Second)),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func() testtypes.InterfaceA: WithCustomLifetime: cannot be used with WithMemo")
+	})
+}
@@ -0,0 +1,41 @@
+package di
+
+import "slices"
+
+// WithDecoratorsDisabled makes the Container resolve services as if decorators were not registered,
+// when calling [NewContainer] or [Container.NewScope].
+//
+// Decorators are services registered with [WithOptionOrder] at [OrderDecorator],
+// like caching or retry wrappers registered by extensions. With this option, resolving a type
+// returns the service registered before the decorators instead, and decorators are not included in slices.
+// This helps reproduce production issues in a scope without the decorators interfering,
+// without editing registrations.
+//
+// Child scopes inherit this option from the parent Container.
+// [Singleton] services are created by the Container they are registered with,
+// so a Singleton registered with a parent Container still gets the decorated services it depends on.
+//
+// Example:
+//
+//	scope, err := c.NewScope(
+//		di.WithDecoratorsDisabled(),
+//	)
+func WithDecoratorsDisabled() ContainerOption {
+	return containerOption(func(c *Container) error {
+		c.decoratorsDisabled = true
+		return nil
+	})
+}
+
+// withoutDecorators returns the services that were not registered as decorators.
+func withoutDecorators(svcs []*service) []*service {
+	if !slices.ContainsFunc(svcs, isDecorator) {
+		return svcs
+	}
+
+	return slices.DeleteFunc(slices.Clone(svcs), isDecorator)
+}
+
+func isDecorator(s *service) bool {
+	return s.decorator
+}
@@ -0,0 +1,94 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithDecoratorsDisabled(t *testing.T) {
+	ctx := context.Background()
+
+	base := &testtypes.StructA{Tag: "base"}
+	decorated := &testtypes.StructA{Tag: "decorated"}
+
+	newContainer := func(t *testing.T, opts ...di.ContainerOption) *di.Container {
+		c, err := di.NewContainer(append([]di.ContainerOption{
+			di.WithOptionOrder(di.OrderDecorator,
+				di.WithService(decorated, di.As[testtypes.InterfaceA]()),
+			),
+			di.WithService(base, di.As[testtypes.InterfaceA]()),
+		}, opts...)...)
+		require.NoError(t, err)
+		return c
+	}
+
+	t.Run("decorators enabled", func(t *testing.T) {
+		c := newContainer(t)
+
+		got, err := di.Resolve[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Same(t, decorated, got)
+	})
+
+	t.Run("container", func(t *testing.T) {
+		c := newContainer(t, di.WithDecoratorsDisabled())
+
+		got, err := di.Resolve[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Same(t, base, got)
+
+		all, err := di.ResolveAll[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, []testtypes.InterfaceA{base}, all)
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		c := newContainer(t)
+
+		scope, err := c.NewScope(di.WithDecoratorsDisabled())
+		require.NoError(t, err)
+
+		got, err := di.Resolve[testtypes.InterfaceA](ctx, scope)
+		require.NoError(t, err)
+		assert.Same(t, base, got)
+
+		// The parent Container still resolves the decorator
+		got, err = di.Resolve[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Same(t, decorated, got)
+	})
+
+	t.Run("scoped dependency", func(t *testing.T) {
+		var gotA testtypes.InterfaceA
+		c := newContainer(t,
+			di.WithService(func(a testtypes.InterfaceA) *testtypes.StructB {
+				gotA = a
+				return &testtypes.StructB{}
+			}, di.Scoped),
+		)
+
+		scope, err := c.NewScope(di.WithDecoratorsDisabled())
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*testtypes.StructB](ctx, scope)
+		require.NoError(t, err)
+		assert.Same(t, base, gotA)
+	})
+
+	t.Run("only decorators registered", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithOptionOrder(di.OrderDecorator,
+				di.WithService(decorated, di.As[testtypes.InterfaceA]()),
+			),
+			di.WithDecoratorsDisabled(),
+		)
+		require.NoError(t, err)
+
+		assert.False(t, c.Contains(testtypes.TypeInterfaceA))
+	})
+}
@@ -0,0 +1,101 @@
+package di
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// Deprecated marks a service as deprecated when calling [WithService].
+//
+// When the service is resolved, a [DeprecatedResolved] event is emitted with the message
+// and the caller that resolved it, to help teams find and migrate off old components.
+// The event is emitted once per process for each deprecated constructor function,
+// so it can be logged without flooding the logs.
+// The service is still resolved as usual.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithService(NewClient, di.Deprecated("use NewClientV2 instead")),
+//		di.WithEventHandler(func(e di.Event) {
+//			if e.Kind == di.DeprecatedResolved {
+//				logger.Warn("deprecated service resolved", "service", e.Service, "message", e.Message, "caller", e.Caller)
+//			}
+//		}),
+//	)
+//
+// This option will return an error if message is empty.
+func Deprecated(message string) ServiceOption {
+	return serviceOption(func(s *service) error {
+		if message == "" {
+			return errors.New("Deprecated: message is empty")
+		}
+
+		s.deprecated = message
+		return nil
+	})
+}
+
+// deprecationsEmitted tracks the deprecated services a [DeprecatedResolved] event was emitted for.
+var deprecationsEmitted sync.Map
+
+// emitDeprecated emits a [DeprecatedResolved] event the first time a deprecated service is resolved.
+func (c *Container) emitDeprecated(svc *service, key serviceKey) {
+	if svc.deprecated == "" || len(c.eventHandlers) == 0 {
+		return
+	}
+
+	id := svc.Name() + "\x00" + svc.deprecated
+	if _, loaded := deprecationsEmitted.LoadOrStore(id, struct{}{}); loaded {
+		return
+	}
+
+	c.emit(&Event{
+		Kind:    DeprecatedResolved,
+		Service: svc.Info(key),
+		Message: svc.deprecated,
+		Caller:  externalCaller(),
+	})
+}
+
+// externalCaller returns the file and line of the first caller outside this module.
+func externalCaller() string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+		if !isModuleFunc(frame.Function) && !strings.HasPrefix(frame.Function, "runtime.") {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// isModuleFunc returns true if the function is in a package of this module, like di or dihttp.
+// Functions in external test packages, like di_test, are not.
+func isModuleFunc(fn string) bool {
+	const module = "github.com/sectrean/di-kit"
+
+	rest, ok := strings.CutPrefix(fn, module)
+	if !ok || rest == "" || (rest[0] != '.' && rest[0] != '/') {
+		return false
+	}
+
+	// The package path ends at the first dot after the last slash
+	pkg := fn
+	if i := strings.LastIndexByte(pkg, '/'); i >= 0 {
+		if j := strings.IndexByte(pkg[i:], '.'); j >= 0 {
+			pkg = pkg[:i+j]
+		}
+	}
+
+	return !strings.HasSuffix(pkg, "_test")
+}
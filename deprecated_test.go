@@ -0,0 +1,145 @@
+package di_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/ditest"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deprecationMessage returns a message that is unique in this process,
+// since a DeprecatedResolved event is only emitted once per process for each message.
+func deprecationMessage(msg string) string {
+	return fmt.Sprintf("%s (%d)", msg, deprecationMessages.Add(1))
+}
+
+var deprecationMessages atomic.Int64
+
+func Test_Deprecated(t *testing.T) {
+	ctx := context.Background()
+
+	deprecatedEvents := func(r *eventRecorder) []di.Event {
+		var events []di.Event
+		for _, e := range r.events {
+			if e.Kind == di.DeprecatedResolved {
+				events = append(events, e)
+			}
+		}
+		return events
+	}
+
+	t.Run("event emitted once", func(t *testing.T) {
+		newA := func() testtypes.InterfaceA { return testtypes.StructA{} }
+		msg := deprecationMessage("use NewV2 instead")
+
+		r := &eventRecorder{}
+		c, err := di.NewContainer(
+			di.WithService(newA, di.Deprecated(msg), di.Transient),
+			di.WithEventHandler(r.Handler()),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+
+		c2, err := di.NewContainer(
+			di.WithService(newA, di.Deprecated(msg)),
+			di.WithEventHandler(r.Handler()),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, c2)
+		require.NoError(t, err)
+
+		events := deprecatedEvents(r)
+		require.Len(t, events, 1)
+		assert.Equal(t, "DeprecatedResolved", events[0].Kind.String())
+		assert.Equal(t, msg, events[0].Message)
+		assert.Equal(t, "testtypes.InterfaceA", events[0].Service.String())
+		assert.Contains(t, events[0].Caller, "deprecated_test.go:")
+	})
+
+	t.Run("dependency", func(t *testing.T) {
+		r := &eventRecorder{}
+		c, err := di.NewContainer(
+			di.WithService(func() testtypes.InterfaceA { return testtypes.StructA{} },
+				di.Deprecated(deprecationMessage("use NewV2 instead"))),
+			di.WithService(testtypes.NewInterfaceB),
+			di.WithEventHandler(r.Handler()),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceB](ctx, c)
+		require.NoError(t, err)
+
+		events := deprecatedEvents(r)
+		require.Len(t, events, 1)
+		assert.Equal(t, "testtypes.InterfaceA", events[0].Service.String())
+		assert.Contains(t, events[0].Caller, "deprecated_test.go:")
+	})
+
+	t.Run("resolved by another package", func(t *testing.T) {
+		r := &eventRecorder{}
+		c, err := di.NewContainer(
+			di.WithService(func() testtypes.InterfaceA { return testtypes.StructA{} },
+				di.Deprecated(deprecationMessage("use NewV2 instead"))),
+			di.WithEventHandler(r.Handler()),
+		)
+		require.NoError(t, err)
+
+		_, err = ditest.FindGoroutineLeaks(ctx, c)
+		require.NoError(t, err)
+
+		events := deprecatedEvents(r)
+		require.Len(t, events, 1)
+		assert.Contains(t, events[0].Caller, "deprecated_test.go:")
+	})
+
+	t.Run("not resolved", func(t *testing.T) {
+		r := &eventRecorder{}
+		c, err := di.NewContainer(
+			di.WithService(func() testtypes.InterfaceA { return testtypes.StructA{} },
+				di.Deprecated("use NewV2 instead")),
+			di.WithService(&testtypes.StructB{}),
+			di.WithEventHandler(r.Handler()),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*testtypes.StructB](ctx, c)
+		require.NoError(t, err)
+		assert.Empty(t, deprecatedEvents(r))
+	})
+
+	t.Run("manifest", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.Deprecated("use NewV2 instead")),
+		)
+		require.NoError(t, err)
+
+		var got []string
+		for _, s := range c.Manifest().Services {
+			if s.Deprecated != "" {
+				got = append(got, s.Type+": "+s.Deprecated)
+			}
+		}
+		assert.Equal(t, []string{"testtypes.InterfaceA: use NewV2 instead"}, got)
+	})
+
+	t.Run("empty message", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.Deprecated("")),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func() testtypes.InterfaceA: Deprecated: message is empty")
+	})
+}
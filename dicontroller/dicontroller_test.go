@@ -0,0 +1,316 @@
+package dicontroller_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/dicontext"
+	"github.com/sectrean/di-kit/dicontroller"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/mocks"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type Request struct {
+	Name string
+}
+
+type Result struct {
+	Requeue bool
+}
+
+type Logger struct {
+	Values []any
+}
+
+type loggerKey struct{}
+
+func FromContext(ctx context.Context, keysAndValues ...any) Logger {
+	l, _ := ctx.Value(loggerKey{}).(Logger)
+	l.Values = append(l.Values, keysAndValues...)
+	return l
+}
+
+type PodReconciler struct {
+	req    Request
+	logger Logger
+}
+
+func NewPodReconciler(req Request, logger Logger) *PodReconciler {
+	return &PodReconciler{req: req, logger: logger}
+}
+
+func (r *PodReconciler) Reconcile(_ context.Context, req Request) (Result, error) {
+	if r.req != req {
+		return Result{}, errors.New("unexpected request")
+	}
+
+	return Result{Requeue: len(r.logger.Values) > 0}, nil
+}
+
+type AdmissionRequest struct {
+	UID string
+}
+
+type AdmissionResponse struct {
+	Err     error
+	Allowed bool
+}
+
+func errored(err error) AdmissionResponse {
+	return AdmissionResponse{Err: err}
+}
+
+func newCloserService(t *testing.T, closeErr error, closed *int) func() testtypes.InterfaceA {
+	return func() testtypes.InterfaceA {
+		a := mocks.NewInterfaceAMock(t)
+		a.EXPECT().
+			Close(mock.Anything).
+			RunAndReturn(func(context.Context) error {
+				*closed++
+				return closeErr
+			})
+
+		return a
+	}
+}
+
+func Test_Reconcile(t *testing.T) {
+	t.Run("parent nil", func(t *testing.T) {
+		assert.PanicsWithValue(t, "dicontroller.Reconcile: parent is nil", func() {
+			dicontroller.Reconcile(nil, func(context.Context, Request) (Result, error) { return Result{}, nil })
+		})
+	})
+
+	t.Run("request registered", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func(req Request) *testtypes.StructA {
+				return &testtypes.StructA{Tag: req.Name}
+			}, di.Scoped),
+		)
+		require.NoError(t, err)
+
+		var got string
+		reconcile := dicontroller.Reconcile(c, func(ctx context.Context, _ Request) (Result, error) {
+			got = dicontext.MustResolve[*testtypes.StructA](ctx).Tag.(string)
+			return Result{}, nil
+		})
+
+		_, err = reconcile(context.Background(), Request{Name: "pod-1"})
+		require.NoError(t, err)
+		assert.Equal(t, "pod-1", got)
+	})
+
+	t.Run("scope per call", func(t *testing.T) {
+		closed := 0
+		c, err := di.NewContainer(
+			di.WithService(newCloserService(t, nil, &closed), di.Scoped),
+		)
+		require.NoError(t, err)
+
+		var scopes []di.Scope
+		reconcile := dicontroller.Reconcile(c, func(ctx context.Context, _ Request) (Result, error) {
+			_ = dicontext.MustResolve[testtypes.InterfaceA](ctx)
+			scopes = append(scopes, dicontext.Scope(ctx))
+			return Result{}, nil
+		})
+
+		ctx := context.Background()
+		_, err = reconcile(ctx, Request{Name: "a"})
+		require.NoError(t, err)
+		_, err = reconcile(ctx, Request{Name: "b"})
+		require.NoError(t, err)
+
+		require.Len(t, scopes, 2)
+		assert.NotSame(t, scopes[0], scopes[1])
+		assert.Equal(t, 2, closed)
+	})
+
+	t.Run("NewScope error", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		reconcile := dicontroller.Reconcile(c, func(context.Context, Request) (Result, error) {
+			assert.Fail(t, "reconcile should not get called")
+			return Result{}, nil
+		}, dicontroller.WithContainerOptions(di.WithService(nil)))
+
+		_, err = reconcile(context.Background(), Request{})
+		testutils.LogError(t, err)
+
+		assert.EqualError(t, err, "dicontroller.Reconcile: di.Container.NewScope: WithService: funcOrValue is nil")
+	})
+
+	t.Run("Close error", func(t *testing.T) {
+		closed := 0
+		c, err := di.NewContainer(
+			di.WithService(newCloserService(t, errors.New("close error"), &closed), di.Scoped),
+		)
+		require.NoError(t, err)
+
+		reconcile := dicontroller.Reconcile(c, func(ctx context.Context, _ Request) (Result, error) {
+			_ = dicontext.MustResolve[testtypes.InterfaceA](ctx)
+			return Result{Requeue: true}, nil
+		})
+
+		got, err := reconcile(context.Background(), Request{})
+		testutils.LogError(t, err)
+
+		assert.Equal(t, Result{Requeue: true}, got)
+		assert.EqualError(t, err, "dicontroller.Reconcile: di.Container.Close: close error")
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewStructAPtr, di.Scoped,
+				di.UseCloseFunc(func(ctx context.Context, _ *testtypes.StructA) error {
+					return ctx.Err()
+				}),
+			),
+		)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		reconcile := dicontroller.Reconcile(c, func(ctx context.Context, _ Request) (Result, error) {
+			_ = dicontext.MustResolve[*testtypes.StructA](ctx)
+			cancel()
+			return Result{}, nil
+		})
+
+		_, err = reconcile(ctx, Request{})
+		assert.NoError(t, err)
+	})
+}
+
+func Test_Reconciler(t *testing.T) {
+	t.Run("parent nil", func(t *testing.T) {
+		assert.PanicsWithValue(t, "dicontroller.Reconciler: parent is nil", func() {
+			dicontroller.Reconciler[*PodReconciler, Request, Result](nil)
+		})
+	})
+
+	t.Run("WithLogger", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(NewPodReconciler, di.Scoped),
+		)
+		require.NoError(t, err)
+
+		reconcile := dicontroller.Reconciler[*PodReconciler, Request, Result](c,
+			dicontroller.WithLogger(FromContext),
+		)
+
+		ctx := context.WithValue(context.Background(), loggerKey{}, Logger{Values: []any{"controller", "pod"}})
+		got, err := reconcile(ctx, Request{Name: "pod-1"})
+		require.NoError(t, err)
+		assert.Equal(t, Result{Requeue: true}, got)
+	})
+
+	t.Run("not registered", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		reconcile := dicontroller.Reconciler[*PodReconciler, Request, Result](c)
+
+		_, err = reconcile(context.Background(), Request{})
+		testutils.LogError(t, err)
+
+		assert.EqualError(t, err, "dicontroller.Reconciler: "+
+			"di.Container.Resolve *dicontroller_test.PodReconciler: service not registered")
+	})
+}
+
+func Test_Webhook(t *testing.T) {
+	t.Run("parent nil", func(t *testing.T) {
+		assert.PanicsWithValue(t, "dicontroller.Webhook: parent is nil", func() {
+			dicontroller.Webhook(nil, func(context.Context, AdmissionRequest) AdmissionResponse {
+				return AdmissionResponse{}
+			}, errored)
+		})
+	})
+
+	t.Run("errored nil", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		assert.PanicsWithValue(t, "dicontroller.Webhook: errored is nil", func() {
+			dicontroller.Webhook(c, func(context.Context, AdmissionRequest) AdmissionResponse {
+				return AdmissionResponse{}
+			}, nil)
+		})
+	})
+
+	t.Run("request registered", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		handle := dicontroller.Webhook(c, func(ctx context.Context, _ AdmissionRequest) AdmissionResponse {
+			req := dicontext.MustResolve[AdmissionRequest](ctx)
+			return AdmissionResponse{Allowed: req.UID == "uid-1"}
+		}, errored)
+
+		got := handle(context.Background(), AdmissionRequest{UID: "uid-1"})
+		assert.Equal(t, AdmissionResponse{Allowed: true}, got)
+	})
+
+	t.Run("NewScope error", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		handle := dicontroller.Webhook(c, func(context.Context, AdmissionRequest) AdmissionResponse {
+			assert.Fail(t, "handler should not get called")
+			return AdmissionResponse{}
+		}, errored, dicontroller.WithContainerOptions(di.WithService(nil)))
+
+		got := handle(context.Background(), AdmissionRequest{})
+		testutils.LogError(t, got.Err)
+
+		assert.False(t, got.Allowed)
+		assert.EqualError(t, got.Err, "dicontroller.Webhook: di.Container.NewScope: WithService: funcOrValue is nil")
+	})
+
+	t.Run("Close error", func(t *testing.T) {
+		closed := 0
+		c, err := di.NewContainer(
+			di.WithService(newCloserService(t, errors.New("close error"), &closed), di.Scoped),
+		)
+		require.NoError(t, err)
+
+		var closeErr error
+		handle := dicontroller.Webhook(c, func(ctx context.Context, _ AdmissionRequest) AdmissionResponse {
+			_ = dicontext.MustResolve[testtypes.InterfaceA](ctx)
+			return AdmissionResponse{Allowed: true}
+		}, errored, dicontroller.WithCloseErrorHandler(func(_ context.Context, err error) {
+			closeErr = err
+		}))
+
+		got := handle(context.Background(), AdmissionRequest{})
+		testutils.LogError(t, closeErr)
+
+		assert.Equal(t, AdmissionResponse{Allowed: true}, got)
+		assert.Equal(t, 1, closed)
+		assert.EqualError(t, closeErr, "dicontroller.Webhook: di.Container.Close: close error")
+	})
+
+	t.Run("handler panics", func(t *testing.T) {
+		closed := 0
+		c, err := di.NewContainer(
+			di.WithService(newCloserService(t, nil, &closed), di.Scoped),
+		)
+		require.NoError(t, err)
+
+		handle := dicontroller.Webhook(c, func(ctx context.Context, _ AdmissionRequest) AdmissionResponse {
+			_ = dicontext.MustResolve[testtypes.InterfaceA](ctx)
+			panic("handler panic")
+		}, errored)
+
+		assert.PanicsWithValue(t, "handler panic", func() {
+			handle(context.Background(), AdmissionRequest{})
+		})
+		assert.Equal(t, 1, closed)
+	})
+}
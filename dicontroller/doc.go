@@ -0,0 +1,31 @@
+/*
+Package dicontroller provides utilities for running Kubernetes [controller-runtime] controllers
+and webhooks with a [di.Container].
+
+Reconcilers and webhook handlers can be wrapped to create a new child scope for each reconcile call
+or admission request. The request, and optionally a logger, are registered with the child scope,
+so they can be used as dependencies of scoped services.
+Use [Reconciler] to resolve a reconciler from the child scope for each reconcile call,
+so reconcilers can be constructed from the container.
+
+The package is compatible with controller-runtime without depending on it directly.
+
+Example:
+
+	c, err := di.NewContainer(
+		di.WithService(mgr.GetClient),
+		di.WithService(NewPodReconciler, di.Scoped), // NewPodReconciler(client.Client, reconcile.Request, logr.Logger) *PodReconciler
+	)
+	...
+
+	err = ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.Pod{}).
+		Complete(reconcile.Func(
+			dicontroller.Reconciler[*PodReconciler, reconcile.Request, reconcile.Result](c,
+				dicontroller.WithLogger(log.FromContext),
+			),
+		))
+
+[controller-runtime]: https://github.com/kubernetes-sigs/controller-runtime
+*/
+package dicontroller
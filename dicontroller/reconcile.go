@@ -0,0 +1,220 @@
+package dicontroller
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/dicontext"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/scopecall"
+)
+
+// Reconcile wraps a reconcile function to create a new child container by calling [di.Container.NewScope]
+// for each reconcile call.
+// The child container is stored on the context, where [dicontext.Resolve] can use it,
+// and is closed with [di.CloseWithGrace] after the reconcile function returns.
+//
+// The request is registered with the child container as type *Req*, so it can be used as a dependency
+// of scoped services.
+//
+// Use with controller-runtime:
+//
+//	reconcile.Func(dicontroller.Reconcile(c, r.Reconcile))
+//
+// Available options:
+//   - [WithContainerOptions]: Set [di.ContainerOption]s to use when creating each scope.
+//   - [WithLogger]: Register the logger for the request with each scope.
+//
+// Errors creating or closing the scope are returned from the reconcile function.
+//
+// This will panic if parent is nil.
+func Reconcile[Req, Result any](
+	parent di.ContainerInterface,
+	fn func(context.Context, Req) (Result, error),
+	opts ...ScopeOption,
+) func(context.Context, Req) (Result, error) {
+	if parent == nil {
+		panic("dicontroller.Reconcile: parent is nil")
+	}
+
+	cfg := newScopeConfig(opts)
+
+	return func(ctx context.Context, req Req) (Result, error) {
+		call := func(ctx context.Context) (Result, error) {
+			return fn(ctx, req)
+		}
+
+		return scopecall.Call(ctx, parent, &cfg.Config, "dicontroller.Reconcile", call, di.WithDeclaredService(req))
+	}
+}
+
+// Reconciler returns a reconcile function that resolves a reconciler of type *R* from a new child container
+// for each reconcile call, and calls its Reconcile method.
+//
+// Register the reconciler as a [di.Scoped] service, so its dependencies can include the request,
+// the logger, and other scoped services. See [Reconcile] for more information.
+//
+// Use with controller-runtime:
+//
+//	reconcile.Func(dicontroller.Reconciler[*PodReconciler, reconcile.Request, reconcile.Result](c))
+//
+// Errors resolving the reconciler are returned from the reconcile function.
+//
+// This will panic if parent is nil.
+func Reconciler[
+	R interface {
+		Reconcile(context.Context, Req) (Result, error)
+	},
+	Req, Result any,
+](
+	parent di.ContainerInterface,
+	opts ...ScopeOption,
+) func(context.Context, Req) (Result, error) {
+	if parent == nil {
+		panic("dicontroller.Reconciler: parent is nil")
+	}
+
+	return Reconcile(parent, func(ctx context.Context, req Req) (res Result, err error) {
+		r, err := di.Resolve[R](ctx, dicontext.Scope(ctx))
+		if err != nil {
+			return res, errors.Wrap(err, "dicontroller.Reconciler")
+		}
+
+		return r.Reconcile(ctx, req)
+	}, opts...)
+}
+
+// Webhook wraps an admission webhook handler function to create a new child container
+// by calling [di.Container.NewScope] for each request.
+// The child container is stored on the context, where [dicontext.Resolve] can use it,
+// and is closed with [di.CloseWithGrace] after the handler function returns.
+//
+// The request is registered with the child container as type *Req*, so it can be used as a dependency
+// of scoped services.
+//
+// Since the handler function does not return an error, errored is called to create the response
+// if there is an error creating the scope.
+// The scope is closed after the response has been created, so errors closing the scope
+// are passed to the close error handler instead. Use [WithCloseErrorHandler] to handle them.
+//
+// Use with controller-runtime:
+//
+//	mgr.GetWebhookServer().Register("/validate-pod", &webhook.Admission{
+//		Handler: admission.HandlerFunc(dicontroller.Webhook(c, v.Handle, func(err error) admission.Response {
+//			return admission.Errored(http.StatusInternalServerError, err)
+//		})),
+//	})
+//
+// This will panic if parent or errored is nil.
+func Webhook[Req, Resp any](
+	parent di.ContainerInterface,
+	fn func(context.Context, Req) Resp,
+	errored func(error) Resp,
+	opts ...ScopeOption,
+) func(context.Context, Req) Resp {
+	if parent == nil {
+		panic("dicontroller.Webhook: parent is nil")
+	}
+	if errored == nil {
+		panic("dicontroller.Webhook: errored is nil")
+	}
+
+	cfg := newScopeConfig(opts)
+
+	return func(ctx context.Context, req Req) Resp {
+		scope, err := cfg.NewScope(ctx, parent, di.WithDeclaredService(req))
+		if err != nil {
+			return errored(errors.Wrap(err, "dicontroller.Webhook"))
+		}
+
+		defer func() {
+			closeErr := scopecall.Close(ctx, scope)
+			if closeErr != nil {
+				cfg.closeHandler(ctx, errors.Wrap(closeErr, "dicontroller.Webhook"))
+			}
+		}()
+
+		return fn(dicontext.WithScope(ctx, scope), req)
+	}
+}
+
+// ScopeOption is an option used to configure the scope created for each call
+// when calling [Reconcile], [Reconciler], or [Webhook].
+type ScopeOption interface {
+	applyScopeConfig(*scopeConfig)
+}
+
+type scopeOption func(*scopeConfig)
+
+func (o scopeOption) applyScopeConfig(c *scopeConfig) {
+	o(c)
+}
+
+type scopeConfig struct {
+	closeHandler CloseErrorHandler
+	scopecall.Config
+}
+
+func newScopeConfig(opts []ScopeOption) *scopeConfig {
+	cfg := &scopeConfig{
+		closeHandler: defaultCloseErrorHandler,
+	}
+	for _, opt := range opts {
+		opt.applyScopeConfig(cfg)
+	}
+
+	return cfg
+}
+
+// WithContainerOptions sets the options to use when calling [di.Container.NewScope] for each call.
+func WithContainerOptions(opts ...di.ContainerOption) ScopeOption {
+	return scopeOption(func(c *scopeConfig) {
+		c.AddContainerOptions(opts...)
+	})
+}
+
+// CloseErrorHandler is a function that handles errors when closing the scope
+// after a [Webhook] handler function has returned.
+//
+// The default handler logs the error to [slog.Default].
+type CloseErrorHandler = func(context.Context, error)
+
+func defaultCloseErrorHandler(ctx context.Context, err error) {
+	slog.ErrorContext(ctx,
+		"error closing di.Container scope for webhook request",
+		"error", err,
+	)
+}
+
+// WithCloseErrorHandler sets the error handler for when there is an error closing the scope
+// after a [Webhook] handler function has returned.
+//
+// [Reconcile] and [Reconciler] return errors closing the scope from the reconcile function instead.
+//
+// The default handler logs the error to [slog.Default].
+func WithCloseErrorHandler(h CloseErrorHandler) ScopeOption {
+	return scopeOption(func(c *scopeConfig) {
+		if h != nil {
+			c.closeHandler = h
+		}
+	})
+}
+
+// WithLogger registers the logger returned by get with each new scope.
+// It can be used as a dependency for scoped services.
+//
+// The logger is registered as type *Logger*, which is the return type of get.
+// controller-runtime adds the controller name and the request to the logger on the context
+// for each reconcile call.
+//
+// Use with controller-runtime:
+//
+//	dicontroller.WithLogger(log.FromContext)
+func WithLogger[Logger any](get func(context.Context, ...any) Logger) ScopeOption {
+	return scopeOption(func(c *scopeConfig) {
+		c.AddContextOption(func(ctx context.Context) di.ContainerOption {
+			return di.WithDeclaredService(get(ctx))
+		})
+	})
+}
@@ -0,0 +1,189 @@
+package dievent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/scopecall"
+)
+
+// Mode specifies how a [Dispatcher] calls event handlers.
+//
+// Available modes:
+//   - [Sync] calls handlers one at a time and returns after all handlers have completed.
+//   - [Async] calls handlers in a new goroutine and returns immediately.
+type Mode uint8
+
+const (
+	// Sync specifies that handlers are called one at a time, in registration order.
+	// Dispatch returns after all handlers have completed.
+	//
+	// This is the default mode.
+	Sync Mode = iota
+
+	// Async specifies that handlers are called in a new goroutine.
+	// Dispatch returns immediately and handler errors are passed to the [ErrorHandler].
+	Async Mode = iota
+)
+
+func (m Mode) String() string {
+	switch m {
+	case Sync:
+		return "Sync"
+	case Async:
+		return "Async"
+	default:
+		return fmt.Sprintf("Unknown Mode %d", m)
+	}
+}
+
+// ErrorHandler handles errors from event handlers when dispatching in [Async] mode.
+//
+// The default handler logs the error to [slog.Default].
+type ErrorHandler = func(ctx context.Context, event any, err error)
+
+func defaultErrorHandler(ctx context.Context, event any, err error) {
+	slog.ErrorContext(ctx,
+		"error dispatching event",
+		"error", err,
+		"event", event,
+	)
+}
+
+// scopeFactory is implemented by [di.Container] and the [di.Scope] injected into constructor functions.
+type scopeFactory interface {
+	NewScope(opts ...di.ContainerOption) (*di.Container, error)
+}
+
+// Dispatcher dispatches events to all [Handler] services registered with a [di.Container].
+//
+// Use [NewDispatcher] to create a Dispatcher, and [Dispatch] to dispatch events.
+type Dispatcher struct {
+	scope        scopeFactory
+	errorHandler ErrorHandler
+	cfg          scopecall.Config
+	wg           sync.WaitGroup
+	mode         Mode
+}
+
+// NewDispatcher creates a new [Dispatcher] that resolves event handlers from the provided scope.
+//
+// NewDispatcher can be registered directly as a service constructor function:
+//
+//	di.WithService(dievent.NewDispatcher)
+//
+// Available options:
+//   - [WithMode] sets how handlers are called.
+//   - [WithErrorHandler] sets the error handler used in [Async] mode.
+//   - [WithContainerOptions] sets the options used when creating each dispatch scope.
+//
+// This will panic if s is nil or does not support creating child scopes.
+func NewDispatcher(s di.Scope, opts ...DispatcherOption) *Dispatcher {
+	f, ok := s.(scopeFactory)
+	if !ok {
+		panic("dievent.NewDispatcher: scope does not support NewScope")
+	}
+
+	d := &Dispatcher{
+		scope:        f,
+		errorHandler: defaultErrorHandler,
+	}
+
+	for _, opt := range opts {
+		opt.applyDispatcher(d)
+	}
+
+	return d
+}
+
+// Wait blocks until all events dispatched in [Async] mode have been handled.
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// Dispatch an event to all [Handler] services registered for type *Event*.
+//
+// A new child scope is created for each dispatch. The event is registered with the child scope as type *Event*
+// so it can be used as a dependency of [di.Scoped] services. Events of basic or unnamed types,
+// which can't be registered with a [di.Container], are only passed to the handlers.
+// The child scope is closed with [di.CloseWithGrace] after all handlers have been called.
+//
+// In [Sync] mode, errors returned by handlers are joined together and returned.
+// In [Async] mode, Dispatch returns immediately and errors are passed to the [ErrorHandler].
+//
+// Dispatching an event with no registered handlers is not an error.
+func Dispatch[Event any](ctx context.Context, d *Dispatcher, event Event) error {
+	eventType := reflect.TypeFor[Event]()
+
+	var opts []di.ContainerOption
+	if isServiceType(eventType) {
+		opts = append(opts, di.WithDeclaredService(event))
+	}
+
+	scope, err := d.scope.NewScope(d.cfg.ContainerOptions(ctx, opts...)...)
+	if err != nil {
+		return errors.Wrapf(err, "dievent.Dispatch %s", eventType)
+	}
+
+	if d.mode == Async {
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+
+			// The caller may cancel the context after Dispatch returns
+			asyncCtx := context.WithoutCancel(ctx)
+
+			dispatchErr := dispatch(asyncCtx, scope, event)
+			if dispatchErr != nil {
+				d.errorHandler(asyncCtx, event, errors.Wrapf(dispatchErr, "dievent.Dispatch %s", eventType))
+			}
+		}()
+
+		return nil
+	}
+
+	err = dispatch(ctx, scope, event)
+	if err != nil {
+		return errors.Wrapf(err, "dievent.Dispatch %s", eventType)
+	}
+
+	return nil
+}
+
+func dispatch[Event any](ctx context.Context, scope *di.Container, event Event) error {
+	var errs []error
+
+	if scope.Contains(reflect.TypeFor[Handler[Event]]()) {
+		handlers, err := di.Resolve[[]Handler[Event]](ctx, scope)
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		for _, h := range handlers {
+			if err := h.Handle(ctx, event); err != nil {
+				errs = append(errs, errors.Wrapf(err, "handler %T", h))
+			}
+		}
+	}
+
+	if err := scopecall.Close(ctx, scope); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// isServiceType returns true if events of type t can be registered with a [di.Container].
+// Basic and unnamed types, and types from package di, are not supported as services.
+func isServiceType(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t.Name() != "" && t.PkgPath() != "" && t.PkgPath() != reflect.TypeFor[di.Scope]().PkgPath()
+}
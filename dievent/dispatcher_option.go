@@ -0,0 +1,43 @@
+package dievent
+
+import (
+	"github.com/sectrean/di-kit"
+)
+
+// DispatcherOption is an option used to configure a [Dispatcher] when calling [NewDispatcher].
+type DispatcherOption interface {
+	applyDispatcher(*Dispatcher)
+}
+
+type dispatcherOption func(*Dispatcher)
+
+func (o dispatcherOption) applyDispatcher(d *Dispatcher) {
+	o(d)
+}
+
+// WithMode sets how the [Dispatcher] calls event handlers.
+//
+// The default mode is [Sync].
+func WithMode(m Mode) DispatcherOption {
+	return dispatcherOption(func(d *Dispatcher) {
+		d.mode = m
+	})
+}
+
+// WithErrorHandler sets the error handler for errors returned by event handlers in [Async] mode.
+//
+// The default handler logs the error to [slog.Default].
+func WithErrorHandler(h ErrorHandler) DispatcherOption {
+	return dispatcherOption(func(d *Dispatcher) {
+		if h != nil {
+			d.errorHandler = h
+		}
+	})
+}
+
+// WithContainerOptions sets the options to use when calling [di.Container.NewScope] for each dispatch.
+func WithContainerOptions(opts ...di.ContainerOption) DispatcherOption {
+	return dispatcherOption(func(d *Dispatcher) {
+		d.cfg.AddContainerOptions(opts...)
+	})
+}
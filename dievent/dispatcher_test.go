@@ -0,0 +1,295 @@
+package dievent_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/dievent"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/mocks"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type UserCreated struct {
+	UserID string
+}
+
+type RecordingHandler struct {
+	events []UserCreated
+	mu     sync.Mutex
+}
+
+func (h *RecordingHandler) Handle(_ context.Context, e UserCreated) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.events = append(h.events, e)
+	return nil
+}
+
+func (h *RecordingHandler) Events() []UserCreated {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.events
+}
+
+func Test_NewDispatcher(t *testing.T) {
+	t.Run("scope does not support NewScope", func(t *testing.T) {
+		scope := mocks.NewScopeMock(t)
+
+		assert.PanicsWithValue(t, "dievent.NewDispatcher: scope does not support NewScope", func() {
+			dievent.NewDispatcher(scope)
+		})
+	})
+
+	t.Run("resolve from container", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(dievent.NewDispatcher),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		d, err := di.Resolve[*dievent.Dispatcher](ctx, c)
+		assert.NotNil(t, d)
+		assert.NoError(t, err)
+	})
+}
+
+func Test_Dispatch(t *testing.T) {
+	t.Run("multiple handlers", func(t *testing.T) {
+		h1 := &RecordingHandler{}
+		h2 := &RecordingHandler{}
+
+		c, err := di.NewContainer(
+			di.WithService(h1, dievent.Handles[UserCreated]()),
+			di.WithService(h2, dievent.Handles[UserCreated]()),
+			di.WithService(dievent.NewDispatcher),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		d := di.MustResolve[*dievent.Dispatcher](ctx, c)
+
+		err = dievent.Dispatch(ctx, d, UserCreated{UserID: "1"})
+		assert.NoError(t, err)
+
+		assert.Equal(t, []UserCreated{{UserID: "1"}}, h1.Events())
+		assert.Equal(t, []UserCreated{{UserID: "1"}}, h2.Events())
+	})
+
+	t.Run("no handlers", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(dievent.NewDispatcher),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		d := di.MustResolve[*dievent.Dispatcher](ctx, c)
+
+		err = dievent.Dispatch(ctx, d, UserCreated{UserID: "1"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("scoped handler", func(t *testing.T) {
+		var got []UserCreated
+		closed := 0
+
+		c, err := di.NewContainer(
+			di.WithService(func(e UserCreated) dievent.Handler[UserCreated] {
+				return dievent.HandlerFunc[UserCreated](func(_ context.Context, event UserCreated) error {
+					assert.Equal(t, e, event)
+					got = append(got, event)
+					return nil
+				})
+			}, di.Scoped, di.UseCloseFunc(func(context.Context, dievent.Handler[UserCreated]) error {
+				closed++
+				return nil
+			})),
+			di.WithService(dievent.NewDispatcher),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		d := di.MustResolve[*dievent.Dispatcher](ctx, c)
+
+		err = dievent.Dispatch(ctx, d, UserCreated{UserID: "1"})
+		assert.NoError(t, err)
+		err = dievent.Dispatch(ctx, d, UserCreated{UserID: "2"})
+		assert.NoError(t, err)
+
+		assert.Equal(t, []UserCreated{{UserID: "1"}, {UserID: "2"}}, got)
+		assert.Equal(t, 2, closed)
+	})
+
+	t.Run("interface event", func(t *testing.T) {
+		var got []fmt.Stringer
+
+		c, err := di.NewContainer(
+			di.WithService(func(e fmt.Stringer) dievent.Handler[fmt.Stringer] {
+				return dievent.HandlerFunc[fmt.Stringer](func(_ context.Context, event fmt.Stringer) error {
+					assert.Same(t, e, event)
+					got = append(got, event)
+					return nil
+				})
+			}, di.Scoped),
+			di.WithService(dievent.NewDispatcher),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		d := di.MustResolve[*dievent.Dispatcher](ctx, c)
+
+		event := &net.IPNet{}
+		err = dievent.Dispatch[fmt.Stringer](ctx, d, event)
+		assert.NoError(t, err)
+		assert.Equal(t, []fmt.Stringer{event}, got)
+	})
+
+	t.Run("basic event type", func(t *testing.T) {
+		var got []string
+
+		c, err := di.NewContainer(
+			di.WithDeclaredService[dievent.Handler[string]](
+				dievent.HandlerFunc[string](func(_ context.Context, event string) error {
+					got = append(got, event)
+					return nil
+				}),
+			),
+			di.WithService(dievent.NewDispatcher),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		d := di.MustResolve[*dievent.Dispatcher](ctx, c)
+
+		err = dievent.Dispatch(ctx, d, "user created")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"user created"}, got)
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		c, err := di.NewContainer(
+			di.WithService(func() dievent.Handler[UserCreated] {
+				return dievent.HandlerFunc[UserCreated](func(context.Context, UserCreated) error {
+					cancel()
+					return nil
+				})
+			}, di.Scoped, di.UseCloseFunc(func(ctx context.Context, _ dievent.Handler[UserCreated]) error {
+				return ctx.Err()
+			})),
+			di.WithService(dievent.NewDispatcher),
+		)
+		require.NoError(t, err)
+
+		d := di.MustResolve[*dievent.Dispatcher](ctx, c)
+
+		err = dievent.Dispatch(ctx, d, UserCreated{UserID: "1"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("handler errors", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() dievent.Handler[UserCreated] {
+				return dievent.HandlerFunc[UserCreated](func(context.Context, UserCreated) error {
+					return errors.New("handler error 1")
+				})
+			}),
+			di.WithService(func() dievent.Handler[UserCreated] {
+				return dievent.HandlerFunc[UserCreated](func(context.Context, UserCreated) error {
+					return errors.New("handler error 2")
+				})
+			}),
+			di.WithService(dievent.NewDispatcher),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		d := di.MustResolve[*dievent.Dispatcher](ctx, c)
+
+		err = dievent.Dispatch(ctx, d, UserCreated{UserID: "1"})
+		testutils.LogError(t, err)
+
+		assert.EqualError(t, err, "dievent.Dispatch dievent_test.UserCreated: "+
+			"handler dievent.HandlerFunc[github.com/sectrean/di-kit/dievent_test.UserCreated]: handler error 1\n"+
+			"handler dievent.HandlerFunc[github.com/sectrean/di-kit/dievent_test.UserCreated]: handler error 2")
+	})
+
+	t.Run("Handles not implemented", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(&struct{ RecordingHandler }{}, dievent.Handles[string]()),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.Error(t, err)
+	})
+
+	t.Run("Async", func(t *testing.T) {
+		h := &RecordingHandler{}
+		var handledErr error
+
+		c, err := di.NewContainer(
+			di.WithService(h, dievent.Handles[UserCreated]()),
+			di.WithService(func() dievent.Handler[UserCreated] {
+				return dievent.HandlerFunc[UserCreated](func(context.Context, UserCreated) error {
+					return errors.New("handler error")
+				})
+			}),
+			di.WithService(func(s di.Scope) *dievent.Dispatcher {
+				return dievent.NewDispatcher(s,
+					dievent.WithMode(dievent.Async),
+					dievent.WithErrorHandler(func(_ context.Context, _ any, err error) {
+						handledErr = err
+					}),
+				)
+			}),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		d := di.MustResolve[*dievent.Dispatcher](ctx, c)
+
+		err = dievent.Dispatch(ctx, d, UserCreated{UserID: "1"})
+		assert.NoError(t, err)
+
+		d.Wait()
+		assert.Equal(t, []UserCreated{{UserID: "1"}}, h.Events())
+		assert.EqualError(t, handledErr, "dievent.Dispatch dievent_test.UserCreated: "+
+			"handler dievent.HandlerFunc[github.com/sectrean/di-kit/dievent_test.UserCreated]: handler error")
+	})
+
+	t.Run("WithContainerOptions error", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func(s di.Scope) *dievent.Dispatcher {
+				return dievent.NewDispatcher(s,
+					dievent.WithContainerOptions(di.WithService(nil)),
+				)
+			}),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		d := di.MustResolve[*dievent.Dispatcher](ctx, c)
+
+		err = dievent.Dispatch(ctx, d, UserCreated{UserID: "1"})
+		testutils.LogError(t, err)
+
+		assert.EqualError(t, err, "dievent.Dispatch dievent_test.UserCreated: "+
+			"di.Container.NewScope: WithService: funcOrValue is nil")
+	})
+}
+
+func Test_Mode_String(t *testing.T) {
+	assert.Equal(t, "Sync", dievent.Sync.String())
+	assert.Equal(t, "Async", dievent.Async.String())
+	assert.Equal(t, "Unknown Mode 99", dievent.Mode(99).String())
+}
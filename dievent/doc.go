@@ -0,0 +1,39 @@
+/*
+Package dievent provides an event bus where event handlers are registered as services with a [di.Container].
+
+Handlers are registered using [di.WithService] and the [Handles] option.
+A [Dispatcher] is resolved from the container and used to dispatch events to all registered handlers.
+Each dispatch creates a new child scope, so handlers may be registered with [di.Scoped].
+
+Example:
+
+	type UserCreated struct {
+		UserID string
+	}
+
+	type UserCreatedHandler struct {
+		mailer *Mailer
+	}
+
+	func NewUserCreatedHandler(m *Mailer) *UserCreatedHandler {
+		return &UserCreatedHandler{mailer: m}
+	}
+
+	func (h *UserCreatedHandler) Handle(ctx context.Context, e UserCreated) error {
+		return h.mailer.SendWelcome(ctx, e.UserID)
+	}
+
+	func main() {
+		c, err := di.NewContainer(
+			di.WithService(NewMailer),
+			di.WithService(NewUserCreatedHandler, dievent.Handles[UserCreated]()),
+			di.WithService(dievent.NewDispatcher),
+		)
+		...
+
+		d := di.MustResolve[*dievent.Dispatcher](ctx, c)
+		err = dievent.Dispatch(ctx, d, UserCreated{UserID: "123"})
+		...
+	}
+*/
+package dievent
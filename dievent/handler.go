@@ -0,0 +1,40 @@
+package dievent
+
+import (
+	"context"
+
+	"github.com/sectrean/di-kit"
+)
+
+// Handler handles events of type *Event*.
+//
+// Register a handler service with [di.WithService] and the [Handles] option.
+type Handler[Event any] interface {
+	// Handle the event.
+	Handle(ctx context.Context, event Event) error
+}
+
+// HandlerFunc is a function that implements [Handler].
+type HandlerFunc[Event any] func(ctx context.Context, event Event) error
+
+// Handle calls f(ctx, event).
+func (f HandlerFunc[Event]) Handle(ctx context.Context, event Event) error {
+	return f(ctx, event)
+}
+
+// Handles registers the service as a [Handler] for events of type *Event* when calling [di.WithService].
+//
+// This option can be used multiple times if a service handles more than one event type.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithService(NewUserCreatedHandler, // NewUserCreatedHandler() *UserCreatedHandler
+//			dievent.Handles[UserCreated](),
+//		),
+//	)
+//
+// This option will return an error if the service does not implement Handler[Event].
+func Handles[Event any]() di.ServiceOption {
+	return di.As[Handler[Event]]()
+}
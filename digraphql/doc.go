@@ -0,0 +1,50 @@
+/*
+Package digraphql provides GraphQL server middleware for creating [di.Container] scopes for each
+operation or resolver.
+
+The middleware is compatible with [gqlgen] without depending on it directly.
+The child scope is stored on the context and can be accessed using [dicontext.Scope], [dicontext.Resolve],
+or [dicontext.MustResolve].
+
+Example:
+
+	package main
+
+	import (
+		"net/http"
+
+		"github.com/99designs/gqlgen/graphql"
+		"github.com/99designs/gqlgen/graphql/handler"
+		"github.com/sectrean/di-kit"
+		"github.com/sectrean/di-kit/digraphql"
+	)
+
+	func main() {
+		c, err := di.NewContainer(
+			di.WithService(NewService),
+			di.WithService(NewLoaders, di.Scoped), // NewLoaders(*graphql.OperationContext) *Loaders
+			di.WithService(graph.NewResolver),     // NewResolver(*Service) *graph.Resolver
+		)
+		...
+
+		resolver := di.MustResolve[*graph.Resolver](ctx, c)
+		srv := handler.NewDefaultServer(generated.NewExecutableSchema(generated.Config{Resolvers: resolver}))
+
+		// Create a new scope for each GraphQL operation
+		srv.AroundOperations(digraphql.NewOperationScopeMiddleware[graphql.OperationHandler, graphql.ResponseHandler](c,
+			digraphql.WithOperationContext(graphql.GetOperationContext),
+		))
+
+		http.Handle("/query", srv)
+		http.ListenAndServe(":8080", nil)
+	}
+
+	// Resolve scoped services from the operation context in resolvers
+	func (r *queryResolver) User(ctx context.Context, id string) (*model.User, error) {
+		loaders := dicontext.MustResolve[*Loaders](ctx)
+		return loaders.User.Load(ctx, id)
+	}
+
+[gqlgen]: https://gqlgen.com
+*/
+package digraphql
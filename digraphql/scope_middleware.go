@@ -0,0 +1,188 @@
+package digraphql
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/dicontext"
+	"github.com/sectrean/di-kit/internal/scopecall"
+)
+
+// OperationMiddleware is a function that wraps the execution of a GraphQL operation.
+//
+// This is compatible with gqlgen's graphql.OperationMiddleware.
+type OperationMiddleware[OperationHandler, ResponseHandler any] = func(context.Context, OperationHandler) ResponseHandler
+
+// ResolverMiddleware is a function that wraps a GraphQL field resolver.
+//
+// This is compatible with gqlgen's graphql.FieldMiddleware.
+type ResolverMiddleware[Resolver any] = func(context.Context, Resolver) (any, error)
+
+// NewOperationScopeMiddleware returns GraphQL operation middleware that creates a new child container by calling
+// [di.Container.NewScope] for each operation.
+// The child container is stored on the operation context, where [dicontext.Resolve] can use it.
+//
+// The child container is closed when the response handler returns a nil response, which ends the response stream,
+// or with [di.CloseOnDone] when the operation context is done, whichever happens first.
+// For queries and mutations served over HTTP, this is when the request has completed.
+// For subscriptions, this is when the subscription has ended.
+// When executing operations with a context that is never canceled, call the response handler until it
+// returns nil so the child container is closed.
+//
+// Use with gqlgen:
+//
+//	srv.AroundOperations(digraphql.NewOperationScopeMiddleware[graphql.OperationHandler, graphql.ResponseHandler](c))
+//
+// Available options:
+//   - [WithContainerOptions]: Set [di.ContainerOption]s to use when creating each scope.
+//   - [WithOperationContext]: Register a service from the operation context with each scope.
+//   - [WithNewScopeErrorHandler]: Set the error handler for when there is an error creating a new scope.
+//   - [WithScopeCloseErrorHandler]: Set the error handler for when there is an error closing the scope.
+//
+// This will panic if parent is nil.
+func NewOperationScopeMiddleware[
+	OperationHandler ~func(context.Context) ResponseHandler,
+	ResponseHandler ~func(context.Context) Response,
+	Response any,
+](parent di.ContainerInterface, opts ...ScopeMiddlewareOption) OperationMiddleware[OperationHandler, ResponseHandler] {
+	if parent == nil {
+		panic("digraphql.NewOperationScopeMiddleware: parent is nil")
+	}
+
+	mw := newScopeMiddleware(opts)
+
+	return func(ctx context.Context, next OperationHandler) ResponseHandler {
+		scope, err := mw.NewScope(ctx, parent)
+		if err != nil {
+			mw.newScopeHandler(ctx, err)
+			return next(ctx)
+		}
+
+		// Close the scope when the operation is done, if the response stream hasn't ended first
+		stop := di.CloseOnDone(ctx, scope, func(err error) {
+			mw.closeHandler(ctx, err)
+		})
+
+		responses := next(dicontext.WithScope(ctx, scope))
+
+		return func(ctx context.Context) Response {
+			// Add the scope to the context passed to each response
+			res := responses(dicontext.WithScope(ctx, scope))
+
+			// A nil response ends the response stream
+			if isNil(res) && stop() {
+				mw.closeScope(ctx, scope)
+			}
+
+			return res
+		}
+	}
+}
+
+// NewResolverScopeMiddleware returns GraphQL field middleware that creates a new child container by calling
+// [di.Container.NewScope] for each resolver.
+// The child container is stored on the resolver context and is closed after the resolver returns.
+//
+// If the context already carries a [di.Container] scope, for example from [NewOperationScopeMiddleware],
+// the new scope is created as a child of that scope. Otherwise, it is created as a child of parent.
+//
+// Use with gqlgen:
+//
+//	srv.AroundFields(digraphql.NewResolverScopeMiddleware[graphql.Resolver](c))
+//
+// Services resolved from the resolver scope should not be returned from the resolver,
+// since the scope is closed before child fields are resolved.
+//
+// This will panic if parent is nil.
+func NewResolverScopeMiddleware[Resolver ~func(context.Context) (any, error)](
+	parent di.ContainerInterface,
+	opts ...ScopeMiddlewareOption,
+) ResolverMiddleware[Resolver] {
+	if parent == nil {
+		panic("digraphql.NewResolverScopeMiddleware: parent is nil")
+	}
+
+	mw := newScopeMiddleware(opts)
+
+	return func(ctx context.Context, next Resolver) (any, error) {
+		var p di.ContainerInterface = parent
+		if s, ok := dicontext.Scope(ctx).(di.ContainerInterface); ok {
+			p = s
+		}
+
+		scope, err := mw.NewScope(ctx, p)
+		if err != nil {
+			mw.newScopeHandler(ctx, err)
+			return nil, err
+		}
+		defer mw.closeScope(ctx, scope)
+
+		return next(dicontext.WithScope(ctx, scope))
+	}
+}
+
+// NewScopeErrorHandler is a function that handles errors when creating a new [di.Container] scope.
+//
+// For operations, execution continues without a new scope on the context.
+// For resolvers, the error is also returned from the resolver.
+//
+// The default handler logs the error to [slog.Default].
+type NewScopeErrorHandler = func(context.Context, error)
+
+func defaultNewScopeErrorHandler(ctx context.Context, err error) {
+	slog.ErrorContext(ctx,
+		"error creating new di.Container scope for GraphQL operation",
+		"error", err,
+	)
+}
+
+// ScopeCloseErrorHandler is a function that handles errors when closing a [di.Container] scope.
+//
+// The default handler logs the error to [slog.Default].
+type ScopeCloseErrorHandler = func(context.Context, error)
+
+func defaultScopeCloseErrorHandler(ctx context.Context, err error) {
+	slog.ErrorContext(ctx,
+		"error closing di.Container scope for GraphQL operation",
+		"error", err,
+	)
+}
+
+type scopeMiddleware struct {
+	newScopeHandler NewScopeErrorHandler
+	closeHandler    ScopeCloseErrorHandler
+	scopecall.Config
+}
+
+func newScopeMiddleware(opts []ScopeMiddlewareOption) *scopeMiddleware {
+	mw := &scopeMiddleware{
+		newScopeHandler: defaultNewScopeErrorHandler,
+		closeHandler:    defaultScopeCloseErrorHandler,
+	}
+
+	for _, opt := range opts {
+		opt.applyScopeMiddleware(mw)
+	}
+
+	return mw
+}
+
+func (m *scopeMiddleware) closeScope(ctx context.Context, scope di.ContainerInterface) {
+	err := scopecall.Close(ctx, scope)
+	if err != nil {
+		m.closeHandler(ctx, err)
+	}
+}
+
+// isNil returns true if the response is nil, like a nil *graphql.Response from gqlgen.
+func isNil[Response any](res Response) bool {
+	v := reflect.ValueOf(&res).Elem()
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,62 @@
+package digraphql
+
+import (
+	"context"
+
+	"github.com/sectrean/di-kit"
+)
+
+// ScopeMiddlewareOption is an option used to configure the scope middleware when calling
+// [NewOperationScopeMiddleware] or [NewResolverScopeMiddleware].
+type ScopeMiddlewareOption interface {
+	applyScopeMiddleware(*scopeMiddleware)
+}
+
+type scopeMiddlewareOption func(*scopeMiddleware)
+
+func (o scopeMiddlewareOption) applyScopeMiddleware(m *scopeMiddleware) {
+	o(m)
+}
+
+// WithContainerOptions sets the options to use when calling [di.Container.NewScope] for each scope.
+func WithContainerOptions(opts ...di.ContainerOption) ScopeMiddlewareOption {
+	return scopeMiddlewareOption(func(m *scopeMiddleware) {
+		m.AddContainerOptions(opts...)
+	})
+}
+
+// WithOperationContext registers the value returned by get with each new scope.
+// It can be used as a dependency for scoped services.
+//
+// This is used to register the operation context from the GraphQL server:
+//
+//	digraphql.WithOperationContext(graphql.GetOperationContext)
+func WithOperationContext[Service any](get func(context.Context) Service) ScopeMiddlewareOption {
+	return scopeMiddlewareOption(func(m *scopeMiddleware) {
+		m.AddContextOption(func(ctx context.Context) di.ContainerOption {
+			return di.WithDeclaredService[Service](get(ctx))
+		})
+	})
+}
+
+// WithNewScopeErrorHandler sets the error handler for when there is an error creating a new scope.
+//
+// The default handler logs the error to [slog.Default].
+func WithNewScopeErrorHandler(h NewScopeErrorHandler) ScopeMiddlewareOption {
+	return scopeMiddlewareOption(func(m *scopeMiddleware) {
+		if h != nil {
+			m.newScopeHandler = h
+		}
+	})
+}
+
+// WithScopeCloseErrorHandler sets the error handler for when there is an error closing a scope.
+//
+// The default handler logs the error to [slog.Default].
+func WithScopeCloseErrorHandler(h ScopeCloseErrorHandler) ScopeMiddlewareOption {
+	return scopeMiddlewareOption(func(m *scopeMiddleware) {
+		if h != nil {
+			m.closeHandler = h
+		}
+	})
+}
@@ -0,0 +1,322 @@
+package digraphql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/dicontext"
+	"github.com/sectrean/di-kit/digraphql"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/mocks"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// These types mirror the gqlgen middleware types.
+type (
+	Response         struct{ Data string }
+	ResponseHandler  func(ctx context.Context) *Response
+	OperationHandler func(ctx context.Context) ResponseHandler
+	Resolver         func(ctx context.Context) (res any, err error)
+
+	OperationContext struct{ Name string }
+)
+
+type operationContextKey struct{}
+
+func GetOperationContext(ctx context.Context) *OperationContext {
+	return ctx.Value(operationContextKey{}).(*OperationContext)
+}
+
+func Test_NewOperationScopeMiddleware(t *testing.T) {
+	t.Run("parent nil", func(t *testing.T) {
+		assert.PanicsWithValue(t, "digraphql.NewOperationScopeMiddleware: parent is nil", func() {
+			digraphql.NewOperationScopeMiddleware[OperationHandler, ResponseHandler](nil)
+		})
+	})
+
+	t.Run("Resolve scoped service", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(testtypes.NewInterfaceB, di.Scoped),
+		)
+		require.NoError(t, err)
+
+		mw := digraphql.NewOperationScopeMiddleware[OperationHandler, ResponseHandler](c)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		res := RunOperation(ctx, mw, func(ctx context.Context) *Response {
+			b, resolveErr := dicontext.Resolve[testtypes.InterfaceB](ctx)
+			assert.NotNil(t, b)
+			assert.NoError(t, resolveErr)
+
+			return &Response{Data: "ok"}
+		})
+		cancel()
+
+		assert.Equal(t, &Response{Data: "ok"}, res)
+	})
+
+	t.Run("WithOperationContext", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func(oc *OperationContext) *testtypes.StructA {
+				return &testtypes.StructA{Tag: oc.Name}
+			}, di.Scoped),
+		)
+		require.NoError(t, err)
+
+		mw := digraphql.NewOperationScopeMiddleware[OperationHandler, ResponseHandler](c,
+			digraphql.WithOperationContext(GetOperationContext),
+		)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		ctx = context.WithValue(ctx, operationContextKey{}, &OperationContext{Name: "GetUser"})
+
+		res := RunOperation(ctx, mw, func(ctx context.Context) *Response {
+			a := dicontext.MustResolve[*testtypes.StructA](ctx)
+			return &Response{Data: a.Tag.(string)}
+		})
+		cancel()
+
+		assert.Equal(t, &Response{Data: "GetUser"}, res)
+	})
+
+	t.Run("scope closed when context done", func(t *testing.T) {
+		closed := make(chan struct{})
+
+		c, err := di.NewContainer(
+			di.WithService(func() testtypes.InterfaceA {
+				a := mocks.NewInterfaceAMock(t)
+				a.EXPECT().
+					Close(mock.Anything).
+					RunAndReturn(func(ctx context.Context) error {
+						// Closed with a grace period, even though the context is canceled
+						_, ok := ctx.Deadline()
+						assert.True(t, ok)
+						assert.NoError(t, ctx.Err())
+
+						close(closed)
+						return nil
+					})
+
+				return a
+			}, di.Scoped),
+		)
+		require.NoError(t, err)
+
+		mw := digraphql.NewOperationScopeMiddleware[OperationHandler, ResponseHandler](c)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		_ = RunOperation(ctx, mw, func(ctx context.Context) *Response {
+			_ = dicontext.MustResolve[testtypes.InterfaceA](ctx)
+			return &Response{}
+		})
+
+		select {
+		case <-closed:
+			assert.Fail(t, "scope should not be closed before the context is done")
+		default:
+		}
+
+		cancel()
+		<-closed
+	})
+
+	t.Run("scope closed when response stream ends", func(t *testing.T) {
+		closed := 0
+
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.Scoped,
+				di.UseCloseFunc(func(context.Context, testtypes.InterfaceA) error {
+					closed++
+					return nil
+				}),
+			),
+		)
+		require.NoError(t, err)
+
+		mw := digraphql.NewOperationScopeMiddleware[OperationHandler, ResponseHandler](c)
+
+		// The context is never canceled
+		ctx := context.Background()
+		responses := []*Response{{Data: "1"}, {Data: "2"}, nil}
+		next := OperationHandler(func(context.Context) ResponseHandler {
+			return func(ctx context.Context) *Response {
+				_ = dicontext.MustResolve[testtypes.InterfaceA](ctx)
+
+				res := responses[0]
+				responses = responses[1:]
+				return res
+			}
+		})
+
+		handler := mw(ctx, next)
+		assert.Equal(t, &Response{Data: "1"}, handler(ctx))
+		assert.Equal(t, &Response{Data: "2"}, handler(ctx))
+		assert.Equal(t, 0, closed)
+
+		assert.Nil(t, handler(ctx))
+		assert.Equal(t, 1, closed)
+	})
+
+	t.Run("response context", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.Scoped),
+		)
+		require.NoError(t, err)
+
+		mw := digraphql.NewOperationScopeMiddleware[OperationHandler, ResponseHandler](c)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var opScope di.Scope
+		next := OperationHandler(func(ctx context.Context) ResponseHandler {
+			opScope = dicontext.Scope(ctx)
+			return func(ctx context.Context) *Response {
+				// Values added by later middleware are kept, and the scope is added
+				assert.Same(t, opScope, dicontext.Scope(ctx))
+				return &Response{Data: testutils.TestValue(ctx).(string)}
+			}
+		})
+
+		res := mw(ctx, next)(testutils.ContextWithTestValue(ctx, "value"))
+		assert.Equal(t, &Response{Data: "value"}, res)
+	})
+
+	t.Run("NewScope error", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		called := false
+
+		mw := digraphql.NewOperationScopeMiddleware[OperationHandler, ResponseHandler](c,
+			digraphql.WithContainerOptions(
+				di.WithService(nil),
+			),
+			digraphql.WithNewScopeErrorHandler(func(_ context.Context, err error) {
+				assert.EqualError(t, err, "di.Container.NewScope: WithService: funcOrValue is nil")
+				called = true
+			}),
+		)
+
+		res := RunOperation(context.Background(), mw, func(ctx context.Context) *Response {
+			assert.Nil(t, dicontext.Scope(ctx))
+			return &Response{}
+		})
+
+		assert.NotNil(t, res)
+		assert.True(t, called)
+	})
+
+	t.Run("Close error", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() testtypes.InterfaceA {
+				a := mocks.NewInterfaceAMock(t)
+				a.EXPECT().
+					Close(mock.Anything).
+					Return(errors.New("close error"))
+
+				return a
+			}, di.Transient),
+		)
+		require.NoError(t, err)
+
+		closeErr := make(chan error, 1)
+
+		mw := digraphql.NewOperationScopeMiddleware[OperationHandler, ResponseHandler](c,
+			digraphql.WithScopeCloseErrorHandler(func(_ context.Context, err error) {
+				closeErr <- err
+			}),
+		)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		_ = RunOperation(ctx, mw, func(ctx context.Context) *Response {
+			_ = dicontext.MustResolve[testtypes.InterfaceA](ctx)
+			return &Response{}
+		})
+		cancel()
+
+		assert.EqualError(t, <-closeErr, "di.Container.Close: close error")
+	})
+}
+
+func Test_NewResolverScopeMiddleware(t *testing.T) {
+	t.Run("parent nil", func(t *testing.T) {
+		assert.PanicsWithValue(t, "digraphql.NewResolverScopeMiddleware: parent is nil", func() {
+			digraphql.NewResolverScopeMiddleware[Resolver](nil)
+		})
+	})
+
+	t.Run("Resolve scoped service", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(testtypes.NewInterfaceB, di.Scoped),
+		)
+		require.NoError(t, err)
+
+		mw := digraphql.NewResolverScopeMiddleware[Resolver](c)
+
+		res, err := mw(context.Background(), func(ctx context.Context) (any, error) {
+			return dicontext.Resolve[testtypes.InterfaceB](ctx)
+		})
+		assert.NotNil(t, res)
+		assert.NoError(t, err)
+	})
+
+	t.Run("child of operation scope", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+
+		opScope, err := c.NewScope(
+			di.WithService(testtypes.NewInterfaceB),
+		)
+		require.NoError(t, err)
+
+		mw := digraphql.NewResolverScopeMiddleware[Resolver](c)
+
+		ctx := dicontext.WithScope(context.Background(), opScope)
+		res, err := mw(ctx, func(ctx context.Context) (any, error) {
+			assert.NotSame(t, opScope, dicontext.Scope(ctx))
+			return dicontext.Resolve[testtypes.InterfaceB](ctx)
+		})
+		assert.NotNil(t, res)
+		assert.NoError(t, err)
+	})
+
+	t.Run("NewScope error", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		mw := digraphql.NewResolverScopeMiddleware[Resolver](c,
+			digraphql.WithContainerOptions(
+				di.WithService(nil),
+			),
+		)
+
+		res, err := mw(context.Background(), func(context.Context) (any, error) {
+			assert.Fail(t, "resolver should not get called")
+			return "result", nil
+		})
+		assert.Nil(t, res)
+		assert.EqualError(t, err, "di.Container.NewScope: WithService: funcOrValue is nil")
+	})
+}
+
+func RunOperation(
+	ctx context.Context,
+	mw digraphql.OperationMiddleware[OperationHandler, ResponseHandler],
+	handler ResponseHandler,
+) *Response {
+	next := OperationHandler(func(context.Context) ResponseHandler {
+		return handler
+	})
+
+	return mw(ctx, next)(ctx)
+}
@@ -3,6 +3,7 @@ package dihttp
 import (
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/sectrean/di-kit"
 	"github.com/sectrean/di-kit/dicontext"
@@ -14,31 +15,37 @@ type Middleware = func(http.Handler) http.Handler
 // NewRequestScopeMiddleware returns HTTP middleware that creates a new child container by calling
 // [di.Container.NewScope] for each request.
 // The child container is stored on the request context and can be accessed using [dicontext.Scope], [dicontext.Resolve], or [dicontext.MustResolve].
-// The child container is closed after the request is processed.
+// The child container is closed after the request is processed, even if the request context has been canceled.
+// Closing the child container is limited to [di.DefaultCloseGracePeriod], which can be changed with WithCloseGracePeriod.
 //
 // The current [*http.Request] is automatically registered with the child-scoped container. It can be used as a dependency for scoped services.
 //
 // Available options:
 //   - WithScopeOptions: Set [di.ContainerOptions]s options to use when creating each request scope.
+//   - WithPrincipal: Register the authenticated principal for each request.
 //   - WithNewScopeErrorHandler: Set the error handler for when there is an error creating a new scope.
 //   - WithScopeCloseErrorHandler: Set the error handler for when there is an error closing the scope.
+//   - WithCloseGracePeriod: Set the time allowed for closing the scope after the request has been processed.
+//
+// The parent is usually a [*di.Container]. A fake [di.ContainerInterface] can be used in tests.
 //
 // This will panic if parent is nil.
-func NewRequestScopeMiddleware(parent *di.Container, opts ...ScopeMiddlewareOption) Middleware {
+func NewRequestScopeMiddleware(parent di.ContainerInterface, opts ...ScopeMiddlewareOption) Middleware {
 	if parent == nil {
 		panic("dihttp.NewRequestScopeMiddleware: parent is nil")
 	}
 
 	return func(next http.Handler) http.Handler {
-		mw := scopeMiddleware{
+		mw := &scopeMiddleware{
 			next:            next,
 			parent:          parent,
 			newScopeHandler: defaultNewScopeErrorHandler,
 			closeHandler:    defaultScopeCloseErrorHandler,
+			closeGrace:      di.DefaultCloseGracePeriod,
 		}
 
 		for _, opt := range opts {
-			opt.applyScopeMiddleware(&mw)
+			opt.applyScopeMiddleware(mw)
 		}
 
 		return mw
@@ -77,20 +84,32 @@ func defaultScopeCloseErrorHandler(r *http.Request, err error) {
 
 type scopeMiddleware struct {
 	next            http.Handler
-	parent          *di.Container
+	parent          di.ContainerInterface
 	newScopeHandler NewScopeErrorHandler
 	closeHandler    ScopeCloseErrorHandler
 	opts            []di.ContainerOption
+	reqOpts         []func(*http.Request) (di.ContainerOption, error)
+	closeGrace      time.Duration
 }
 
-func (m scopeMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+func (m *scopeMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Use provided options and also register the current HTTP request
-	opts := make([]di.ContainerOption, len(m.opts)+1)
-	copy(opts, m.opts)
-	opts[len(m.opts)] = di.WithService(r)
+	opts := make([]di.ContainerOption, 0, len(m.opts)+len(m.reqOpts)+1)
+	opts = append(opts, m.opts...)
+	opts = append(opts, di.WithService(r))
+
+	// Register services extracted from the request
+	for _, f := range m.reqOpts {
+		opt, err := f(r)
+		if err != nil {
+			m.newScopeHandler(w, r, err)
+			return
+		}
+		opts = append(opts, opt)
+	}
 
 	// Create child scope for the request
-	scope, err := m.parent.NewScope(opts...)
+	scope, err := m.parent.NewChildScope(opts...)
 	if err != nil {
 		m.newScopeHandler(w, r, err)
 		return
@@ -99,12 +118,14 @@ func (m scopeMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Add the scope to the request context
 	ctx := dicontext.WithScope(r.Context(), scope)
 
+	// Close the scope after the request has been processed, even if the handler panics
+	defer func() {
+		closeErr := di.CloseWithGrace(ctx, scope, m.closeGrace)
+		if closeErr != nil {
+			m.closeHandler(r, closeErr)
+		}
+	}()
+
 	// Call the next handler with the new context
 	m.next.ServeHTTP(w, r.WithContext(ctx))
-
-	// Close the scope after the request has been processed
-	err = scope.Close(ctx)
-	if err != nil {
-		m.closeHandler(r, err)
-	}
 }
@@ -1,7 +1,12 @@
 package dihttp
 
 import (
+	"net/http"
+	"reflect"
+	"time"
+
 	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
 )
 
 // ScopeMiddlewareOption is an option used to configure the scope middleware when calling [NewRequestScopeMiddleware].
@@ -22,6 +27,42 @@ func WithContainerOptions(opts ...di.ContainerOption) ScopeMiddlewareOption {
 	})
 }
 
+// WithPrincipal registers the authenticated principal for each request with the request scope.
+// It can be used as a dependency for scoped services.
+//
+// The extract function is called for each request before the scope is created.
+// If extract returns an error, the [NewScopeErrorHandler] is called and the next handler is not called.
+// Use [WithNewScopeErrorHandler] to write an appropriate response, like "401 Unauthorized".
+//
+// If extract returns a nil principal without an error, it is treated as an error and the
+// [NewScopeErrorHandler] is called. Return an error for anonymous requests, or a principal that
+// represents an anonymous user, so scoped services never depend on a nil principal.
+//
+// Unlike [di.WithContextValue], extract is called once for each request instead of each time the
+// principal is resolved, and its errors are handled by the middleware instead of by each resolver.
+//
+// Example:
+//
+//	mw := dihttp.NewRequestScopeMiddleware(c,
+//		dihttp.WithPrincipal(auth.UserFromRequest), // UserFromRequest(*http.Request) (*auth.User, error)
+//	)
+func WithPrincipal[Principal any](extract func(*http.Request) (Principal, error)) ScopeMiddlewareOption {
+	return scopeMiddlewareOption(func(m *scopeMiddleware) {
+		m.reqOpts = append(m.reqOpts, func(r *http.Request) (di.ContainerOption, error) {
+			p, err := extract(r)
+			if err != nil {
+				return nil, errors.Wrapf(err, "dihttp.WithPrincipal %s", reflect.TypeFor[Principal]())
+			}
+
+			if v := reflect.ValueOf(p); !v.IsValid() || (v.Kind() == reflect.Pointer && v.IsNil()) {
+				return nil, errors.Errorf("dihttp.WithPrincipal %s: principal is nil", reflect.TypeFor[Principal]())
+			}
+
+			return di.WithDeclaredService[Principal](p), nil
+		})
+	})
+}
+
 // WithNewScopeErrorHandler sets the error handler for when there is an error creating a new scope.
 //
 // The default handler logs the error to [slog.Default] and writes a "500 Internal Server Error" response.
@@ -44,3 +85,15 @@ func WithScopeCloseErrorHandler(h ScopeCloseErrorHandler) ScopeMiddlewareOption
 		}
 	})
 }
+
+// WithCloseGracePeriod sets the time allowed for closing the request-scoped [di.Container]
+// after the request has been processed.
+//
+// The scope is closed even if the request context has been canceled.
+// The default is [di.DefaultCloseGracePeriod]. If d is not positive, closing the scope has no deadline.
+// See [di.CloseWithGrace] for more information.
+func WithCloseGracePeriod(d time.Duration) ScopeMiddlewareOption {
+	return scopeMiddlewareOption(func(m *scopeMiddleware) {
+		m.closeGrace = d
+	})
+}
@@ -1,10 +1,12 @@
 package dihttp_test
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/sectrean/di-kit"
 	"github.com/sectrean/di-kit/dicontext"
@@ -209,6 +211,59 @@ func Test_Middleware(t *testing.T) {
 		assert.True(t, called)
 	})
 
+	t.Run("NewScope error ContainerInterfaceMock", func(t *testing.T) {
+		parent := mocks.NewContainerInterfaceMock(t)
+		parent.EXPECT().
+			NewChildScope(mock.Anything).
+			Return(nil, errors.New("new scope error")).
+			Once()
+
+		called := false
+
+		mw := dihttp.NewRequestScopeMiddleware(parent,
+			dihttp.WithNewScopeErrorHandler(func(_ http.ResponseWriter, _ *http.Request, err error) {
+				assert.EqualError(t, err, "new scope error")
+				called = true
+			}),
+		)
+
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Fail(t, "handler should not get called")
+		})
+
+		_ = RunRequest(t, mw(handler), "/")
+		assert.True(t, called)
+	})
+
+	t.Run("NewChildScope ContainerInterfaceMock", func(t *testing.T) {
+		scope := mocks.NewContainerInterfaceMock(t)
+		scope.EXPECT().
+			Resolve(mock.Anything, testtypes.TypeInterfaceA).
+			Return(testtypes.NewInterfaceA(), nil).
+			Once()
+		scope.EXPECT().
+			Close(mock.Anything).
+			Return(nil).
+			Once()
+
+		parent := mocks.NewContainerInterfaceMock(t)
+		parent.EXPECT().
+			NewChildScope(mock.Anything).
+			Return(scope, nil).
+			Once()
+
+		mw := dihttp.NewRequestScopeMiddleware(parent)
+
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			a, err := dicontext.Resolve[testtypes.InterfaceA](r.Context())
+			assert.NoError(t, err)
+			assert.NotNil(t, a)
+		})
+
+		code := RunRequest(t, mw(handler), "/")
+		assert.Equal(t, http.StatusOK, code)
+	})
+
 	t.Run("NewScope error default handler", func(t *testing.T) {
 		c, err := di.NewContainer()
 		require.NoError(t, err)
@@ -228,6 +283,98 @@ func Test_Middleware(t *testing.T) {
 		// TODO: Assert log output
 	})
 
+	t.Run("WithPrincipal", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func(p *Principal) *testtypes.StructA {
+				return &testtypes.StructA{Tag: p.Name}
+			}, di.Scoped),
+		)
+		require.NoError(t, err)
+
+		mw := dihttp.NewRequestScopeMiddleware(c,
+			dihttp.WithPrincipal(PrincipalFromRequest),
+		)
+
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			a, resolveErr := dicontext.Resolve[*testtypes.StructA](r.Context())
+			assert.NoError(t, resolveErr)
+			assert.Equal(t, "/alice", a.Tag)
+
+			w.WriteHeader(http.StatusOK)
+		})
+
+		code := RunRequest(t, mw(handler), "/alice")
+		assert.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("WithPrincipal interface", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		mw := dihttp.NewRequestScopeMiddleware(c,
+			dihttp.WithPrincipal(func(r *http.Request) (testtypes.InterfaceA, error) {
+				return testtypes.StructA{Tag: r.URL.Path}, nil
+			}),
+		)
+
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// The principal is registered as the declared type
+			a, resolveErr := dicontext.Resolve[testtypes.InterfaceA](r.Context())
+			assert.NoError(t, resolveErr)
+			assert.Equal(t, testtypes.StructA{Tag: "/alice"}, a)
+
+			w.WriteHeader(http.StatusOK)
+		})
+
+		code := RunRequest(t, mw(handler), "/alice")
+		assert.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("WithPrincipal error", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		mw := dihttp.NewRequestScopeMiddleware(c,
+			dihttp.WithPrincipal(func(*http.Request) (*Principal, error) {
+				return nil, errors.New("unauthenticated")
+			}),
+			dihttp.WithNewScopeErrorHandler(func(w http.ResponseWriter, _ *http.Request, err error) {
+				assert.EqualError(t, err, "dihttp.WithPrincipal *dihttp_test.Principal: unauthenticated")
+				w.WriteHeader(http.StatusUnauthorized)
+			}),
+		)
+
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Fail(t, "handler should not get called")
+		})
+
+		code := RunRequest(t, mw(handler), "/")
+		assert.Equal(t, http.StatusUnauthorized, code)
+	})
+
+	t.Run("WithPrincipal nil", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		mw := dihttp.NewRequestScopeMiddleware(c,
+			dihttp.WithPrincipal(func(*http.Request) (*Principal, error) {
+				var p *Principal
+				return p, nil
+			}),
+			dihttp.WithNewScopeErrorHandler(func(w http.ResponseWriter, _ *http.Request, err error) {
+				assert.EqualError(t, err, "dihttp.WithPrincipal *dihttp_test.Principal: principal is nil")
+				w.WriteHeader(http.StatusUnauthorized)
+			}),
+		)
+
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Fail(t, "handler should not get called")
+		})
+
+		code := RunRequest(t, mw(handler), "/")
+		assert.Equal(t, http.StatusUnauthorized, code)
+	})
+
 	t.Run("Close error", func(t *testing.T) {
 		c, err := di.NewContainer(
 			di.WithService(func() testtypes.InterfaceA {
@@ -262,6 +409,68 @@ func Test_Middleware(t *testing.T) {
 		assert.True(t, called)
 	})
 
+	t.Run("Close after handler panics", func(t *testing.T) {
+		closed := false
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.Transient,
+				di.UseCloseFunc(func(context.Context, testtypes.InterfaceA) error {
+					closed = true
+					return nil
+				}),
+			),
+		)
+		require.NoError(t, err)
+
+		mw := dihttp.NewRequestScopeMiddleware(c)
+
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = dicontext.MustResolve[testtypes.InterfaceA](r.Context())
+			panic("handler panic")
+		})
+
+		assert.PanicsWithValue(t, "handler panic", func() {
+			_ = RunRequest(t, mw(handler), "/")
+		})
+		assert.True(t, closed)
+	})
+
+	t.Run("Close after request canceled", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() testtypes.InterfaceA {
+				a := mocks.NewInterfaceAMock(t)
+				a.EXPECT().
+					Close(mock.Anything).
+					RunAndReturn(func(ctx context.Context) error {
+						assert.NoError(t, ctx.Err())
+
+						deadline, ok := ctx.Deadline()
+						assert.True(t, ok)
+						assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+						return nil
+					})
+
+				return a
+			}, di.Transient),
+		)
+		require.NoError(t, err)
+
+		mw := dihttp.NewRequestScopeMiddleware(c,
+			dihttp.WithCloseGracePeriod(time.Minute),
+		)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = dicontext.MustResolve[testtypes.InterfaceA](r.Context())
+			cancel()
+			w.WriteHeader(http.StatusOK)
+		})
+
+		res := httptest.NewRecorder()
+		req := httptest.NewRequestWithContext(ctx, http.MethodGet, "/", http.NoBody)
+		mw(handler).ServeHTTP(res, req)
+		assert.Equal(t, http.StatusOK, res.Code)
+	})
+
 	t.Run("Close error default handler", func(t *testing.T) {
 		c, err := di.NewContainer(
 			di.WithService(func() testtypes.InterfaceA {
@@ -288,6 +497,14 @@ func Test_Middleware(t *testing.T) {
 	})
 }
 
+type Principal struct {
+	Name string
+}
+
+func PrincipalFromRequest(r *http.Request) (*Principal, error) {
+	return &Principal{Name: r.URL.Path}, nil
+}
+
 func RunRequest(t *testing.T, h http.Handler, path string) int {
 	res := httptest.NewRecorder()
 	req, err := http.NewRequest(http.MethodGet, path, http.NoBody)
@@ -0,0 +1,229 @@
+package dilambda_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/dicontext"
+	"github.com/sectrean/di-kit/dilambda"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/mocks"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type OrderEvent struct {
+	OrderID string
+}
+
+type LambdaContext struct {
+	AwsRequestID string
+}
+
+var reflectTypeLambdaContext = reflect.TypeFor[*LambdaContext]()
+
+type lambdaContextKey struct{}
+
+func FromContext(ctx context.Context) (*LambdaContext, bool) {
+	lc, ok := ctx.Value(lambdaContextKey{}).(*LambdaContext)
+	return lc, ok
+}
+
+func Test_Handler(t *testing.T) {
+	t.Run("parent nil", func(t *testing.T) {
+		assert.PanicsWithValue(t, "dilambda.Handler: parent is nil", func() {
+			dilambda.Handler(nil, func(context.Context, OrderEvent) (string, error) { return "", nil })
+		})
+	})
+
+	t.Run("event registered", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func(event OrderEvent) *testtypes.StructA {
+				return &testtypes.StructA{Tag: event.OrderID}
+			}, di.Scoped),
+		)
+		require.NoError(t, err)
+
+		handler := dilambda.Handler(c, func(ctx context.Context, _ OrderEvent) (string, error) {
+			a, resolveErr := dicontext.Resolve[*testtypes.StructA](ctx)
+			if resolveErr != nil {
+				return "", resolveErr
+			}
+
+			return a.Tag.(string), nil
+		})
+
+		got, err := handler(context.Background(), OrderEvent{OrderID: "order-1"})
+		assert.NoError(t, err)
+		assert.Equal(t, "order-1", got)
+	})
+
+	t.Run("WithLambdaContext", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func(lc *LambdaContext) *testtypes.StructA {
+				return &testtypes.StructA{Tag: lc.AwsRequestID}
+			}, di.Scoped),
+		)
+		require.NoError(t, err)
+
+		handler := dilambda.Handler(c, func(ctx context.Context, _ OrderEvent) (string, error) {
+			a := dicontext.MustResolve[*testtypes.StructA](ctx)
+			return a.Tag.(string), nil
+		}, dilambda.WithLambdaContext(FromContext))
+
+		ctx := context.WithValue(context.Background(), lambdaContextKey{}, &LambdaContext{AwsRequestID: "request-1"})
+		got, err := handler(ctx, OrderEvent{})
+		assert.NoError(t, err)
+		assert.Equal(t, "request-1", got)
+	})
+
+	t.Run("WithLambdaContext not found", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		handler := dilambda.Handler(c, func(ctx context.Context, _ OrderEvent) (bool, error) {
+			return dicontext.Scope(ctx).Contains(reflectTypeLambdaContext), nil
+		}, dilambda.WithLambdaContext(FromContext))
+
+		got, err := handler(context.Background(), OrderEvent{})
+		assert.NoError(t, err)
+		assert.False(t, got)
+	})
+
+	t.Run("WithoutEvent", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		handler := dilambda.Handler(c, func(_ context.Context, event map[string]any) (any, error) {
+			return event["id"], nil
+		}, dilambda.WithoutEvent())
+
+		got, err := handler(context.Background(), map[string]any{"id": 1})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, got)
+	})
+
+	t.Run("WithContainerOptions", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		handler := dilambda.Handler(c, func(ctx context.Context, _ OrderEvent) (testtypes.InterfaceA, error) {
+			return dicontext.Resolve[testtypes.InterfaceA](ctx)
+		}, dilambda.WithContainerOptions(di.WithService(testtypes.NewInterfaceA)))
+
+		got, err := handler(context.Background(), OrderEvent{})
+		assert.NoError(t, err)
+		assert.NotNil(t, got)
+	})
+
+	t.Run("scope per invocation", func(t *testing.T) {
+		closed := 0
+
+		c, err := di.NewContainer(
+			di.WithService(func() testtypes.InterfaceA {
+				a := mocks.NewInterfaceAMock(t)
+				a.EXPECT().
+					Close(mock.Anything).
+					RunAndReturn(func(context.Context) error {
+						closed++
+						return nil
+					})
+
+				return a
+			}, di.Scoped),
+		)
+		require.NoError(t, err)
+
+		var scopes []di.Scope
+		handler := dilambda.Handler(c, func(ctx context.Context, _ OrderEvent) (int, error) {
+			_ = dicontext.MustResolve[testtypes.InterfaceA](ctx)
+			scopes = append(scopes, dicontext.Scope(ctx))
+			return closed, nil
+		})
+
+		ctx := context.Background()
+		_, err = handler(ctx, OrderEvent{})
+		assert.NoError(t, err)
+		_, err = handler(ctx, OrderEvent{})
+		assert.NoError(t, err)
+
+		require.Len(t, scopes, 2)
+		assert.NotSame(t, scopes[0], scopes[1])
+		assert.Equal(t, 2, closed)
+	})
+
+	t.Run("handler error", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		handler := dilambda.Handler(c, func(context.Context, OrderEvent) (int, error) {
+			return 0, errors.New("handler error")
+		})
+
+		_, err = handler(context.Background(), OrderEvent{})
+		assert.EqualError(t, err, "handler error")
+	})
+
+	t.Run("invalid event type", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		assert.PanicsWithValue(t, "dilambda.Handler: invalid service type map[string]interface {}; "+
+			"use a named type or a pointer to a named type, or use WithoutEvent", func() {
+			dilambda.Handler(c, func(context.Context, map[string]any) (int, error) {
+				return 0, nil
+			})
+		})
+	})
+
+	t.Run("Close error", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() testtypes.InterfaceA {
+				a := mocks.NewInterfaceAMock(t)
+				a.EXPECT().
+					Close(mock.Anything).
+					Return(errors.New("close error"))
+
+				return a
+			}, di.Scoped),
+		)
+		require.NoError(t, err)
+
+		handler := dilambda.Handler(c, func(ctx context.Context, _ OrderEvent) (int, error) {
+			_ = dicontext.MustResolve[testtypes.InterfaceA](ctx)
+			return 1, nil
+		})
+
+		got, err := handler(context.Background(), OrderEvent{})
+		testutils.LogError(t, err)
+
+		assert.Equal(t, 1, got)
+		assert.EqualError(t, err, "dilambda.Handler: di.Container.Close: close error")
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewStructAPtr, di.Scoped,
+				di.UseCloseFunc(func(ctx context.Context, _ *testtypes.StructA) error {
+					return ctx.Err()
+				}),
+			),
+		)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		handler := dilambda.Handler(c, func(ctx context.Context, _ OrderEvent) (int, error) {
+			_ = dicontext.MustResolve[*testtypes.StructA](ctx)
+			cancel()
+			return 1, nil
+		})
+
+		_, err = handler(ctx, OrderEvent{})
+		assert.NoError(t, err)
+	})
+}
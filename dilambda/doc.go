@@ -0,0 +1,31 @@
+/*
+Package dilambda provides utilities for running [AWS Lambda] functions with a [di.Container].
+
+The root container is created once, when the Lambda function starts, and is kept warm across invocations.
+Handler functions can be wrapped with [Handler] to create a new child scope for each invocation.
+The event payload, and optionally the Lambda context, are registered with the child scope,
+so they can be used as dependencies of scoped services.
+
+The package is compatible with the AWS Lambda Go runtime without depending on it directly.
+
+Example:
+
+	func main() {
+		c, err := di.NewContainer(
+			di.WithService(NewDynamoClient),
+			di.WithService(NewOrderHandler, di.Scoped), // NewOrderHandler(OrderEvent, *lambdacontext.LambdaContext, *DynamoClient) *OrderHandler
+		)
+		...
+
+		lambda.Start(dilambda.Handler(c, HandleOrder, dilambda.WithLambdaContext(lambdacontext.FromContext)))
+	}
+
+	// Resolve scoped services from the invocation context
+	func HandleOrder(ctx context.Context, event OrderEvent) (OrderResult, error) {
+		h := dicontext.MustResolve[*OrderHandler](ctx)
+		return h.Handle(ctx)
+	}
+
+[AWS Lambda]: https://aws.amazon.com/lambda/
+*/
+package dilambda
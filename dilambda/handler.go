@@ -0,0 +1,112 @@
+package dilambda
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/scopecall"
+)
+
+// Handler wraps a Lambda handler function to create a new child container by calling [di.Container.NewScope]
+// for each invocation.
+// The child container is stored on the invocation context, where
+// [github.com/sectrean/di-kit/dicontext.Resolve] can use it, and is closed with [di.CloseWithGrace]
+// after the handler function returns.
+//
+// The event payload is registered with the child container as type *In*, so it can be used as a dependency
+// of scoped services. *In* must be a valid service type, such as a named struct or a pointer to one.
+// Use [WithoutEvent] if it is not.
+//
+// Available options:
+//   - [WithContainerOptions]: Set [di.ContainerOption]s to use when creating each scope.
+//   - [WithLambdaContext]: Register the Lambda context with each scope.
+//   - [WithoutEvent]: Don't register the event payload with each scope.
+//
+// Errors creating or closing the scope are returned from the handler.
+//
+// This will panic if parent is nil, or if *In* is not a valid service type and WithoutEvent is not used.
+func Handler[In, Out any](
+	parent di.ContainerInterface,
+	fn func(context.Context, In) (Out, error),
+	opts ...ScopeOption,
+) func(context.Context, In) (Out, error) {
+	if parent == nil {
+		panic("dilambda.Handler: parent is nil")
+	}
+
+	cfg := &scopeConfig{}
+	for _, opt := range opts {
+		opt.applyScopeConfig(cfg)
+	}
+
+	// Check the event type once, so it doesn't fail every invocation
+	if !cfg.withoutEvent {
+		if err := di.ValidateServiceType(reflect.TypeFor[In]()); err != nil {
+			panic("dilambda.Handler: " + err.Error() + ", or use WithoutEvent")
+		}
+	}
+
+	return func(ctx context.Context, in In) (Out, error) {
+		var eventOpts []di.ContainerOption
+		if !cfg.withoutEvent {
+			eventOpts = append(eventOpts, di.WithDeclaredService(in))
+		}
+
+		return scopecall.Call(ctx, parent, &cfg.Config, "dilambda.Handler", func(ctx context.Context) (Out, error) {
+			return fn(ctx, in)
+		}, eventOpts...)
+	}
+}
+
+// ScopeOption is an option used to configure the scope created for each invocation when calling [Handler].
+type ScopeOption interface {
+	applyScopeConfig(*scopeConfig)
+}
+
+type scopeOption func(*scopeConfig)
+
+func (o scopeOption) applyScopeConfig(c *scopeConfig) {
+	o(c)
+}
+
+type scopeConfig struct {
+	scopecall.Config
+	withoutEvent bool
+}
+
+// WithContainerOptions sets the options to use when calling [di.Container.NewScope] for each invocation.
+func WithContainerOptions(opts ...di.ContainerOption) ScopeOption {
+	return scopeOption(func(c *scopeConfig) {
+		c.AddContainerOptions(opts...)
+	})
+}
+
+// WithLambdaContext registers the Lambda context returned by get with each new scope.
+// It can be used as a dependency for scoped services.
+// Nothing is registered if get returns false.
+//
+// Use with the AWS Lambda Go runtime:
+//
+//	dilambda.WithLambdaContext(lambdacontext.FromContext)
+func WithLambdaContext[LambdaContext any](get func(context.Context) (LambdaContext, bool)) ScopeOption {
+	return scopeOption(func(c *scopeConfig) {
+		c.AddContextOption(func(ctx context.Context) di.ContainerOption {
+			lc, ok := get(ctx)
+			if !ok {
+				return di.Module{}
+			}
+
+			return di.WithDeclaredService[LambdaContext](lc)
+		})
+	})
+}
+
+// WithoutEvent skips registering the event payload with each new scope.
+//
+// Use this if the event type is not a valid service type, such as map[string]any.
+func WithoutEvent() ScopeOption {
+	return scopeOption(func(c *scopeConfig) {
+		c.withoutEvent = true
+	})
+}
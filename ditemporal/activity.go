@@ -0,0 +1,86 @@
+package ditemporal
+
+import (
+	"context"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/scopecall"
+)
+
+// Activity wraps an activity function to create a new child container by calling [di.Container.NewScope]
+// for each activity execution.
+// The child container is stored on the context passed to fn, where
+// [github.com/sectrean/di-kit/dicontext.Resolve] can use it, and is closed with [di.CloseWithGrace]
+// after fn returns.
+//
+// The wrapped function should be registered with an explicit activity name since the name
+// of the function cannot be inferred:
+//
+//	w.RegisterActivityWithOptions(
+//		ditemporal.Activity(c, SendReport),
+//		activity.RegisterOptions{Name: "SendReport"},
+//	)
+//
+// Available options:
+//   - [WithContainerOptions]: Set [di.ContainerOption]s to use when creating each scope.
+//   - [WithActivityInfo]: Register the activity info with each scope.
+//
+// Errors creating or closing the scope are returned from the activity.
+//
+// This will panic if parent is nil.
+func Activity[In, Out any](
+	parent di.ContainerInterface,
+	fn func(context.Context, In) (Out, error),
+	opts ...ScopeOption,
+) func(context.Context, In) (Out, error) {
+	if parent == nil {
+		panic("ditemporal.Activity: parent is nil")
+	}
+
+	cfg := &scopeConfig{}
+	for _, opt := range opts {
+		opt.applyScopeConfig(cfg)
+	}
+
+	return func(ctx context.Context, in In) (Out, error) {
+		return scopecall.Call(ctx, parent, &cfg.Config, "ditemporal.Activity", func(ctx context.Context) (Out, error) {
+			return fn(ctx, in)
+		})
+	}
+}
+
+// ScopeOption is an option used to configure the scope created for each execution when calling [Activity].
+type ScopeOption interface {
+	applyScopeConfig(*scopeConfig)
+}
+
+type scopeOption func(*scopeConfig)
+
+func (o scopeOption) applyScopeConfig(c *scopeConfig) {
+	o(c)
+}
+
+type scopeConfig struct {
+	scopecall.Config
+}
+
+// WithContainerOptions sets the options to use when calling [di.Container.NewScope] for each execution.
+func WithContainerOptions(opts ...di.ContainerOption) ScopeOption {
+	return scopeOption(func(c *scopeConfig) {
+		c.AddContainerOptions(opts...)
+	})
+}
+
+// WithActivityInfo registers the activity info returned by get with each new scope.
+// It can be used as a dependency for scoped services.
+//
+// Use with the Temporal SDK:
+//
+//	ditemporal.WithActivityInfo(activity.GetInfo)
+func WithActivityInfo[Info any](get func(context.Context) Info) ScopeOption {
+	return scopeOption(func(c *scopeConfig) {
+		c.AddContextOption(func(ctx context.Context) di.ContainerOption {
+			return di.WithDeclaredService[Info](get(ctx))
+		})
+	})
+}
@@ -0,0 +1,239 @@
+package ditemporal_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/dicontext"
+	"github.com/sectrean/di-kit/ditemporal"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/mocks"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type FakeRegistry struct {
+	Workflows  []any
+	Activities []any
+}
+
+func (r *FakeRegistry) RegisterWorkflow(w any) { r.Workflows = append(r.Workflows, w) }
+func (r *FakeRegistry) RegisterActivity(a any) { r.Activities = append(r.Activities, a) }
+
+type ActivityInfo struct {
+	ActivityType string
+}
+
+type activityInfoKey struct{}
+
+func GetActivityInfo(ctx context.Context) ActivityInfo {
+	return ctx.Value(activityInfoKey{}).(ActivityInfo)
+}
+
+type GreetingWorkflow func(ctx context.Context, name string) (string, error)
+
+func Test_RegisterActivity(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewStructAPtr),
+		)
+		require.NoError(t, err)
+
+		r := &FakeRegistry{}
+		err = ditemporal.RegisterActivity[*testtypes.StructA](context.Background(), r, c)
+		assert.NoError(t, err)
+
+		assert.Equal(t, []any{&testtypes.StructA{}}, r.Activities)
+	})
+
+	t.Run("not registered", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		r := &FakeRegistry{}
+		err = ditemporal.RegisterActivity[*testtypes.StructA](context.Background(), r, c)
+		testutils.LogError(t, err)
+
+		assert.EqualError(t, err, "ditemporal.RegisterActivity *testtypes.StructA: "+
+			"di.Container.Resolve *testtypes.StructA: service not registered")
+		assert.Empty(t, r.Activities)
+	})
+}
+
+func Test_RegisterWorkflow(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func(a testtypes.InterfaceA) GreetingWorkflow {
+				return func(_ context.Context, name string) (string, error) {
+					return "Hello " + name, nil
+				}
+			}),
+			di.WithService(testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+
+		r := &FakeRegistry{}
+		err = ditemporal.RegisterWorkflow[GreetingWorkflow](context.Background(), r, c)
+		assert.NoError(t, err)
+
+		require.Len(t, r.Workflows, 1)
+		assert.IsType(t, GreetingWorkflow(nil), r.Workflows[0])
+	})
+
+	t.Run("not registered", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		r := &FakeRegistry{}
+		err = ditemporal.RegisterWorkflow[GreetingWorkflow](context.Background(), r, c)
+		testutils.LogError(t, err)
+
+		assert.EqualError(t, err, "ditemporal.RegisterWorkflow ditemporal_test.GreetingWorkflow: "+
+			"di.Container.Resolve ditemporal_test.GreetingWorkflow: service not registered")
+	})
+}
+
+func Test_Activity(t *testing.T) {
+	t.Run("parent nil", func(t *testing.T) {
+		assert.PanicsWithValue(t, "ditemporal.Activity: parent is nil", func() {
+			ditemporal.Activity(nil, func(context.Context, string) (string, error) { return "", nil })
+		})
+	})
+
+	t.Run("WithActivityInfo", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func(info ActivityInfo) *testtypes.StructA {
+				return &testtypes.StructA{Tag: info.ActivityType}
+			}, di.Scoped),
+		)
+		require.NoError(t, err)
+
+		activity := ditemporal.Activity(c, func(ctx context.Context, in string) (string, error) {
+			a, resolveErr := dicontext.Resolve[*testtypes.StructA](ctx)
+			if resolveErr != nil {
+				return "", resolveErr
+			}
+
+			return in + " " + a.Tag.(string), nil
+		}, ditemporal.WithActivityInfo(GetActivityInfo))
+
+		ctx := context.WithValue(context.Background(), activityInfoKey{}, ActivityInfo{ActivityType: "SendEmail"})
+		got, err := activity(ctx, "test")
+
+		assert.Equal(t, "test SendEmail", got)
+		assert.NoError(t, err)
+	})
+
+	t.Run("scope per execution", func(t *testing.T) {
+		closed := 0
+
+		c, err := di.NewContainer(
+			di.WithService(func() testtypes.InterfaceA {
+				a := mocks.NewInterfaceAMock(t)
+				a.EXPECT().
+					Close(mock.Anything).
+					RunAndReturn(func(context.Context) error {
+						closed++
+						return nil
+					})
+
+				return a
+			}, di.Scoped),
+		)
+		require.NoError(t, err)
+
+		var scopes []di.Scope
+		activity := ditemporal.Activity(c, func(ctx context.Context, _ int) (int, error) {
+			_ = dicontext.MustResolve[testtypes.InterfaceA](ctx)
+			scopes = append(scopes, dicontext.Scope(ctx))
+			return closed, nil
+		})
+
+		ctx := context.Background()
+		_, err = activity(ctx, 1)
+		assert.NoError(t, err)
+		_, err = activity(ctx, 2)
+		assert.NoError(t, err)
+
+		require.Len(t, scopes, 2)
+		assert.NotSame(t, scopes[0], scopes[1])
+		assert.Equal(t, 2, closed)
+	})
+
+	t.Run("activity error", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		activity := ditemporal.Activity(c, func(context.Context, int) (int, error) {
+			return 0, errors.New("activity error")
+		})
+
+		_, err = activity(context.Background(), 1)
+		assert.EqualError(t, err, "activity error")
+	})
+
+	t.Run("NewScope error", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		activity := ditemporal.Activity(c, func(context.Context, int) (int, error) {
+			assert.Fail(t, "activity should not get called")
+			return 0, nil
+		}, ditemporal.WithContainerOptions(di.WithService(nil)))
+
+		_, err = activity(context.Background(), 1)
+		testutils.LogError(t, err)
+
+		assert.EqualError(t, err, "ditemporal.Activity: di.Container.NewScope: WithService: funcOrValue is nil")
+	})
+
+	t.Run("Close error", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() testtypes.InterfaceA {
+				a := mocks.NewInterfaceAMock(t)
+				a.EXPECT().
+					Close(mock.Anything).
+					Return(errors.New("close error"))
+
+				return a
+			}, di.Scoped),
+		)
+		require.NoError(t, err)
+
+		activity := ditemporal.Activity(c, func(ctx context.Context, _ int) (int, error) {
+			_ = dicontext.MustResolve[testtypes.InterfaceA](ctx)
+			return 1, nil
+		})
+
+		got, err := activity(context.Background(), 1)
+		testutils.LogError(t, err)
+
+		assert.Equal(t, 1, got)
+		assert.EqualError(t, err, "ditemporal.Activity: di.Container.Close: close error")
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewStructAPtr, di.Scoped,
+				di.UseCloseFunc(func(ctx context.Context, _ *testtypes.StructA) error {
+					return ctx.Err()
+				}),
+			),
+		)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		activity := ditemporal.Activity(c, func(ctx context.Context, _ int) (int, error) {
+			_ = dicontext.MustResolve[*testtypes.StructA](ctx)
+			cancel()
+			return 1, nil
+		})
+
+		_, err = activity(ctx, 1)
+		assert.NoError(t, err)
+	})
+}
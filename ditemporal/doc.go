@@ -0,0 +1,42 @@
+/*
+Package ditemporal provides utilities for running [Temporal] workers with a [di.Container].
+
+Activities and workflows can be resolved from the container and registered with a worker.
+Activity functions can be wrapped with [Activity] to create a new child scope for each activity execution.
+
+The package is compatible with the Temporal Go SDK without depending on it directly.
+
+Example:
+
+	c, err := di.NewContainer(
+		di.WithService(NewEmailClient),
+		di.WithService(NewEmailActivities),                 // NewEmailActivities(*EmailClient) *EmailActivities
+		di.WithService(NewActivityLogger, di.Scoped),       // NewActivityLogger(activity.Info) *ActivityLogger
+	)
+	...
+
+	w := worker.New(client, "email", worker.Options{})
+
+	// Register activities resolved from the container
+	err = ditemporal.RegisterActivity[*EmailActivities](ctx, w, c)
+	...
+
+	// Create a new scope for each execution of an activity function
+	w.RegisterActivityWithOptions(
+		ditemporal.Activity(c, SendReport, ditemporal.WithActivityInfo(activity.GetInfo)),
+		activity.RegisterOptions{Name: "SendReport"},
+	)
+
+	// Resolve scoped services from the activity context
+	func SendReport(ctx context.Context, req ReportRequest) (ReportResult, error) {
+		logger := dicontext.MustResolve[*ActivityLogger](ctx)
+		...
+	}
+
+Child scopes are only created for activity executions. Workflow code must be deterministic and is
+replayed, so child scopes are not created for workflow tasks: [RegisterWorkflow] resolves the workflow
+function once, and it should only depend on deterministic services.
+
+[Temporal]: https://temporal.io
+*/
+package ditemporal
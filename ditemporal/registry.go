@@ -0,0 +1,55 @@
+package ditemporal
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// Registry registers workflows and activities with a worker.
+//
+// This is implemented by the Temporal SDK worker.Worker and worker.Registry types.
+type Registry interface {
+	// RegisterWorkflow registers a workflow function with the worker.
+	RegisterWorkflow(w any)
+	// RegisterActivity registers an activity function, or a struct with activity methods, with the worker.
+	RegisterActivity(a any)
+}
+
+// RegisterActivity resolves a service of type *Activity* from the scope and registers it with the worker.
+//
+// The service may be an activity function, or a struct with activity methods.
+//
+// Available options:
+//   - [di.WithTag] specifies a tag associated with the service.
+func RegisterActivity[Activity any](ctx context.Context, r Registry, s di.Scope, opts ...di.ResolveOption) error {
+	a, err := di.Resolve[Activity](ctx, s, opts...)
+	if err != nil {
+		return errors.Wrapf(err, "ditemporal.RegisterActivity %s", reflect.TypeFor[Activity]())
+	}
+
+	r.RegisterActivity(a)
+	return nil
+}
+
+// RegisterWorkflow resolves a service of type *Workflow* from the scope and registers it with the worker.
+//
+// The service must be a workflow function.
+// It is resolved once, when registering, and the same function is called for every workflow task.
+// Child scopes are not created for workflow tasks, since workflow code must be deterministic and
+// is replayed; a workflow should only depend on deterministic services resolved from s.
+// Use [Activity] to create a new child scope for each activity execution.
+//
+// Available options:
+//   - [di.WithTag] specifies a tag associated with the service.
+func RegisterWorkflow[Workflow any](ctx context.Context, r Registry, s di.Scope, opts ...di.ResolveOption) error {
+	w, err := di.Resolve[Workflow](ctx, s, opts...)
+	if err != nil {
+		return errors.Wrapf(err, "ditemporal.RegisterWorkflow %s", reflect.TypeFor[Workflow]())
+	}
+
+	r.RegisterWorkflow(w)
+	return nil
+}
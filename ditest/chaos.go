@@ -0,0 +1,112 @@
+package ditest
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// ErrChaos is returned from constructor functions that fail because of [WithChaos].
+var ErrChaos = errors.New("ditest: injected constructor failure")
+
+// Chaos configures [WithChaos].
+//
+// The zero value disables chaos, so it can be toggled for each test.
+type Chaos struct {
+	// Seed for the pseudo-random numbers used to make decisions.
+	// Use the same seed to reproduce a failure.
+	Seed uint64
+	// MaxDelay is the maximum time to wait before calling each constructor function.
+	MaxDelay time.Duration
+	// FailureRate is the fraction of constructor function calls that return [ErrChaos]
+	// instead of calling the function, between 0 and 1.
+	FailureRate float64
+	// ShuffleClose closes services in a random order that still closes each service
+	// before the services it depends on. See [di.WithShuffledClose].
+	ShuffleClose bool
+}
+
+// WithChaos makes a [di.Container] less predictable, to find hidden ordering assumptions in an application.
+//
+// Constructor functions are delayed and fail randomly, and services can be closed in a random order.
+// A failure is not cached for a [di.Singleton] or [di.Scoped] service, so resolving it again may succeed.
+// Child scopes inherit chaos from the parent Container.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		app.Dependencies,
+//		ditest.WithChaos(ditest.Chaos{
+//			Seed:         42,
+//			MaxDelay:     10 * time.Millisecond,
+//			FailureRate:  0.1,
+//			ShuffleClose: true,
+//		}),
+//	)
+func WithChaos(chaos Chaos) di.ContainerOption {
+	r := newLockedRand(chaos.Seed)
+
+	var opts di.Module
+	if chaos.MaxDelay > 0 || chaos.FailureRate > 0 {
+		opts = append(opts, di.WithConstructorHook(func(ctx context.Context, _ di.ServiceInfo) error {
+			return chaos.before(ctx, r)
+		}))
+	}
+	if chaos.ShuffleClose {
+		opts = append(opts, di.WithShuffledClose(r))
+	}
+
+	return opts
+}
+
+func (chaos Chaos) before(ctx context.Context, r di.Rand) error {
+	if chaos.MaxDelay > 0 {
+		delay := time.Duration(r.Int64() % int64(chaos.MaxDelay))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+
+	if chaos.FailureRate > 0 && r.Float64() < chaos.FailureRate {
+		return ErrChaos
+	}
+
+	return nil
+}
+
+// lockedRand is a [di.Rand] that is safe for concurrent use.
+type lockedRand struct {
+	r  *rand.Rand
+	mu sync.Mutex
+}
+
+func newLockedRand(seed uint64) *lockedRand {
+	return &lockedRand{r: rand.New(rand.NewPCG(seed, seed))}
+}
+
+func (r *lockedRand) Int64() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.r.Int64()
+}
+
+func (r *lockedRand) IntN(n int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.r.IntN(n)
+}
+
+func (r *lockedRand) Float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.r.Float64()
+}
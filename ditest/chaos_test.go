@@ -0,0 +1,122 @@
+package ditest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/ditest"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithChaos(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("disabled", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.Transient),
+			ditest.WithChaos(ditest.Chaos{}),
+		)
+		require.NoError(t, err)
+
+		for range 10 {
+			_, err := di.Resolve[testtypes.InterfaceA](ctx, c)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.Transient),
+			ditest.WithChaos(ditest.Chaos{Seed: 1, FailureRate: 0.5}),
+		)
+		require.NoError(t, err)
+
+		failures := 0
+		for range 100 {
+			_, err := di.Resolve[testtypes.InterfaceA](ctx, c)
+			if err != nil {
+				assert.ErrorIs(t, err, ditest.ErrChaos)
+				failures++
+			}
+		}
+
+		assert.Positive(t, failures)
+		assert.Less(t, failures, 100)
+	})
+
+	t.Run("Singleton failures not cached", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			ditest.WithChaos(ditest.Chaos{Seed: 1, FailureRate: 0.5}),
+		)
+		require.NoError(t, err)
+
+		var a testtypes.InterfaceA
+		for range 100 {
+			a, err = di.Resolve[testtypes.InterfaceA](ctx, c)
+			if err == nil {
+				break
+			}
+			assert.ErrorIs(t, err, ditest.ErrChaos)
+		}
+		require.NoError(t, err)
+		assert.NotNil(t, a)
+	})
+
+	t.Run("same seed", func(t *testing.T) {
+		results := func() []bool {
+			c, err := di.NewContainer(
+				di.WithService(testtypes.NewInterfaceA, di.Transient),
+				ditest.WithChaos(ditest.Chaos{Seed: 7, FailureRate: 0.5}),
+			)
+			require.NoError(t, err)
+
+			ok := make([]bool, 0, 20)
+			for range 20 {
+				_, err := di.Resolve[testtypes.InterfaceA](ctx, c)
+				ok = append(ok, err == nil)
+			}
+			return ok
+		}
+
+		// Recorded with seed 7, so a change to how failures are chosen is caught
+		want := []bool{
+			false, true, false, true, true, false, false, false, true, false,
+			false, true, true, false, true, true, true, false, false, false,
+		}
+		assert.Equal(t, want, results())
+		assert.Equal(t, want, results())
+	})
+
+	t.Run("delay", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.Transient),
+			ditest.WithChaos(ditest.Chaos{Seed: 1, MaxDelay: time.Hour}),
+		)
+		require.NoError(t, err)
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		_, err = di.Resolve[testtypes.InterfaceA](timeoutCtx, c)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("ShuffleClose", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(testtypes.NewInterfaceB),
+			ditest.WithChaos(ditest.Chaos{Seed: 1, ShuffleClose: true}),
+		)
+		require.NoError(t, err)
+
+		_ = di.MustResolve[testtypes.InterfaceB](ctx, c)
+
+		err = c.Close(ctx)
+		assert.NoError(t, err)
+	})
+}
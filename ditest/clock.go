@@ -0,0 +1,66 @@
+package ditest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sectrean/di-kit"
+)
+
+// FakeClock is a [di.Clock] for tests that only changes time when told to.
+type FakeClock struct {
+	now time.Time
+	mu  sync.Mutex
+}
+
+var _ di.Clock = (*FakeClock)(nil)
+
+// NewFakeClock creates a new [FakeClock] set to the provided time.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the current time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Set the current time of the clock.
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = now
+}
+
+// Advance the current time of the clock by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// WithClock overrides the default [di.Clock] registered with a [di.Container].
+//
+// Example:
+//
+//	clock := ditest.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+//	c, err := di.NewContainer(
+//		app.Dependencies,
+//		ditest.WithClock(clock),
+//	)
+func WithClock(clock di.Clock) di.ContainerOption {
+	return di.WithServiceOverride(clock, di.As[di.Clock]())
+}
+
+// WithRandSeed overrides the default [di.Rand] registered with a [di.Container] with a deterministic
+// source of pseudo-random numbers created from seed.
+//
+// The [di.Rand] is safe for concurrent use, like the default.
+func WithRandSeed(seed uint64) di.ContainerOption {
+	return di.WithServiceOverride(newLockedRand(seed), di.As[di.Rand]())
+}
@@ -0,0 +1,67 @@
+package ditest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/ditest"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := ditest.NewFakeClock(start)
+	assert.Equal(t, start, clock.Now())
+
+	clock.Advance(time.Hour)
+	assert.Equal(t, start.Add(time.Hour), clock.Now())
+
+	clock.Set(start)
+	assert.Equal(t, start, clock.Now())
+}
+
+func TestWithClock(t *testing.T) {
+	clock := ditest.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	c, err := di.NewContainer(
+		ditest.WithClock(clock),
+	)
+	require.NoError(t, err)
+
+	got, err := di.Resolve[di.Clock](context.Background(), c)
+	assert.Same(t, clock, got)
+	assert.NoError(t, err)
+}
+
+func TestWithRandSeed(t *testing.T) {
+	c1, err := di.NewContainer(ditest.WithRandSeed(42))
+	require.NoError(t, err)
+	c2, err := di.NewContainer(ditest.WithRandSeed(42))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	r1 := di.MustResolve[di.Rand](ctx, c1)
+	r2 := di.MustResolve[di.Rand](ctx, c2)
+
+	for range 10 {
+		assert.Equal(t, r1.Int64(), r2.Int64())
+	}
+}
+
+func TestWithRandSeed_Concurrent(t *testing.T) {
+	c, err := di.NewContainer(ditest.WithRandSeed(42))
+	require.NoError(t, err)
+
+	r := di.MustResolve[di.Rand](context.Background(), c)
+
+	// Run with -race to check for data races
+	testutils.RunParallel(10, func(int) {
+		_ = r.Int64()
+		_ = r.IntN(10)
+		_ = r.Float64()
+	})
+}
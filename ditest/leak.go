@@ -0,0 +1,140 @@
+package ditest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// GoroutineLeak describes a service whose constructor function started goroutines that are still running,
+// without registering a closer to stop them.
+type GoroutineLeak struct {
+	// Stacks of the goroutines that are still running.
+	Stacks []string
+	// Service whose constructor function leaked goroutines.
+	Service di.ServiceInfo
+}
+
+// leakSettleTime is how long to wait for goroutines started by a constructor function to exit.
+const leakSettleTime = 100 * time.Millisecond
+
+// FindGoroutineLeaks resolves each service registered with the Container in dependency order,
+// and returns the services whose constructor functions started goroutines that are still running
+// without registering a closer.
+//
+// Services that register a closer with c or one of its parent scopes are not reported.
+//
+// Services are resolved one at a time, so this should not be run in parallel with other tests.
+// Value services are skipped, and [di.Scoped] services are skipped unless c is a child scope.
+func FindGoroutineLeaks(ctx context.Context, c *di.Container) ([]GoroutineLeak, error) {
+	order, err := c.TopologicalOrder()
+	if err != nil {
+		return nil, errors.Wrap(err, "ditest.FindGoroutineLeaks")
+	}
+
+	lifetimes := make(map[string]string)
+	values := make(map[string]bool)
+	services := c.Manifest().Services
+	for i := range services {
+		lifetimes[services[i].ID] = services[i].Lifetime
+		values[services[i].ID] = services[i].Value
+	}
+
+	var leaks []GoroutineLeak
+	for _, info := range order {
+		if values[info.ID] {
+			continue
+		}
+
+		before := goroutines()
+		closersBefore := c.CloserCount()
+
+		_, err := c.Resolve(ctx, info.Type, di.WithTag(info.Tag))
+		if err != nil {
+			if lifetimes[info.ID] == di.Scoped.String() {
+				continue
+			}
+			return nil, errors.Wrapf(err, "ditest.FindGoroutineLeaks %s", info)
+		}
+
+		if c.CloserCount() > closersBefore {
+			continue
+		}
+		if stacks := leakedGoroutines(before); len(stacks) > 0 {
+			leaks = append(leaks, GoroutineLeak{Service: info, Stacks: stacks})
+		}
+	}
+
+	return leaks, nil
+}
+
+// AssertNoGoroutineLeaks asserts that no service registered with the Container leaks goroutines.
+//
+// See [FindGoroutineLeaks] for more information.
+func AssertNoGoroutineLeaks(t TestingT, ctx context.Context, c *di.Container) bool {
+	t.Helper()
+
+	leaks, err := FindGoroutineLeaks(ctx, c)
+	if err != nil {
+		t.Errorf("ditest.AssertNoGoroutineLeaks: %v", err)
+		return false
+	}
+
+	for i := range leaks {
+		leak := &leaks[i]
+		t.Errorf("ditest.AssertNoGoroutineLeaks: service %s started %d goroutines without a closer:\n%s",
+			leak.Service, len(leak.Stacks), strings.Join(leak.Stacks, "\n\n"))
+	}
+
+	return len(leaks) == 0
+}
+
+// leakedGoroutines returns the stacks of goroutines that were not running before,
+// after waiting for goroutines that are exiting.
+func leakedGoroutines(before map[int]string) []string {
+	deadline := time.Now().Add(leakSettleTime)
+	for {
+		var stacks []string
+		for id, stack := range goroutines() {
+			if _, ok := before[id]; !ok {
+				stacks = append(stacks, stack)
+			}
+		}
+
+		if len(stacks) == 0 || time.Now().After(deadline) {
+			return stacks
+		}
+		time.Sleep(leakSettleTime / 10)
+	}
+}
+
+// goroutines returns the stacks of all running goroutines by ID.
+func goroutines() map[int]string {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, len(buf)*2)
+	}
+
+	stacks := make(map[int]string)
+	for stack := range bytes.SplitSeq(buf, []byte("\n\n")) {
+		// Each stack starts with a header like "goroutine 1 [running]:"
+		var id int
+		if _, err := fmt.Sscanf(string(stack), "goroutine %d ", &id); err != nil {
+			continue
+		}
+		stacks[id] = string(stack)
+	}
+
+	return stacks
+}
@@ -0,0 +1,129 @@
+package ditest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/ditest"
+	"github.com/sectrean/di-kit/internal/mocks"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type worker struct {
+	stop chan struct{}
+}
+
+func newWorker() *worker {
+	w := &worker{stop: make(chan struct{})}
+	go func() { <-w.stop }()
+	return w
+}
+
+func (w *worker) Close() { close(w.stop) }
+
+type leakyWorker struct {
+	*worker
+}
+
+func newLeakyWorker() leakyWorker {
+	return leakyWorker{newWorker()}
+}
+
+func TestFindGoroutineLeaks(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("leak", func(t *testing.T) {
+		var w leakyWorker
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(func() leakyWorker {
+				w = newLeakyWorker()
+				return w
+			}, di.IgnoreCloser()),
+			di.WithService(newWorker),
+			di.WithService(&testtypes.StructB{}),
+			di.WithService(testtypes.NewInterfaceC, di.Scoped),
+		)
+		require.NoError(t, err)
+
+		leaks, err := ditest.FindGoroutineLeaks(ctx, c)
+		require.NoError(t, err)
+		require.Len(t, leaks, 1)
+		assert.Equal(t, "ditest_test.leakyWorker", leaks[0].Service.String())
+		assert.Len(t, leaks[0].Stacks, 1)
+
+		w.Close()
+		require.NoError(t, c.Close(ctx))
+	})
+
+	t.Run("child scope", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(newWorker),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope()
+		require.NoError(t, err)
+
+		// The worker is closed by the parent Container
+		leaks, err := ditest.FindGoroutineLeaks(ctx, scope)
+		require.NoError(t, err)
+		assert.Empty(t, leaks)
+
+		require.NoError(t, scope.Close(ctx))
+		require.NoError(t, c.Close(ctx))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceB),
+		)
+		require.NoError(t, err)
+
+		_, err = ditest.FindGoroutineLeaks(ctx, c)
+		assert.EqualError(t, err, "ditest.FindGoroutineLeaks testtypes.InterfaceB: "+
+			"di.Container.Resolve testtypes.InterfaceB: dependency testtypes.InterfaceA: service not registered")
+	})
+}
+
+func TestAssertNoGoroutineLeaks(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("no leaks", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(newWorker),
+		)
+		require.NoError(t, err)
+
+		mockT := mocks.NewTestingTMock(t)
+		mockT.EXPECT().Helper().Once()
+
+		assert.True(t, ditest.AssertNoGoroutineLeaks(mockT, ctx, c))
+		require.NoError(t, c.Close(ctx))
+	})
+
+	t.Run("leak", func(t *testing.T) {
+		var w leakyWorker
+		c, err := di.NewContainer(
+			di.WithService(func() leakyWorker {
+				w = newLeakyWorker()
+				return w
+			}, di.IgnoreCloser()),
+		)
+		require.NoError(t, err)
+
+		mockT := mocks.NewTestingTMock(t)
+		mockT.EXPECT().Helper().Once()
+		mockT.EXPECT().Errorf(
+			"ditest.AssertNoGoroutineLeaks: service %s started %d goroutines without a closer:\n%s",
+			mock.Anything, 1, mock.Anything,
+		).Once()
+
+		assert.False(t, ditest.AssertNoGoroutineLeaks(mockT, ctx, c))
+		w.Close()
+	})
+}
@@ -0,0 +1,68 @@
+package ditest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+)
+
+// Mocks collects mocks created for a test so they can be registered with a [di.Container].
+//
+// Example:
+//
+//	m := ditest.NewMocks(t)
+//	store := ditest.Mock[storage.Store](m, mocks.NewStoreMock)
+//	store.EXPECT().Get(mock.Anything, "id").Return(&User{}, nil)
+//
+//	c, err := di.NewContainer(
+//		app.Dependencies,
+//		m.Module(), // Register mocks last so they replace the real services
+//	)
+type Mocks struct {
+	t    testing.TB
+	opts di.Module
+}
+
+// NewMocks creates a new [Mocks] for the test.
+func NewMocks(t testing.TB) *Mocks {
+	return &Mocks{t: t}
+}
+
+// Module returns a [di.Module] that registers all mocks created with [Mock].
+//
+// Each mock is registered as a value service using [di.As] with the mocked *Service* type and [di.Replace],
+// so it replaces the services registered earlier for that type. The module should be added after
+// any services being replaced by mocks.
+func (m *Mocks) Module() di.Module {
+	return m.opts
+}
+
+// Mock creates a new mock for type *Service* using the provided factory function and adds it to [Mocks].
+// The mock is returned so expectations can be set up.
+//
+// The factory function is called with the test passed to [NewMocks].
+// It's compatible with mockery-generated constructors:
+//
+//	a := ditest.Mock[InterfaceA](m, mocks.NewInterfaceAMock)
+//
+// This will stop the test if the test is not compatible with the factory function,
+// or the mock does not implement *Service*.
+func Mock[Service, TB, M any](m *Mocks, newMock func(TB) M) M {
+	m.t.Helper()
+
+	t, ok := any(m.t).(TB)
+	if !ok {
+		m.t.Fatalf("ditest.Mock %s: %T is not assignable to %s",
+			reflect.TypeFor[Service](), m.t, reflect.TypeFor[TB]())
+	}
+
+	mock := newMock(t)
+	if _, ok := any(mock).(Service); !ok {
+		m.t.Fatalf("ditest.Mock %s: %T does not implement %s",
+			reflect.TypeFor[Service](), mock, reflect.TypeFor[Service]())
+	}
+
+	m.opts = append(m.opts, di.WithServiceOverride(mock, di.As[Service]()))
+	return mock
+}
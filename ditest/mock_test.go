@@ -0,0 +1,98 @@
+package ditest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/ditest"
+	"github.com/sectrean/di-kit/internal/mocks"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMock(t *testing.T) {
+	t.Run("override service", func(t *testing.T) {
+		m := ditest.NewMocks(t)
+		a := ditest.Mock[testtypes.InterfaceA](m, mocks.NewInterfaceAMock)
+		a.EXPECT().A().Once()
+
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(testtypes.NewInterfaceB),
+			m.Module(),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		got, err := di.Resolve[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+
+		assert.Same(t, a, got)
+		got.A()
+	})
+
+	t.Run("override service with policies", func(t *testing.T) {
+		policies := []di.ContainerOption{
+			di.WithStrictResolve(),
+			di.WithDuplicatePolicy(di.DuplicateFirstWins),
+			di.WithDuplicatePolicy(di.DuplicateError),
+		}
+
+		for _, policy := range policies {
+			m := ditest.NewMocks(t)
+			a := ditest.Mock[testtypes.InterfaceA](m, mocks.NewInterfaceAMock)
+
+			c, err := di.NewContainer(
+				policy,
+				di.WithService(testtypes.NewInterfaceA),
+				m.Module(),
+			)
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			got, err := di.Resolve[testtypes.InterfaceA](ctx, c)
+			require.NoError(t, err)
+			assert.Same(t, a, got)
+
+			all, err := di.Resolve[[]testtypes.InterfaceA](ctx, c)
+			require.NoError(t, err)
+			assert.Equal(t, []testtypes.InterfaceA{a}, all)
+		}
+	})
+
+	t.Run("multiple mocks", func(t *testing.T) {
+		m := ditest.NewMocks(t)
+		a := ditest.Mock[testtypes.InterfaceA](m, mocks.NewInterfaceAMock)
+		b := ditest.Mock[testtypes.InterfaceB](m, mocks.NewInterfaceBMock)
+
+		c, err := di.NewContainer(m.Module())
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		assert.Same(t, a, di.MustResolve[testtypes.InterfaceA](ctx, c))
+		assert.Same(t, b, di.MustResolve[testtypes.InterfaceB](ctx, c))
+	})
+
+	t.Run("mock not closed", func(t *testing.T) {
+		m := ditest.NewMocks(t)
+		a := ditest.Mock[testtypes.InterfaceA](m, mocks.NewInterfaceAMock)
+
+		c, err := di.NewContainer(m.Module())
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		_ = di.MustResolve[testtypes.InterfaceA](ctx, c)
+
+		// The mock would fail if Close is called unexpectedly
+		err = c.Close(ctx)
+		assert.NoError(t, err)
+		a.AssertNotCalled(t, "Close")
+	})
+
+	t.Run("no mocks", func(t *testing.T) {
+		m := ditest.NewMocks(t)
+		assert.Empty(t, m.Module())
+	})
+}
@@ -0,0 +1,190 @@
+package ditest
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/sectrean/di-kit"
+)
+
+// Call is a method call recorded by a [Spy].
+type Call struct {
+	// Method is the name of the method called.
+	Method string
+	// Args are the arguments passed to the method.
+	Args []any
+	// Results are the values returned from the method.
+	Results []any
+	// Duration is how long the method call took.
+	Duration time.Duration
+}
+
+// Spy records calls made to a service of type *Service*.
+//
+// Use [WithSpy] to register a service wrapped with a Spy, so tests can assert on the calls made
+// to the service without replacing it with a mock.
+//
+// Function services are wrapped automatically. Interface services are wrapped using the function
+// passed to [NewSpy], which should return a proxy that calls [Spy.Record] for each method.
+//
+// Example:
+//
+//	type storeSpy struct {
+//		storage.Store
+//		spy *ditest.Spy[storage.Store]
+//	}
+//
+//	func (s storeSpy) Get(ctx context.Context, id string) (*User, error) {
+//		done := s.spy.Record("Get", ctx, id)
+//		u, err := s.Store.Get(ctx, id)
+//		done(u, err)
+//		return u, err
+//	}
+//
+//	spy := ditest.NewSpy(func(s storage.Store, spy *ditest.Spy[storage.Store]) storage.Store {
+//		return storeSpy{Store: s, spy: spy}
+//	})
+type Spy[Service any] struct {
+	wrap  func(Service, *Spy[Service]) Service
+	calls []Call
+	mu    sync.Mutex
+}
+
+// NewSpy creates a new [Spy] for type *Service*.
+//
+// The wrap function returns a proxy for the service that records calls using [Spy.Record].
+// It may be nil if *Service* is a function type.
+func NewSpy[Service any](wrap func(s Service, spy *Spy[Service]) Service) *Spy[Service] {
+	return &Spy[Service]{wrap: wrap}
+}
+
+// Record records a call to the named method with the provided arguments.
+// Call the returned function with the method results when the call returns.
+func (s *Spy[Service]) Record(method string, args ...any) (done func(results ...any)) {
+	start := time.Now()
+
+	return func(results ...any) {
+		call := Call{
+			Method:   method,
+			Args:     args,
+			Results:  results,
+			Duration: time.Since(start),
+		}
+
+		s.mu.Lock()
+		s.calls = append(s.calls, call)
+		s.mu.Unlock()
+	}
+}
+
+// Calls returns all calls recorded by the Spy in the order they returned.
+func (s *Spy[Service]) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.calls)
+}
+
+// CallsTo returns the calls recorded by the Spy to the named method.
+func (s *Spy[Service]) CallsTo(method string) []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var calls []Call
+	for _, c := range s.calls {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+
+	return calls
+}
+
+// Reset clears the calls recorded by the Spy.
+func (s *Spy[Service]) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls = nil
+}
+
+func (s *Spy[Service]) decorate(svc Service) Service {
+	if s.wrap != nil {
+		return s.wrap(svc, s)
+	}
+
+	// Function services can be wrapped using reflection
+	v := reflect.ValueOf(svc)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return svc
+	}
+
+	t := v.Type()
+	method := t.Name()
+
+	wrapped := reflect.MakeFunc(t, func(in []reflect.Value) []reflect.Value {
+		args := make([]any, len(in))
+		for i, arg := range in {
+			args[i] = arg.Interface()
+		}
+
+		done := s.Record(method, args...)
+
+		var out []reflect.Value
+		if t.IsVariadic() {
+			out = v.CallSlice(in)
+		} else {
+			out = v.Call(in)
+		}
+
+		results := make([]any, len(out))
+		for i, res := range out {
+			results[i] = res.Interface()
+		}
+		done(results...)
+
+		return out
+	})
+
+	return wrapped.Interface().(Service)
+}
+
+type spyTag struct {
+	spy any
+}
+
+// WithSpy registers the provided function or value like [di.WithService], and decorates the service
+// with the [Spy] when it is resolved as type *Service*.
+//
+// The service should be registered as type *Service*, using [di.As] if needed.
+// The decorated service is not closed by the container, since the original service is closed instead.
+//
+// Example:
+//
+//	spy := ditest.NewSpy(newStoreSpy)
+//
+//	c, err := di.NewContainer(
+//		ditest.WithSpy(spy, storage.NewDBStore, di.As[storage.Store]()),
+//		di.WithService(service.NewService), // NewService(storage.Store) *service.Service
+//	)
+//	...
+//
+//	assert.Len(t, spy.CallsTo("Get"), 1)
+func WithSpy[Service any](spy *Spy[Service], funcOrValue any, opts ...di.ServiceOption) di.ContainerOption {
+	tag := spyTag{spy: spy}
+
+	serviceOpts := make([]di.ServiceOption, len(opts)+1)
+	copy(serviceOpts, opts)
+	serviceOpts[len(opts)] = di.WithTag(tag)
+
+	return di.Module{
+		di.WithService(funcOrValue, serviceOpts...),
+		di.WithService(spy.decorate,
+			di.WithTagged[Service](tag),
+			di.Transient,
+			di.IgnoreCloser(),
+		),
+	}
+}
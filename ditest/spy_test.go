@@ -0,0 +1,130 @@
+package ditest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/ditest"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Greeter interface {
+	Greet(name string) string
+}
+
+type englishGreeter struct{}
+
+func (englishGreeter) Greet(name string) string { return "Hello " + name }
+
+type greeterSpy struct {
+	Greeter
+	spy *ditest.Spy[Greeter]
+}
+
+func (s greeterSpy) Greet(name string) string {
+	done := s.spy.Record("Greet", name)
+	res := s.Greeter.Greet(name)
+	done(res)
+	return res
+}
+
+func newGreeterSpy(g Greeter, spy *ditest.Spy[Greeter]) Greeter {
+	return greeterSpy{Greeter: g, spy: spy}
+}
+
+type GreetFunc func(name string) string
+
+func TestSpy(t *testing.T) {
+	t.Run("interface service", func(t *testing.T) {
+		spy := ditest.NewSpy(newGreeterSpy)
+
+		c, err := di.NewContainer(
+			ditest.WithSpy(spy, func() Greeter { return englishGreeter{} }),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		g := di.MustResolve[Greeter](ctx, c)
+
+		assert.Equal(t, "Hello A", g.Greet("A"))
+		assert.Equal(t, "Hello B", g.Greet("B"))
+
+		calls := spy.CallsTo("Greet")
+		require.Len(t, calls, 2)
+		assert.Equal(t, []any{"A"}, calls[0].Args)
+		assert.Equal(t, []any{"Hello A"}, calls[0].Results)
+		assert.Equal(t, []any{"B"}, calls[1].Args)
+		assert.Len(t, spy.Calls(), 2)
+		assert.Empty(t, spy.CallsTo("Other"))
+
+		spy.Reset()
+		assert.Empty(t, spy.Calls())
+	})
+
+	t.Run("dependency", func(t *testing.T) {
+		spy := ditest.NewSpy(newGreeterSpy)
+
+		c, err := di.NewContainer(
+			ditest.WithSpy(spy, englishGreeter{}, di.As[Greeter]()),
+			di.WithService(func(g Greeter) *testtypes.StructA {
+				return &testtypes.StructA{Tag: g.Greet("dependency")}
+			}),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		a := di.MustResolve[*testtypes.StructA](ctx, c)
+
+		assert.Equal(t, "Hello dependency", a.Tag)
+		assert.Len(t, spy.CallsTo("Greet"), 1)
+	})
+
+	t.Run("func service", func(t *testing.T) {
+		spy := ditest.NewSpy[GreetFunc](nil)
+
+		c, err := di.NewContainer(
+			ditest.WithSpy(spy, func() GreetFunc {
+				return func(name string) string { return "Hi " + name }
+			}),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		greet := di.MustResolve[GreetFunc](ctx, c)
+
+		assert.Equal(t, "Hi A", greet("A"))
+
+		calls := spy.CallsTo("GreetFunc")
+		require.Len(t, calls, 1)
+		assert.Equal(t, []any{"A"}, calls[0].Args)
+		assert.Equal(t, []any{"Hi A"}, calls[0].Results)
+	})
+
+	t.Run("original service closed once", func(t *testing.T) {
+		closed := 0
+		spy := ditest.NewSpy(func(a testtypes.InterfaceA, _ *ditest.Spy[testtypes.InterfaceA]) testtypes.InterfaceA {
+			return a
+		})
+
+		c, err := di.NewContainer(
+			ditest.WithSpy(spy, testtypes.NewInterfaceA,
+				di.UseCloseFunc(func(context.Context, testtypes.InterfaceA) error {
+					closed++
+					return nil
+				}),
+			),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		_ = di.MustResolve[testtypes.InterfaceA](ctx, c)
+		_ = di.MustResolve[testtypes.InterfaceA](ctx, c)
+
+		err = c.Close(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, closed)
+	})
+}
@@ -0,0 +1,33 @@
+/*
+Package ditestinfra provides a pattern for sharing heavyweight test resources, like databases running
+in Docker containers, across the tests in a package.
+
+Resources are registered with a [Suite] using [WithResource]. A resource is created and started the
+first time it is resolved, and it is shared by all tests in the package.
+The resources are closed after all tests have run when using [Suite.Main] from TestMain.
+
+Example:
+
+	var suite = ditestinfra.NewSuite(
+		ditestinfra.WithResource(NewPostgresContainer), // NewPostgresContainer() *PostgresContainer
+		di.WithService(NewUserStore),                   // NewUserStore(*PostgresContainer) *UserStore
+	)
+
+	func TestMain(m *testing.M) {
+		os.Exit(suite.Main(m))
+	}
+
+	func TestUserStore(t *testing.T) {
+		store := ditestinfra.MustResolve[*UserStore](t, suite)
+		...
+	}
+
+	type PostgresContainer struct { ... }
+
+	// Start is called when the resource is first resolved.
+	func (p *PostgresContainer) Start(ctx context.Context) error { ... }
+
+	// Close is called after all tests in the package have run.
+	func (p *PostgresContainer) Close(ctx context.Context) error { ... }
+*/
+package ditestinfra
@@ -0,0 +1,51 @@
+package ditestinfra
+
+import (
+	"context"
+	"reflect"
+	"slices"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// Starter is implemented by resources that need to be started after they are created.
+//
+// Resources are closed when the [Suite] is closed if they implement [di.Closer],
+// or a compatible Close method signature.
+type Starter interface {
+	// Start the resource.
+	Start(ctx context.Context) error
+}
+
+// WithResource registers a resource constructor function with a [Suite] or [di.Container].
+//
+// This works like [di.WithService] with a constructor function, except that Start is called
+// on the resource after it is created if it implements [Starter]. See [di.UseStartFunc].
+// If Start returns an error, the resource is closed, and the error is returned when the resource is resolved.
+//
+// Resources are singletons by default, so they are created and started once, and shared by all tests
+// using the same [Suite].
+//
+// This option will return an error if fn is not a function.
+func WithResource(fn any, opts ...di.ServiceOption) di.ContainerOption {
+	if reflect.ValueOf(fn).Kind() != reflect.Func {
+		return di.WithError(errors.Errorf("ditestinfra.WithResource %T: fn must be a function", fn))
+	}
+
+	return di.WithService(fn, append(slices.Clip(opts), di.UseStartFunc(startResource))...)
+}
+
+// startResource starts the resource if it implements [Starter].
+func startResource(ctx context.Context, resource any) error {
+	s, ok := resource.(Starter)
+	if !ok {
+		return nil
+	}
+
+	if err := s.Start(ctx); err != nil {
+		return errors.Wrapf(err, "ditestinfra: start %T", resource)
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package ditestinfra
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// TestingT is an interface that defines the methods required for testing in Go.
+type TestingT interface {
+	// Helper marks the calling function as a test helper function.
+	Helper()
+	// Fatalf formats its arguments according to the format, records the error, and stops the test.
+	Fatalf(format string, args ...any)
+}
+
+var _ TestingT = (*testing.T)(nil)
+
+// Suite holds a [di.Container] that is shared by all tests in a package.
+//
+// The container is created the first time it is used, and closed by [Suite.Main] or [Suite.Close].
+type Suite struct {
+	c    *di.Container
+	err  error
+	opts []di.ContainerOption
+	once sync.Once
+}
+
+// NewSuite creates a new [Suite] with the provided options.
+//
+// The options are used to create the container the first time it is used.
+// Use [WithResource] to register resources that need to be started.
+func NewSuite(opts ...di.ContainerOption) *Suite {
+	return &Suite{opts: opts}
+}
+
+// Container returns the shared [di.Container], creating it if needed.
+//
+// This will stop the test if there is an error creating the container.
+func (s *Suite) Container(t TestingT) *di.Container {
+	t.Helper()
+
+	c, err := s.container()
+	if err != nil {
+		t.Fatalf("ditestinfra.Suite.Container: %v", err)
+	}
+
+	return c
+}
+
+func (s *Suite) container() (*di.Container, error) {
+	s.once.Do(func() {
+		s.c, s.err = di.NewContainer(s.opts...)
+	})
+
+	return s.c, s.err
+}
+
+// Main runs the tests and closes the shared container after all tests have run.
+// It returns an exit code to pass to [os.Exit].
+//
+// Example:
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(suite.Main(m))
+//	}
+func (s *Suite) Main(m *testing.M) int {
+	code := m.Run()
+
+	err := s.Close(context.Background())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		if code == 0 {
+			code = 1
+		}
+	}
+
+	return code
+}
+
+// Close closes the shared container and all resources it created.
+//
+// It does nothing if the container was never used.
+func (s *Suite) Close(ctx context.Context) error {
+	// Make sure the container can't be created after it's closed
+	s.once.Do(func() {
+		s.err = errors.New("suite closed")
+	})
+
+	if s.c == nil {
+		return nil
+	}
+
+	return errors.Wrap(s.c.Close(ctx), "ditestinfra.Suite.Close")
+}
+
+// Resolve a service of type *Service* from the shared container.
+//
+// Resources are resolved with a background context since they are shared by all tests
+// and outlive any single test.
+//
+// See [di.Container.Resolve] for more information.
+func Resolve[Service any](t TestingT, s *Suite, opts ...di.ResolveOption) (Service, error) {
+	t.Helper()
+
+	c, err := s.container()
+	if err != nil {
+		var zero Service
+		return zero, errors.Wrapf(err, "ditestinfra.Resolve %s", reflect.TypeFor[Service]())
+	}
+
+	return di.Resolve[Service](context.Background(), c, opts...)
+}
+
+// MustResolve resolves a service of type *Service* from the shared container.
+//
+// This will stop the test if the service cannot be resolved.
+func MustResolve[Service any](t TestingT, s *Suite, opts ...di.ResolveOption) Service {
+	t.Helper()
+
+	val, err := Resolve[Service](t, s, opts...)
+	if err != nil {
+		t.Fatalf("ditestinfra.MustResolve: %v", err)
+	}
+
+	return val
+}
@@ -0,0 +1,189 @@
+package ditestinfra_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/ditestinfra"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type FakeDatabase struct {
+	StartErr error
+	Starts   int
+	Closes   int
+}
+
+func (d *FakeDatabase) Start(context.Context) error {
+	d.Starts++
+	return d.StartErr
+}
+
+func (d *FakeDatabase) Close(context.Context) error {
+	d.Closes++
+	return nil
+}
+
+func NewFakeDatabase(testtypes.InterfaceA) *FakeDatabase {
+	return &FakeDatabase{}
+}
+
+func Test_WithResource(t *testing.T) {
+	t.Run("started once", func(t *testing.T) {
+		db := &FakeDatabase{}
+		suite := ditestinfra.NewSuite(
+			ditestinfra.WithResource(func() *FakeDatabase { return db }),
+		)
+
+		got1 := ditestinfra.MustResolve[*FakeDatabase](t, suite)
+		got2 := ditestinfra.MustResolve[*FakeDatabase](t, suite)
+
+		assert.Same(t, db, got1)
+		assert.Same(t, db, got2)
+		assert.Equal(t, 1, db.Starts)
+
+		err := suite.Close(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, db.Closes)
+	})
+
+	t.Run("dependencies", func(t *testing.T) {
+		suite := ditestinfra.NewSuite(
+			di.WithService(testtypes.NewInterfaceA),
+			ditestinfra.WithResource(func(a testtypes.InterfaceA) (*FakeDatabase, error) {
+				assert.NotNil(t, a)
+				return &FakeDatabase{}, nil
+			}),
+		)
+
+		got := ditestinfra.MustResolve[*FakeDatabase](t, suite)
+		assert.Equal(t, 1, got.Starts)
+	})
+
+	t.Run("start error", func(t *testing.T) {
+		db := &FakeDatabase{StartErr: errors.New("start error")}
+		suite := ditestinfra.NewSuite(
+			ditestinfra.WithResource(func() *FakeDatabase { return db }),
+		)
+
+		got, err := ditestinfra.Resolve[*FakeDatabase](t, suite)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, got)
+		assert.EqualError(t, err, "di.Container.Resolve *ditestinfra_test.FakeDatabase: "+
+			"ditestinfra: start *ditestinfra_test.FakeDatabase: start error")
+
+		// The half-started resource is closed
+		assert.Equal(t, 1, db.Closes)
+	})
+
+	t.Run("cleanup func", func(t *testing.T) {
+		db := &FakeDatabase{}
+		cleanups := 0
+		suite := ditestinfra.NewSuite(
+			ditestinfra.WithResource(func() (*FakeDatabase, func(), error) {
+				return db, func() { cleanups++ }, nil
+			}),
+		)
+
+		got := ditestinfra.MustResolve[*FakeDatabase](t, suite)
+		assert.Same(t, db, got)
+		assert.Equal(t, 1, got.Starts)
+
+		err := suite.Close(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, cleanups)
+	})
+
+	t.Run("constructor identity", func(t *testing.T) {
+		suite := ditestinfra.NewSuite(
+			di.WithService(testtypes.NewInterfaceA),
+			ditestinfra.WithResource(NewFakeDatabase),
+		)
+
+		c := suite.Container(t)
+		svcs := c.Manifest().Services
+		require.NotEmpty(t, svcs)
+
+		db := svcs[len(svcs)-1]
+		assert.Contains(t, db.Constructor, "NewFakeDatabase")
+		assert.Equal(t, []string{"testtypes.InterfaceA"}, db.Dependencies)
+	})
+
+	t.Run("invalid parameter", func(t *testing.T) {
+		c, err := di.NewContainer(
+			ditestinfra.WithResource(func(testtypes.InterfaceA, int) *FakeDatabase { return nil }),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.ErrorContains(t, err, "parameter 1: invalid dependency type int")
+	})
+
+	t.Run("not starter", func(t *testing.T) {
+		suite := ditestinfra.NewSuite(
+			ditestinfra.WithResource(testtypes.NewInterfaceA),
+		)
+
+		got := ditestinfra.MustResolve[testtypes.InterfaceA](t, suite)
+		assert.NotNil(t, got)
+	})
+
+	t.Run("invalid func signature", func(t *testing.T) {
+		suite := ditestinfra.NewSuite(
+			ditestinfra.WithResource(func() {}),
+		)
+
+		_, err := ditestinfra.Resolve[*FakeDatabase](t, suite)
+		testutils.LogError(t, err)
+
+		assert.EqualError(t, err, "ditestinfra.Resolve *ditestinfra_test.FakeDatabase: "+
+			"di.NewContainer: WithService func(): function must return Service, (Service, error), or (Service, func(), error); function has no return values")
+	})
+
+	t.Run("not a func", func(t *testing.T) {
+		c, err := di.NewContainer(
+			ditestinfra.WithResource(&FakeDatabase{}),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: ditestinfra.WithResource *ditestinfra_test.FakeDatabase: fn must be a function")
+	})
+}
+
+func Test_Suite(t *testing.T) {
+	t.Run("Container shared", func(t *testing.T) {
+		suite := ditestinfra.NewSuite()
+
+		c1 := suite.Container(t)
+		c2 := suite.Container(t)
+		assert.Same(t, c1, c2)
+	})
+
+	t.Run("Close not used", func(t *testing.T) {
+		suite := ditestinfra.NewSuite()
+
+		err := suite.Close(context.Background())
+		assert.NoError(t, err)
+
+		_, err = ditestinfra.Resolve[*FakeDatabase](t, suite)
+		assert.EqualError(t, err, "ditestinfra.Resolve *ditestinfra_test.FakeDatabase: suite closed")
+	})
+
+	t.Run("Close twice", func(t *testing.T) {
+		suite := ditestinfra.NewSuite()
+		_ = suite.Container(t)
+
+		err := suite.Close(context.Background())
+		require.NoError(t, err)
+
+		err = suite.Close(context.Background())
+		assert.EqualError(t, err, "ditestinfra.Suite.Close: di.Container.Close: closed already: container closed")
+	})
+}
@@ -0,0 +1,120 @@
+package di
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// DuplicatePolicy specifies what happens when multiple services are registered for the same type and tag.
+//
+// Use with [WithDuplicatePolicy].
+//
+// Available policies:
+//   - [DuplicateLastWins] resolves the last service registered.
+//   - [DuplicateFirstWins] resolves the first service registered.
+//   - [DuplicateError] returns an error when the Container is created.
+type DuplicatePolicy uint8
+
+const (
+	// DuplicateLastWins resolves the last service registered when resolving a single service.
+	//
+	// This is the default policy.
+	DuplicateLastWins DuplicatePolicy = iota
+
+	// DuplicateFirstWins resolves the first service registered when resolving a single service.
+	DuplicateFirstWins DuplicatePolicy = iota
+
+	// DuplicateError returns an error from [NewContainer] or [Container.NewScope]
+	// if multiple services are registered for the same type and tag.
+	DuplicateError DuplicatePolicy = iota
+)
+
+func (p DuplicatePolicy) String() string {
+	switch p {
+	case DuplicateLastWins:
+		return "DuplicateLastWins"
+	case DuplicateFirstWins:
+		return "DuplicateFirstWins"
+	case DuplicateError:
+		return "DuplicateError"
+	default:
+		return fmt.Sprintf("Unknown DuplicatePolicy %d", p)
+	}
+}
+
+// WithDuplicatePolicy sets what happens when multiple services are registered for the same type and tag,
+// when calling [NewContainer] or [Container.NewScope].
+//
+// By default, resolving a single service resolves the last service registered, so registering a service twice
+// by accident goes unnoticed. Use [DuplicateFirstWins] to resolve the first service registered instead,
+// or [DuplicateError] to catch double registrations when the Container is created.
+// Resolving a slice of services still returns all of them with any policy.
+//
+// With [DuplicateError], a type and tag can still have multiple services if one of them is registered
+// with [Chain] or [WithPrimary], since that is on purpose. Decorators registered at [OrderDecorator],
+// services that replace others with [Replace], and overrides of the built-in [Clock] and [Rand] services
+// are not duplicates either. Register services meant to be resolved as a slice with different tags,
+// or use one of these options.
+//
+// Services registered with a child scope for the same type and tag as a parent Container are not duplicates.
+// Child scopes inherit the policy of the parent Container.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithDuplicatePolicy(di.DuplicateError),
+//		app.Dependencies,
+//	)
+//
+// This option will return an error if the policy is unknown.
+func WithDuplicatePolicy(p DuplicatePolicy) ContainerOption {
+	return containerOption(func(c *Container) error {
+		if p > DuplicateError {
+			return errors.Errorf("WithDuplicatePolicy: %s", p)
+		}
+
+		c.duplicatePolicy = p
+		return nil
+	})
+}
+
+// checkDuplicates returns an error for the first type and tag registered more than once
+// with the [DuplicateError] policy.
+func (c *Container) checkDuplicates() error {
+	if c.duplicatePolicy != DuplicateError {
+		return nil
+	}
+
+	checked := make(map[serviceKey]bool)
+	for _, svc := range c.registered {
+		for _, key := range svc.Keys() {
+			if checked[key] {
+				continue
+			}
+			checked[key] = true
+
+			svcs := c.services[key]
+			if slices.ContainsFunc(svcs, (*service).IsChain) || slices.ContainsFunc(svcs, (*service).IsPrimary) {
+				continue
+			}
+
+			var names []string
+			for i, s := range svcs {
+				if s.builtin || s.decorator {
+					continue
+				}
+				names = append(names, fmt.Sprintf("#%d %s", i, s.Name()))
+			}
+
+			if len(names) > 1 {
+				return errors.Errorf("WithDuplicatePolicy DuplicateError: %s registered %d times: %s",
+					key, len(names), strings.Join(names, ", "))
+			}
+		}
+	}
+
+	return nil
+}
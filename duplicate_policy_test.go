@@ -0,0 +1,157 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithDuplicatePolicy(t *testing.T) {
+	ctx := context.Background()
+
+	newA := func(tag string) *testtypes.StructA {
+		return &testtypes.StructA{Tag: tag}
+	}
+
+	t.Run("last wins", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithDuplicatePolicy(di.DuplicateLastWins),
+			di.WithService(newA("first")),
+			di.WithService(newA("last")),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[*testtypes.StructA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, newA("last"), got)
+	})
+
+	t.Run("first wins", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithDuplicatePolicy(di.DuplicateFirstWins),
+			di.WithService(newA("first")),
+			di.WithService(newA("last")),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[*testtypes.StructA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, newA("first"), got)
+
+		all, err := di.ResolveAll[*testtypes.StructA](ctx, c)
+		require.NoError(t, err)
+		assert.Len(t, all, 2)
+	})
+
+	t.Run("first wins scope", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithDuplicatePolicy(di.DuplicateFirstWins),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope(
+			di.WithService(newA("first")),
+			di.WithService(newA("last")),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[*testtypes.StructA](ctx, scope)
+		require.NoError(t, err)
+		assert.Equal(t, newA("first"), got)
+	})
+
+	t.Run("first wins clock override", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithDuplicatePolicy(di.DuplicateFirstWins),
+			di.WithService(fixedClock{}, di.As[di.Clock]()),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[di.Clock](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, fixedClock{}, got)
+	})
+
+	t.Run("first wins strict candidates", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithDuplicatePolicy(di.DuplicateFirstWins),
+			di.WithStrictResolve(),
+			di.WithService(&testtypes.StructA{}),
+			di.WithService(&testtypes.StructA{}),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*testtypes.StructA](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve *testtypes.StructA: "+
+			"2 services registered; use di.ResolveLast or di.ResolveAll; "+
+			"candidates: #0 *testtypes.StructA (first), #1 *testtypes.StructA")
+	})
+
+	t.Run("error", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithDuplicatePolicy(di.DuplicateError),
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(testtypes.NewInterfaceB),
+			di.WithService(testtypes.NewInterfaceA),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithDuplicatePolicy DuplicateError: testtypes.InterfaceA registered 2 times: "+
+			"#0 github.com/sectrean/di-kit/internal/testtypes.NewInterfaceA, "+
+			"#1 github.com/sectrean/di-kit/internal/testtypes.NewInterfaceA")
+	})
+
+	t.Run("error allows intentional duplicates", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithDuplicatePolicy(di.DuplicateError),
+			di.WithService(newA("a"), di.WithTag("a")),
+			di.WithService(newA("b"), di.WithTag("b")),
+			di.WithService(newA("primary"), di.As[testtypes.InterfaceA](), di.WithPrimary()),
+			di.WithService(newA("other"), di.As[testtypes.InterfaceA]()),
+			di.WithService(fixedClock{}, di.As[di.Clock]()),
+			di.WithService(testtypes.NewInterfaceB),
+			di.WithOptionOrder(di.OrderDecorator,
+				di.WithService(func(testtypes.InterfaceB) testtypes.InterfaceB { return testtypes.StructB{} }),
+			),
+			di.WithServiceOverride(testtypes.NewInterfaceC),
+			di.WithServiceOverride(testtypes.NewInterfaceC),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope(
+			di.WithService(newA("child"), di.WithTag("a")),
+		)
+		require.NoError(t, err)
+		assert.NotNil(t, scope)
+	})
+
+	t.Run("error scope", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithDuplicatePolicy(di.DuplicateError),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope(
+			di.WithService(newA("a")),
+			di.WithService(newA("b")),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, scope)
+		assert.ErrorContains(t, err, "WithDuplicatePolicy DuplicateError: *testtypes.StructA registered 2 times")
+	})
+
+	t.Run("unknown policy", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithDuplicatePolicy(10),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithDuplicatePolicy: Unknown DuplicatePolicy 10")
+	})
+}
@@ -0,0 +1,199 @@
+package di
+
+import (
+	"fmt"
+	"time"
+)
+
+// EventKind specifies the kind of an [Event].
+type EventKind uint8
+
+const (
+	// Registered is emitted for each service registered with a [Container] after it is created.
+	Registered EventKind = iota
+
+	// Resolved is emitted after a service constructor function returns successfully.
+	// It is not emitted when a cached instance of a service is returned.
+	Resolved EventKind = iota
+
+	// ConstructorFailed is emitted after a service constructor function returns an error.
+	ConstructorFailed EventKind = iota
+
+	// ScopeCreated is emitted after a child scope is created with [Container.NewScope].
+	ScopeCreated EventKind = iota
+
+	// ScopeClosed is emitted after a child scope is closed.
+	ScopeClosed EventKind = iota
+
+	// Closed is emitted after the root [Container] is closed.
+	Closed EventKind = iota
+
+	// Shadowed is emitted for each service registered with a child scope for a type and tag
+	// that is already registered with a parent Container.
+	// The child scope resolves its own service, so a [Singleton] registered with the parent is not shared.
+	Shadowed EventKind = iota
+
+	// DeprecatedResolved is emitted the first time a service marked with [Deprecated] is resolved in the process.
+	DeprecatedResolved EventKind = iota
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case Registered:
+		return "Registered"
+	case Resolved:
+		return "Resolved"
+	case ConstructorFailed:
+		return "ConstructorFailed"
+	case ScopeCreated:
+		return "ScopeCreated"
+	case ScopeClosed:
+		return "ScopeClosed"
+	case Closed:
+		return "Closed"
+	case Shadowed:
+		return "Shadowed"
+	case DeprecatedResolved:
+		return "DeprecatedResolved"
+	default:
+		return fmt.Sprintf("Unknown EventKind %d", k)
+	}
+}
+
+// Event describes something that happened in a [Container].
+//
+// See [WithEventHandler] for more information.
+type Event struct {
+	// Time the event occurred.
+	Time time.Time
+	// Err is the error returned from the constructor function for [ConstructorFailed] events,
+	// or from closing the Container for [ScopeClosed] and [Closed] events.
+	Err error
+	// Scope is the Container the event occurred in.
+	Scope *Container
+	// Message is the message passed to [Deprecated] for [DeprecatedResolved] events.
+	Message string
+	// Caller is the file and line of the code that resolved the service for [DeprecatedResolved] events.
+	Caller string
+	// Service is the service the event is about.
+	// It is set for [Registered], [Resolved], [ConstructorFailed], [Shadowed], and [DeprecatedResolved] events.
+	Service ServiceInfo
+	// Shadowed is the service registered with a parent Container for [Shadowed] events.
+	Shadowed ServiceInfo
+	// Duration of the constructor function call for [Resolved] and [ConstructorFailed] events.
+	Duration time.Duration
+	// Kind of event.
+	Kind EventKind
+}
+
+// EventHandler is called for each [Event] emitted by a [Container].
+type EventHandler = func(Event)
+
+// WithEventHandler registers a function that is called for each [Event] emitted by the [Container]
+// when calling [NewContainer] or [Container.NewScope].
+//
+// Child scopes inherit the event handlers of the parent Container.
+// This allows external tooling like dashboards and tests to observe the behavior of the Container.
+//
+// The handler is called synchronously, and may be called concurrently from multiple goroutines.
+// It should return quickly, and it must not resolve services or create scopes.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithEventHandler(func(e di.Event) {
+//			if e.Kind == di.ConstructorFailed {
+//				logger.Error("service constructor failed", "service", e.Service, "error", e.Err)
+//			}
+//		}),
+//		// ...
+//	)
+func WithEventHandler(h EventHandler) ContainerOption {
+	return containerOption(func(c *Container) error {
+		if h != nil {
+			c.eventHandlers = append(c.eventHandlers, h)
+		}
+		return nil
+	})
+}
+
+func (c *Container) emit(e *Event) {
+	if len(c.eventHandlers) == 0 {
+		return
+	}
+
+	e.Time = time.Now()
+	e.Scope = c
+	for _, h := range c.eventHandlers {
+		h(*e)
+	}
+}
+
+// emitCreated emits events after the Container is created.
+func (c *Container) emitCreated() {
+	if len(c.eventHandlers) == 0 {
+		return
+	}
+
+	for _, svc := range c.registered {
+		for _, key := range svc.Keys() {
+			c.emit(&Event{
+				Kind:    Registered,
+				Service: svc.Info(key),
+			})
+		}
+	}
+
+	if c.parent != nil {
+		c.emitShadowed()
+		c.emit(&Event{Kind: ScopeCreated})
+	}
+}
+
+// emitShadowed emits an event for each service that shadows a service registered with a parent Container.
+func (c *Container) emitShadowed() {
+	for _, svc := range c.registered {
+		if svc.replace {
+			continue
+		}
+
+		for _, key := range svc.Keys() {
+			shadowed := c.parent.lookupService(key)
+			if shadowed == nil || shadowed.builtin {
+				continue
+			}
+
+			c.emit(&Event{
+				Kind:     Shadowed,
+				Service:  svc.Info(key),
+				Shadowed: shadowed.Info(key),
+			})
+		}
+	}
+}
+
+func (c *Container) emitConstructed(svc *service, key serviceKey, d time.Duration, err error) {
+	kind := Resolved
+	if err != nil {
+		kind = ConstructorFailed
+	}
+
+	c.emit(&Event{
+		Kind:     kind,
+		Service:  svc.Info(key),
+		Err:      err,
+		Duration: d,
+	})
+}
+
+func (c *Container) emitClosed(err error) {
+	kind := Closed
+	if c.parent != nil {
+		kind = ScopeClosed
+	}
+
+	c.emit(&Event{
+		Kind: kind,
+		Err:  err,
+	})
+}
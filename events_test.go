@@ -0,0 +1,213 @@
+package di_test
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type eventRecorder struct {
+	events []di.Event
+	mu     sync.Mutex
+}
+
+// Handler returns an EventHandler that records each event.
+func (r *eventRecorder) Handler() di.EventHandler {
+	return func(e di.Event) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.events = append(r.events, e)
+	}
+}
+
+// Kinds returns the kinds of events recorded, ignoring Registered events for the built-in services.
+func (r *eventRecorder) Kinds() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kinds := make([]string, 0, len(r.events))
+	for i := range r.events {
+		e := &r.events[i]
+		if e.Kind == di.Registered &&
+			(e.Service.Type == reflect.TypeFor[di.Clock]() || e.Service.Type == reflect.TypeFor[di.Rand]()) {
+			continue
+		}
+
+		kind := e.Kind.String()
+		if e.Service.Type != nil {
+			kind += " " + e.Service.String()
+		}
+		kinds = append(kinds, kind)
+	}
+
+	return kinds
+}
+
+func Test_WithEventHandler(t *testing.T) {
+	t.Run("lifecycle", func(t *testing.T) {
+		rec := &eventRecorder{}
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(testtypes.NewInterfaceB, di.Scoped, di.WithTag("tag")),
+			di.WithEventHandler(rec.Handler()),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope()
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		_ = di.MustResolve[testtypes.InterfaceB](ctx, scope, di.WithTag("tag"))
+		_ = di.MustResolve[testtypes.InterfaceB](ctx, scope, di.WithTag("tag"))
+
+		require.NoError(t, scope.Close(ctx))
+		require.NoError(t, c.Close(ctx))
+
+		assert.Equal(t, []string{
+			"Registered testtypes.InterfaceA",
+			"Registered testtypes.InterfaceB: WithTag tag",
+			"ScopeCreated",
+			"Resolved testtypes.InterfaceA",
+			"Resolved testtypes.InterfaceB: WithTag tag",
+			"ScopeClosed",
+			"Closed",
+		}, rec.Kinds())
+
+		assert.Same(t, scope, rec.events[len(rec.events)-2].Scope)
+		assert.Same(t, c, rec.events[len(rec.events)-1].Scope)
+	})
+
+	t.Run("ConstructorFailed", func(t *testing.T) {
+		rec := &eventRecorder{}
+		c, err := di.NewContainer(
+			di.WithService(func() (testtypes.InterfaceA, error) {
+				return nil, errors.New("constructor error")
+			}),
+			di.WithService(testtypes.NewInterfaceB),
+			di.WithEventHandler(rec.Handler()),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceB](context.Background(), c)
+		assert.Error(t, err)
+
+		assert.Equal(t, []string{
+			"Registered testtypes.InterfaceA",
+			"Registered testtypes.InterfaceB",
+			"ConstructorFailed testtypes.InterfaceA",
+		}, rec.Kinds())
+
+		e := rec.events[len(rec.events)-1]
+		assert.EqualError(t, e.Err, "constructor error")
+		assert.Positive(t, e.Duration)
+		assert.False(t, e.Time.IsZero())
+	})
+
+	t.Run("Closed error", func(t *testing.T) {
+		rec := &eventRecorder{}
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA,
+				di.UseCloseFunc(func(context.Context, testtypes.InterfaceA) error {
+					return errors.New("close error")
+				}),
+			),
+			di.WithEventHandler(rec.Handler()),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		_ = di.MustResolve[testtypes.InterfaceA](ctx, c)
+		assert.Error(t, c.Close(ctx))
+
+		e := rec.events[len(rec.events)-1]
+		assert.Equal(t, di.Closed, e.Kind)
+		assert.EqualError(t, e.Err, "di.Container.Close: close error")
+	})
+
+	t.Run("child scope handler", func(t *testing.T) {
+		parentRec := &eventRecorder{}
+		childRec := &eventRecorder{}
+		c, err := di.NewContainer(
+			di.WithEventHandler(parentRec.Handler()),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithEventHandler(childRec.Handler()),
+		)
+		require.NoError(t, err)
+
+		_ = di.MustResolve[testtypes.InterfaceA](context.Background(), scope)
+
+		expected := []string{
+			"Registered testtypes.InterfaceA",
+			"ScopeCreated",
+			"Resolved testtypes.InterfaceA",
+		}
+		assert.Equal(t, expected, parentRec.Kinds())
+		assert.Equal(t, expected, childRec.Kinds())
+	})
+
+	t.Run("Shadowed", func(t *testing.T) {
+		rec := &eventRecorder{}
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(testtypes.NewInterfaceB, di.WithTag("tag")),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(testtypes.NewInterfaceB),
+			di.WithService(fixedClock{}, di.As[di.Clock]()),
+			di.WithEventHandler(rec.Handler()),
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"Registered testtypes.InterfaceA",
+			"Registered testtypes.InterfaceB",
+			"Shadowed testtypes.InterfaceA",
+			"ScopeCreated",
+		}, rec.Kinds())
+
+		e := rec.events[3]
+		require.Equal(t, di.Shadowed, e.Kind)
+		assert.Equal(t, 1, e.Service.Depth)
+		assert.Equal(t, 0, e.Shadowed.Depth)
+		assert.NotEqual(t, e.Service.ID, e.Shadowed.ID)
+
+		_, err = scope.NewScope(
+			di.WithService(testtypes.NewInterfaceB),
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, "Shadowed testtypes.InterfaceB", rec.Kinds()[5])
+		assert.Equal(t, 1, rec.events[len(rec.events)-2].Shadowed.Depth)
+	})
+
+	t.Run("nil handler", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithEventHandler(nil),
+		)
+		require.NoError(t, err)
+
+		_ = di.MustResolve[testtypes.InterfaceA](context.Background(), c)
+	})
+}
+
+func Test_EventKind_String(t *testing.T) {
+	assert.Equal(t, "Resolved", di.Resolved.String())
+	assert.Equal(t, "Shadowed", di.Shadowed.String())
+	assert.Equal(t, "Unknown EventKind 100", di.EventKind(100).String())
+}
@@ -0,0 +1,76 @@
+package di
+
+import (
+	"slices"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// Experiment is the name of an unstable feature that must be enabled with [Experimental].
+//
+// The APIs of experimental features may change in any release.
+type Experiment string
+
+const (
+	// ExperimentKeyedSingletons enables [SingletonPer].
+	ExperimentKeyedSingletons Experiment = "keyed-singletons"
+)
+
+var experiments = []Experiment{
+	ExperimentKeyedSingletons,
+}
+
+// Experimental enables unstable features when calling [NewContainer] or [Container.NewScope].
+//
+// Experimental features are not covered by the compatibility guarantees of this package,
+// so their APIs can change in any release. Registering a service that uses an experimental feature
+// returns an error unless the experiment is enabled, so using them is an explicit decision.
+// Use [Container.Experiments] to get the enabled experiments.
+//
+// Child scopes inherit the experiments enabled by the parent Container.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.Experimental(di.ExperimentKeyedSingletons),
+//		di.WithService(db.NewTenantPool, di.SingletonPer(tenant.IDFromContext)),
+//	)
+//
+// This option will return an error if an experiment is unknown.
+func Experimental(names ...Experiment) ContainerOption {
+	return containerOption(func(c *Container) error {
+		for _, name := range names {
+			if !slices.Contains(experiments, name) {
+				return errors.Errorf("Experimental: unknown experiment %q", name)
+			}
+			if !slices.Contains(c.experiments, name) {
+				c.experiments = append(c.experiments, name)
+			}
+		}
+		return nil
+	})
+}
+
+// Experiments returns the experiments enabled for the Container with [Experimental], sorted by name.
+func (c *Container) Experiments() []Experiment {
+	names := slices.Clone(c.experiments)
+	slices.Sort(names)
+	return names
+}
+
+// ExperimentEnabled returns true if the experiment is enabled for the Container with [Experimental].
+func (c *Container) ExperimentEnabled(name Experiment) bool {
+	return slices.Contains(c.experiments, name)
+}
+
+// checkExperiments returns an error if a registered service uses an experiment that is not enabled.
+func (c *Container) checkExperiments() error {
+	for _, svc := range c.registered {
+		if svc.keyed != nil && !c.ExperimentEnabled(ExperimentKeyedSingletons) {
+			return errors.Errorf("service %s: SingletonPer: experiment %q is not enabled; use di.Experimental",
+				svc, ExperimentKeyedSingletons)
+		}
+	}
+
+	return nil
+}
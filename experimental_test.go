@@ -0,0 +1,80 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Experimental(t *testing.T) {
+	key := func(context.Context) any { return "key" }
+
+	t.Run("enabled", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewStructAPtr, di.SingletonPer(key)),
+			di.Experimental(di.ExperimentKeyedSingletons),
+		)
+		require.NoError(t, err)
+
+		assert.True(t, c.ExperimentEnabled(di.ExperimentKeyedSingletons))
+		assert.Equal(t, []di.Experiment{di.ExperimentKeyedSingletons}, c.Experiments())
+	})
+
+	t.Run("not enabled", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewStructAPtr, di.SingletonPer(key)),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: service func() *testtypes.StructA: "+
+			`SingletonPer: experiment "keyed-singletons" is not enabled; use di.Experimental`)
+	})
+
+	t.Run("none enabled", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		assert.False(t, c.ExperimentEnabled(di.ExperimentKeyedSingletons))
+		assert.Empty(t, c.Experiments())
+	})
+
+	t.Run("scope inherits", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons, di.ExperimentKeyedSingletons),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope(
+			di.WithService(testtypes.NewStructAPtr, di.SingletonPer(key)),
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, []di.Experiment{di.ExperimentKeyedSingletons}, scope.Experiments())
+	})
+
+	t.Run("scope not enabled", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		scope, err := c.NewScope(
+			di.WithService(testtypes.NewStructAPtr, di.SingletonPer(key)),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, scope)
+		assert.ErrorContains(t, err, `experiment "keyed-singletons" is not enabled`)
+	})
+
+	t.Run("unknown experiment", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.Experimental("cycle-proxies"),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, `di.NewContainer: Experimental: unknown experiment "cycle-proxies"`)
+	})
+}
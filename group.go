@@ -0,0 +1,231 @@
+package di
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// Group runs functions in goroutines, each with its own child scope.
+//
+// This is similar to errgroup.Group, except that each function gets a new child scope
+// that is closed when the function returns.
+//
+// Use [NewGroup] to create a Group.
+//
+// Example:
+//
+//	g, ctx := di.NewGroup(ctx, c)
+//	for _, job := range jobs {
+//		g.Go(func(ctx context.Context, scope di.Scope) error {
+//			worker, err := di.Resolve[*Worker](ctx, scope)
+//			if err != nil {
+//				return err
+//			}
+//			return worker.Run(ctx, job)
+//		}, di.WithService(job))
+//	}
+//	err := g.Wait()
+type Group struct {
+	parent Scope
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	sem    chan struct{}
+	opts   []ContainerOption
+	errs   []error
+	wg     sync.WaitGroup
+	errsMu sync.Mutex
+}
+
+// NewGroup creates a new [Group] that creates child scopes from the parent [Scope].
+//
+// The parent must be a [Container], or a [Scope] injected into a constructor function.
+// The options are used to create every child scope.
+//
+// The returned context is canceled the first time a function returns an error,
+// or when Wait returns, whichever occurs first.
+func NewGroup(ctx context.Context, parent Scope, opts ...ContainerOption) (*Group, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	return &Group{
+		parent: parent,
+		ctx:    ctx,
+		cancel: cancel,
+		opts:   opts,
+	}, ctx
+}
+
+// scopeFactory is implemented by [Container] and the [Scope] injected into constructor functions.
+type scopeFactory interface {
+	NewScope(opts ...ContainerOption) (*Container, error)
+}
+
+// SetLimit limits the number of active goroutines in this Group to at most n.
+// A negative value indicates no limit. This is the default.
+//
+// Any subsequent call to the Go method will block until it can add an active goroutine
+// without exceeding the limit.
+//
+// The limit must not be modified while any goroutines in the Group are active.
+func (g *Group) SetLimit(n int) {
+	if n < 0 {
+		g.sem = nil
+		return
+	}
+
+	g.sem = make(chan struct{}, n)
+}
+
+// Go calls the given function in a new goroutine with a new child scope.
+//
+// If the Group has a limit set with SetLimit, this will block until the function can be started.
+//
+// The options are used to create the child scope, after the options passed to [NewGroup].
+// The child scope is closed after the function returns, even if the Group's context has been canceled,
+// using [CloseWithGrace] with [DefaultCloseGracePeriod].
+//
+// Errors returned from creating the scope, the function, or closing the scope are returned by Wait.
+func (g *Group) Go(fn func(ctx context.Context, scope Scope) error, opts ...ContainerOption) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.wg.Add(1)
+
+	go func() {
+		defer g.done()
+
+		err := g.run(fn, opts)
+		if err != nil {
+			g.errsMu.Lock()
+			g.errs = append(g.errs, err)
+			g.errsMu.Unlock()
+
+			g.cancel(err)
+		}
+	}()
+}
+
+func (g *Group) done() {
+	if g.sem != nil {
+		<-g.sem
+	}
+	g.wg.Done()
+}
+
+func (g *Group) run(fn func(ctx context.Context, scope Scope) error, opts []ContainerOption) (err error) {
+	factory, ok := g.parent.(scopeFactory)
+	if !ok {
+		return errors.Errorf("di.Group.Go: scope %T does not support NewScope", g.parent)
+	}
+
+	scope, err := factory.NewScope(slices.Concat(g.opts, opts)...)
+	if err != nil {
+		return errors.Wrap(err, "di.Group.Go")
+	}
+
+	defer func() {
+		closeErr := CloseWithGrace(g.ctx, scope, DefaultCloseGracePeriod)
+		if closeErr != nil {
+			err = errors.Join(err, errors.Wrap(closeErr, "di.Group.Go"))
+		}
+	}()
+
+	return fn(g.ctx, scope)
+}
+
+// Wait blocks until all function calls from the Go method have returned, then returns
+// all the errors joined together, if any.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel(nil)
+
+	g.errsMu.Lock()
+	defer g.errsMu.Unlock()
+
+	return errors.Join(g.errs...)
+}
+
+// ForEachScope calls fn for each item concurrently, each with its own child scope created from the parent [Scope].
+//
+// The item is registered as a value service with the child scope as type *Item*,
+// so it can be injected into [Scoped] services. The child scope is closed after fn returns.
+// This mirrors the per-request scope model for batch jobs.
+//
+// The parent must be a [Container], or a [Scope] injected into a constructor function.
+// The context passed to fn is canceled the first time fn returns an error.
+// Errors from all items are joined together.
+//
+// Available options:
+//   - [WithConcurrency] limits the number of items processed concurrently.
+//
+// Example:
+//
+//	err := di.ForEachScope(ctx, c, orders,
+//		func(ctx context.Context, scope di.Scope, order *Order) error {
+//			p, err := di.Resolve[*OrderProcessor](ctx, scope) // NewOrderProcessor(*Order) *OrderProcessor
+//			if err != nil {
+//				return err
+//			}
+//			return p.Process(ctx)
+//		},
+//		di.WithConcurrency(10),
+//	)
+func ForEachScope[Item any](
+	ctx context.Context,
+	parent Scope,
+	items []Item,
+	fn func(ctx context.Context, scope Scope, item Item) error,
+	opts ...ForEachOption,
+) error {
+	config := &forEachConfig{concurrency: -1}
+	err := applyOptions(opts, func(opt ForEachOption) error {
+		return opt.applyForEach(config)
+	})
+	if err != nil {
+		return errors.Wrap(err, "di.ForEachScope")
+	}
+
+	g, _ := NewGroup(ctx, parent)
+	g.SetLimit(config.concurrency)
+
+	for _, item := range items {
+		g.Go(func(ctx context.Context, scope Scope) error {
+			return fn(ctx, scope, item)
+		}, WithService(item, As[Item]()))
+	}
+
+	return g.Wait()
+}
+
+// ForEachOption is used to configure [ForEachScope].
+type ForEachOption interface {
+	applyForEach(*forEachConfig) error
+}
+
+type forEachConfig struct {
+	concurrency int
+}
+
+type forEachOption func(*forEachConfig) error
+
+func (o forEachOption) applyForEach(c *forEachConfig) error {
+	return o(c)
+}
+
+// WithConcurrency limits the number of items processed concurrently when calling [ForEachScope].
+//
+// By default, all items are processed concurrently.
+//
+// This option will return an error if n is not positive.
+func WithConcurrency(n int) ForEachOption {
+	return forEachOption(func(c *forEachConfig) error {
+		if n <= 0 {
+			return errors.Errorf("WithConcurrency %d: must be positive", n)
+		}
+
+		c.concurrency = n
+		return nil
+	})
+}
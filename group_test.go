@@ -0,0 +1,305 @@
+package di_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/mocks"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Group(t *testing.T) {
+	t.Run("scope per function", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		tags := make(chan any, 10)
+
+		g, _ := di.NewGroup(context.Background(), c)
+		for i := range 10 {
+			g.Go(func(ctx context.Context, scope di.Scope) error {
+				a, resolveErr := di.Resolve[*testtypes.StructA](ctx, scope)
+				if resolveErr != nil {
+					return resolveErr
+				}
+
+				tags <- a.Tag
+				return nil
+			}, di.WithService(&testtypes.StructA{Tag: i}))
+		}
+
+		err = g.Wait()
+		require.NoError(t, err)
+		close(tags)
+
+		assert.ElementsMatch(t, []any{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, testutils.CollectChannel(tags))
+	})
+
+	t.Run("scope closed", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() testtypes.InterfaceA {
+				a := mocks.NewInterfaceAMock(t)
+				a.EXPECT().Close(mock.Anything).Return(nil).Once()
+				return a
+			}, di.Scoped),
+		)
+		require.NoError(t, err)
+
+		g, _ := di.NewGroup(context.Background(), c)
+		g.Go(func(ctx context.Context, scope di.Scope) error {
+			_, resolveErr := di.Resolve[testtypes.InterfaceA](ctx, scope)
+			return resolveErr
+		})
+
+		assert.NoError(t, g.Wait())
+	})
+
+	t.Run("errors joined", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		g, ctx := di.NewGroup(context.Background(), c)
+		g.Go(func(context.Context, di.Scope) error {
+			return errors.New("error 1")
+		})
+		g.Go(func(ctx context.Context, _ di.Scope) error {
+			<-ctx.Done()
+			return errors.New("error 2")
+		})
+
+		err = g.Wait()
+		testutils.LogError(t, err)
+		assert.ErrorContains(t, err, "error 1")
+		assert.ErrorContains(t, err, "error 2")
+		assert.Error(t, ctx.Err())
+	})
+
+	t.Run("NewScope error", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		g, _ := di.NewGroup(context.Background(), c, di.WithService(nil))
+		g.Go(func(context.Context, di.Scope) error {
+			assert.Fail(t, "should not be called")
+			return nil
+		})
+
+		err = g.Wait()
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Group.Go: di.Container.NewScope: WithService: funcOrValue is nil")
+	})
+
+	t.Run("Close error", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() testtypes.InterfaceA {
+				a := mocks.NewInterfaceAMock(t)
+				a.EXPECT().Close(mock.Anything).Return(errors.New("close error"))
+				return a
+			}, di.Scoped),
+		)
+		require.NoError(t, err)
+
+		g, _ := di.NewGroup(context.Background(), c)
+		g.Go(func(ctx context.Context, scope di.Scope) error {
+			_, resolveErr := di.Resolve[testtypes.InterfaceA](ctx, scope)
+			return resolveErr
+		})
+
+		err = g.Wait()
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Group.Go: di.Container.Close: close error")
+	})
+
+	t.Run("close deadline", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewStructAPtr, di.Scoped,
+				di.UseCloseFunc(func(ctx context.Context, _ *testtypes.StructA) error {
+					if _, ok := ctx.Deadline(); !ok {
+						return errors.New("no deadline")
+					}
+					return nil
+				}),
+			),
+		)
+		require.NoError(t, err)
+
+		g, _ := di.NewGroup(context.Background(), c)
+		g.Go(func(ctx context.Context, scope di.Scope) error {
+			_, resolveErr := di.Resolve[*testtypes.StructA](ctx, scope)
+			return resolveErr
+		})
+
+		err = g.Wait()
+		assert.NoError(t, err)
+	})
+
+	t.Run("scope not supported", func(t *testing.T) {
+		g, _ := di.NewGroup(context.Background(), fakeScope{})
+		g.Go(func(context.Context, di.Scope) error {
+			assert.Fail(t, "should not be called")
+			return nil
+		})
+
+		err := g.Wait()
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Group.Go: scope di_test.fakeScope does not support NewScope")
+	})
+
+	t.Run("context canceled after Wait", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		g, ctx := di.NewGroup(context.Background(), c)
+		assert.NoError(t, g.Wait())
+		assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	})
+}
+
+func Test_Group_SetLimit(t *testing.T) {
+	c, err := di.NewContainer()
+	require.NoError(t, err)
+
+	var active, maxActive atomic.Int32
+
+	g, _ := di.NewGroup(context.Background(), c)
+	g.SetLimit(2)
+	for range 10 {
+		g.Go(func(context.Context, di.Scope) error {
+			n := active.Add(1)
+			defer active.Add(-1)
+
+			for {
+				m := maxActive.Load()
+				if n <= m || maxActive.CompareAndSwap(m, n) {
+					break
+				}
+			}
+
+			time.Sleep(time.Millisecond)
+			return nil
+		})
+	}
+
+	assert.NoError(t, g.Wait())
+	assert.LessOrEqual(t, maxActive.Load(), int32(2))
+}
+
+func Test_ForEachScope(t *testing.T) {
+	t.Run("item registered with scope", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func(a *testtypes.StructA) testtypes.InterfaceB {
+				return &testtypes.StructB{}
+			}, di.Scoped),
+		)
+		require.NoError(t, err)
+
+		items := []*testtypes.StructA{{Tag: 1}, {Tag: 2}, {Tag: 3}}
+		got := make(chan any, len(items))
+
+		err = di.ForEachScope(context.Background(), c, items,
+			func(ctx context.Context, scope di.Scope, item *testtypes.StructA) error {
+				a, resolveErr := di.Resolve[*testtypes.StructA](ctx, scope)
+				if resolveErr != nil {
+					return resolveErr
+				}
+				assert.Same(t, item, a)
+
+				_, resolveErr = di.Resolve[testtypes.InterfaceB](ctx, scope)
+				if resolveErr != nil {
+					return resolveErr
+				}
+
+				got <- a.Tag
+				return nil
+			},
+		)
+		require.NoError(t, err)
+		close(got)
+
+		assert.ElementsMatch(t, []any{1, 2, 3}, testutils.CollectChannel(got))
+	})
+
+	t.Run("interface item", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		items := []testtypes.InterfaceA{&testtypes.StructA{}}
+
+		err = di.ForEachScope(context.Background(), c, items,
+			func(ctx context.Context, scope di.Scope, item testtypes.InterfaceA) error {
+				a, resolveErr := di.Resolve[testtypes.InterfaceA](ctx, scope)
+				assert.Same(t, item, a)
+				return resolveErr
+			},
+		)
+		assert.NoError(t, err)
+	})
+
+	t.Run("WithConcurrency", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		var active, maxActive atomic.Int32
+		items := make([]*testtypes.StructA, 20)
+		for i := range items {
+			items[i] = &testtypes.StructA{Tag: i}
+		}
+
+		err = di.ForEachScope(context.Background(), c, items,
+			func(context.Context, di.Scope, *testtypes.StructA) error {
+				n := active.Add(1)
+				defer active.Add(-1)
+
+				for {
+					m := maxActive.Load()
+					if n <= m || maxActive.CompareAndSwap(m, n) {
+						break
+					}
+				}
+
+				time.Sleep(time.Millisecond)
+				return nil
+			},
+			di.WithConcurrency(3),
+		)
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, maxActive.Load(), int32(3))
+	})
+
+	t.Run("errors joined", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		items := []*testtypes.StructA{{Tag: 1}, {Tag: 2}}
+
+		err = di.ForEachScope(context.Background(), c, items,
+			func(_ context.Context, _ di.Scope, item *testtypes.StructA) error {
+				return fmt.Errorf("error %v", item.Tag)
+			},
+		)
+		testutils.LogError(t, err)
+		assert.ErrorContains(t, err, "error 1")
+		assert.ErrorContains(t, err, "error 2")
+	})
+
+	t.Run("WithConcurrency invalid", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		err = di.ForEachScope(context.Background(), c, []*testtypes.StructA{},
+			func(context.Context, di.Scope, *testtypes.StructA) error { return nil },
+			di.WithConcurrency(0),
+		)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.ForEachScope: WithConcurrency 0: must be positive")
+	})
+}
@@ -0,0 +1,56 @@
+package di
+
+import (
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// AssertImmutable returns an error if services have been registered with the [Container],
+// or any of its parent Containers, or options like event handlers, hooks, and policies have been applied,
+// after it was created.
+//
+// A Container is safe for concurrent use because its registered services and options are never modified
+// after [NewContainer] or [Container.NewScope] returns. Only the resolved services and closers
+// change after that, and they are protected by locks.
+// This can be used in tests to catch regressions that could introduce data races in the scope hierarchy.
+//
+// Build with the diassert build tag to panic as soon as a service is registered or an option is applied
+// to a Container after it was created:
+//
+//	go test -tags diassert -race ./...
+func (c *Container) AssertImmutable() error {
+	for scope := c; scope != nil; scope = scope.parent {
+		if !scope.sealed.Load() {
+			return errors.New("di.Container.AssertImmutable: container not created")
+		}
+		if len(scope.registered) != scope.sealedRegistrations {
+			return errors.Errorf(
+				"di.Container.AssertImmutable: %d services registered after container created",
+				len(scope.registered)-scope.sealedRegistrations,
+			)
+		}
+		if n := scope.sealedMutations.Load(); n > 0 {
+			return errors.Errorf("di.Container.AssertImmutable: container modified %d times after created", n)
+		}
+	}
+
+	return nil
+}
+
+// seal marks the Container as created. Services should not be registered after this.
+func (c *Container) seal() {
+	c.sealedRegistrations = len(c.registered)
+	c.sealed.Store(true)
+}
+
+// assertMutable records that the Container was modified after it was sealed,
+// or panics if the diassert build tag is set.
+func (c *Container) assertMutable(what string) {
+	if !c.sealed.Load() {
+		return
+	}
+	if assertImmutable {
+		panic("di: " + what + " after container created")
+	}
+
+	c.sealedMutations.Add(1)
+}
@@ -0,0 +1,7 @@
+//go:build !diassert
+
+package di
+
+// assertImmutable enables panics when a Container is modified after it was created.
+// Build with the diassert build tag to enable.
+const assertImmutable = false
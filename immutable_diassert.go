@@ -0,0 +1,6 @@
+//go:build diassert
+
+package di
+
+// assertImmutable enables panics when a Container is modified after it was created.
+const assertImmutable = true
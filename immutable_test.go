@@ -0,0 +1,60 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Container_AssertImmutable(t *testing.T) {
+	t.Run("new container", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+
+		assert.NoError(t, c.AssertImmutable())
+	})
+
+	t.Run("concurrent use of scope hierarchy", func(t *testing.T) {
+		// Run with -race to detect data races in the scope hierarchy
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(testtypes.NewInterfaceB, di.Scoped),
+			di.WithService(testtypes.NewInterfaceC, di.Transient),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		testutils.RunParallel(100, func(int) {
+			scope, scopeErr := c.NewScope(
+				di.WithService(testtypes.NewInterfaceD, di.Scoped),
+			)
+			if !assert.NoError(t, scopeErr) {
+				return
+			}
+
+			child, scopeErr := scope.NewScope()
+			if !assert.NoError(t, scopeErr) {
+				return
+			}
+
+			_, resolveErr := di.Resolve[testtypes.InterfaceC](ctx, scope)
+			assert.NoError(t, resolveErr)
+			_, resolveErr = di.Resolve[testtypes.InterfaceD](ctx, child)
+			assert.NoError(t, resolveErr)
+			assert.NoError(t, child.AssertImmutable())
+
+			assert.NoError(t, child.Close(ctx))
+			assert.NoError(t, scope.Close(ctx))
+		})
+
+		assert.NoError(t, c.AssertImmutable())
+		assert.NoError(t, c.Close(ctx))
+	})
+}
@@ -0,0 +1,95 @@
+package di
+
+import (
+	"reflect"
+	"slices"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// InheritOnly makes a child scope inherit only the services of the given types from its parent Containers
+// when calling [Container.NewScope].
+//
+// By default, a child scope can resolve every service registered with its parent Containers.
+// This sandboxes code, like plugin or tenant code, that shouldn't see privileged services.
+// Services registered with the child scope, and its own child scopes, are not filtered.
+// The built-in [Clock] and [Rand] services are always inherited.
+//
+// Using this option more than once, or with [InheritWhere], inherits the services allowed by any of them.
+// Services resolved from the parent Containers still get their own dependencies from the parent Containers.
+//
+// Example:
+//
+//	scope, err := c.NewScope(
+//		di.InheritOnly(reflect.TypeFor[*slog.Logger](), reflect.TypeFor[plugin.API]()),
+//		di.WithService(plugin.New),
+//	)
+//
+// This option will return an error if used with [NewContainer], since a root Container has no parent.
+func InheritOnly(types ...reflect.Type) ContainerOption {
+	return inheritWhere("InheritOnly", func(t reflect.Type, _ any) bool {
+		return slices.Contains(types, t)
+	})
+}
+
+// InheritWhere makes a child scope inherit only the services from its parent Containers
+// for which allow returns true, when calling [Container.NewScope].
+//
+// allow is called with the type and tag, or nil, that the service is resolved with.
+// See [InheritOnly] for more information.
+//
+// Example:
+//
+//	scope, err := c.NewScope(
+//		di.InheritWhere(func(t reflect.Type, tag any) bool {
+//			return tag == "public"
+//		}),
+//	)
+//
+// This option will return an error if allow is nil, or if used with [NewContainer],
+// since a root Container has no parent.
+func InheritWhere(allow func(t reflect.Type, tag any) bool) ContainerOption {
+	return inheritWhere("InheritWhere", allow)
+}
+
+func inheritWhere(name string, allow func(t reflect.Type, tag any) bool) ContainerOption {
+	return containerOption(func(c *Container) error {
+		if allow == nil {
+			return errors.Errorf("%s: allow is nil", name)
+		}
+		if c.parent == nil {
+			return errors.Errorf("%s: a root Container has no parent; use Container.NewScope", name)
+		}
+
+		c.inheritFilters = append(c.inheritFilters, allow)
+		c.inheritFiltered = true
+		return nil
+	})
+}
+
+// visible returns true if services registered with the ancestor Container for key can be resolved from c.
+func (c *Container) visible(ancestor *Container, key serviceKey) bool {
+	if !c.inheritFiltered || key.Type == typeClock || key.Type == typeRand {
+		return true
+	}
+
+	for s := c; s != ancestor; s = s.parent {
+		if s.inherits(key) {
+			continue
+		}
+		return false
+	}
+
+	return true
+}
+
+// inherits returns true if the Container inherits services for key from its parent.
+func (c *Container) inherits(key serviceKey) bool {
+	if len(c.inheritFilters) == 0 {
+		return true
+	}
+
+	return slices.ContainsFunc(c.inheritFilters, func(allow func(reflect.Type, any) bool) bool {
+		return allow(key.Type, key.Tag)
+	})
+}
@@ -0,0 +1,163 @@
+package di_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_InheritOnly(t *testing.T) {
+	ctx := context.Background()
+
+	newParent := func(t *testing.T) *di.Container {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(testtypes.NewInterfaceB),
+			di.WithService(&testtypes.StructA{Tag: "public"}, di.WithTag("public")),
+			di.WithService(&testtypes.StructA{Tag: "private"}, di.WithTag("private")),
+		)
+		require.NoError(t, err)
+		return c
+	}
+
+	t.Run("allowed types", func(t *testing.T) {
+		c := newParent(t)
+
+		scope, err := c.NewScope(
+			di.InheritOnly(testtypes.TypeInterfaceA),
+			di.WithService(testtypes.NewStructBPtr),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, scope)
+		require.NoError(t, err)
+
+		assert.True(t, scope.Contains(testtypes.TypeInterfaceA))
+		assert.False(t, scope.Contains(testtypes.TypeInterfaceB))
+		assert.True(t, scope.Contains(testtypes.TypeStructBPtr))
+
+		_, err = di.Resolve[testtypes.InterfaceB](ctx, scope)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve testtypes.InterfaceB: service not registered")
+	})
+
+	t.Run("parent service gets its own dependencies", func(t *testing.T) {
+		c := newParent(t)
+
+		scope, err := c.NewScope(
+			di.InheritOnly(testtypes.TypeInterfaceB),
+		)
+		require.NoError(t, err)
+
+		// InterfaceB depends on InterfaceA, which is resolved from the parent
+		_, err = di.Resolve[testtypes.InterfaceB](ctx, scope)
+		require.NoError(t, err)
+	})
+
+	t.Run("nested scope", func(t *testing.T) {
+		c := newParent(t)
+
+		scope, err := c.NewScope(
+			di.InheritOnly(testtypes.TypeInterfaceA),
+			di.WithService(testtypes.NewStructAPtr),
+		)
+		require.NoError(t, err)
+
+		child, err := scope.NewScope()
+		require.NoError(t, err)
+
+		assert.True(t, child.Contains(testtypes.TypeInterfaceA))
+		assert.True(t, child.Contains(testtypes.TypeStructAPtr))
+		assert.False(t, child.Contains(testtypes.TypeInterfaceB))
+	})
+
+	t.Run("built-in services", func(t *testing.T) {
+		c := newParent(t)
+
+		scope, err := c.NewScope(di.InheritOnly())
+		require.NoError(t, err)
+
+		_, err = di.Resolve[di.Clock](ctx, scope)
+		require.NoError(t, err)
+		_, err = di.Resolve[di.Rand](ctx, scope)
+		require.NoError(t, err)
+	})
+
+	t.Run("slices", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope(
+			di.InheritOnly(),
+			di.WithService(testtypes.NewInterfaceAStruct),
+		)
+		require.NoError(t, err)
+
+		got, err := di.ResolveAll[testtypes.InterfaceA](ctx, scope)
+		require.NoError(t, err)
+		assert.Equal(t, []testtypes.InterfaceA{testtypes.StructA{}}, got)
+	})
+
+	t.Run("InheritWhere", func(t *testing.T) {
+		c := newParent(t)
+
+		scope, err := c.NewScope(
+			di.InheritWhere(func(_ reflect.Type, tag any) bool {
+				return tag == "public"
+			}),
+		)
+		require.NoError(t, err)
+
+		got, err := di.ResolveMap[string, *testtypes.StructA](ctx, scope)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]*testtypes.StructA{"public": {Tag: "public"}}, got)
+
+		assert.False(t, scope.Contains(testtypes.TypeStructAPtr, di.WithTag("private")))
+		assert.False(t, scope.Contains(testtypes.TypeInterfaceA))
+	})
+
+	t.Run("combined", func(t *testing.T) {
+		c := newParent(t)
+
+		scope, err := c.NewScope(
+			di.InheritOnly(testtypes.TypeInterfaceA),
+			di.InheritWhere(func(_ reflect.Type, tag any) bool {
+				return tag == "public"
+			}),
+		)
+		require.NoError(t, err)
+
+		assert.True(t, scope.Contains(testtypes.TypeInterfaceA))
+		assert.True(t, scope.Contains(testtypes.TypeStructAPtr, di.WithTag("public")))
+		assert.False(t, scope.Contains(testtypes.TypeInterfaceB))
+	})
+
+	t.Run("InheritWhere nil", func(t *testing.T) {
+		c := newParent(t)
+
+		scope, err := c.NewScope(di.InheritWhere(nil))
+		testutils.LogError(t, err)
+
+		assert.Nil(t, scope)
+		assert.EqualError(t, err, "di.Container.NewScope: InheritWhere: allow is nil")
+	})
+
+	t.Run("root Container", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.InheritOnly(testtypes.TypeInterfaceA),
+			di.WithService(testtypes.NewInterfaceA),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: InheritOnly: a root Container has no parent; use Container.NewScope")
+	})
+}
@@ -0,0 +1,112 @@
+package di
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// WithStruct registers a struct type *Service* whose fields are populated from the Container,
+// without a constructor function, when calling [NewContainer] or [Container.NewScope].
+//
+// *Service* must be a struct or a pointer to a struct. Exported fields with the `di:"inject"` struct tag
+// are resolved like constructor function parameters. Use `di:"inject,tag=name"` to resolve a field
+// with [WithTag]("name"). Fields without the tag, or with `di:"-"`, are left as the zero value.
+//
+// Example:
+//
+//	type Handler struct {
+//		Logger  *slog.Logger   `di:"inject"`
+//		Store   storage.Store  `di:"inject"`
+//		Replica *sql.DB        `di:"inject,tag=replica"`
+//		Cache   di.Optional[*cache.Cache] `di:"inject"`
+//	}
+//
+//	c, err := di.NewContainer(
+//		di.WithStruct[*Handler](di.Scoped),
+//	)
+//
+// All [ServiceOption]s supported by function services are available.
+//
+// This option will return an error if *Service* is not a struct or a pointer to a struct,
+// or if a field with the `di` tag is unexported, has an invalid tag, or is not a valid dependency type.
+func WithStruct[Service any](opts ...ServiceOption) ContainerOption {
+	return containerOption(func(c *Container) error {
+		t := reflect.TypeFor[Service]()
+
+		b, err := newStructBuilder(t)
+		if err != nil {
+			return errors.Wrapf(err, "WithStruct %s", t)
+		}
+		b.opts = opts
+		b.name = "di.WithStruct " + t.String()
+
+		s, err := b.newService(c)
+		if err != nil {
+			return errors.Wrapf(err, "WithStruct %s", t)
+		}
+
+		c.register(s)
+		return nil
+	})
+}
+
+// newStructBuilder returns a ServiceBuilder that creates a struct of type t with the injected fields set.
+func newStructBuilder(t reflect.Type) (*ServiceBuilder, error) {
+	structType := t
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return nil, errors.New("must be a struct or pointer to struct")
+	}
+
+	b := NewServiceBuilder(t)
+
+	fields := make([]int, 0, structType.NumField())
+	var errs []error
+	for i := range structType.NumField() {
+		field := structType.Field(i)
+
+		st, err := parseStructTag(&field, tagInject)
+		if err == nil && (st.skip || !st.inject) {
+			if _, ok := field.Tag.Lookup("di"); !ok || st.skip {
+				continue
+			}
+			err = errors.Errorf("invalid di tag %q: option \"inject\" is required", field.Tag.Get("di"))
+		}
+		if err == nil && !field.IsExported() {
+			err = errors.New("unexported field cannot be injected")
+		}
+		if err == nil && !validateDependencyType(field.Type) {
+			err = errors.Errorf("invalid dependency type %s; %s", field.Type, invalidTypeHint(field.Type))
+		}
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "field %s", field.Name))
+			continue
+		}
+
+		b.Dependency(field.Type, st.tag)
+		fields = append(fields, i)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	b.Factory(func(_ context.Context, deps []any) (any, error) {
+		structVal := reflect.New(structType).Elem()
+		for i, dep := range deps {
+			field := structVal.Field(fields[i])
+			field.Set(safeReflectValue(field.Type(), dep))
+		}
+
+		if t.Kind() == reflect.Pointer {
+			return structVal.Addr().Interface(), nil
+		}
+		return structVal.Interface(), nil
+	})
+
+	return b, nil
+}
@@ -0,0 +1,155 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type injectedStruct struct {
+	A           testtypes.InterfaceA `di:"inject"`
+	Tagged      testtypes.InterfaceA `di:"inject,tag=tag"`
+	Ctx         context.Context      `di:"inject"`
+	NotInjected testtypes.InterfaceA
+	Optional    di.Optional[testtypes.InterfaceB] `di:"inject"`
+	Slice       []testtypes.InterfaceA            `di:"inject"`
+}
+
+func Test_WithStruct(t *testing.T) {
+	ctx := testutils.ContextWithTestValue(context.Background(), "value")
+
+	a := testtypes.StructA{}
+	tagged := testtypes.StructA{Tag: "tag"}
+
+	t.Run("pointer", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(a, di.As[testtypes.InterfaceA]()),
+			di.WithService(tagged, di.As[testtypes.InterfaceA](), di.WithTag("tag")),
+			di.WithStruct[*injectedStruct](),
+			di.WithDependencyValidation(),
+		)
+		require.NoError(t, err)
+
+		s, err := di.Resolve[*injectedStruct](ctx, c)
+		require.NoError(t, err)
+
+		assert.Equal(t, a, s.A)
+		assert.Equal(t, tagged, s.Tagged)
+		assert.Equal(t, []testtypes.InterfaceA{a}, s.Slice)
+		_, ok := s.Optional.Get()
+		assert.False(t, ok)
+		assert.Equal(t, "value", testutils.TestValue(s.Ctx))
+		assert.Nil(t, s.NotInjected)
+
+		assert.Same(t, s, di.MustResolve[*injectedStruct](ctx, c))
+	})
+
+	t.Run("struct", func(t *testing.T) {
+		type handler struct {
+			A testtypes.InterfaceA `di:"inject"`
+		}
+
+		c, err := di.NewContainer(
+			di.WithService(a, di.As[testtypes.InterfaceA]()),
+			di.WithStruct[handler](),
+		)
+		require.NoError(t, err)
+
+		s, err := di.Resolve[handler](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, handler{A: a}, s)
+	})
+
+	t.Run("Scoped WithTag", func(t *testing.T) {
+		type handler struct {
+			A testtypes.InterfaceA `di:"inject"`
+		}
+
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithStruct[*handler](di.Scoped, di.WithTag("handler")),
+		)
+		require.NoError(t, err)
+
+		scope1, err := c.NewScope()
+		require.NoError(t, err)
+		scope2, err := c.NewScope()
+		require.NoError(t, err)
+
+		h1 := di.MustResolve[*handler](ctx, scope1, di.WithTag("handler"))
+		h2 := di.MustResolve[*handler](ctx, scope2, di.WithTag("handler"))
+		assert.NotSame(t, h1, h2)
+		assert.Same(t, h1.A, h2.A)
+	})
+
+	t.Run("dependency not registered", func(t *testing.T) {
+		type handler struct {
+			A testtypes.InterfaceA `di:"inject"`
+		}
+
+		c, err := di.NewContainer(
+			di.WithStruct[*handler](),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*handler](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve *di_test.handler: "+
+			"dependency testtypes.InterfaceA: service not registered")
+	})
+
+	t.Run("not a struct", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithStruct[testtypes.InterfaceA](),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithStruct testtypes.InterfaceA: must be a struct or pointer to struct")
+	})
+
+	t.Run("invalid fields", func(t *testing.T) {
+		type handler struct {
+			a       testtypes.InterfaceA `di:"inject"`
+			BadTag  testtypes.InterfaceA `di:"tag"`
+			NoTag   testtypes.InterfaceA `di:"inject,tag="`
+			Tagged  testtypes.InterfaceA `di:"tag=a"`
+			Opt     testtypes.InterfaceA `di:"inject,optional"`
+			Skipped testtypes.InterfaceA `di:"-"`
+			Invalid int                  `di:"inject"`
+		}
+		_ = handler{}.a
+
+		c, err := di.NewContainer(
+			di.WithStruct[*handler](),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithStruct *di_test.handler: "+
+			"field a: unexported field cannot be injected\n"+
+			"field BadTag: invalid di tag \"tag\"\n"+
+			"field NoTag: invalid di tag \"inject,tag=\"\n"+
+			"field Tagged: invalid di tag \"tag=a\": option \"inject\" is required\n"+
+			"field Opt: invalid di tag \"inject,optional\": option \"optional\" is not supported\n"+
+			"field Invalid: invalid dependency type int; use a named type or a pointer to a named type")
+	})
+
+	t.Run("invalid option", func(t *testing.T) {
+		type handler struct{}
+
+		c, err := di.NewContainer(
+			di.WithStruct[*handler](di.As[testtypes.InterfaceA]()),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithStruct *di_test.handler: "+
+			"As testtypes.InterfaceA: type *di_test.handler not assignable to testtypes.InterfaceA")
+	})
+}
@@ -0,0 +1,84 @@
+package di
+
+import (
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// InstanceStore stores the results of creating [Singleton] and [Scoped] services for a [Container].
+//
+// By default, results are stored in a map. Use [WithInstanceStore] to use a custom InstanceStore,
+// for example to record metrics or to inspect the instances a Container has created.
+//
+// Services are identified by the [ServiceInfo] for the first type and tag the service is registered with.
+// The error returned from the constructor function is stored with the instance,
+// so the same error is returned each time the service is resolved.
+//
+// Load may be called concurrently from multiple goroutines, but never at the same time as Store.
+// The Container is responsible for closing the instances, so the InstanceStore must not evict them.
+// Services registered with [SingletonPer] are cached separately, see [WithCacheLimit].
+type InstanceStore interface {
+	// Load returns the stored result for the service, or false if there is none.
+	Load(svc *ServiceInfo) (Instance, bool)
+
+	// Store stores the result of creating the service.
+	Store(svc *ServiceInfo, inst Instance)
+}
+
+// Instance is the result of creating a service, stored in an [InstanceStore].
+type Instance struct {
+	// Value is the service returned from the constructor function.
+	Value any
+	// Err is the error returned from the constructor function.
+	Err error
+}
+
+// WithInstanceStore sets a function that creates the [InstanceStore] for each Container
+// when calling [NewContainer] or [Container.NewScope].
+//
+// Child scopes inherit this option from the parent Container, and f is called for each child scope.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithInstanceStore(func() di.InstanceStore {
+//			return metrics.NewInstanceStore(registry)
+//		}),
+//	)
+//
+// This option will return an error if f is nil.
+func WithInstanceStore(f func() InstanceStore) ContainerOption {
+	return containerOption(func(c *Container) error {
+		if f == nil {
+			return errors.New("WithInstanceStore: f is nil")
+		}
+
+		c.newInstanceStore = f
+		c.instanceStore = f()
+		return nil
+	})
+}
+
+// loadResolved returns the result of creating svc, if any.
+// The caller must hold a lock on resolvedMu.
+func (c *Container) loadResolved(svc *service) (resolveResult, bool) {
+	if c.instanceStore == nil {
+		res, ok := c.resolved[svc]
+		return res, ok
+	}
+
+	info := svc.Info(svc.Keys()[0])
+	inst, ok := c.instanceStore.Load(&info)
+	return resolveResult{Val: inst.Value, Err: inst.Err}, ok
+}
+
+// storeResolved stores the result of creating svc.
+// The caller must hold a write lock on resolvedMu.
+func (c *Container) storeResolved(svc *service, res resolveResult) {
+	if c.instanceStore == nil {
+		c.resolved[svc] = res
+		return
+	}
+
+	info := svc.Info(svc.Keys()[0])
+	c.instanceStore.Store(&info, Instance{Value: res.Val, Err: res.Err})
+}
@@ -0,0 +1,129 @@
+package di_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingStore struct {
+	insts  map[string]di.Instance
+	stored []string
+	mu     sync.Mutex
+}
+
+func (s *recordingStore) Load(svc *di.ServiceInfo) (di.Instance, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inst, ok := s.insts[svc.ID]
+	return inst, ok
+}
+
+func (s *recordingStore) Store(svc *di.ServiceInfo, inst di.Instance) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.insts == nil {
+		s.insts = make(map[string]di.Instance)
+	}
+	s.insts[svc.ID] = inst
+	s.stored = append(s.stored, svc.String())
+}
+
+func Test_WithInstanceStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Singleton", func(t *testing.T) {
+		store := &recordingStore{}
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(testtypes.NewInterfaceB, di.Transient),
+			di.WithInstanceStore(func() di.InstanceStore { return store }),
+		)
+		require.NoError(t, err)
+
+		a1 := di.MustResolve[testtypes.InterfaceA](ctx, c)
+		a2 := di.MustResolve[testtypes.InterfaceA](ctx, c)
+		_ = di.MustResolve[testtypes.InterfaceB](ctx, c)
+
+		assert.Same(t, a1, a2)
+		assert.Equal(t, []string{"testtypes.InterfaceA"}, store.stored)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		store := &recordingStore{}
+		calls := 0
+		c, err := di.NewContainer(
+			di.WithService(func() (testtypes.InterfaceA, error) {
+				calls++
+				return nil, errors.New("constructor error")
+			}),
+			di.WithInstanceStore(func() di.InstanceStore { return store }),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, c)
+		assert.EqualError(t, err, "di.Container.Resolve testtypes.InterfaceA: constructor error")
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, c)
+		assert.EqualError(t, err, "di.Container.Resolve testtypes.InterfaceA: constructor error")
+
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("child scopes", func(t *testing.T) {
+		var stores []*recordingStore
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(testtypes.NewInterfaceB, di.Scoped),
+			di.WithInstanceStore(func() di.InstanceStore {
+				store := &recordingStore{}
+				stores = append(stores, store)
+				return store
+			}),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope()
+		require.NoError(t, err)
+
+		_ = di.MustResolve[testtypes.InterfaceB](ctx, scope)
+
+		require.Len(t, stores, 2)
+		assert.Equal(t, []string{"testtypes.InterfaceA"}, stores[0].stored)
+		assert.Equal(t, []string{"testtypes.InterfaceB"}, stores[1].stored)
+	})
+
+	t.Run("registered As", func(t *testing.T) {
+		store := &recordingStore{}
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewStructAPtr,
+				di.As[*testtypes.StructA](),
+				di.As[testtypes.InterfaceA](),
+			),
+			di.WithInstanceStore(func() di.InstanceStore { return store }),
+		)
+		require.NoError(t, err)
+
+		a := di.MustResolve[testtypes.InterfaceA](ctx, c)
+		assert.Same(t, a, di.MustResolve[*testtypes.StructA](ctx, c))
+		assert.Equal(t, []string{"*testtypes.StructA"}, store.stored)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithInstanceStore(nil),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithInstanceStore: f is nil")
+	})
+}
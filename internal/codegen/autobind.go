@@ -0,0 +1,232 @@
+// Package codegen generates di-kit registrations from Go source code.
+//
+// It is used by the digen command.
+package codegen
+
+import (
+	"bytes"
+	"go/format"
+	"go/types"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+const diPath = "github.com/sectrean/di-kit"
+
+// Binding is an interface with exactly one implementation.
+type Binding struct {
+	// Interface is the interface type.
+	Interface *types.TypeName
+	// Impl is the type that implements Interface.
+	Impl *types.TypeName
+	// Pointer is true if the implementation is bound as *Impl.
+	Pointer bool
+}
+
+// Autobind generates a Go source file for the package in dir, declaring a [di.NamedModule] variable
+// named varName with a di.Bind option for each interface with exactly one implementation.
+// The module checks the checksum of the bindings with [di.WithWiringChecksum],
+// so it fails if the bindings no longer match what this version of di-kit registers.
+//
+// Interfaces and implementations are found in the packages matching patterns, relative to dir.
+// If no patterns are given, all packages in the module containing dir are used.
+func Autobind(dir, varName string, patterns ...string) ([]byte, error) {
+	out, err := loadPackages(dir, packages.NeedName|packages.NeedModule, ".")
+	if err != nil {
+		return nil, err
+	}
+	if len(out) != 1 {
+		return nil, errors.Errorf("autobind %s: expected one package, found %d", dir, len(out))
+	}
+	outPkg := out[0]
+
+	if len(patterns) == 0 {
+		if outPkg.Module == nil {
+			return nil, errors.Errorf("autobind %s: not in a module", dir)
+		}
+		patterns = []string{filepath.Join(outPkg.Module.Dir, "...")}
+	}
+
+	pkgs, err := loadPackages(dir, packages.NeedName|packages.NeedTypes, patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	return writeAutobind(outPkg, varName, FindBindings(pkgs))
+}
+
+func loadPackages(dir string, mode packages.LoadMode, patterns ...string) ([]*packages.Package, error) {
+	cfg := &packages.Config{
+		Dir:  dir,
+		Mode: mode,
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "load %s", strings.Join(patterns, " "))
+	}
+
+	var errs []error
+	packages.Visit(pkgs, nil, func(p *packages.Package) {
+		for _, e := range p.Errors {
+			errs = append(errs, e)
+		}
+	})
+	if len(errs) > 0 {
+		return nil, errors.Wrapf(errors.Join(errs...), "load %s", strings.Join(patterns, " "))
+	}
+
+	return pkgs, nil
+}
+
+// FindBindings returns a Binding for each exported interface declared in pkgs that is implemented
+// by exactly one exported type declared in pkgs.
+//
+// Generic interfaces, interfaces without methods, and type constraints are skipped.
+// Struct types are bound as pointers, and other types are bound as pointers only if
+// the methods have pointer receivers.
+//
+// The bindings are sorted by interface.
+func FindBindings(pkgs []*packages.Package) []Binding {
+	var ifaces, impls []*types.TypeName
+	for _, p := range pkgs {
+		scope := p.Types.Scope()
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || !tn.Exported() || tn.IsAlias() {
+				continue
+			}
+			named, ok := tn.Type().(*types.Named)
+			if !ok || named.TypeParams().Len() > 0 {
+				continue
+			}
+
+			if iface, ok := named.Underlying().(*types.Interface); ok {
+				if iface.NumMethods() > 0 && iface.IsMethodSet() {
+					ifaces = append(ifaces, tn)
+				}
+				continue
+			}
+			impls = append(impls, tn)
+		}
+	}
+
+	var bindings []Binding
+	for _, iface := range ifaces {
+		it := iface.Type().Underlying().(*types.Interface)
+
+		var found []Binding
+		for _, impl := range impls {
+			if !types.Implements(types.NewPointer(impl.Type()), it) {
+				continue
+			}
+
+			_, isStruct := impl.Type().Underlying().(*types.Struct)
+			found = append(found, Binding{
+				Interface: iface,
+				Impl:      impl,
+				Pointer:   isStruct || !types.Implements(impl.Type(), it),
+			})
+		}
+
+		if len(found) == 1 {
+			bindings = append(bindings, found[0])
+		}
+	}
+
+	slices.SortFunc(bindings, func(a, b Binding) int {
+		return strings.Compare(a.Interface.Type().String(), b.Interface.Type().String())
+	})
+	return bindings
+}
+
+func writeAutobind(out *packages.Package, varName string, bindings []Binding) ([]byte, error) {
+	// Assign a unique name to each imported package
+	imports := map[string]string{diPath: "di"}
+	used := map[string]bool{"di": true}
+	var paths []string
+	qualifier := func(p *types.Package) string {
+		if p.Path() == out.PkgPath {
+			return ""
+		}
+		if name, ok := imports[p.Path()]; ok {
+			return name
+		}
+
+		name := p.Name()
+		for i := 2; used[name]; i++ {
+			name = p.Name() + strconv.Itoa(i)
+		}
+		imports[p.Path()] = name
+		used[name] = true
+		paths = append(paths, p.Path())
+		return name
+	}
+
+	var body bytes.Buffer
+	for _, b := range bindings {
+		body.WriteString("\tdi.Bind[" + types.TypeString(b.Interface.Type(), qualifier) + ", " +
+			implString(b, qualifier) + "](),\n")
+	}
+	body.WriteString("\tdi.WithWiringChecksum(" + strconv.Quote(checksum(bindings)) + "),\n")
+
+	var src bytes.Buffer
+	src.WriteString("// Code generated by digen -autobind. DO NOT EDIT.\n\n")
+	src.WriteString("package " + out.Name + "\n\n")
+	src.WriteString("import (\n")
+	src.WriteString("\t" + strconv.Quote(diPath) + "\n")
+	slices.Sort(paths)
+	for _, p := range paths {
+		if name := imports[p]; name != path.Base(p) {
+			src.WriteString("\t" + name + " " + strconv.Quote(p) + "\n")
+		} else {
+			src.WriteString("\t" + strconv.Quote(p) + "\n")
+		}
+	}
+	src.WriteString(")\n\n")
+	src.WriteString("// " + varName + " binds each interface with exactly one implementation in the module to that implementation.\n")
+	src.WriteString("var " + varName + " = di.NamedModule(" + strconv.Quote(out.Name+"."+varName) + ",\n")
+	src.Write(body.Bytes())
+	src.WriteString(")\n")
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, errors.Wrap(err, "format generated source")
+	}
+	return formatted, nil
+}
+
+// implString returns the implementation type of the binding, qualified with qualifier.
+func implString(b Binding, qualifier types.Qualifier) string {
+	impl := types.TypeString(b.Impl.Type(), qualifier)
+	if b.Pointer {
+		return "*" + impl
+	}
+	return impl
+}
+
+// checksum returns the [di.Manifest.Checksum] of the services registered by the bindings.
+func checksum(bindings []Binding) string {
+	// Qualify types with the package name, like reflect.Type.String
+	qualifier := func(p *types.Package) string {
+		return p.Name()
+	}
+
+	// di.Bind registers a Transient service that depends on the context and the implementation
+	m := di.Manifest{Services: make([]di.ManifestService, len(bindings))}
+	for i, b := range bindings {
+		m.Services[i] = di.ManifestService{
+			Type:         types.TypeString(b.Interface.Type(), qualifier),
+			Lifetime:     di.Transient.String(),
+			Dependencies: []string{"context.Context", implString(b, qualifier)},
+		}
+	}
+	return m.Checksum()
+}
@@ -0,0 +1,80 @@
+package codegen_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/codegen"
+	"github.com/sectrean/di-kit/internal/codegen/testdata/autobind/app"
+	"github.com/sectrean/di-kit/internal/codegen/testdata/autobind/logging"
+	"github.com/sectrean/di-kit/internal/codegen/testdata/autobind/store"
+)
+
+// checksum is the checksum of the bindings generated for the testdata packages.
+const checksum = "cd6e08ee6ac3b97fea36c8db86377e5a03d016e0ed0c9004ab48a094cf19fafa"
+
+func Test_Autobind(t *testing.T) {
+	src, err := codegen.Autobind("testdata/autobind/app", "AutoBindings", "../...")
+	require.NoError(t, err)
+
+	want := `// Code generated by digen -autobind. DO NOT EDIT.
+
+package app
+
+import (
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/codegen/testdata/autobind/logging"
+	"github.com/sectrean/di-kit/internal/codegen/testdata/autobind/store"
+)
+
+// AutoBindings binds each interface with exactly one implementation in the module to that implementation.
+var AutoBindings = di.NamedModule("app.AutoBindings",
+	di.Bind[Runner, *App](),
+	di.Bind[logging.Logger, *logging.StdLogger](),
+	di.Bind[store.Clock, store.SystemClock](),
+	di.Bind[store.Store, *store.DBStore](),
+	di.WithWiringChecksum("` + checksum + `"),
+)
+`
+	assert.Equal(t, want, string(src))
+}
+
+func Test_Autobind_Checksum(t *testing.T) {
+	// The generated module, as it is compiled in the app package
+	autoBindings := di.NamedModule("app.AutoBindings",
+		di.Bind[app.Runner, *app.App](),
+		di.Bind[logging.Logger, *logging.StdLogger](),
+		di.Bind[store.Clock, store.SystemClock](),
+		di.Bind[store.Store, *store.DBStore](),
+		di.WithWiringChecksum(checksum),
+	)
+
+	t.Run("match", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(&app.App{}),
+			autoBindings,
+		)
+		require.NoError(t, err)
+		assert.NotNil(t, c)
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.NamedModule("app.AutoBindings",
+				di.Bind[app.Runner, *app.App](),
+				di.WithWiringChecksum(checksum),
+			),
+		)
+		assert.Nil(t, c)
+		assert.ErrorContains(t, err, "WithWiringChecksum: registered services have changed")
+	})
+}
+
+func Test_Autobind_LoadError(t *testing.T) {
+	src, err := codegen.Autobind("testdata/autobind/app", "AutoBindings", "../missing")
+	assert.Error(t, err)
+	assert.Nil(t, src)
+}
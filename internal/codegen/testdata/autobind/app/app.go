@@ -0,0 +1,21 @@
+package app
+
+// Runner is implemented in the output package.
+type Runner interface {
+	Run() error
+}
+
+type App struct{}
+
+func (*App) Run() error { return nil }
+
+// Handler only has an unexported implementation, so it is not bound.
+type Handler interface {
+	Handle()
+}
+
+type handler struct{}
+
+func (handler) Handle() {}
+
+var _ Handler = handler{}
@@ -0,0 +1,10 @@
+package logging
+
+// Logger is implemented with a value receiver on a struct type.
+type Logger interface {
+	Log(msg string)
+}
+
+type StdLogger struct{}
+
+func (StdLogger) Log(string) {}
@@ -0,0 +1,51 @@
+package store
+
+import "time"
+
+// Store has a single implementation.
+type Store interface {
+	Load(key string) (string, error)
+}
+
+type DBStore struct{}
+
+func (*DBStore) Load(string) (string, error) { return "", nil }
+
+// Cache has two implementations, so it is not bound.
+type Cache interface {
+	Get(key string) (string, bool)
+	Set(key, val string)
+}
+
+type MemoryCache struct{}
+
+func (*MemoryCache) Get(string) (string, bool) { return "", false }
+func (*MemoryCache) Set(string, string)        {}
+
+type RedisCache struct{}
+
+func (*RedisCache) Get(string) (string, bool) { return "", false }
+func (*RedisCache) Set(string, string)        {}
+
+// Clock is implemented with a value receiver on a non-struct type.
+type Clock interface {
+	Now() time.Time
+}
+
+type SystemClock int
+
+func (SystemClock) Now() time.Time { return time.Now() }
+
+// Generic interfaces and empty interfaces are not bound.
+type Getter[T any] interface {
+	Get() T
+}
+
+type Any interface{}
+
+// Unexported interfaces are not bound.
+type loader interface {
+	Load(key string) (string, error)
+}
+
+var _ loader = (*DBStore)(nil)
@@ -21,27 +21,56 @@ func Errorf(format string, a ...any) error {
 
 // Wrap returns an error with the given message and wraps the original error.
 //
+// If the original error is a multi-error, the returned error unwraps to the same errors.
+//
 // Returns nil if the original error is nil.
 func Wrap(err error, msg string) error {
 	if err == nil {
 		return nil
 	}
 
+	if multi, ok := err.(interface{ Unwrap() []error }); ok {
+		return &multiWrapError{msg: msg, err: err, errs: multi.Unwrap()}
+	}
+
 	return fmt.Errorf("%s: %w", msg, err)
 }
 
 // Wrapf returns an error with a formatted message and wraps the original error.
 //
+// If the original error is a multi-error, the returned error unwraps to the same errors.
+//
 // Returns nil if the original error is nil.
 func Wrapf(err error, format string, a ...any) error {
 	if err == nil {
 		return nil
 	}
 
-	return fmt.Errorf("%s: %w", fmt.Sprintf(format, a...), err)
+	return Wrap(err, fmt.Sprintf(format, a...))
 }
 
 // Join multiple errors together.
 func Join(errs ...error) error {
 	return stderrors.Join(errs...)
 }
+
+// As finds the first error in err's tree that matches target, and if one is found,
+// sets target to that error value and returns true.
+func As(err error, target any) bool {
+	return stderrors.As(err, target)
+}
+
+// multiWrapError adds a message to a multi-error, but still unwraps to the individual errors.
+type multiWrapError struct {
+	err  error
+	msg  string
+	errs []error
+}
+
+func (e *multiWrapError) Error() string {
+	return e.msg + ": " + e.err.Error()
+}
+
+func (e *multiWrapError) Unwrap() []error {
+	return e.errs
+}
@@ -0,0 +1,86 @@
+// Package scopecall creates and closes the child scope used for each call by the adapter packages,
+// like a Lambda invocation, an activity execution, or a reconcile call.
+//
+// It is used by the dicontroller, dievent, digraphql, dilambda, and ditemporal packages.
+package scopecall
+
+import (
+	"context"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/dicontext"
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// Config holds the options used to create the scope for each call.
+//
+// Adapter packages embed it in the config set by their options.
+type Config struct {
+	opts    []di.ContainerOption
+	ctxOpts []func(context.Context) di.ContainerOption
+}
+
+// AddContainerOptions adds options to use when creating each scope.
+func (c *Config) AddContainerOptions(opts ...di.ContainerOption) {
+	c.opts = append(c.opts, opts...)
+}
+
+// AddContextOption adds a function that returns an option from the context of each call.
+func (c *Config) AddContextOption(f func(context.Context) di.ContainerOption) {
+	c.ctxOpts = append(c.ctxOpts, f)
+}
+
+// ContainerOptions returns the options to create a scope for a call with ctx, followed by extra.
+func (c *Config) ContainerOptions(ctx context.Context, extra ...di.ContainerOption) []di.ContainerOption {
+	opts := make([]di.ContainerOption, 0, len(c.opts)+len(c.ctxOpts)+len(extra))
+	opts = append(opts, c.opts...)
+	for _, f := range c.ctxOpts {
+		opts = append(opts, f(ctx))
+	}
+	opts = append(opts, extra...)
+
+	return opts
+}
+
+// NewScope creates a child scope of parent for a call with ctx.
+func (c *Config) NewScope(
+	ctx context.Context,
+	parent di.ContainerInterface,
+	extra ...di.ContainerOption,
+) (di.ContainerInterface, error) {
+	return parent.NewChildScope(c.ContainerOptions(ctx, extra...)...)
+}
+
+// Close closes the scope created for a call after it returns.
+//
+// The scope is closed even if ctx has been canceled. See [di.CloseWithGrace] for more information.
+func Close(ctx context.Context, scope di.Closer) error {
+	return di.CloseWithGrace(ctx, scope, di.DefaultCloseGracePeriod)
+}
+
+// Call creates a child scope of parent, calls fn with the scope stored on the context,
+// and closes the scope after fn returns.
+//
+// Errors creating or closing the scope are wrapped with name and returned.
+func Call[Out any](
+	ctx context.Context,
+	parent di.ContainerInterface,
+	cfg *Config,
+	name string,
+	fn func(context.Context) (Out, error),
+	extra ...di.ContainerOption,
+) (out Out, err error) {
+	scope, err := cfg.NewScope(ctx, parent, extra...)
+	if err != nil {
+		return out, errors.Wrap(err, name)
+	}
+
+	defer func() {
+		closeErr := Close(ctx, scope)
+		if closeErr != nil {
+			err = errors.Join(err, errors.Wrap(closeErr, name))
+		}
+	}()
+
+	return fn(dicontext.WithScope(ctx, scope))
+}
@@ -0,0 +1,78 @@
+package scopecall_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/dicontext"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/scopecall"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Call(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("options", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		var cfg scopecall.Config
+		cfg.AddContainerOptions(di.WithService(testtypes.NewInterfaceA))
+		cfg.AddContextOption(func(context.Context) di.ContainerOption {
+			return di.WithService(testtypes.NewInterfaceB)
+		})
+
+		got, err := scopecall.Call(ctx, c, &cfg, "test", func(ctx context.Context) (int, error) {
+			_ = dicontext.MustResolve[testtypes.InterfaceA](ctx)
+			_ = dicontext.MustResolve[testtypes.InterfaceB](ctx)
+			_ = dicontext.MustResolve[testtypes.InterfaceC](ctx)
+			return 1, nil
+		}, di.WithService(testtypes.NewInterfaceC))
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, got)
+		assert.False(t, c.Contains(reflect.TypeFor[testtypes.InterfaceA]()))
+	})
+
+	t.Run("NewScope error", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		var cfg scopecall.Config
+		cfg.AddContainerOptions(di.WithService(nil))
+
+		_, err = scopecall.Call(ctx, c, &cfg, "test", func(context.Context) (int, error) {
+			assert.Fail(t, "fn should not get called")
+			return 0, nil
+		})
+		testutils.LogError(t, err)
+
+		assert.EqualError(t, err, "test: di.Container.NewScope: WithService: funcOrValue is nil")
+	})
+
+	t.Run("Close error", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		var cfg scopecall.Config
+		cfg.AddContainerOptions(di.WithService(testtypes.NewStructAPtr,
+			di.UseCloseFunc(func(context.Context, *testtypes.StructA) error {
+				return errors.New("close error")
+			}),
+		))
+
+		_, err = scopecall.Call(ctx, c, &cfg, "test", func(ctx context.Context) (int, error) {
+			_ = dicontext.MustResolve[*testtypes.StructA](ctx)
+			return 0, errors.New("call error")
+		})
+		testutils.LogError(t, err)
+
+		assert.EqualError(t, err, "call error\ntest: di.Container.Close: close error")
+	})
+}
@@ -25,6 +25,11 @@ func ContextWithTestValue(ctx context.Context, val any) context.Context {
 	return context.WithValue(ctx, ctxKey{}, val)
 }
 
+// TestValue returns the value added to the context with ContextWithTestValue.
+func TestValue(ctx context.Context) any {
+	return ctx.Value(ctxKey{})
+}
+
 // RunParallel runs a function in parallel with the given concurrency.
 func RunParallel(concurrency int, f func(int)) {
 	wg := sync.WaitGroup{}
@@ -3,6 +3,7 @@ package di
 import (
 	"context"
 	"reflect"
+	"sync"
 
 	"github.com/sectrean/di-kit/internal/errors"
 )
@@ -12,6 +13,10 @@ import (
 // The function may take any number of parameters which will be resolved from the container,
 // and may return any number of results.
 // An [error] return parameter will be passed along and any other return parameters are ignored.
+//
+// Like constructor functions, the function may accept a [context.Context] or [Scope].
+// The Scope passed is s, which may be used to resolve services while the function runs.
+// If the function is variadic, the variadic services are optional.
 func Invoke(ctx context.Context, s Scope, fn any, opts ...InvokeOption) error {
 	fnType := reflect.TypeOf(fn)
 	fnVal := reflect.ValueOf(fn)
@@ -44,6 +49,8 @@ func Invoke(ctx context.Context, s Scope, fn any, opts ...InvokeOption) error {
 	}
 
 	// Resolve deps from the Scope
+	c, isContainer := s.(*Container)
+	variadic := fnType.IsVariadic()
 	in := make([]reflect.Value, fnType.NumIn())
 	for i, dep := range config.deps {
 		var depVal any
@@ -52,10 +59,24 @@ func Invoke(ctx context.Context, s Scope, fn any, opts ...InvokeOption) error {
 		switch {
 		case dep.Type == typeContext:
 			depVal = ctx
-		case dep.Tag != nil:
-			depVal, depErr = s.Resolve(ctx, dep.Type, WithTag(dep.Tag))
+		case dep.Type == typeScope:
+			// The function isn't called while any locks are held,
+			// so the Scope can be used right away unlike in a constructor function.
+			depVal = s
+		case variadic && i == len(config.deps)-1 && !s.Contains(dep.Type, dep.resolveOptions()...):
+			// Variadic services are optional
+			depVal = nil
+		case isZeroDefault(config.zeroDeps, i) && !s.Contains(dep.Type, dep.resolveOptions()...):
+			depVal = nil
+		case isContainer:
+			// Invoke is allowed on the root Container with WithRequireScope
+			depVal, depErr = c.resolve(ctx, c.serviceKeyFor(dep.Type, dep.resolveOptions()))
 		default:
-			depVal, depErr = s.Resolve(ctx, dep.Type)
+			depVal, depErr = s.Resolve(ctx, dep.Type, dep.resolveOptions()...)
+		}
+
+		if n := minCount(config.minCounts, i); n > 0 && depErr == nil {
+			depErr = errors.Wrapf(checkCount(n, depVal), "dependency %s", dep)
 		}
 
 		if depErr != nil {
@@ -71,7 +92,12 @@ func Invoke(ctx context.Context, s Scope, fn any, opts ...InvokeOption) error {
 	}
 
 	// Invoke the function
-	out := fnVal.Call(in)
+	var out []reflect.Value
+	if variadic {
+		out = fnVal.CallSlice(in)
+	} else {
+		out = fnVal.Call(in)
+	}
 
 	// Return the first error return value, if any.
 	// Don't wrap the error, return it as-is.
@@ -85,12 +111,138 @@ func Invoke(ctx context.Context, s Scope, fn any, opts ...InvokeOption) error {
 	return nil
 }
 
+// InvokeOnce calls the given function like [Invoke], but only once for each [Container].
+//
+// This is useful for initialization routines like migrations or cache warmers that may be
+// invoked from several places, but should only run once per Container lifetime.
+// Subsequent calls with the same key return the error returned by the first call, if any.
+// If the first call panics, the panic is returned as an error. Concurrent calls wait for the first call to complete.
+//
+// Calls are identified by the key passed with [OnceKey]. Without a key, a top-level function is identified by itself.
+// Closures and method values must have a key, since two closures created from the same function literal
+// can capture different values, and two method values can have different receivers.
+//
+// Example:
+//
+//	err := di.InvokeOnce(ctx, c, db.Migrate)
+//	err = di.InvokeOnce(ctx, c, func(db *sql.DB) error {
+//		return migrate(db, dir)
+//	}, di.OnceKey("migrate"))
+//
+// This will return an error if fn is not a function, fn is a closure or method value without a key,
+// or the Scope is not a [Container] or a [Scope] injected by a Container.
+func InvokeOnce(ctx context.Context, s Scope, fn any, opts ...InvokeOption) error {
+	fnVal := reflect.ValueOf(fn)
+	if fnVal.Kind() != reflect.Func {
+		return errors.Errorf("di.InvokeOnce %T: fn must be a function", fn)
+	}
+
+	var c *Container
+	switch scope := s.(type) {
+	case *Container:
+		c = scope
+	case *injectedScope:
+		c = scope.scope
+	default:
+		return errors.Errorf("di.InvokeOnce %T: scope %T not supported", fn, s)
+	}
+
+	// Find the key, and pass the other options to Invoke
+	var key any
+	invokeOpts := make([]InvokeOption, 0, len(opts))
+	for _, opt := range opts {
+		if k, ok := opt.(onceKeyOption); ok {
+			if err := k.validate(); err != nil {
+				return errors.Wrapf(err, "di.InvokeOnce %T", fn)
+			}
+			key = k.key
+			continue
+		}
+		invokeOpts = append(invokeOpts, opt)
+	}
+	if key == nil {
+		if !isTopLevelFunc(fnVal) {
+			return errors.Errorf("di.InvokeOnce %T: closure or method value requires OnceKey", fn)
+		}
+		key = invokeFuncKey(fnVal.Pointer())
+	}
+
+	res := c.invokeOnce(key)
+	res.once.Do(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				res.err = errors.Errorf("di.InvokeOnce %T: function panicked: %v", fn, r)
+			}
+		}()
+
+		res.err = Invoke(ctx, s, fn, invokeOpts...)
+	})
+
+	return res.err
+}
+
+// OnceKey identifies a call to [InvokeOnce].
+//
+// Calls with the same key are only invoked once for each [Container], even with different functions.
+// It is required to call InvokeOnce with a closure or method value.
+//
+// This option will return an error if key is nil or not comparable, or it is used with [Invoke].
+func OnceKey(key any) InvokeOption {
+	return onceKeyOption{key: key}
+}
+
+type onceKeyOption struct {
+	key any
+}
+
+func (o onceKeyOption) applyInvokeConfig(*invokeConfig) error {
+	return errors.New("OnceKey: only supported by InvokeOnce")
+}
+
+func (o onceKeyOption) validate() error {
+	switch {
+	case o.key == nil:
+		return errors.New("OnceKey: key is nil")
+	case !reflect.TypeOf(o.key).Comparable():
+		return errors.Errorf("OnceKey: key %T is not comparable", o.key)
+	default:
+		return nil
+	}
+}
+
+// invokeFuncKey identifies a call to [InvokeOnce] with a top-level function and no [OnceKey].
+type invokeFuncKey uintptr
+
+type invokeOnceResult struct {
+	err  error
+	once sync.Once
+}
+
+func (c *Container) invokeOnce(key any) *invokeOnceResult {
+	c.invokedMu.Lock()
+	defer c.invokedMu.Unlock()
+
+	if c.invoked == nil {
+		c.invoked = make(map[any]*invokeOnceResult)
+	}
+
+	res, ok := c.invoked[key]
+	if !ok {
+		res = &invokeOnceResult{}
+		c.invoked[key] = res
+	}
+
+	return res
+}
+
 // InvokeOption is used to configure the behavior of Invoke.
 type InvokeOption interface {
 	applyInvokeConfig(*invokeConfig) error
 }
 
 type invokeConfig struct {
-	fn   reflect.Value
-	deps []serviceKey
+	fn        reflect.Value
+	deps      []serviceKey
+	zeroDeps  []bool
+	minCounts []int
 }
@@ -2,6 +2,7 @@ package di_test
 
 import (
 	"context"
+	"sync"
 	"testing"
 
 	"github.com/sectrean/di-kit"
@@ -113,6 +114,63 @@ func Test_Invoke(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("di.Scope dependency", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		err = di.Invoke(ctx, c, func(s di.Scope) error {
+			assert.Same(t, c, s)
+
+			// The Scope can be used within the function
+			_, resolveErr := di.Resolve[testtypes.InterfaceA](ctx, s)
+			return resolveErr
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("variadic dependency", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		err = di.Invoke(ctx, c, func(as ...testtypes.InterfaceA) {
+			assert.Len(t, as, 2)
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("variadic dependency not registered", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		called := false
+		ctx := context.Background()
+		err = di.Invoke(ctx, c, func(bs ...testtypes.InterfaceB) {
+			assert.Empty(t, bs)
+			called = true
+		})
+		assert.NoError(t, err)
+		assert.True(t, called)
+	})
+
+	t.Run("slice dependency not registered", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		err = di.Invoke(ctx, c, func([]testtypes.InterfaceB) {})
+		testutils.LogError(t, err)
+
+		assert.EqualError(t, err, "di.Invoke func([]testtypes.InterfaceB): "+
+			"di.Container.Resolve []testtypes.InterfaceB: service not registered")
+	})
+
 	t.Run("context canceled", func(t *testing.T) {
 		c, err := di.NewContainer(
 			di.WithService(testtypes.NewInterfaceA),
@@ -188,3 +246,186 @@ func Test_Invoke(t *testing.T) {
 		assert.EqualError(t, err, "di.Invoke func(testtypes.InterfaceA): WithTagged testtypes.InterfaceB: parameter not found")
 	})
 }
+
+func Test_InvokeOnce(t *testing.T) {
+	migrate := func(calls *int) func(testtypes.InterfaceA) error {
+		return func(testtypes.InterfaceA) error {
+			*calls++
+			return nil
+		}
+	}
+
+	t.Run("called once per container", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+
+		calls := 0
+		fn := migrate(&calls)
+
+		ctx := context.Background()
+		err = di.InvokeOnce(ctx, c, fn, di.OnceKey("migrate"))
+		assert.NoError(t, err)
+		err = di.InvokeOnce(ctx, c, fn, di.OnceKey("migrate"))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, calls)
+
+		c2, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+
+		err = di.InvokeOnce(ctx, c2, fn, di.OnceKey("migrate"))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("child scope", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope()
+		require.NoError(t, err)
+
+		calls := 0
+		fn := migrate(&calls)
+
+		ctx := context.Background()
+		assert.NoError(t, di.InvokeOnce(ctx, c, fn, di.OnceKey("migrate")))
+		assert.NoError(t, di.InvokeOnce(ctx, scope, fn, di.OnceKey("migrate")))
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("error cached", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		calls := 0
+		fn := func() error {
+			calls++
+			return errors.New("invoke error")
+		}
+
+		ctx := context.Background()
+		err = di.InvokeOnce(ctx, c, fn, di.OnceKey("fn"))
+		assert.EqualError(t, err, "invoke error")
+		err = di.InvokeOnce(ctx, c, fn, di.OnceKey("fn"))
+		assert.EqualError(t, err, "invoke error")
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+
+		var mu sync.Mutex
+		calls := 0
+		fn := func(testtypes.InterfaceA) {
+			mu.Lock()
+			calls++
+			mu.Unlock()
+		}
+
+		testutils.RunParallel(100, func(int) {
+			assert.NoError(t, di.InvokeOnce(context.Background(), c, fn, di.OnceKey("fn")))
+		})
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("top-level function", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		assert.NoError(t, di.InvokeOnce(ctx, c, testtypes.NewInterfaceB))
+		assert.NoError(t, di.InvokeOnce(ctx, c, testtypes.NewInterfaceB))
+	})
+
+	t.Run("closures with different keys", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+
+		calls1, calls2 := 0, 0
+
+		ctx := context.Background()
+		assert.NoError(t, di.InvokeOnce(ctx, c, migrate(&calls1), di.OnceKey("first")))
+		assert.NoError(t, di.InvokeOnce(ctx, c, migrate(&calls2), di.OnceKey("second")))
+		assert.Equal(t, 1, calls1)
+		assert.Equal(t, 1, calls2)
+	})
+
+	t.Run("closure without key", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		err = di.InvokeOnce(context.Background(), c, func() {})
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.InvokeOnce func(): closure or method value requires OnceKey")
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		calls := 0
+		fn := func() {
+			calls++
+			panic("invoke panic")
+		}
+
+		ctx := context.Background()
+		err = di.InvokeOnce(ctx, c, fn, di.OnceKey("fn"))
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.InvokeOnce func(): function panicked: invoke panic")
+
+		err = di.InvokeOnce(ctx, c, fn, di.OnceKey("fn"))
+		assert.EqualError(t, err, "di.InvokeOnce func(): function panicked: invoke panic")
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("OnceKey errors", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		err = di.InvokeOnce(ctx, c, func() {}, di.OnceKey(nil))
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.InvokeOnce func(): OnceKey: key is nil")
+
+		err = di.InvokeOnce(ctx, c, func() {}, di.OnceKey([]string{"a"}))
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.InvokeOnce func(): OnceKey: key []string is not comparable")
+
+		err = di.Invoke(ctx, c, func() {}, di.OnceKey("fn"))
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Invoke func(): OnceKey: only supported by InvokeOnce")
+	})
+
+	t.Run("not func", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		err = di.InvokeOnce(context.Background(), c, 1234)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.InvokeOnce int: fn must be a function")
+	})
+
+	t.Run("scope not supported", func(t *testing.T) {
+		err := di.InvokeOnce(context.Background(), fakeScope{}, func() {}, di.OnceKey("fn"))
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.InvokeOnce func(): scope di_test.fakeScope not supported")
+	})
+}
+
+type fakeScope struct {
+	di.Scope
+}
@@ -0,0 +1,507 @@
+package di
+
+import (
+	"container/list"
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// DefaultKeyedCacheLimit is the maximum number of instances cached for a service registered with [SingletonPer].
+const DefaultKeyedCacheLimit = 128
+
+// SingletonPer specifies that a [Singleton] service is created once per key when calling [WithService].
+//
+// The key function is called with the context passed to Resolve each time the service is resolved.
+// One instance of the service is cached for each key with the [Container] the service is registered with,
+// so this can be used for services like per-tenant connection pools without creating a scope per tenant.
+//
+// Up to [DefaultKeyedCacheLimit] instances are cached. Use [WithCacheLimit] to change the limit.
+// When the limit is reached, the least recently used instance is evicted and closed asynchronously.
+// Use [WithCacheTTL] to also expire instances after a duration.
+// The remaining instances are closed when the Container is closed.
+// Errors returned from closing evicted instances are returned when the Container is closed.
+// Use [Container.CacheStats] to monitor the cache.
+//
+// Errors returned from the constructor function are not cached.
+//
+// This is an experimental feature, so it must be enabled with [Experimental] and [ExperimentKeyedSingletons].
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.Experimental(di.ExperimentKeyedSingletons),
+//		di.WithService(db.NewTenantPool, // NewTenantPool(context.Context, *db.Config) (*db.Pool, error)
+//			di.SingletonPer(tenant.IDFromContext), // IDFromContext(context.Context) any
+//		),
+//	)
+//
+// This option will return an error if the key function is nil, the service is a value service,
+// or the service is not a [Singleton].
+// Resolving the service will return an error if the key returned is not comparable.
+func SingletonPer(key func(ctx context.Context) any) ServiceOption {
+	return serviceOption(func(s *service) error {
+		if key == nil {
+			return errors.New("SingletonPer: key function is nil")
+		}
+		if s.IsValue() {
+			return errors.New("SingletonPer: not supported for value service")
+		}
+
+		s.keyed = &keyedCache{
+			key:      key,
+			limit:    DefaultKeyedCacheLimit,
+			entries:  make(map[any]*list.Element),
+			inflight: make(map[any]*keyedCall),
+			order:    list.New(),
+		}
+		return nil
+	})
+}
+
+// WithCacheLimit sets the maximum number of instances cached for a service registered with [SingletonPer]
+// when calling [WithService].
+//
+// When the limit is reached, the least recently used instance is evicted and closed asynchronously.
+// This caps memory used by services with a high number of keys.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithService(db.NewTenantPool,
+//			di.SingletonPer(tenant.IDFromContext),
+//			di.WithCacheLimit(1000),
+//		),
+//	)
+//
+// This option will return an error if the limit is not positive, or the service is not registered with [SingletonPer].
+func WithCacheLimit(n int) ServiceOption {
+	return serviceOption(func(s *service) error {
+		if n <= 0 {
+			return errors.Errorf("WithCacheLimit %d: limit must be positive", n)
+		}
+
+		s.cacheLimit = n
+		return nil
+	})
+}
+
+// WithCacheTTL sets how long an instance is cached for a service registered with [SingletonPer]
+// when calling [WithService].
+//
+// The duration starts when the instance is created. An expired instance is not resolved again;
+// a new instance is created for the key instead.
+// A background janitor owned by the [Container] closes expired instances proactively,
+// so idle keys don't hold resources until the next resolve or until the cache is full.
+// The janitor is started when the first instance is cached, and stopped when the Container is closed.
+// Errors returned from closing expired instances are returned when the Container is closed.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithService(db.NewTenantPool,
+//			di.SingletonPer(tenant.IDFromContext),
+//			di.WithCacheTTL(10*time.Minute),
+//		),
+//	)
+//
+// This option will return an error if the duration is not positive, or the service is not registered with [SingletonPer].
+func WithCacheTTL(d time.Duration) ServiceOption {
+	return serviceOption(func(s *service) error {
+		if d <= 0 {
+			return errors.Errorf("WithCacheTTL %s: duration must be positive", d)
+		}
+
+		s.cacheTTL = d
+		return nil
+	})
+}
+
+// CacheStats reports the state of the instance cache for a service registered with [SingletonPer].
+//
+// See [Container.CacheStats] for more information.
+type CacheStats struct {
+	// Size is the number of instances currently cached.
+	Size int
+	// Limit is the maximum number of instances cached.
+	Limit int
+	// Hits is the number of times a cached instance was resolved.
+	Hits uint64
+	// Misses is the number of times an instance was created because none was cached.
+	Misses uint64
+	// Evictions is the number of instances evicted because the cache was full.
+	Evictions uint64
+	// Expirations is the number of instances removed because they expired.
+	Expirations uint64
+}
+
+// CacheStats returns the [CacheStats] for the service registered with [SingletonPer] for the given [reflect.Type].
+//
+// It returns false if the service is not registered, or was not registered with [SingletonPer].
+//
+// Available options:
+//   - [WithTag] specifies a key associated with the service.
+func (c *Container) CacheStats(t reflect.Type, opts ...ResolveOption) (CacheStats, bool) {
+	svc := c.lookupService(c.resolvableKey(c.serviceKeyFor(t, opts)))
+	if svc == nil || svc.Keyed() == nil {
+		return CacheStats{}, false
+	}
+
+	return svc.Keyed().Stats(), true
+}
+
+type keyedEntry struct {
+	Key     any
+	Val     any
+	Closer  Closer
+	Expires time.Time
+}
+
+// Expired returns true if the entry has a TTL and it has passed.
+func (e *keyedEntry) Expired(now time.Time) bool {
+	return !e.Expires.IsZero() && !now.Before(e.Expires)
+}
+
+// keyedCall is an instance being created for a key.
+// Other goroutines resolving the same key wait for done instead of calling the constructor function again.
+type keyedCall struct {
+	done     chan struct{}
+	val      any
+	err      error
+	canceled bool
+}
+
+// keyedCache is a least recently used cache of service instances by key.
+type keyedCache struct {
+	key         func(context.Context) any
+	clock       Clock
+	entries     map[any]*list.Element
+	inflight    map[any]*keyedCall
+	order       *list.List
+	janitor     chan struct{}
+	janitorDone chan struct{}
+	evictErrs   []error
+	evicting    sync.WaitGroup
+	hits        uint64
+	misses      uint64
+	evictions   uint64
+	expirations uint64
+	ttl         time.Duration
+	limit       int
+	dropped     int
+	mu          sync.Mutex
+	registered  bool
+	closed      bool
+}
+
+// Key returns the cache key for the context.
+func (k *keyedCache) Key(ctx context.Context) (any, error) {
+	key := k.key(ctx)
+	if key != nil && !reflect.TypeOf(key).Comparable() {
+		return nil, errors.Errorf("SingletonPer: key %T is not comparable", key)
+	}
+
+	return key, nil
+}
+
+// Load returns the cached instance for the key if it hasn't expired at now, and marks it as most recently used.
+// The lock must be held by the caller.
+func (k *keyedCache) Load(key any, now time.Time) (any, bool) {
+	elem, ok := k.entries[key]
+	if !ok || elem.Value.(*keyedEntry).Expired(now) {
+		// An expired instance is replaced and closed by Store
+		return nil, false
+	}
+
+	k.hits++
+	k.order.MoveToFront(elem)
+	return elem.Value.(*keyedEntry).Val, true
+}
+
+// Store an instance for the key at now and evict the least recently used instances over the limit.
+// An expired instance for the key is replaced.
+// The evicted instances are returned so they can be closed.
+// The lock must be held by the caller.
+func (k *keyedCache) Store(key, val any, closer Closer, now time.Time) []*keyedEntry {
+	evicted := make([]*keyedEntry, 0, max(k.order.Len()+1-k.limit, 0))
+	if elem, ok := k.entries[key]; ok {
+		evicted = append(evicted, k.order.Remove(elem).(*keyedEntry))
+		k.expirations++
+	}
+
+	entry := &keyedEntry{
+		Key:    key,
+		Val:    val,
+		Closer: closer,
+	}
+	if k.ttl > 0 {
+		entry.Expires = now.Add(k.ttl)
+	}
+
+	k.misses++
+	k.entries[key] = k.order.PushFront(entry)
+
+	for k.order.Len() > k.limit {
+		entry := k.order.Remove(k.order.Back()).(*keyedEntry)
+		delete(k.entries, entry.Key)
+		evicted = append(evicted, entry)
+		k.evictions++
+	}
+
+	return evicted
+}
+
+// RemoveExpired removes the instances expired by the Clock and returns them so they can be closed.
+func (k *keyedCache) RemoveExpired() []*keyedEntry {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := k.clock.Now()
+
+	var expired []*keyedEntry
+	for elem := k.order.Front(); elem != nil; {
+		next := elem.Next()
+
+		entry := elem.Value.(*keyedEntry)
+		if entry.Expired(now) {
+			k.order.Remove(elem)
+			delete(k.entries, entry.Key)
+			expired = append(expired, entry)
+			k.expirations++
+		}
+
+		elem = next
+	}
+
+	return expired
+}
+
+// startJanitor starts a goroutine that closes expired instances with ctx until the cache is closed.
+// The lock must be held by the caller.
+func (k *keyedCache) startJanitor(ctx context.Context) {
+	if k.ttl <= 0 || k.janitor != nil || k.closed {
+		return
+	}
+
+	k.janitor = make(chan struct{})
+	k.janitorDone = make(chan struct{})
+
+	// Check twice per TTL so instances are closed soon after they expire
+	interval := max(k.ttl/2, time.Millisecond)
+
+	go func(stop, done chan struct{}) {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				k.Evict(ctx, k.RemoveExpired())
+			}
+		}
+	}(k.janitor, k.janitorDone)
+}
+
+// stopJanitor stops the janitor goroutine and waits for it to return.
+func (k *keyedCache) stopJanitor() {
+	k.mu.Lock()
+	stop, done := k.janitor, k.janitorDone
+	k.janitor, k.janitorDone = nil, nil
+	k.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+
+	close(stop)
+	<-done
+}
+
+// Evict closes evicted instances in the background and keeps up to maxEvictErrors errors to be returned by Close.
+// The caller must hold the lock, or be the janitor, so Close waits for the instances to be closed.
+func (k *keyedCache) Evict(ctx context.Context, evicted []*keyedEntry) {
+	for _, entry := range evicted {
+		if entry.Closer == nil {
+			continue
+		}
+
+		k.evicting.Add(1)
+		go func() {
+			defer k.evicting.Done()
+
+			err := entry.Closer.Close(ctx)
+			if err != nil {
+				k.mu.Lock()
+				if len(k.evictErrs) < maxEvictErrors {
+					k.evictErrs = append(k.evictErrs, err)
+				} else {
+					k.dropped++
+				}
+				k.mu.Unlock()
+			}
+		}()
+	}
+}
+
+// Stats returns the current cache stats.
+func (k *keyedCache) Stats() CacheStats {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	return CacheStats{
+		Size:        k.order.Len(),
+		Limit:       k.limit,
+		Hits:        k.hits,
+		Misses:      k.misses,
+		Evictions:   k.evictions,
+		Expirations: k.expirations,
+	}
+}
+
+// Create the instance for the key if another goroutine hasn't already.
+// If another goroutine is creating the instance for the key, this waits for it instead.
+// The constructor function is called without holding the lock, so instances for other keys
+// can be resolved, or created, at the same time.
+// Instances evicted from the cache are closed in the background.
+func (k *keyedCache) Create(
+	ctx context.Context,
+	scope *Container,
+	svc *service,
+	key any,
+	deps []reflect.Value,
+	clock Clock,
+) (any, error) {
+	k.mu.Lock()
+	for {
+		// Check if another goroutine created the instance since the last check
+		if val, ok := k.Load(key, clock.Now()); ok {
+			k.mu.Unlock()
+			return val, nil
+		}
+
+		call, ok := k.inflight[key]
+		if !ok {
+			break
+		}
+		k.mu.Unlock()
+
+		// Wait for another goroutine creating the instance for the same key
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, context.Cause(ctx)
+		}
+
+		// Try again if the other caller's context was canceled
+		if !call.canceled {
+			return call.val, call.err
+		}
+		k.mu.Lock()
+	}
+
+	call := &keyedCall{
+		done: make(chan struct{}),
+		err:  errors.Errorf("SingletonPer: constructor function for key %v panicked", key),
+	}
+	k.inflight[key] = call
+	k.mu.Unlock()
+
+	defer func() {
+		k.mu.Lock()
+		delete(k.inflight, key)
+		k.mu.Unlock()
+
+		close(call.done)
+	}()
+
+	val, cleanup, err := scope.construct(ctx, svc, svc.Keys()[0], deps)
+	if breaker := svc.Breaker(); breaker != nil {
+		breaker.Record(err, clock.Now())
+	}
+	if err != nil {
+		call.err, call.canceled = err, ctx.Err() != nil
+		return val, err
+	}
+
+	closer := svc.CloserFor(val, cleanup)
+	closeCtx := scope.backgroundCloseContext(ctx)
+
+	k.mu.Lock()
+	if k.closed {
+		// The Container was closed while the instance was being created
+		k.mu.Unlock()
+
+		if closer != nil {
+			err = closer.Close(closeCtx)
+		}
+		err = errors.Join(errContainerClosed, err)
+		call.err = err
+		return nil, err
+	}
+
+	evicted := k.Store(key, val, closer, clock.Now())
+
+	// The janitor runs for the life of the Container, so it doesn't use the context of this call
+	k.clock = clock
+	k.startJanitor(scope.backgroundCloseContext(context.Background()))
+
+	// Close the remaining instances with the Container
+	if !k.registered {
+		k.registered = true
+
+		scope.lockClosers()
+		scope.appendCloser(k, svc)
+		scope.closersMu.Unlock()
+	}
+
+	// Start closing evicted instances before Close can wait for them
+	k.Evict(closeCtx, evicted)
+	k.mu.Unlock()
+
+	call.val, call.err = val, nil
+	return val, nil
+}
+
+// Close the remaining instances, starting with the most recently used.
+// This stops the janitor and waits for evicted instances to finish closing.
+func (k *keyedCache) Close(ctx context.Context) error {
+	k.mu.Lock()
+	k.closed = true
+	k.mu.Unlock()
+
+	k.stopJanitor()
+	k.evicting.Wait()
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	errs := k.evictErrs
+	if k.dropped > 0 {
+		errs = append(errs, errors.Errorf("%d more errors closing evicted instances", k.dropped))
+	}
+	for elem := k.order.Front(); elem != nil; elem = elem.Next() {
+		entry := elem.Value.(*keyedEntry)
+		if entry.Closer == nil {
+			continue
+		}
+
+		err := entry.Closer.Close(ctx)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	k.entries = make(map[any]*list.Element)
+	k.order.Init()
+	k.evictErrs = nil
+	k.dropped = 0
+
+	return errors.Join(errs...)
+}
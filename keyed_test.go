@@ -0,0 +1,603 @@
+package di_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/ditest"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SingletonPer(t *testing.T) {
+	t.Run("cached per key", func(t *testing.T) {
+		f := &testtypes.Factory{}
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			di.WithService(f.NewStructA, di.SingletonPer(testutils.TestValue)),
+		)
+		require.NoError(t, err)
+
+		ctxA := testutils.ContextWithTestValue(context.Background(), "tenant-a")
+		ctxB := testutils.ContextWithTestValue(context.Background(), "tenant-b")
+
+		gotA1 := di.MustResolve[*testtypes.StructA](ctxA, c)
+		gotA2 := di.MustResolve[*testtypes.StructA](ctxA, c)
+		gotB := di.MustResolve[*testtypes.StructA](ctxB, c)
+
+		assert.Same(t, gotA1, gotA2)
+		assert.NotSame(t, gotA1, gotB)
+	})
+
+	t.Run("resolved from child scope", func(t *testing.T) {
+		f := &testtypes.Factory{}
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			di.WithService(f.NewStructA, di.SingletonPer(testutils.TestValue)),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope()
+		require.NoError(t, err)
+
+		ctx := testutils.ContextWithTestValue(context.Background(), "tenant-a")
+		got1 := di.MustResolve[*testtypes.StructA](ctx, c)
+		got2 := di.MustResolve[*testtypes.StructA](ctx, scope)
+
+		assert.Same(t, got1, got2)
+	})
+
+	t.Run("evicts least recently used", func(t *testing.T) {
+		tracker := &tenantTracker{}
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			di.WithService(tracker.NewTenant, di.SingletonPer(testutils.TestValue)),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		ctxFor := func(i int) context.Context {
+			return testutils.ContextWithTestValue(ctx, i)
+		}
+
+		first := di.MustResolve[*Tenant](ctxFor(0), c)
+		for i := 1; i < di.DefaultKeyedCacheLimit; i++ {
+			_ = di.MustResolve[*Tenant](ctxFor(i), c)
+		}
+
+		// Use the first tenant so the second tenant is evicted instead
+		assert.Same(t, first, di.MustResolve[*Tenant](ctxFor(0), c))
+		_ = di.MustResolve[*Tenant](ctxFor(di.DefaultKeyedCacheLimit), c)
+
+		assert.Same(t, first, di.MustResolve[*Tenant](ctxFor(0), c))
+		assert.Equal(t, di.DefaultKeyedCacheLimit+1, tracker.Created())
+
+		// The evicted tenant is created again
+		_ = di.MustResolve[*Tenant](ctxFor(1), c)
+		assert.Equal(t, di.DefaultKeyedCacheLimit+2, tracker.Created())
+
+		assert.EventuallyWithT(t, func(t *assert.CollectT) {
+			assert.ElementsMatch(t, []any{1, 2}, tracker.Closed())
+		}, time.Second, time.Millisecond)
+	})
+
+	t.Run("WithCacheLimit", func(t *testing.T) {
+		tracker := &tenantTracker{}
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			di.WithService(tracker.NewTenant,
+				di.SingletonPer(testutils.TestValue),
+				di.WithCacheLimit(2),
+			),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		for _, key := range []string{"a", "b", "a", "c", "a"} {
+			_ = di.MustResolve[*Tenant](testutils.ContextWithTestValue(ctx, key), c)
+		}
+
+		stats, ok := c.CacheStats(reflect.TypeFor[*Tenant]())
+		assert.True(t, ok)
+		assert.Equal(t, di.CacheStats{
+			Size:      2,
+			Limit:     2,
+			Hits:      2,
+			Misses:    3,
+			Evictions: 1,
+		}, stats)
+
+		err = c.Close(ctx)
+		require.NoError(t, err)
+
+		// The evicted tenant is closed first, then the remaining tenants
+		assert.Equal(t, []any{"b", "a", "c"}, tracker.Closed())
+	})
+
+	t.Run("WithCacheLimit invalid", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			di.WithService(testtypes.NewStructAPtr, di.SingletonPer(testutils.TestValue), di.WithCacheLimit(0)),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func() *testtypes.StructA: WithCacheLimit 0: limit must be positive")
+	})
+
+	t.Run("WithCacheLimit without SingletonPer", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			di.WithService(testtypes.NewStructAPtr, di.WithCacheLimit(10)),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func() *testtypes.StructA: WithCacheLimit 10: service must use SingletonPer")
+	})
+
+	t.Run("WithCacheTTL janitor closes expired", func(t *testing.T) {
+		tracker := &tenantTracker{}
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			di.WithService(tracker.NewTenant,
+				di.SingletonPer(testutils.TestValue),
+				di.WithCacheTTL(10*time.Millisecond),
+			),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		_ = di.MustResolve[*Tenant](testutils.ContextWithTestValue(ctx, "a"), c)
+		_ = di.MustResolve[*Tenant](testutils.ContextWithTestValue(ctx, "b"), c)
+
+		// Expired tenants are closed without resolving again
+		assert.EventuallyWithT(t, func(t *assert.CollectT) {
+			assert.ElementsMatch(t, []any{"a", "b"}, tracker.Closed())
+		}, time.Second, time.Millisecond)
+
+		stats, ok := c.CacheStats(reflect.TypeFor[*Tenant]())
+		assert.True(t, ok)
+		assert.Equal(t, di.CacheStats{
+			Limit:       di.DefaultKeyedCacheLimit,
+			Misses:      2,
+			Expirations: 2,
+		}, stats)
+
+		err = c.Close(ctx)
+		require.NoError(t, err)
+		assert.Len(t, tracker.Closed(), 2)
+	})
+
+	t.Run("WithCacheTTL janitor context", func(t *testing.T) {
+		closed := make(chan any, 1)
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			di.WithService(testtypes.NewStructAPtr,
+				di.SingletonPer(testutils.TestValue),
+				di.WithCacheTTL(10*time.Millisecond),
+				di.UseCloseFunc(func(ctx context.Context, _ *testtypes.StructA) error {
+					closed <- testutils.TestValue(ctx)
+					return nil
+				}),
+			),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		_ = di.MustResolve[*testtypes.StructA](testutils.ContextWithTestValue(ctx, "a"), c)
+
+		// The janitor doesn't use the context of the first call
+		select {
+		case val := <-closed:
+			assert.Nil(t, val)
+		case <-time.After(time.Second):
+			assert.Fail(t, "expired instance not closed")
+		}
+
+		err = c.Close(ctx)
+		require.NoError(t, err)
+	})
+
+	t.Run("WithCacheTTL expired not resolved", func(t *testing.T) {
+		tracker := &tenantTracker{}
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			di.WithService(tracker.NewTenant,
+				di.SingletonPer(testutils.TestValue),
+				di.WithCacheTTL(time.Hour),
+			),
+		)
+		require.NoError(t, err)
+
+		ctx := testutils.ContextWithTestValue(context.Background(), "a")
+		got1 := di.MustResolve[*Tenant](ctx, c)
+		got2 := di.MustResolve[*Tenant](ctx, c)
+		assert.Same(t, got1, got2)
+		assert.Equal(t, 1, tracker.Created())
+
+		err = c.Close(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []any{"a"}, tracker.Closed())
+	})
+
+	t.Run("WithCacheTTL replaces expired", func(t *testing.T) {
+		tracker := &tenantTracker{}
+		clock := ditest.NewFakeClock(time.Now())
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			ditest.WithClock(clock),
+			di.WithService(tracker.NewTenant,
+				di.SingletonPer(testutils.TestValue),
+				di.WithCacheTTL(time.Hour),
+			),
+		)
+		require.NoError(t, err)
+
+		ctx := testutils.ContextWithTestValue(context.Background(), "a")
+		got1 := di.MustResolve[*Tenant](ctx, c)
+		assert.Same(t, got1, di.MustResolve[*Tenant](ctx, c))
+
+		clock.Advance(time.Hour)
+		got2 := di.MustResolve[*Tenant](ctx, c)
+
+		assert.NotSame(t, got1, got2)
+		assert.Equal(t, 2, tracker.Created())
+
+		err = c.Close(ctx)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []any{"a", "a"}, tracker.Closed())
+	})
+
+	t.Run("WithCacheTTL close error", func(t *testing.T) {
+		tracker := &tenantTracker{closeErr: errors.New("close error")}
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			di.WithService(tracker.NewTenant,
+				di.SingletonPer(testutils.TestValue),
+				di.WithCacheTTL(time.Millisecond),
+			),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		_ = di.MustResolve[*Tenant](testutils.ContextWithTestValue(ctx, "a"), c)
+
+		assert.EventuallyWithT(t, func(t *assert.CollectT) {
+			assert.Equal(t, []any{"a"}, tracker.Closed())
+		}, time.Second, time.Millisecond)
+
+		err = c.Close(ctx)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Close: close error a")
+	})
+
+	t.Run("WithCacheTTL invalid", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			di.WithService(testtypes.NewStructAPtr, di.SingletonPer(testutils.TestValue), di.WithCacheTTL(0)),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func() *testtypes.StructA: WithCacheTTL 0s: duration must be positive")
+	})
+
+	t.Run("WithCacheTTL without SingletonPer", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			di.WithService(testtypes.NewStructAPtr, di.WithCacheTTL(time.Minute)),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func() *testtypes.StructA: WithCacheTTL 1m0s: service must use SingletonPer")
+	})
+
+	t.Run("CacheStats not keyed", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			di.WithService(testtypes.NewStructAPtr),
+		)
+		require.NoError(t, err)
+
+		_, ok := c.CacheStats(reflect.TypeFor[*testtypes.StructA]())
+		assert.False(t, ok)
+
+		_, ok = c.CacheStats(reflect.TypeFor[*testtypes.StructB]())
+		assert.False(t, ok)
+	})
+
+	t.Run("closed with container", func(t *testing.T) {
+		tracker := &tenantTracker{}
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			di.WithService(tracker.NewTenant, di.SingletonPer(testutils.TestValue)),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		_ = di.MustResolve[*Tenant](testutils.ContextWithTestValue(ctx, "a"), c)
+		_ = di.MustResolve[*Tenant](testutils.ContextWithTestValue(ctx, "b"), c)
+
+		err = c.Close(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, []any{"b", "a"}, tracker.Closed())
+	})
+
+	t.Run("eviction close error", func(t *testing.T) {
+		tracker := &tenantTracker{closeErr: errors.New("close error")}
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			di.WithService(tracker.NewTenant,
+				di.SingletonPer(testutils.TestValue),
+				di.WithCacheLimit(1),
+			),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		_ = di.MustResolve[*Tenant](testutils.ContextWithTestValue(ctx, "a"), c)
+		_ = di.MustResolve[*Tenant](testutils.ContextWithTestValue(ctx, "b"), c)
+
+		err = c.Close(ctx)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Close: close error a\nclose error b")
+	})
+
+	t.Run("eviction close errors capped", func(t *testing.T) {
+		tracker := &tenantTracker{closeErr: errors.New("close error")}
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			di.WithService(tracker.NewTenant,
+				di.SingletonPer(testutils.TestValue),
+				di.WithCacheLimit(1),
+			),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		for i := range 103 {
+			_ = di.MustResolve[*Tenant](testutils.ContextWithTestValue(ctx, fmt.Sprint(i)), c)
+		}
+
+		err = c.Close(ctx)
+		require.Error(t, err)
+		assert.Len(t, strings.Split(err.Error(), "\n"), 102)
+		assert.Contains(t, err.Error(), "\n2 more errors closing evicted instances\n")
+	})
+
+	t.Run("error not cached", func(t *testing.T) {
+		calls := 0
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			di.WithService(func() (*testtypes.StructA, error) {
+				calls++
+				if calls == 1 {
+					return nil, errors.New("first call error")
+				}
+				return &testtypes.StructA{}, nil
+			}, di.SingletonPer(testutils.TestValue)),
+		)
+		require.NoError(t, err)
+
+		ctx := testutils.ContextWithTestValue(context.Background(), "tenant-a")
+		_, err = di.Resolve[*testtypes.StructA](ctx, c)
+		assert.EqualError(t, err, "di.Container.Resolve *testtypes.StructA: first call error")
+
+		got, err := di.Resolve[*testtypes.StructA](ctx, c)
+		assert.NotNil(t, got)
+		assert.NoError(t, err)
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		tracker := &tenantTracker{}
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			di.WithService(tracker.NewTenant, di.SingletonPer(testutils.TestValue)),
+		)
+		require.NoError(t, err)
+
+		testutils.RunParallel(100, func(i int) {
+			ctx := testutils.ContextWithTestValue(context.Background(), i%10)
+			got := di.MustResolve[*Tenant](ctx, c)
+			assert.Equal(t, i%10, got.Key)
+		})
+
+		assert.Equal(t, 10, tracker.Created())
+	})
+
+	t.Run("slow key does not block other keys", func(t *testing.T) {
+		release := make(chan struct{})
+		started := make(chan struct{})
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			di.WithService(func(ctx context.Context) *Tenant {
+				key := testutils.TestValue(ctx)
+				if key == "slow" {
+					close(started)
+					<-release
+				}
+				return &Tenant{Key: key}
+			}, di.SingletonPer(testutils.TestValue)),
+		)
+		require.NoError(t, err)
+
+		slow := make(chan *Tenant)
+		go func() {
+			slow <- di.MustResolve[*Tenant](testutils.ContextWithTestValue(context.Background(), "slow"), c)
+		}()
+		<-started
+
+		fast := di.MustResolve[*Tenant](testutils.ContextWithTestValue(context.Background(), "fast"), c)
+		assert.Equal(t, "fast", fast.Key)
+
+		close(release)
+		assert.Equal(t, "slow", (<-slow).Key)
+	})
+
+	t.Run("constructor resolves another key", func(t *testing.T) {
+		type Node struct {
+			Key    any
+			Parent *Node
+		}
+
+		var c *di.Container
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			di.WithService(func(ctx context.Context) (*Node, error) {
+				node := &Node{Key: testutils.TestValue(ctx)}
+				if node.Key == "parent" {
+					return node, nil
+				}
+
+				// Resolve the same service for another key
+				var err error
+				node.Parent, err = di.Resolve[*Node](testutils.ContextWithTestValue(ctx, "parent"), c)
+				return node, err
+			}, di.SingletonPer(testutils.TestValue)),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[*Node](testutils.ContextWithTestValue(context.Background(), "child"), c)
+		require.NoError(t, err)
+		assert.Equal(t, "parent", got.Parent.Key)
+	})
+
+	t.Run("waiters retry after canceled context", func(t *testing.T) {
+		tracker := &tenantTracker{}
+		started := make(chan struct{})
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			di.WithService(func(ctx context.Context) (*Tenant, error) {
+				if ctx.Value(cancelKey{}) != nil {
+					close(started)
+					<-ctx.Done()
+					return nil, ctx.Err()
+				}
+				return tracker.NewTenant(ctx), nil
+			}, di.SingletonPer(testutils.TestValue)),
+		)
+		require.NoError(t, err)
+
+		ctx := testutils.ContextWithTestValue(context.Background(), "tenant")
+		canceledCtx, cancel := context.WithCancel(context.WithValue(ctx, cancelKey{}, true))
+
+		first := make(chan error)
+		go func() {
+			_, err := di.Resolve[*Tenant](canceledCtx, c)
+			first <- err
+		}()
+		<-started
+
+		second := make(chan *Tenant)
+		go func() {
+			second <- di.MustResolve[*Tenant](ctx, c)
+		}()
+
+		cancel()
+		assert.ErrorIs(t, <-first, context.Canceled)
+		assert.Equal(t, "tenant", (<-second).Key)
+	})
+
+	t.Run("key not comparable", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			di.WithService(testtypes.NewStructAPtr, di.SingletonPer(func(context.Context) any {
+				return []string{"a"}
+			})),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*testtypes.StructA](context.Background(), c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve *testtypes.StructA: SingletonPer: key []string is not comparable")
+	})
+
+	t.Run("key func nil", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			di.WithService(testtypes.NewStructAPtr, di.SingletonPer(nil)),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func() *testtypes.StructA: SingletonPer: key function is nil")
+	})
+
+	t.Run("value service", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			di.WithService(&testtypes.StructA{}, di.SingletonPer(testutils.TestValue)),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService *testtypes.StructA: SingletonPer: not supported for value service")
+	})
+
+	t.Run("not singleton", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			di.WithService(testtypes.NewStructAPtr, di.SingletonPer(testutils.TestValue), di.Scoped),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func() *testtypes.StructA: SingletonPer: invalid lifetime Scoped")
+	})
+}
+
+type cancelKey struct{}
+
+type Tenant struct {
+	Key     any
+	tracker *tenantTracker
+}
+
+func (t *Tenant) Close() error {
+	return t.tracker.close(t.Key)
+}
+
+type tenantTracker struct {
+	closeErr error
+	closed   []any
+	created  int
+	mu       sync.Mutex
+}
+
+func (tt *tenantTracker) NewTenant(ctx context.Context) *Tenant {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	tt.created++
+	return &Tenant{Key: testutils.TestValue(ctx), tracker: tt}
+}
+
+func (tt *tenantTracker) close(key any) error {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	tt.closed = append(tt.closed, key)
+	if tt.closeErr != nil {
+		return fmt.Errorf("%w %v", tt.closeErr, key)
+	}
+	return nil
+}
+
+func (tt *tenantTracker) Closed() []any {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	return tt.closed
+}
+
+func (tt *tenantTracker) Created() int {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	return tt.created
+}
@@ -43,6 +43,28 @@ func (l Lifetime) applyService(s *service) error {
 	}
 
 	s.lifetime = l
+	s.lifetimeSet = true
+	return nil
+}
+
+// applyImpliedLifetime makes the service [Transient] if it uses [WithCustomLifetime] or [Prototype].
+// It returns an error if a [Lifetime] was also set explicitly, regardless of the order of the options.
+func (s *service) applyImpliedLifetime() error {
+	var opt string
+	switch {
+	case s.custom != nil:
+		opt = "WithCustomLifetime"
+	case s.prototype != nil:
+		opt = "Prototype"
+	default:
+		return nil
+	}
+
+	if s.lifetimeSet {
+		return errors.Errorf("%s: cannot be used with %s", opt, s.lifetime)
+	}
+
+	s.lifetime = Transient
 	return nil
 }
 
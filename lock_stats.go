@@ -0,0 +1,117 @@
+package di
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// WithLockStats records how long resolving services waits on the locks of each [Container]
+// when calling [NewContainer] or [Container.NewScope].
+//
+// Use [Container.LockStats] to get the stats. This is useful to check whether lock contention
+// is a problem for a workload, for example before and after upgrading.
+// Recording the stats adds a small overhead, so it is disabled by default.
+//
+// Child scopes inherit this option from the parent Container, but record their own stats.
+func WithLockStats() ContainerOption {
+	return containerOption(func(c *Container) error {
+		c.lockStats = &lockStats{}
+		return nil
+	})
+}
+
+// LockStats reports lock contention for a [Container].
+//
+// See [WithLockStats] for more information.
+type LockStats struct {
+	// Resolved is the stats for the lock on resolved Singleton and Scoped services.
+	Resolved LockStat
+	// Closers is the stats for the lock on the closers of resolved services.
+	Closers LockStat
+}
+
+// LockStat reports contention for a single lock.
+type LockStat struct {
+	// Wait is the total time spent waiting to acquire the lock.
+	Wait time.Duration
+	// Acquired is the number of times the lock was acquired.
+	Acquired uint64
+	// Contended is the number of times the lock was held by another goroutine and had to be waited for.
+	Contended uint64
+}
+
+// LockStats returns the [LockStats] for the Container.
+//
+// It returns false if the Container was not created with [WithLockStats].
+func (c *Container) LockStats() (LockStats, bool) {
+	if c.lockStats == nil {
+		return LockStats{}, false
+	}
+
+	return LockStats{
+		Resolved: c.lockStats.resolved.Stat(),
+		Closers:  c.lockStats.closers.Stat(),
+	}, true
+}
+
+type lockStats struct {
+	resolved lockCounter
+	closers  lockCounter
+}
+
+type lockCounter struct {
+	wait      atomic.Int64
+	acquired  atomic.Uint64
+	contended atomic.Uint64
+}
+
+// Acquire calls tryLock, or records the time spent waiting in lock if the lock is contended.
+func (l *lockCounter) Acquire(tryLock func() bool, lock func()) {
+	l.acquired.Add(1)
+	if tryLock() {
+		return
+	}
+
+	start := time.Now()
+	lock()
+	l.wait.Add(int64(time.Since(start)))
+	l.contended.Add(1)
+}
+
+func (l *lockCounter) Stat() LockStat {
+	return LockStat{
+		Wait:      time.Duration(l.wait.Load()),
+		Acquired:  l.acquired.Load(),
+		Contended: l.contended.Load(),
+	}
+}
+
+// lockResolved locks the resolved services for writing.
+func (c *Container) lockResolved() {
+	if c.lockStats == nil {
+		c.resolvedMu.Lock()
+		return
+	}
+
+	c.lockStats.resolved.Acquire(c.resolvedMu.TryLock, c.resolvedMu.Lock)
+}
+
+// rlockResolved locks the resolved services for reading.
+func (c *Container) rlockResolved() {
+	if c.lockStats == nil {
+		c.resolvedMu.RLock()
+		return
+	}
+
+	c.lockStats.resolved.Acquire(c.resolvedMu.TryRLock, c.resolvedMu.RLock)
+}
+
+// lockClosers locks the closers.
+func (c *Container) lockClosers() {
+	if c.lockStats == nil {
+		c.closersMu.Lock()
+		return
+	}
+
+	c.lockStats.closers.Acquire(c.closersMu.TryLock, c.closersMu.Lock)
+}
@@ -0,0 +1,93 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithLockStats(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		stats, ok := c.LockStats()
+		assert.False(t, ok)
+		assert.Zero(t, stats)
+	})
+
+	t.Run("uncontended", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithLockStats(),
+			di.WithService(testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](context.Background(), c)
+		require.NoError(t, err)
+
+		stats, ok := c.LockStats()
+		require.True(t, ok)
+		assert.Equal(t, uint64(2), stats.Resolved.Acquired)
+		assert.Zero(t, stats.Resolved.Contended)
+		assert.Zero(t, stats.Resolved.Wait)
+		assert.Equal(t, uint64(1), stats.Closers.Acquired)
+	})
+
+	t.Run("contended", func(t *testing.T) {
+		started := make(chan struct{})
+		c, err := di.NewContainer(
+			di.WithLockStats(),
+			di.WithService(func() *testtypes.StructA {
+				close(started)
+				time.Sleep(10 * time.Millisecond)
+				return &testtypes.StructA{}
+			}),
+		)
+		require.NoError(t, err)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, resolveErr := di.Resolve[*testtypes.StructA](context.Background(), c)
+			assert.NoError(t, resolveErr)
+		}()
+		<-started
+
+		_, err = di.Resolve[*testtypes.StructA](context.Background(), c)
+		require.NoError(t, err)
+		<-done
+
+		stats, ok := c.LockStats()
+		require.True(t, ok)
+		assert.Equal(t, uint64(1), stats.Resolved.Contended)
+		assert.Positive(t, stats.Resolved.Wait)
+	})
+
+	t.Run("child scope", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithLockStats(),
+			di.WithService(testtypes.NewInterfaceA, di.Scoped),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope()
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](context.Background(), scope)
+		require.NoError(t, err)
+
+		stats, ok := scope.LockStats()
+		require.True(t, ok)
+		assert.Equal(t, uint64(2), stats.Resolved.Acquired)
+
+		stats, ok = c.LockStats()
+		require.True(t, ok)
+		assert.Zero(t, stats.Resolved.Acquired)
+	})
+}
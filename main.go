@@ -0,0 +1,79 @@
+package di
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// ExitCoder is implemented by errors that specify the exit code for [Main].
+//
+// This is implemented by [*os/exec.ExitError].
+type ExitCoder interface {
+	// ExitCode returns the exit code for the process.
+	ExitCode() int
+}
+
+// Main is a minimal entrypoint helper for command-line applications.
+//
+// It creates a new [Container] with the provided options, calls fn using [Invoke],
+// and closes the Container. The returned exit code should be passed to [os.Exit].
+//
+// The context passed to fn is canceled when the process receives an interrupt or termination signal.
+// The Container is always closed, even if fn returns an error.
+//
+// Exit codes:
+//   - 0 if fn returns a nil error, or does not return an error.
+//   - The result of ExitCode if the error implements [ExitCoder] and the result is positive.
+//   - 1 for any other error, including errors creating or closing the Container.
+//
+// Errors are written to [os.Stderr].
+//
+// Example:
+//
+//	func main() {
+//		os.Exit(di.Main(run, app.Dependencies))
+//	}
+//
+//	func run(ctx context.Context, svc *service.Service) error {
+//		return svc.Run(ctx)
+//	}
+func Main(fn any, opts ...ContainerOption) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	c, err := NewContainer(opts...)
+	if err != nil {
+		return exitCode(err)
+	}
+
+	code := exitCode(Invoke(ctx, c, fn))
+
+	// Close with a new context since ctx may have been canceled
+	err = c.Close(context.WithoutCancel(ctx))
+	if closeCode := exitCode(err); code == 0 {
+		code = closeCode
+	}
+
+	return code
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	fmt.Fprintln(os.Stderr, err)
+
+	// An error must not exit with success, or with -1 like an [*os/exec.ExitError] for a signal
+	var coder ExitCoder
+	if errors.As(err, &coder) && coder.ExitCode() > 0 {
+		return coder.ExitCode()
+	}
+
+	return 1
+}
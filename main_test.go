@@ -0,0 +1,115 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/stretchr/testify/assert"
+)
+
+type exitError struct {
+	code int
+}
+
+func (e exitError) Error() string { return "exit error" }
+func (e exitError) ExitCode() int { return e.code }
+
+func Test_Main(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		called := false
+
+		code := di.Main(func(ctx context.Context, a testtypes.InterfaceA) {
+			assert.NotNil(t, ctx)
+			assert.NotNil(t, a)
+			called = true
+		}, di.WithService(testtypes.NewInterfaceA))
+
+		assert.Equal(t, 0, code)
+		assert.True(t, called)
+	})
+
+	t.Run("return nil error", func(t *testing.T) {
+		code := di.Main(func() error { return nil })
+		assert.Equal(t, 0, code)
+	})
+
+	t.Run("return error", func(t *testing.T) {
+		code := di.Main(func() error { return errors.New("run error") })
+		assert.Equal(t, 1, code)
+	})
+
+	t.Run("return ExitCoder", func(t *testing.T) {
+		code := di.Main(func() error {
+			return errors.Wrap(exitError{code: 3}, "wrapped")
+		})
+		assert.Equal(t, 3, code)
+	})
+
+	t.Run("return ExitCoder with zero or negative code", func(t *testing.T) {
+		for _, exitCode := range []int{0, -1} {
+			code := di.Main(func() error {
+				return exitError{code: exitCode}
+			})
+			assert.Equal(t, 1, code)
+		}
+	})
+
+	t.Run("NewContainer error", func(t *testing.T) {
+		code := di.Main(func() {
+			assert.Fail(t, "fn should not get called")
+		}, di.WithService(nil))
+
+		assert.Equal(t, 1, code)
+	})
+
+	t.Run("dependency not registered", func(t *testing.T) {
+		code := di.Main(func(testtypes.InterfaceA) {
+			assert.Fail(t, "fn should not get called")
+		})
+
+		assert.Equal(t, 1, code)
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		closed := false
+
+		code := di.Main(func(testtypes.InterfaceA) {},
+			di.WithService(testtypes.NewInterfaceA,
+				di.UseCloseFunc(func(context.Context, testtypes.InterfaceA) error {
+					closed = true
+					return nil
+				}),
+			),
+		)
+
+		assert.Equal(t, 0, code)
+		assert.True(t, closed)
+	})
+
+	t.Run("Close error", func(t *testing.T) {
+		code := di.Main(func(testtypes.InterfaceA) {},
+			di.WithService(testtypes.NewInterfaceA,
+				di.UseCloseFunc(func(context.Context, testtypes.InterfaceA) error {
+					return exitError{code: 4}
+				}),
+			),
+		)
+
+		assert.Equal(t, 4, code)
+	})
+
+	t.Run("Close error after fn error", func(t *testing.T) {
+		code := di.Main(func(testtypes.InterfaceA) error { return exitError{code: 2} },
+			di.WithService(testtypes.NewInterfaceA,
+				di.UseCloseFunc(func(context.Context, testtypes.InterfaceA) error {
+					return exitError{code: 4}
+				}),
+			),
+		)
+
+		assert.Equal(t, 2, code)
+	})
+}
@@ -0,0 +1,196 @@
+package di
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// Manifest is a deterministic description of the services registered with a [Container].
+//
+// It can be serialized, for example with [encoding/json], and committed alongside the code
+// so changes to how services are wired are visible in code review.
+type Manifest struct {
+	// Services registered with the Container and its parent Containers, starting with the root Container.
+	// Services registered with the same Container are in the order they were registered.
+	Services []ManifestService `json:"services" yaml:"services"`
+	// Modules applied with [NamedModule] to the Container and its parent Containers, nested as they were applied.
+	// Modules are in the order they were first applied, starting with the root Container.
+	Modules []ManifestModule `json:"modules,omitempty" yaml:"modules,omitempty"`
+}
+
+// ManifestModule describes a module applied with [NamedModule] in a [Manifest].
+type ManifestModule struct {
+	// Name of the module.
+	Name string `json:"name" yaml:"name"`
+	// Path of the module, with the names of the modules it is nested in, like "app/storage".
+	// This is the Module of the services the module registers.
+	Path string `json:"path" yaml:"path"`
+	// Modules nested in the module.
+	Modules []ManifestModule `json:"modules,omitempty" yaml:"modules,omitempty"`
+}
+
+// ManifestService describes a service registration in a [Manifest].
+//
+// A service registered as several types with [As] or several tags with [WithTag]
+// is described once for each type and tag.
+type ManifestService struct {
+	// ID is the deterministic ID of the registration. See [ServiceInfo].
+	ID string `json:"id" yaml:"id"`
+	// Type the service is registered as.
+	Type string `json:"type" yaml:"type"`
+	// Tag the service is registered with, if any.
+	Tag string `json:"tag,omitempty" yaml:"tag,omitempty"`
+	// Lifetime of the service.
+	Lifetime string `json:"lifetime" yaml:"lifetime"`
+	// Constructor is the name of the service. See [ServiceInfo].
+	Constructor string `json:"constructor" yaml:"constructor"`
+	// Name of the service set with [WithName] or a [ServiceBuilder], if any.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Deprecated is the deprecation message of the service, if any. See [Deprecated].
+	Deprecated string `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+	// Metadata of the service, if any. See [WithMetadata].
+	Metadata map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	// Module is the name of the module the service was registered with. See [NamedModule].
+	Module string `json:"module,omitempty" yaml:"module,omitempty"`
+	// Dependencies of the constructor function.
+	Dependencies []string `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+	// Depth is the scope level of the Container the service is registered with.
+	Depth int `json:"depth" yaml:"depth"`
+	// Value is true for value services.
+	Value bool `json:"value,omitempty" yaml:"value,omitempty"`
+	// Primary is true for services registered with [WithPrimary].
+	Primary bool `json:"primary,omitempty" yaml:"primary,omitempty"`
+}
+
+// Manifest returns a [Manifest] describing the services registered with the Container
+// and its parent Containers.
+//
+// The Manifest is the same each time the same services are registered in the same order.
+//
+// Example:
+//
+//	m := c.Manifest()
+//	data, err := json.MarshalIndent(m, "", "  ")
+func (c *Container) Manifest() Manifest {
+	scopes := make([]*Container, 0, c.depth()+1)
+	for scope := c; scope != nil; scope = scope.parent {
+		scopes = append(scopes, scope)
+	}
+
+	var m Manifest
+	for i := len(scopes) - 1; i >= 0; i-- {
+		for _, svc := range scopes[i].registered {
+			for _, key := range svc.Keys() {
+				m.Services = append(m.Services, svc.manifest(key))
+			}
+		}
+		for _, path := range scopes[i].modules {
+			m.Modules = addManifestModule(m.Modules, strings.Split(path, "/"), "")
+		}
+	}
+
+	return m
+}
+
+// addManifestModule adds the module with the names in the path to the tree of modules,
+// if it's not already there.
+func addManifestModule(modules []ManifestModule, names []string, parent string) []ManifestModule {
+	path := names[0]
+	if parent != "" {
+		path = parent + "/" + path
+	}
+
+	i := slices.IndexFunc(modules, func(m ManifestModule) bool {
+		return m.Name == names[0]
+	})
+	if i < 0 {
+		modules = append(modules, ManifestModule{Name: names[0], Path: path})
+		i = len(modules) - 1
+	}
+
+	if len(names) > 1 {
+		modules[i].Modules = addManifestModule(modules[i].Modules, names[1:], path)
+	}
+	return modules
+}
+
+func (s *service) manifest(key serviceKey) ManifestService {
+	info := s.Info(key)
+
+	ms := ManifestService{
+		ID:          info.ID,
+		Type:        key.Type.String(),
+		Lifetime:    s.Lifetime().String(),
+		Constructor: info.Name,
+		Name:        s.name,
+		Deprecated:  s.deprecated,
+		Metadata:    maps.Clone(s.metadata),
+		Module:      s.module,
+		Depth:       info.Depth,
+		Value:       s.IsValue(),
+		Primary:     s.primary,
+	}
+	if key.Tag != nil {
+		ms.Tag = fmt.Sprint(key.Tag)
+	}
+	for i, dep := range s.Dependencies() {
+		if s.arg(i).IsValid() {
+			continue
+		}
+		ms.Dependencies = append(ms.Dependencies, dep.String())
+	}
+
+	return ms
+}
+
+// NamedModule applies the container options as a module with a name when calling [NewContainer]
+// or [Container.NewScope].
+//
+// Services registered by the options are described with the module name in the [Manifest]
+// and in their [ServiceInfo]. The names of nested modules are joined with a slash, like "app/storage",
+// through any number of unnamed [Module]s in between. The nesting tree is described by [Manifest.Modules].
+//
+// Example:
+//
+//	var Deps = di.NamedModule("storage",
+//		di.WithService(NewDB),
+//		di.WithService(NewStore),
+//	)
+//
+// This option will return an error if the name is empty or contains a slash.
+func NamedModule(name string, opts ...ContainerOption) ContainerOption {
+	return namedModule{
+		name: name,
+		opts: opts,
+	}
+}
+
+type namedModule struct {
+	name string
+	opts Module
+}
+
+func (m namedModule) applyContainer(c *Container) error {
+	if m.name == "" || strings.Contains(m.name, "/") {
+		return errors.Errorf("NamedModule %q: name must not be empty or contain a slash", m.name)
+	}
+	c.assertMutable("option applied")
+
+	parent := c.module
+	if parent != "" {
+		c.module = parent + "/" + m.name
+	} else {
+		c.module = m.name
+	}
+	defer func() { c.module = parent }()
+
+	if !slices.Contains(c.modules, c.module) {
+		c.modules = append(c.modules, c.module)
+	}
+
+	return m.opts.applyContainer(c)
+}
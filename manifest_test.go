@@ -0,0 +1,285 @@
+package di_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// manifestServices returns the services in the manifest without IDs and the built-in services.
+func manifestServices(m di.Manifest) []di.ManifestService {
+	svcs := make([]di.ManifestService, 0, len(m.Services))
+	for i := range m.Services {
+		svc := m.Services[i]
+		if svc.Type == "di.Clock" || svc.Type == "di.Rand" {
+			continue
+		}
+
+		svc.ID = ""
+		svcs = append(svcs, svc)
+	}
+
+	return svcs
+}
+
+func Test_Container_Manifest(t *testing.T) {
+	newContainer := func(t *testing.T) *di.Container {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.StructA{}, di.As[testtypes.InterfaceA]()),
+			di.NamedModule("app",
+				di.WithService(testtypes.NewInterfaceB, di.WithTag("tag")),
+				di.NamedModule("nested",
+					di.WithService(testtypes.NewInterfaceCStruct, di.Transient),
+				),
+				di.WithOptionOrder(di.OrderValidation,
+					di.WithService(testtypes.NewInterfaceD, di.Scoped),
+				),
+			),
+		)
+		require.NoError(t, err)
+		return c
+	}
+
+	t.Run("services", func(t *testing.T) {
+		c := newContainer(t)
+		scope, err := c.NewScope(
+			di.WithService(testtypes.NewStructAPtr),
+		)
+		require.NoError(t, err)
+
+		m := scope.Manifest()
+		assert.Equal(t, []di.ManifestService{
+			{
+				Type:        "testtypes.InterfaceA",
+				Lifetime:    "Singleton",
+				Constructor: "testtypes.StructA",
+				Value:       true,
+			},
+			{
+				Type:         "testtypes.InterfaceB",
+				Tag:          "tag",
+				Lifetime:     "Singleton",
+				Constructor:  "github.com/sectrean/di-kit/internal/testtypes.NewInterfaceB",
+				Module:       "app",
+				Dependencies: []string{"testtypes.InterfaceA"},
+			},
+			{
+				Type:         "testtypes.InterfaceC",
+				Lifetime:     "Transient",
+				Constructor:  "github.com/sectrean/di-kit/internal/testtypes.NewInterfaceCStruct",
+				Module:       "app/nested",
+				Dependencies: []string{"testtypes.InterfaceA", "testtypes.InterfaceB"},
+			},
+			{
+				Type:         "testtypes.InterfaceD",
+				Lifetime:     "Scoped",
+				Constructor:  "github.com/sectrean/di-kit/internal/testtypes.NewInterfaceD",
+				Module:       "app",
+				Dependencies: []string{"testtypes.InterfaceA", "testtypes.InterfaceB", "testtypes.InterfaceC"},
+			},
+			{
+				Type:        "*testtypes.StructA",
+				Lifetime:    "Singleton",
+				Constructor: "github.com/sectrean/di-kit/internal/testtypes.NewStructAPtr",
+				Depth:       1,
+			},
+		}, manifestServices(m))
+
+		info, ok := c.Lookup(testtypes.TypeInterfaceA)
+		require.True(t, ok)
+		assert.Contains(t, m.Services, di.ManifestService{
+			ID:          info.ID,
+			Type:        "testtypes.InterfaceA",
+			Lifetime:    "Singleton",
+			Constructor: "testtypes.StructA",
+			Value:       true,
+		})
+	})
+
+	t.Run("modules", func(t *testing.T) {
+		c := newContainer(t)
+		scope, err := c.NewScope(
+			di.NamedModule("request",
+				di.WithService(testtypes.NewStructAPtr),
+			),
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, []di.ManifestModule{
+			{
+				Name: "app",
+				Path: "app",
+				Modules: []di.ManifestModule{
+					{Name: "nested", Path: "app/nested"},
+				},
+			},
+			{Name: "request", Path: "request"},
+		}, scope.Manifest().Modules)
+	})
+
+	t.Run("deterministic JSON", func(t *testing.T) {
+		data1, err := json.Marshal(newContainer(t).Manifest())
+		require.NoError(t, err)
+		data2, err := json.Marshal(newContainer(t).Manifest())
+		require.NoError(t, err)
+
+		assert.JSONEq(t, string(data1), string(data2))
+	})
+}
+
+func Test_NamedModule(t *testing.T) {
+	t.Run("nested in unnamed modules", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.Module{
+				di.NamedModule("app",
+					di.Module{
+						di.WithModule(di.Module{
+							di.NamedModule("storage",
+								di.Module{di.WithService(testtypes.NewInterfaceA)},
+							),
+						}),
+					},
+					di.WithService(testtypes.NewInterfaceB),
+				),
+			},
+		)
+		require.NoError(t, err)
+
+		info, ok := c.Lookup(testtypes.TypeInterfaceA)
+		require.True(t, ok)
+		assert.Equal(t, "app/storage", info.Module)
+
+		info, ok = c.Lookup(reflect.TypeFor[testtypes.InterfaceB]())
+		require.True(t, ok)
+		assert.Equal(t, "app", info.Module)
+
+		assert.Equal(t, []di.ManifestModule{
+			{
+				Name: "app",
+				Path: "app",
+				Modules: []di.ManifestModule{
+					{Name: "storage", Path: "app/storage"},
+				},
+			},
+		}, c.Manifest().Modules)
+	})
+
+	t.Run("applied twice", func(t *testing.T) {
+		storage := di.NamedModule("storage")
+
+		c, err := di.NewContainer(storage, storage)
+		require.NoError(t, err)
+
+		assert.Equal(t, []di.ManifestModule{
+			{Name: "storage", Path: "storage"},
+		}, c.Manifest().Modules)
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.NamedModule("app/storage"),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, `di.NewContainer: NamedModule "app/storage": name must not be empty or contain a slash`)
+	})
+}
+
+func Test_WithWiringChecksum(t *testing.T) {
+	opts := []di.ContainerOption{
+		di.WithService(testtypes.NewInterfaceA),
+		di.WithService(testtypes.NewInterfaceB, di.Transient),
+	}
+
+	c, err := di.NewContainer(opts...)
+	require.NoError(t, err)
+	checksum := c.Manifest().Checksum()
+
+	t.Run("match", func(t *testing.T) {
+		c2, err := di.NewContainer(
+			di.WithWiringChecksum(checksum),
+			opts[0], opts[1],
+		)
+		require.NoError(t, err)
+		assert.NotNil(t, c2)
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		c2, err := di.NewContainer(
+			di.WithWiringChecksum(checksum),
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(testtypes.NewInterfaceB, di.Scoped),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c2)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "di.NewContainer: WithWiringChecksum: registered services have changed; "+
+			"regenerate code with checksum ")
+		assert.Contains(t, err.Error(), "(was "+checksum+")")
+	})
+
+	t.Run("renamed constructor", func(t *testing.T) {
+		c2, err := di.NewContainer(
+			di.WithWiringChecksum(checksum),
+			di.WithService(func() testtypes.InterfaceA { return &testtypes.StructA{} },
+				di.WithName("a"), di.WithMetadata(map[string]string{"owner": "platform"})),
+			opts[1],
+		)
+		require.NoError(t, err)
+		assert.NotNil(t, c2)
+	})
+
+	t.Run("NewScope", func(t *testing.T) {
+		scope, err := c.NewScope(
+			di.WithService(testtypes.NewInterfaceC),
+		)
+		require.NoError(t, err)
+
+		scopeChecksum := scope.Manifest().Checksum()
+		assert.NotEqual(t, checksum, scopeChecksum)
+
+		_, err = c.NewScope(
+			di.WithService(testtypes.NewInterfaceC),
+			di.WithWiringChecksum(scopeChecksum),
+		)
+		assert.NoError(t, err)
+	})
+
+	// The checksum of the services without the built-in services
+	moduleChecksum := di.Manifest{Services: manifestServices(c.Manifest())}.Checksum()
+
+	t.Run("NamedModule", func(t *testing.T) {
+		c2, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceC),
+			di.NamedModule("gen",
+				di.WithWiringChecksum(moduleChecksum),
+				opts[0],
+				di.NamedModule("nested", opts[1]),
+			),
+		)
+		require.NoError(t, err)
+		assert.NotNil(t, c2)
+	})
+
+	t.Run("NamedModule changed", func(t *testing.T) {
+		c2, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceB),
+			di.NamedModule("gen",
+				di.WithWiringChecksum(moduleChecksum),
+				opts[0],
+			),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c2)
+		assert.ErrorContains(t, err, "(was "+moduleChecksum+")")
+	})
+}
@@ -0,0 +1,69 @@
+package di
+
+import (
+	"time"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// WithMemo caches the result of a [Transient] service for the given duration when calling [WithService].
+//
+// This is useful for services that are expensive to create, but can be reused for a short time,
+// like signed URLs or exchanged tokens.
+//
+// The result is cached separately for each scope the service is resolved from.
+// After the duration has passed, the next resolve will create a new instance of the service.
+// Errors returned from the constructor function are not cached.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithService(auth.ExchangeToken, // ExchangeToken(context.Context, *auth.Client) (auth.Token, error)
+//			di.Transient,
+//			di.WithMemo(time.Minute),
+//		),
+//	)
+//
+// This option will return an error if the service is not [Transient], or the duration is not positive.
+func WithMemo(ttl time.Duration) ServiceOption {
+	return serviceOption(func(s *service) error {
+		if ttl <= 0 {
+			return errors.Errorf("WithMemo %s: duration must be positive", ttl)
+		}
+
+		s.memoTTL = ttl
+		return nil
+	})
+}
+
+type memoResult struct {
+	Val     any
+	Expires time.Time
+}
+
+// loadMemo returns the memoized result of s if it hasn't expired at now.
+func (c *Container) loadMemo(s *service, now time.Time) (any, bool) {
+	c.memosMu.Lock()
+	defer c.memosMu.Unlock()
+
+	res, ok := c.memos[s]
+	if !ok || !now.Before(res.Expires) {
+		return nil, false
+	}
+
+	return res.Val, true
+}
+
+// storeMemo stores the result of s, which expires after the memo TTL has passed since now.
+func (c *Container) storeMemo(s *service, val any, now time.Time) {
+	c.memosMu.Lock()
+	defer c.memosMu.Unlock()
+
+	if c.memos == nil {
+		c.memos = make(map[*service]memoResult)
+	}
+	c.memos[s] = memoResult{
+		Val:     val,
+		Expires: now.Add(s.memoTTL),
+	}
+}
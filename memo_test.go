@@ -0,0 +1,120 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/ditest"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithMemo(t *testing.T) {
+	t.Run("cached within duration", func(t *testing.T) {
+		f := &testtypes.Factory{}
+		c, err := di.NewContainer(
+			di.WithService(f.NewStructA, di.Transient, di.WithMemo(time.Hour)),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		got1 := di.MustResolve[*testtypes.StructA](ctx, c)
+		got2 := di.MustResolve[*testtypes.StructA](ctx, c)
+
+		assert.Same(t, got1, got2)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		f := &testtypes.Factory{}
+		clock := ditest.NewFakeClock(time.Now())
+		c, err := di.NewContainer(
+			ditest.WithClock(clock),
+			di.WithService(f.NewStructA, di.Transient, di.WithMemo(time.Minute)),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		got1 := di.MustResolve[*testtypes.StructA](ctx, c)
+
+		clock.Advance(59 * time.Second)
+		assert.Same(t, got1, di.MustResolve[*testtypes.StructA](ctx, c))
+
+		clock.Advance(time.Second)
+		got2 := di.MustResolve[*testtypes.StructA](ctx, c)
+
+		want := testtypes.ExpectStructA(2)
+		got := []*testtypes.StructA{got1, got2}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("per scope", func(t *testing.T) {
+		f := &testtypes.Factory{}
+		c, err := di.NewContainer(
+			di.WithService(f.NewStructA, di.Transient, di.WithMemo(time.Hour)),
+		)
+		require.NoError(t, err)
+
+		scope1, err := c.NewScope()
+		require.NoError(t, err)
+		scope2, err := c.NewScope()
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		got1 := di.MustResolve[*testtypes.StructA](ctx, scope1)
+		got2 := di.MustResolve[*testtypes.StructA](ctx, scope1)
+		got3 := di.MustResolve[*testtypes.StructA](ctx, scope2)
+
+		assert.Same(t, got1, got2)
+		assert.NotSame(t, got1, got3)
+	})
+
+	t.Run("error not cached", func(t *testing.T) {
+		calls := 0
+		c, err := di.NewContainer(
+			di.WithService(func() (*testtypes.StructA, error) {
+				calls++
+				if calls == 1 {
+					return nil, errors.New("first call error")
+				}
+				return &testtypes.StructA{}, nil
+			}, di.Transient, di.WithMemo(time.Hour)),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		_, err = di.Resolve[*testtypes.StructA](ctx, c)
+		assert.EqualError(t, err, "di.Container.Resolve *testtypes.StructA: first call error")
+
+		got, err := di.Resolve[*testtypes.StructA](ctx, c)
+		assert.NotNil(t, got)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("not transient", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.WithMemo(time.Minute)),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func() testtypes.InterfaceA: "+
+			"WithMemo 1m0s: service must be Transient")
+	})
+
+	t.Run("invalid duration", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.Transient, di.WithMemo(0)),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func() testtypes.InterfaceA: "+
+			"WithMemo 0s: duration must be positive")
+	})
+}
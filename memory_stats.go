@@ -0,0 +1,105 @@
+package di
+
+import (
+	"sync/atomic"
+)
+
+// Sizer returns the estimated size in bytes of a service instance. See [WithMemoryStats].
+type Sizer = func(val any) int64
+
+// WithMemoryStats records the number of service instances created by each [Container], and their estimated size,
+// when calling [NewContainer] or [Container.NewScope].
+//
+// Use [Container.MemoryStats] to get the stats. This is useful to find scopes that accumulate
+// too many closers for [Transient] services, or cache large services.
+// The sizer is called once for each [Singleton] or [Scoped] instance created. It may be nil to only count instances.
+//
+// Child scopes inherit this option from the parent Container, but record their own stats.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithMemoryStats(func(val any) int64 {
+//			if s, ok := val.(interface{ Size() int64 }); ok {
+//				return s.Size()
+//			}
+//			return 0
+//		}),
+//		// ...
+//	)
+func WithMemoryStats(sizer Sizer) ContainerOption {
+	return containerOption(func(c *Container) error {
+		c.memoryStats = &memoryStats{sizer: sizer}
+		return nil
+	})
+}
+
+// MemoryStats reports the service instances created by a [Container].
+//
+// See [WithMemoryStats] for more information.
+type MemoryStats struct {
+	// Instances is the number of [Singleton] and [Scoped] instances created and cached by the Container.
+	Instances uint64
+	// Transients is the number of [Transient] instances created by the Container.
+	Transients uint64
+	// Closers is the number of closers that will be called when the Container is closed.
+	Closers int
+	// Size is the total estimated size in bytes of the cached instances, as returned by the sizer.
+	Size int64
+}
+
+// MemoryStats returns the [MemoryStats] for the Container.
+//
+// It returns false if the Container was not created with [WithMemoryStats].
+func (c *Container) MemoryStats() (MemoryStats, bool) {
+	if c.memoryStats == nil {
+		return MemoryStats{}, false
+	}
+
+	c.lockClosers()
+	closers := len(c.closers)
+	c.closersMu.Unlock()
+
+	return MemoryStats{
+		Instances:  c.memoryStats.instances.Load(),
+		Transients: c.memoryStats.transients.Load(),
+		Closers:    closers,
+		Size:       c.memoryStats.size.Load(),
+	}, true
+}
+
+// CloserCount returns the number of closers that will be called when the Container and its parent scopes are closed.
+//
+// Services are closed by the scope that owns them, so a [Singleton] resolved from a child scope
+// is counted by the root Container. This does not require [WithMemoryStats].
+// It's useful for tests that check if resolving a service registered a closer.
+func (c *Container) CloserCount() int {
+	n := 0
+	for scope := c; scope != nil; scope = scope.parent {
+		scope.lockClosers()
+		n += len(scope.closers)
+		scope.closersMu.Unlock()
+	}
+
+	return n
+}
+
+type memoryStats struct {
+	sizer      Sizer
+	instances  atomic.Uint64
+	transients atomic.Uint64
+	size       atomic.Int64
+}
+
+// Record an instance of svc created by the Container.
+func (m *memoryStats) Record(svc *service, val any) {
+	if svc.Lifetime() == Transient {
+		m.transients.Add(1)
+		return
+	}
+
+	m.instances.Add(1)
+	if m.sizer != nil && val != nil {
+		m.size.Add(m.sizer(val))
+	}
+}
@@ -0,0 +1,102 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithMemoryStats(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("disabled", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		_, ok := c.MemoryStats()
+		assert.False(t, ok)
+	})
+
+	t.Run("scopes", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewStructAPtr),
+			di.WithService(testtypes.NewStructBPtr, di.Scoped),
+			di.WithService(func() testtypes.InterfaceC { return testtypes.StructC{} }, di.Transient),
+			di.WithMemoryStats(func(val any) int64 {
+				switch val.(type) {
+				case *testtypes.StructA:
+					return 100
+				case *testtypes.StructB:
+					return 10
+				default:
+					return 1
+				}
+			}),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope()
+		require.NoError(t, err)
+
+		_ = di.MustResolve[*testtypes.StructB](ctx, scope)
+		_ = di.MustResolve[*testtypes.StructB](ctx, scope)
+		_ = di.MustResolve[testtypes.InterfaceC](ctx, scope)
+		_ = di.MustResolve[testtypes.InterfaceC](ctx, scope)
+
+		stats, ok := c.MemoryStats()
+		require.True(t, ok)
+		assert.Equal(t, di.MemoryStats{
+			Instances: 1,
+			Closers:   1,
+			Size:      100,
+		}, stats)
+
+		stats, ok = scope.MemoryStats()
+		require.True(t, ok)
+		assert.Equal(t, di.MemoryStats{
+			Instances:  1,
+			Transients: 2,
+			Closers:    3,
+			Size:       10,
+		}, stats)
+	})
+
+	t.Run("nil sizer", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewStructAPtr),
+			di.WithMemoryStats(nil),
+		)
+		require.NoError(t, err)
+
+		_ = di.MustResolve[*testtypes.StructA](ctx, c)
+
+		stats, ok := c.MemoryStats()
+		require.True(t, ok)
+		assert.Equal(t, uint64(1), stats.Instances)
+		assert.Equal(t, int64(0), stats.Size)
+	})
+}
+
+func Test_Container_CloserCount(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := di.NewContainer(
+		di.WithService(testtypes.NewInterfaceA),
+		di.WithService(testtypes.NewInterfaceB, di.Scoped),
+	)
+	require.NoError(t, err)
+
+	scope, err := c.NewScope()
+	require.NoError(t, err)
+	assert.Equal(t, 0, scope.CloserCount())
+
+	_ = di.MustResolve[testtypes.InterfaceB](ctx, scope)
+
+	// InterfaceA is closed by the parent, and InterfaceB by the scope
+	assert.Equal(t, 1, c.CloserCount())
+	assert.Equal(t, 2, scope.CloserCount())
+}
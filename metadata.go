@@ -0,0 +1,56 @@
+package di
+
+import (
+	"maps"
+	"reflect"
+)
+
+// WithMetadata attaches metadata to a service when calling [WithService].
+//
+// Metadata is not used by the [Container]. It describes the registration for people and tools,
+// like a description, or the team that owns the service, which helps when debugging resolution failures.
+// Use [Container.Metadata] to get the metadata, and it's included in the [Manifest].
+// Using this option more than once merges the metadata, with later values replacing earlier ones.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithService(billing.NewClient, di.WithMetadata(map[string]string{
+//			"owner":       "team-billing",
+//			"description": "Client for the billing API",
+//		})),
+//	)
+func WithMetadata(md map[string]string) ServiceOption {
+	return serviceOption(func(s *service) error {
+		if len(md) == 0 {
+			return nil
+		}
+
+		if s.metadata == nil {
+			s.metadata = make(map[string]string, len(md))
+		}
+		maps.Copy(s.metadata, md)
+		return nil
+	})
+}
+
+// Metadata returns the metadata of the service that would be resolved for the given [reflect.Type].
+// See [WithMetadata].
+//
+// It returns false if the service is not registered.
+// The map is a copy, and is nil if the service has no metadata.
+//
+// Available options:
+//   - [WithTag] specifies a key associated with the service.
+func (c *Container) Metadata(t reflect.Type, opts ...ResolveOption) (map[string]string, bool) {
+	if isUnnamedSliceType(t) {
+		t = t.Elem()
+	}
+
+	svc := c.lookupService(c.resolvableKey(c.serviceKeyFor(t, opts)))
+	if svc == nil {
+		return nil, false
+	}
+
+	return maps.Clone(svc.metadata), true
+}
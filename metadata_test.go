@@ -0,0 +1,87 @@
+package di_test
+
+import (
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithMetadata(t *testing.T) {
+	t.Run("Metadata", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA,
+				di.WithMetadata(map[string]string{"owner": "team-a", "description": "old"}),
+				di.WithMetadata(map[string]string{"description": "Service A"}),
+			),
+		)
+		require.NoError(t, err)
+
+		md, ok := c.Metadata(testtypes.TypeInterfaceA)
+		assert.True(t, ok)
+		assert.Equal(t, map[string]string{"owner": "team-a", "description": "Service A"}, md)
+
+		// The map is a copy
+		md["owner"] = "changed"
+		md, _ = c.Metadata(testtypes.TypeInterfaceA)
+		assert.Equal(t, "team-a", md["owner"])
+	})
+
+	t.Run("tagged", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.WithTag("a"),
+				di.WithMetadata(map[string]string{"owner": "team-a"}),
+			),
+		)
+		require.NoError(t, err)
+
+		md, ok := c.Metadata(testtypes.TypeInterfaceA, di.WithTag("a"))
+		assert.True(t, ok)
+		assert.Equal(t, map[string]string{"owner": "team-a"}, md)
+
+		_, ok = c.Metadata(testtypes.TypeInterfaceA)
+		assert.False(t, ok)
+	})
+
+	t.Run("no metadata", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+
+		md, ok := c.Metadata(testtypes.TypeInterfaceA)
+		assert.True(t, ok)
+		assert.Nil(t, md)
+	})
+
+	t.Run("from scope", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.WithMetadata(map[string]string{"owner": "team-a"})),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope()
+		require.NoError(t, err)
+
+		md, ok := scope.Metadata(testtypes.TypeInterfaceA)
+		assert.True(t, ok)
+		assert.Equal(t, map[string]string{"owner": "team-a"}, md)
+	})
+
+	t.Run("Manifest", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.WithMetadata(map[string]string{"owner": "team-a"})),
+		)
+		require.NoError(t, err)
+
+		var got []map[string]string
+		for _, s := range c.Manifest().Services {
+			if s.Metadata != nil {
+				got = append(got, s.Metadata)
+			}
+		}
+		assert.Equal(t, []map[string]string{{"owner": "team-a"}}, got)
+	})
+}
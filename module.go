@@ -3,6 +3,9 @@ package di
 // A Module is a collection of container options.
 // It can be used to create a re-usable collection of related services.
 //
+// Modules can contain other modules, nested to any depth.
+// The options are applied in order, as if the nested modules were flattened into a single list.
+//
 // Example:
 //
 //	var Deps = di.Module{
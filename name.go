@@ -0,0 +1,45 @@
+package di
+
+import (
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// WithName attaches a human-readable name to a service when calling [WithService].
+//
+// The name replaces the constructor function name used to describe the service.
+// It is included in errors from validating, constructing, and closing the service,
+// in [WithStrictResolve] errors, in the [ServiceInfo] returned by [Container.Lookup] and passed to events,
+// and in the [Manifest]. It doesn't change the service ID, see [ServiceInfo].
+// This tells registrations apart when several have the same signature, like anonymous constructor functions.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithService(func() (*sql.DB, error) {
+//			return sql.Open("postgres", primaryURL)
+//		}, di.WithName("primary-db"), di.WithTag(Primary)),
+//		di.WithService(func() (*sql.DB, error) {
+//			return sql.Open("postgres", replicaURL)
+//		}, di.WithName("replica-db"), di.WithTag(Replica)),
+//	)
+//
+// This option will return an error if the name is empty.
+func WithName(name string) ServiceOption {
+	return serviceOption(func(s *service) error {
+		if name == "" {
+			return errors.New("WithName: name is empty")
+		}
+
+		s.name = name
+		return nil
+	})
+}
+
+// wrapNamed adds the name of the service to err, if the service has one.
+func (s *service) wrapNamed(err error) error {
+	if err == nil || s == nil || s.name == "" {
+		return err
+	}
+
+	return errors.Wrapf(err, "service %s", s.name)
+}
@@ -0,0 +1,123 @@
+package di_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/mocks"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithName(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("constructor error", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() (testtypes.InterfaceA, error) {
+				return testtypes.NewInterfaceA(), nil
+			}, di.WithName("first")),
+			di.WithService(func() (testtypes.InterfaceA, error) {
+				return nil, errors.New("constructor error")
+			}, di.WithName("second")),
+		)
+		require.NoError(t, err)
+
+		_, err = di.ResolveAll[testtypes.InterfaceA](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve []testtypes.InterfaceA: service second: constructor error")
+	})
+
+	t.Run("dependency validation", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceB, di.WithName("b")),
+			di.WithDependencyValidation(),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithDependencyValidation: "+
+			"service func(testtypes.InterfaceA) testtypes.InterfaceB (b): "+
+			"dependency testtypes.InterfaceA: service not registered")
+	})
+
+	t.Run("close error", func(t *testing.T) {
+		a := mocks.NewInterfaceAMock(t)
+		a.EXPECT().Close(mock.Anything).Return(errors.New("close error"))
+
+		c, err := di.NewContainer(
+			di.WithService(func() testtypes.InterfaceA { return a }, di.WithName("a")),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+
+		err = c.Close(ctx)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Close: service a: close error")
+	})
+
+	t.Run("strict resolve candidates", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithStrictResolve(),
+			di.WithService(&testtypes.StructA{}, di.WithName("first")),
+			di.WithService(&testtypes.StructA{}),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*testtypes.StructA](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve *testtypes.StructA: "+
+			"2 services registered; use di.ResolveLast or di.ResolveAll; "+
+			"candidates: #0 first, #1 *testtypes.StructA (last)")
+	})
+
+	t.Run("manifest", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.WithName("a")),
+		)
+		require.NoError(t, err)
+
+		m := c.Manifest()
+		var names []string
+		for _, s := range m.Services {
+			if s.Name != "" {
+				names = append(names, s.Type+" "+s.Name)
+			}
+		}
+		assert.Equal(t, []string{"testtypes.InterfaceA a"}, names)
+	})
+
+	t.Run("lookup", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.NamedModule("storage",
+				di.WithService(testtypes.NewInterfaceA,
+					di.WithName("a"),
+					di.WithMetadata(map[string]string{"owner": "platform"}),
+				),
+			),
+		)
+		require.NoError(t, err)
+
+		info, ok := c.Lookup(reflect.TypeFor[testtypes.InterfaceA]())
+		require.True(t, ok)
+		assert.Equal(t, "a", info.Name)
+		assert.Equal(t, "storage", info.Module)
+		assert.Equal(t, map[string]string{"owner": "platform"}, info.Metadata)
+	})
+
+	t.Run("empty name", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.WithName("")),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func() testtypes.InterfaceA: WithName: name is empty")
+	})
+}
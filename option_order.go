@@ -0,0 +1,97 @@
+package di
+
+import (
+	"cmp"
+	"slices"
+)
+
+// OptionOrder specifies when a [ContainerOption] is applied when calling [NewContainer]
+// or [Container.NewScope].
+//
+// Options are applied in this order:
+//
+//  1. [OrderService]: services and settings, in the order the options are passed.
+//  2. [OrderDecorator]: options that depend on all services being registered.
+//  3. [OrderValidation]: options that check the registered services.
+//
+// [WithDependencyValidation] runs after all options have been applied.
+//
+// Use [WithOptionOrder] to declare the order of an option.
+type OptionOrder int
+
+const (
+	// OrderService is the default order. Options are applied in the order they are passed.
+	OrderService OptionOrder = 0
+
+	// OrderDecorator options are applied after all services are registered.
+	OrderDecorator OptionOrder = 100
+
+	// OrderValidation options are applied after decorators.
+	OrderValidation OptionOrder = 200
+)
+
+// WithOptionOrder applies opts at the given order when calling [NewContainer] or [Container.NewScope].
+//
+// This allows options from adapters and extensions to be passed anywhere in the list of options,
+// but still be applied after the services they depend on are registered.
+// Options with the same order are applied in the order they are passed.
+// Custom orders between the defined orders can be used, like OrderDecorator + 10.
+// Options with an order at or before [OrderService] are applied immediately.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithOptionOrder(di.OrderValidation, ext.CheckServices()),
+//		di.WithService(NewService),
+//	)
+func WithOptionOrder(order OptionOrder, opts ...ContainerOption) ContainerOption {
+	return orderedOption{
+		opts:  opts,
+		order: order,
+	}
+}
+
+type orderedOption struct {
+	module string
+	opts   []ContainerOption
+	order  OptionOrder
+}
+
+func (o orderedOption) applyContainer(c *Container) error {
+	if o.order <= OrderService {
+		return Module(o.opts).applyContainer(c)
+	}
+
+	o.module = c.module
+	c.orderedOpts = append(c.orderedOpts, o)
+	return nil
+}
+
+// applyOrderedOptions applies options deferred with WithOptionOrder.
+// Options may defer more options, so this repeats until none are left.
+func (c *Container) applyOrderedOptions() error {
+	for len(c.orderedOpts) > 0 {
+		opts := c.orderedOpts
+		c.orderedOpts = nil
+
+		slices.SortStableFunc(opts, func(a, b orderedOption) int {
+			return cmp.Compare(a.order, b.order)
+		})
+
+		err := applyOptions(opts, func(o orderedOption) error {
+			c.module = o.module
+			c.optionOrder = o.order
+			defer func() {
+				c.module = ""
+				c.optionOrder = OrderService
+			}()
+
+			return Module(o.opts).applyContainer(c)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithOptionOrder(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("applied after services", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithOptionOrder(di.OrderDecorator,
+				di.WithService(testtypes.StructA{Tag: "decorator"}, di.As[testtypes.InterfaceA]()),
+			),
+			di.WithService(testtypes.StructA{Tag: "service"}, di.As[testtypes.InterfaceA]()),
+		)
+		require.NoError(t, err)
+
+		a, err := di.Resolve[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, testtypes.StructA{Tag: "decorator"}, a)
+	})
+
+	t.Run("sorted by order", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithOptionOrder(di.OrderValidation,
+				di.WithService(testtypes.StructA{Tag: "validation"}, di.As[testtypes.InterfaceA]()),
+			),
+			di.WithOptionOrder(di.OrderDecorator+10,
+				di.WithService(testtypes.StructA{Tag: "decorator 10"}, di.As[testtypes.InterfaceA]()),
+			),
+			di.WithOptionOrder(di.OrderDecorator,
+				di.WithService(testtypes.StructA{Tag: "decorator"}, di.As[testtypes.InterfaceA]()),
+			),
+		)
+		require.NoError(t, err)
+
+		all, err := di.ResolveAll[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, []testtypes.InterfaceA{
+			testtypes.StructA{Tag: "decorator"},
+			testtypes.StructA{Tag: "decorator 10"},
+			testtypes.StructA{Tag: "validation"},
+		}, all)
+	})
+
+	t.Run("same order in order passed", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithOptionOrder(di.OrderDecorator,
+				di.WithService(testtypes.StructA{Tag: "first"}, di.As[testtypes.InterfaceA]()),
+			),
+			di.WithOptionOrder(di.OrderDecorator,
+				di.WithService(testtypes.StructA{Tag: "second"}, di.As[testtypes.InterfaceA]()),
+			),
+		)
+		require.NoError(t, err)
+
+		a, err := di.ResolveLast[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, testtypes.StructA{Tag: "second"}, a)
+	})
+
+	t.Run("OrderService applied immediately", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithOptionOrder(di.OrderService,
+				di.WithService(testtypes.StructA{Tag: "first"}, di.As[testtypes.InterfaceA]()),
+			),
+			di.WithService(testtypes.StructA{Tag: "second"}, di.As[testtypes.InterfaceA]()),
+		)
+		require.NoError(t, err)
+
+		a, err := di.ResolveLast[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, testtypes.StructA{Tag: "second"}, a)
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithOptionOrder(di.OrderDecorator,
+				di.WithOptionOrder(di.OrderValidation,
+					di.WithService(testtypes.StructA{Tag: "validation"}, di.As[testtypes.InterfaceA]()),
+				),
+				di.WithService(testtypes.StructA{Tag: "decorator"}, di.As[testtypes.InterfaceA]()),
+			),
+		)
+		require.NoError(t, err)
+
+		a, err := di.ResolveLast[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, testtypes.StructA{Tag: "validation"}, a)
+	})
+
+	t.Run("before WithDependencyValidation", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithDependencyValidation(),
+			di.WithService(testtypes.NewInterfaceB),
+			di.WithOptionOrder(di.OrderValidation,
+				di.WithService(testtypes.NewInterfaceA),
+			),
+		)
+		require.NoError(t, err)
+		assert.True(t, c.Contains(testtypes.TypeInterfaceA))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithOptionOrder(di.OrderDecorator,
+				di.WithService(testtypes.NewInterfaceA, di.As[testtypes.InterfaceB]()),
+			),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func() testtypes.InterfaceA: "+
+			"As testtypes.InterfaceB: type testtypes.InterfaceA not assignable to testtypes.InterfaceB")
+	})
+
+	t.Run("NewScope", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		scope, err := c.NewScope(
+			di.WithOptionOrder(di.OrderDecorator,
+				di.WithService(testtypes.StructA{Tag: "decorator"}, di.As[testtypes.InterfaceA]()),
+			),
+			di.WithService(testtypes.StructA{Tag: "service"}, di.As[testtypes.InterfaceA]()),
+		)
+		require.NoError(t, err)
+
+		a, err := di.Resolve[testtypes.InterfaceA](ctx, scope)
+		require.NoError(t, err)
+		assert.Equal(t, testtypes.StructA{Tag: "decorator"}, a)
+	})
+}
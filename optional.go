@@ -0,0 +1,83 @@
+package di
+
+import (
+	"context"
+	"reflect"
+)
+
+// Optional is a dependency that may not be registered with the [Container].
+//
+// Declare a constructor function parameter as Optional[T] to resolve type T if a service is registered,
+// or an empty Optional if it is not, instead of returning an error.
+// Errors from resolving a registered service are still returned.
+//
+// Use [WithTagged] with the Optional type to specify a tag for the dependency.
+//
+// Example:
+//
+//	func NewService(logger di.Optional[*slog.Logger]) *Service {
+//		l, ok := logger.Get()
+//		if !ok {
+//			l = slog.New(slog.DiscardHandler)
+//		}
+//		// ...
+//	}
+type Optional[T any] struct {
+	value T
+	ok    bool
+}
+
+// Get returns the resolved service, and whether a service was registered.
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.ok
+}
+
+// Value returns the resolved service, or the zero value if no service was registered.
+func (o Optional[T]) Value() T {
+	return o.value
+}
+
+func (Optional[T]) optionalType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+func (Optional[T]) withValue(val any) any {
+	v, _ := val.(T)
+	return Optional[T]{value: v, ok: true}
+}
+
+// optional is implemented by every Optional type.
+type optional interface {
+	optionalType() reflect.Type
+	withValue(val any) any
+}
+
+var typeOptional = reflect.TypeFor[optional]()
+
+// optionalElem returns the wrapped type if t is an [Optional] type.
+func optionalElem(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() != reflect.Struct || !t.Implements(typeOptional) {
+		return nil, false
+	}
+
+	return reflect.Zero(t).Interface().(optional).optionalType(), true
+}
+
+func resolveOptionalKey(
+	ctx context.Context,
+	scope *Container,
+	key serviceKey,
+	elemType reflect.Type,
+	visitor resolveVisitor,
+) (any, error) {
+	val, err := resolveKey(ctx, scope, serviceKey{Type: elemType, Tag: key.Tag}, visitor, false)
+	// Only if the service itself is not registered, not one of its dependencies
+	if err == errServiceNotRegistered {
+		return reflect.Zero(key.Type).Interface(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return reflect.Zero(key.Type).Interface().(optional).withValue(val), nil
+}
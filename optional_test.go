@@ -0,0 +1,166 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Optional(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("not registered", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func(a di.Optional[testtypes.InterfaceA]) testtypes.InterfaceB {
+				val, ok := a.Get()
+				assert.False(t, ok)
+				assert.Nil(t, val)
+				assert.Nil(t, a.Value())
+
+				return testtypes.StructB{}
+			}),
+			di.WithDependencyValidation(),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceB](ctx, c)
+		assert.NoError(t, err)
+	})
+
+	t.Run("registered", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(func(a di.Optional[testtypes.InterfaceA]) testtypes.InterfaceB {
+				val, ok := a.Get()
+				assert.True(t, ok)
+				assert.Equal(t, &testtypes.StructA{}, val)
+
+				return testtypes.StructB{}
+			}),
+			di.WithDependencyValidation(),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceB](ctx, c)
+		assert.NoError(t, err)
+	})
+
+	t.Run("registered nil", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() testtypes.InterfaceA { return nil }),
+		)
+		require.NoError(t, err)
+
+		a, err := di.Resolve[di.Optional[testtypes.InterfaceA]](ctx, c)
+		require.NoError(t, err)
+
+		val, ok := a.Get()
+		assert.True(t, ok)
+		assert.Nil(t, val)
+	})
+
+	t.Run("WithTagged", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.StructA{Tag: "tag"}, di.As[testtypes.InterfaceA](), di.WithTag("tag")),
+			di.WithService(func(a di.Optional[testtypes.InterfaceA]) testtypes.InterfaceB {
+				assert.Equal(t, testtypes.StructA{Tag: "tag"}, a.Value())
+				return testtypes.StructB{}
+			}, di.WithTagged[di.Optional[testtypes.InterfaceA]]("tag")),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceB](ctx, c)
+		assert.NoError(t, err)
+	})
+
+	t.Run("slice", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func(a di.Optional[[]testtypes.InterfaceA]) testtypes.InterfaceB {
+				_, ok := a.Get()
+				assert.False(t, ok)
+				return testtypes.StructB{}
+			}),
+			di.WithDependencyValidation(),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceB](ctx, c)
+		assert.NoError(t, err)
+	})
+
+	t.Run("constructor error", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() (testtypes.InterfaceA, error) {
+				return nil, errors.New("constructor error")
+			}),
+			di.WithService(func(di.Optional[testtypes.InterfaceA]) testtypes.InterfaceB {
+				return testtypes.StructB{}
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceB](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve testtypes.InterfaceB: "+
+			"dependency di.Optional[github.com/sectrean/di-kit/internal/testtypes.InterfaceA]: constructor error")
+	})
+
+	t.Run("dependency not registered", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceB),
+			di.WithService(func(di.Optional[testtypes.InterfaceB]) testtypes.InterfaceC {
+				return testtypes.StructC{}
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceC](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve testtypes.InterfaceC: "+
+			"dependency di.Optional[github.com/sectrean/di-kit/internal/testtypes.InterfaceB]: "+
+			"dependency testtypes.InterfaceA: service not registered")
+	})
+
+	t.Run("WithDependencyValidation registered", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceB),
+			di.WithService(func(di.Optional[testtypes.InterfaceB]) testtypes.InterfaceC {
+				return testtypes.StructC{}
+			}),
+			di.WithDependencyValidation(),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.ErrorContains(t, err, "dependency testtypes.InterfaceA: service not registered")
+	})
+
+	t.Run("invalid type", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func(di.Optional[int]) testtypes.InterfaceA { return nil }),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func(di.Optional[int]) testtypes.InterfaceA: "+
+			"parameter 0: invalid dependency type di.Optional[int]; "+
+			"use a named type or a pointer to a named type")
+	})
+
+	t.Run("Invoke", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		err = di.Invoke(ctx, c, func(a di.Optional[testtypes.InterfaceA]) {
+			_, ok := a.Get()
+			assert.False(t, ok)
+		})
+		assert.NoError(t, err)
+	})
+}
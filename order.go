@@ -0,0 +1,80 @@
+package di
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+)
+
+// WithOrder sets the position of a service when resolving a slice of services when calling [WithService].
+//
+// By default, the elements of a slice are in registration order, which is hard to control
+// when services are registered by several modules.
+// With this option, the elements are sorted by order, lowest first. Services with the same order,
+// including services without this option which have an order of 0, keep their registration order,
+// with services registered with a child scope before services registered with its parent Containers.
+// This applies to slice dependencies, [ResolveAll], and the services combined by [Chain].
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithService(NewRecoveryMiddleware, di.WithOrder(-10)), // Runs first
+//		di.WithService(NewAuthMiddleware),
+//		di.Chain(ComposeMiddleware),
+//	)
+func WithOrder(n int) ServiceOption {
+	return serviceOption(func(s *service) error {
+		s.order = n
+		return nil
+	})
+}
+
+// sliceServices returns the services resolved for a slice of the element key, sorted by [WithOrder].
+// Services registered with [Chain] are not included.
+func (c *Container) sliceServices(elemKey serviceKey) []*service {
+	svcs := slices.Collect(c.sliceServiceSeq(elemKey))
+
+	// Most services don't use WithOrder, so only sort when needed
+	if slices.ContainsFunc(svcs, func(s *service) bool { return s.order != 0 }) {
+		slices.SortStableFunc(svcs, func(a, b *service) int {
+			return cmp.Compare(a.order, b.order)
+		})
+	}
+
+	return svcs
+}
+
+// sliceServiceSeq yields the services resolved for a slice of the element key in registration order,
+// with services registered with a child scope before services registered with its parent Containers.
+// Use this instead of sliceServices to check or count the services without allocating.
+func (c *Container) sliceServiceSeq(elemKey serviceKey) iter.Seq[*service] {
+	return func(yield func(*service) bool) {
+		for s := c; s != nil; s = s.parent {
+			if !c.visible(s, elemKey) {
+				return
+			}
+
+			for _, svc := range s.services[elemKey] {
+				if svc.chain || (svc.decorator && c.decoratorsDisabled) {
+					continue
+				}
+				if !yield(svc) {
+					return
+				}
+			}
+
+			// Services registered with parent Containers were replaced
+			if s.isReplaced(elemKey) {
+				return
+			}
+		}
+	}
+}
+
+// hasSliceServices returns true if any services would be resolved for a slice of the element key.
+func (c *Container) hasSliceServices(elemKey serviceKey) bool {
+	for range c.sliceServiceSeq(elemKey) {
+		return true
+	}
+	return false
+}
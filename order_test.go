@@ -0,0 +1,113 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithOrder(t *testing.T) {
+	ctx := context.Background()
+
+	newA := func(tag string) *testtypes.StructA {
+		return &testtypes.StructA{Tag: tag}
+	}
+
+	t.Run("ResolveAll", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(newA("a"), di.As[testtypes.InterfaceA](), di.WithOrder(10)),
+			di.WithService(newA("b"), di.As[testtypes.InterfaceA]()),
+			di.WithService(newA("c"), di.As[testtypes.InterfaceA](), di.WithOrder(-10)),
+			di.WithService(newA("d"), di.As[testtypes.InterfaceA]()),
+		)
+		require.NoError(t, err)
+
+		got, err := di.ResolveAll[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, []testtypes.InterfaceA{newA("c"), newA("b"), newA("d"), newA("a")}, got)
+	})
+
+	t.Run("slice dependency across modules", func(t *testing.T) {
+		auth := di.Module{
+			di.WithService(newA("auth"), di.As[testtypes.InterfaceA](), di.WithOrder(2)),
+		}
+		logging := di.Module{
+			di.WithService(newA("logging"), di.As[testtypes.InterfaceA](), di.WithOrder(1)),
+		}
+
+		var got []testtypes.InterfaceA
+		c, err := di.NewContainer(
+			di.WithModule(auth),
+			di.WithModule(logging),
+			di.WithService(func(as []testtypes.InterfaceA) *testtypes.StructB {
+				got = as
+				return &testtypes.StructB{}
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*testtypes.StructB](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, []testtypes.InterfaceA{newA("logging"), newA("auth")}, got)
+	})
+
+	t.Run("across scopes", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(newA("parent"), di.As[testtypes.InterfaceA]()),
+			di.WithService(newA("parent last"), di.As[testtypes.InterfaceA](), di.WithOrder(10)),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope(
+			di.WithService(newA("child"), di.As[testtypes.InterfaceA]()),
+			di.WithService(newA("child first"), di.As[testtypes.InterfaceA](), di.WithOrder(-10)),
+		)
+		require.NoError(t, err)
+
+		got, err := di.ResolveAll[testtypes.InterfaceA](ctx, scope)
+		require.NoError(t, err)
+		assert.Equal(t, []testtypes.InterfaceA{
+			newA("child first"),
+			newA("child"),
+			newA("parent"),
+			newA("parent last"),
+		}, got)
+	})
+
+	t.Run("nested scopes", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(newA("root"), di.As[testtypes.InterfaceA]()),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope(
+			di.WithService(newA("scope"), di.As[testtypes.InterfaceA]()),
+		)
+		require.NoError(t, err)
+
+		child, err := scope.NewScope(
+			di.WithService(newA("child"), di.As[testtypes.InterfaceA]()),
+		)
+		require.NoError(t, err)
+
+		got, err := di.ResolveAll[testtypes.InterfaceA](ctx, child)
+		require.NoError(t, err)
+		assert.Equal(t, []testtypes.InterfaceA{newA("child"), newA("scope"), newA("root")}, got)
+	})
+
+	t.Run("single resolve unchanged", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(newA("a"), di.WithOrder(10)),
+			di.WithService(newA("b"), di.WithOrder(-10)),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[*testtypes.StructA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, newA("b"), got)
+	})
+}
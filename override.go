@@ -0,0 +1,93 @@
+package di
+
+import (
+	"slices"
+)
+
+// WithServiceOverride registers a service like [WithService], replacing the services registered earlier
+// for any of the same types and tags.
+//
+// The replaced services are removed from the Container for those types and tags, so they are not resolved
+// as part of a slice of services. A replaced service is still registered for its other types and tags;
+// otherwise it is removed from the Container entirely, and a value service is not closed.
+// When registered with a child scope, services registered for the same types and tags with parent Containers
+// are not resolved from the child scope, even as part of a slice of services,
+// and no [Shadowed] event is emitted.
+//
+// This is useful when layering test or environment-specific modules on top of the application's modules.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		app.Dependencies,
+//		di.WithServiceOverride(storage.NewMemoryStore, di.As[storage.Store]()),
+//	)
+//
+// All [ServiceOption]s supported by [WithService] are available. See [Replace].
+func WithServiceOverride(funcOrValue any, opts ...ServiceOption) ContainerOption {
+	return WithService(funcOrValue, append(slices.Clip(opts), Replace())...)
+}
+
+// Replace configures a service to replace the services registered earlier for any of the same types and tags.
+//
+// This can be used with any function that registers a service, like [Register] or [WithStruct].
+// See [WithServiceOverride] for more information.
+func Replace() ServiceOption {
+	return serviceOption(func(s *service) error {
+		s.replace = true
+		return nil
+	})
+}
+
+// replaceServices removes the services registered for any of the keys of svc.
+// Services registered for other keys as well are only removed from the keys of svc.
+func (c *Container) replaceServices(svc *service) {
+	if c.replaced == nil {
+		c.replaced = make(map[serviceKey]struct{})
+	}
+
+	replaced := make(map[*service]struct{})
+	for _, key := range svc.Keys() {
+		c.replaced[key] = struct{}{}
+		for _, old := range c.services[key] {
+			if old.replacedKeys == nil {
+				old.replacedKeys = make(map[serviceKey]struct{})
+			}
+			old.replacedKeys[key] = struct{}{}
+			replaced[old] = struct{}{}
+		}
+		delete(c.services, key)
+	}
+
+	// Remove the services that don't have any keys left
+	removed := make(map[*service]struct{})
+	for old := range replaced {
+		if len(old.Keys()) == 0 {
+			removed[old] = struct{}{}
+		}
+	}
+	if len(removed) == 0 {
+		return
+	}
+
+	isRemoved := func(s *service) bool {
+		_, ok := removed[s]
+		return ok
+	}
+
+	c.registered = slices.DeleteFunc(c.registered, isRemoved)
+
+	// Only value services have closers while the Container is being created
+	for i := len(c.closerSvcs) - 1; i >= 0; i-- {
+		if isRemoved(c.closerSvcs[i]) {
+			c.closers = slices.Delete(c.closers, i, i+1)
+			c.closerSvcs = slices.Delete(c.closerSvcs, i, i+1)
+		}
+	}
+}
+
+// isReplaced returns true if services registered for key with parent Containers are replaced.
+func (c *Container) isReplaced(key serviceKey) bool {
+	_, ok := c.replaced[key]
+	return ok
+}
@@ -0,0 +1,134 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithServiceOverride(t *testing.T) {
+	ctx := context.Background()
+
+	a1 := testtypes.StructA{Tag: 1}
+	a2 := testtypes.StructA{Tag: 2}
+	a3 := testtypes.StructA{Tag: 3}
+
+	t.Run("replaces slice services", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(a1, di.As[testtypes.InterfaceA]()),
+			di.WithService(a2, di.As[testtypes.InterfaceA]()),
+			di.WithServiceOverride(a3, di.As[testtypes.InterfaceA]()),
+		)
+		require.NoError(t, err)
+
+		a, err := di.Resolve[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, a3, a)
+
+		all, err := di.Resolve[[]testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, []testtypes.InterfaceA{a3}, all)
+	})
+
+	t.Run("later registrations", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(a1, di.As[testtypes.InterfaceA]()),
+			di.WithServiceOverride(a2, di.As[testtypes.InterfaceA]()),
+			di.WithService(a3, di.As[testtypes.InterfaceA]()),
+		)
+		require.NoError(t, err)
+
+		all, err := di.Resolve[[]testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, []testtypes.InterfaceA{a2, a3}, all)
+	})
+
+	t.Run("other keys of replaced service", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewStructAPtr,
+				di.As[*testtypes.StructA](),
+				di.As[testtypes.InterfaceA](),
+			),
+			di.WithServiceOverride(a1, di.As[testtypes.InterfaceA]()),
+		)
+		require.NoError(t, err)
+
+		// The replaced service is still registered for its other types
+		assert.True(t, c.Contains(testtypes.TypeStructAPtr))
+		assert.Equal(t, []string{"*testtypes.StructA", "testtypes.InterfaceA"}, serviceNames(mustTopologicalOrder(t, c)))
+
+		all, err := di.ResolveAll[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, []testtypes.InterfaceA{a1}, all)
+	})
+
+	t.Run("value service not closed", func(t *testing.T) {
+		var closed []string
+		c, err := di.NewContainer(
+			di.WithService(closeRecorder{closed: &closed, name: "old"}, di.UseCloser()),
+			di.WithServiceOverride(closeRecorder{closed: &closed, name: "new"}, di.UseCloser()),
+		)
+		require.NoError(t, err)
+
+		err = c.Close(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"new"}, closed)
+	})
+
+	t.Run("child scope", func(t *testing.T) {
+		rec := &eventRecorder{}
+		c, err := di.NewContainer(
+			di.WithService(a1, di.As[testtypes.InterfaceA]()),
+			di.WithEventHandler(rec.Handler()),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope(
+			di.WithServiceOverride(a2, di.As[testtypes.InterfaceA]()),
+			di.WithService(a3, di.As[testtypes.InterfaceA]()),
+		)
+		require.NoError(t, err)
+
+		all, err := di.Resolve[[]testtypes.InterfaceA](ctx, scope)
+		require.NoError(t, err)
+		assert.Equal(t, []testtypes.InterfaceA{a2, a3}, all)
+
+		all, err = di.Resolve[[]testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, []testtypes.InterfaceA{a1}, all)
+
+		assert.Equal(t, []string{
+			"Registered testtypes.InterfaceA",
+			"Registered testtypes.InterfaceA",
+			"Registered testtypes.InterfaceA",
+			"Shadowed testtypes.InterfaceA",
+			"ScopeCreated",
+		}, rec.Kinds())
+	})
+
+	t.Run("Replace", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(a1, di.As[testtypes.InterfaceA]()),
+			di.Register[testtypes.InterfaceA](func(context.Context) (testtypes.InterfaceA, error) {
+				return a2, nil
+			}, di.Replace()),
+		)
+		require.NoError(t, err)
+
+		all, err := di.Resolve[[]testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, []testtypes.InterfaceA{a2}, all)
+	})
+}
+
+func mustTopologicalOrder(t *testing.T, c *di.Container) []di.ServiceInfo {
+	t.Helper()
+
+	order, err := c.TopologicalOrder()
+	require.NoError(t, err)
+	return order
+}
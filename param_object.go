@@ -0,0 +1,163 @@
+package di
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// In can be embedded in a struct to use the struct as a parameter object.
+//
+// A constructor function or [Invoke] function parameter that is a parameter object is not
+// resolved from the Container itself. Instead, each exported field is resolved independently,
+// like a separate parameter. This keeps function signatures short and stable as dependencies change.
+//
+// Use the `di` struct tag to configure a field:
+//   - `di:"tag=name"` resolves the field with [WithTag]("name").
+//   - `di:"optional"` leaves the field as the zero value if the service is not registered.
+//   - `di:"-"` skips the field.
+//
+// Options can be combined, like `di:"optional,tag=name"`.
+// Fields can also be a [context.Context], a slice of services, or an [Optional] service.
+//
+// Example:
+//
+//	type ServiceParams struct {
+//		di.In
+//
+//		Logger  *slog.Logger
+//		Store   storage.Store
+//		Replica *sql.DB      `di:"tag=replica"`
+//		Cache   *cache.Cache `di:"optional"`
+//	}
+//
+//	func NewService(p ServiceParams) *Service {
+//		// ...
+//	}
+type In struct{}
+
+var typeIn = reflect.TypeFor[In]()
+
+// inField is a field of a parameter object that is resolved from the Container.
+type inField struct {
+	key      serviceKey
+	index    int
+	optional bool
+}
+
+// inFieldsCache caches the fields of parameter object types. The values are []inField.
+var inFieldsCache sync.Map
+
+// isInType returns true if t is a struct that embeds [In].
+func isInType(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	if _, ok := inFieldsCache.Load(t); ok {
+		return true
+	}
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if field.Anonymous && field.Type == typeIn {
+			return true
+		}
+	}
+
+	return false
+}
+
+// inFields returns the fields resolved for the parameter object type t.
+func inFields(t reflect.Type) ([]inField, error) {
+	if fields, ok := inFieldsCache.Load(t); ok {
+		return fields.([]inField), nil
+	}
+
+	fields := make([]inField, 0, t.NumField())
+	var errs []error
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if field.Anonymous && field.Type == typeIn {
+			continue
+		}
+
+		st, err := parseStructTag(&field, tagOptional)
+		if err == nil && st.skip {
+			continue
+		}
+
+		f, err := parseInField(&field, st, err)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "field %s", field.Name))
+			continue
+		}
+
+		f.index = i
+		fields = append(fields, f)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	inFieldsCache.Store(t, fields)
+	return fields, nil
+}
+
+func parseInField(field *reflect.StructField, st structTag, tagErr error) (inField, error) {
+	f := inField{
+		key:      serviceKey{Type: field.Type, Tag: st.tag},
+		optional: st.optional,
+	}
+
+	switch {
+	case tagErr != nil:
+		return f, tagErr
+	case !field.IsExported():
+		return f, errors.New("unexported field cannot be injected")
+	case field.Type == typeScope:
+		return f, errors.New("di.Scope is not supported in a parameter object")
+	case isInType(field.Type):
+		return f, errors.New("parameter objects cannot be nested")
+	case !validateDependencyType(field.Type):
+		return f, errors.Errorf("invalid dependency type %s; %s", field.Type, invalidTypeHint(field.Type))
+	}
+
+	return f, nil
+}
+
+// resolveInKey creates a parameter object with each field resolved from the scope.
+func resolveInKey(
+	ctx context.Context,
+	scope *Container,
+	key serviceKey,
+	visitor resolveVisitor,
+) (any, error) {
+	fields, err := inFields(key.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	structVal := reflect.New(key.Type).Elem()
+	for _, f := range fields {
+		if f.key.Type == typeContext {
+			structVal.Field(f.index).Set(reflect.ValueOf(ctx))
+			continue
+		}
+
+		val, err := resolveKey(ctx, scope, f.key, visitor, false)
+		// Only if the service itself is not registered, not one of its dependencies
+		if f.optional && err == errServiceNotRegistered {
+			continue
+		}
+		if err != nil {
+			return nil, &dependencyError{Key: f.key, Err: err}
+		}
+
+		structVal.Field(f.index).Set(safeReflectValue(f.key.Type, val))
+	}
+
+	return structVal.Interface(), nil
+}
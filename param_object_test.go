@@ -0,0 +1,156 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type serviceParams struct {
+	di.In
+	Ctx      context.Context
+	A        testtypes.InterfaceA
+	Tagged   testtypes.InterfaceA `di:"tag=tag"`
+	B        testtypes.InterfaceB `di:"optional"`
+	Skipped  testtypes.InterfaceC `di:"-"`
+	Optional di.Optional[testtypes.InterfaceD]
+	Slice    []testtypes.InterfaceA
+}
+
+func Test_In(t *testing.T) {
+	ctx := testutils.ContextWithTestValue(context.Background(), "value")
+
+	a := testtypes.StructA{}
+	tagged := testtypes.StructA{Tag: "tag"}
+
+	t.Run("constructor", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(a, di.As[testtypes.InterfaceA]()),
+			di.WithService(tagged, di.As[testtypes.InterfaceA](), di.WithTag("tag")),
+			di.WithService(func(p serviceParams) testtypes.InterfaceC {
+				assert.Equal(t, "value", testutils.TestValue(p.Ctx))
+				assert.Equal(t, a, p.A)
+				assert.Equal(t, tagged, p.Tagged)
+				assert.Nil(t, p.B)
+				assert.Nil(t, p.Skipped)
+				_, ok := p.Optional.Get()
+				assert.False(t, ok)
+				assert.Equal(t, []testtypes.InterfaceA{a}, p.Slice)
+
+				return testtypes.StructC{}
+			}),
+			di.WithDependencyValidation(),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceC](ctx, c)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Invoke", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(a, di.As[testtypes.InterfaceA]()),
+			di.WithService(tagged, di.As[testtypes.InterfaceA](), di.WithTag("tag")),
+			di.WithService(testtypes.NewInterfaceB),
+		)
+		require.NoError(t, err)
+
+		err = di.Invoke(ctx, c, func(p serviceParams) {
+			assert.Equal(t, &testtypes.StructB{}, p.B)
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("dependency not registered", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func(serviceParams) testtypes.InterfaceC {
+				return testtypes.StructC{}
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceC](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve testtypes.InterfaceC: "+
+			"dependency di_test.serviceParams: dependency testtypes.InterfaceA: service not registered")
+	})
+
+	t.Run("optional dependency error", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(a, di.As[testtypes.InterfaceA]()),
+			di.WithService(tagged, di.As[testtypes.InterfaceA](), di.WithTag("tag")),
+			di.WithService(func() (testtypes.InterfaceB, error) {
+				return nil, errors.New("constructor error")
+			}),
+			di.WithService(func(serviceParams) testtypes.InterfaceC {
+				return testtypes.StructC{}
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceC](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve testtypes.InterfaceC: "+
+			"dependency di_test.serviceParams: dependency testtypes.InterfaceB: constructor error")
+	})
+
+	t.Run("WithDependencyValidation", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(a, di.As[testtypes.InterfaceA]()),
+			di.WithService(func(serviceParams) testtypes.InterfaceC {
+				return testtypes.StructC{}
+			}),
+			di.WithDependencyValidation(),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithDependencyValidation: "+
+			"service func(di_test.serviceParams) testtypes.InterfaceC: "+
+			"dependency testtypes.InterfaceA: WithTag tag: service not registered")
+	})
+
+	t.Run("invalid fields", func(t *testing.T) {
+		type params struct {
+			di.In
+
+			a      testtypes.InterfaceA
+			Scope  di.Scope
+			BadTag testtypes.InterfaceA `di:"tag"`
+			Int    int
+		}
+		_ = params{}.a
+
+		c, err := di.NewContainer(
+			di.WithService(func(params) testtypes.InterfaceC { return nil }),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func(di_test.params) testtypes.InterfaceC: parameter 0: "+
+			"field a: unexported field cannot be injected\n"+
+			"field Scope: di.Scope is not supported in a parameter object\n"+
+			"field BadTag: invalid di tag \"tag\"\n"+
+			"field Int: invalid dependency type int; use a named type or a pointer to a named type")
+	})
+
+	t.Run("TopologicalOrder", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func(serviceParams) testtypes.InterfaceC {
+				return testtypes.StructC{}
+			}),
+			di.WithService(a, di.As[testtypes.InterfaceA]()),
+		)
+		require.NoError(t, err)
+
+		order, err := c.TopologicalOrder()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"testtypes.InterfaceA", "testtypes.InterfaceC"}, serviceNames(order))
+	})
+}
@@ -0,0 +1,263 @@
+package di
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// WithPrewarm keeps n instances of a [Transient] service created ahead of time when calling [WithService].
+//
+// This trades memory for latency for services that are expensive to create.
+// The instances are created in the background when the [Container] is created,
+// and another instance is created each time one is resolved.
+// If no instance is ready, the service is created when resolved as usual.
+//
+// Instances are created with [context.Background] and dependencies resolved from the Container
+// the service is registered with. If the constructor function returns an error, the error is ignored
+// and the service is created when resolved instead.
+// An instance is closed by the scope it is resolved from, like any other Transient service.
+// Instances that are never resolved are closed when the Container the service is registered with is closed.
+//
+// A child scope only takes an instance from the pool if it doesn't register any services
+// the instance depends on, so services registered with a child scope are not ignored.
+// Otherwise, the service is created when resolved as usual.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithService(render.NewSandbox, // NewSandbox(*render.Config) (*render.Sandbox, error)
+//			di.Transient,
+//			di.WithPrewarm(4),
+//		),
+//	)
+//
+// This option will return an error if n is not positive, or the service is not [Transient].
+// Creating the Container will return an error if the service depends on a [Scoped] service,
+// since an instance created ahead of time can't be used by more than one scope.
+func WithPrewarm(n int) ServiceOption {
+	return serviceOption(func(s *service) error {
+		if n <= 0 {
+			return errors.Errorf("WithPrewarm %d: count must be positive", n)
+		}
+
+		s.prewarm = &prewarmPool{
+			ready: make(chan *prewarmed, n),
+		}
+		return nil
+	})
+}
+
+// prewarmPool holds instances of a service created ahead of time.
+type prewarmPool struct {
+	ready chan *prewarmed
+	// deps are the types the service depends on, directly or through its dependencies
+	deps    map[reflect.Type]struct{}
+	pending int
+	mu      sync.Mutex
+}
+
+// prewarmed is an instance created ahead of time, with the Closer for the scope that takes it.
+type prewarmed struct {
+	Svc    *service
+	Val    any
+	Closer Closer
+}
+
+// prewarmingKey is a context key used to create an instance for the pool instead of taking one from it.
+type prewarmingKey struct{}
+
+// prewarmingFor returns the instance being created for the pool of svc, if any.
+func prewarmingFor(ctx context.Context, svc *service) *prewarmed {
+	p, _ := ctx.Value(prewarmingKey{}).(*prewarmed)
+	if p == nil || p.Svc != svc {
+		return nil
+	}
+
+	return p
+}
+
+// Take an instance from the pool if one is ready and it can be used by scope.
+// The Closer of the instance is added to scope.
+func (p *prewarmPool) Take(scope *Container, svc *service) (any, bool) {
+	if !p.usableFrom(scope, svc.Scope()) {
+		return nil, false
+	}
+
+	var entry *prewarmed
+	select {
+	case entry = <-p.ready:
+	default:
+		return nil, false
+	}
+
+	if entry.Closer != nil {
+		scope.lockClosers()
+		scope.appendCloser(entry.Closer, svc)
+		scope.closersMu.Unlock()
+	}
+
+	return entry.Val, true
+}
+
+// usableFrom returns true if the scopes from scope up to owner, the Container the service is registered with,
+// don't register any of the types the service depends on.
+func (p *prewarmPool) usableFrom(scope, owner *Container) bool {
+	if scope == owner {
+		return true
+	}
+	if _, ok := p.deps[typeScope]; ok {
+		return false
+	}
+
+	for s := scope; s != nil && s != owner; s = s.parent {
+		for _, svc := range s.registered {
+			for _, key := range svc.Keys() {
+				if _, ok := p.deps[key.Type]; ok {
+					return false
+				}
+			}
+		}
+	}
+
+	return true
+}
+
+// Fill starts creating instances in the background until the pool is full.
+func (p *prewarmPool) Fill(c *Container, key serviceKey, svc *service) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for ; len(p.ready)+p.pending < cap(p.ready); p.pending++ {
+		go p.create(c, key, svc)
+	}
+}
+
+func (p *prewarmPool) create(c *Container, key serviceKey, svc *service) {
+	defer func() {
+		p.mu.Lock()
+		p.pending--
+		p.mu.Unlock()
+	}()
+
+	c.closedMu.RLock()
+	defer c.closedMu.RUnlock()
+
+	if c.closed {
+		return
+	}
+
+	entry := &prewarmed{Svc: svc}
+	ctx := context.WithValue(context.Background(), prewarmingKey{}, entry)
+	val, err := resolveService(ctx, c, key, svc, make(resolveVisitor))
+	if err != nil {
+		return
+	}
+	entry.Val = val
+
+	// There is always room, since pending instances are counted
+	p.ready <- entry
+}
+
+// Close the instances that were never taken from the pool.
+func (p *prewarmPool) Close(ctx context.Context) error {
+	var errs []error
+	for {
+		select {
+		case entry := <-p.ready:
+			if entry.Closer == nil {
+				continue
+			}
+			if err := entry.Closer.Close(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		default:
+			return errors.Join(errs...)
+		}
+	}
+}
+
+// checkPrewarm finds the dependencies of the services registered with [WithPrewarm],
+// and returns an error if any of them depends on a [Scoped] service.
+func (c *Container) checkPrewarm() error {
+	for _, svc := range c.registered {
+		if svc.prewarm == nil {
+			continue
+		}
+
+		deps := make(map[reflect.Type]struct{})
+		if scoped := c.prewarmDeps(svc, deps, make(map[*service]struct{})); scoped != nil {
+			return errors.Errorf("WithPrewarm %s: dependency %s is Scoped", svc, scoped.Keys()[0])
+		}
+		svc.prewarm.deps = deps
+	}
+
+	return nil
+}
+
+// prewarmDeps adds the types svc depends on to deps, directly or through its dependencies.
+// It returns the first Scoped service found, if any.
+func (c *Container) prewarmDeps(svc *service, deps map[reflect.Type]struct{}, seen map[*service]struct{}) *service {
+	if _, ok := seen[svc]; ok {
+		return nil
+	}
+	seen[svc] = struct{}{}
+
+	for i, dep := range svc.Dependencies() {
+		// Parameters bound with WithArgs are not resolved
+		if svc.arg(i).IsValid() {
+			continue
+		}
+
+		addDependencyTypes(dep.Type, deps)
+
+		for _, depSvc := range c.dependencyServices(dep) {
+			if depSvc.Lifetime() == Scoped {
+				return depSvc
+			}
+			if scoped := c.prewarmDeps(depSvc, deps, seen); scoped != nil {
+				return scoped
+			}
+		}
+	}
+
+	return nil
+}
+
+// addDependencyTypes adds the service types resolved for a dependency of type t.
+func addDependencyTypes(t reflect.Type, deps map[reflect.Type]struct{}) {
+	if isInType(t) {
+		fields, _ := inFields(t)
+		for _, f := range fields {
+			addDependencyTypes(f.key.Type, deps)
+		}
+		return
+	}
+
+	if elemType, ok := optionalElem(t); ok {
+		t = elemType
+	}
+	if isUnnamedSliceType(t) || isStringMapType(t) {
+		t = t.Elem()
+	}
+
+	deps[t] = struct{}{}
+}
+
+// startPrewarm starts creating instances of services registered with [WithPrewarm].
+// The instances that are never resolved are closed with the Container.
+func (c *Container) startPrewarm() {
+	for _, svc := range c.registered {
+		if svc.prewarm == nil {
+			continue
+		}
+
+		c.lockClosers()
+		c.appendCloser(svc.prewarm, svc)
+		c.closersMu.Unlock()
+
+		svc.prewarm.Fill(c, svc.Keys()[0], svc)
+	}
+}
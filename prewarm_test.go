@@ -0,0 +1,164 @@
+package di_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithPrewarm(t *testing.T) {
+	t.Run("instances created ahead of time", func(t *testing.T) {
+		var created atomic.Int32
+
+		c, err := di.NewContainer(
+			di.WithService(func() *testtypes.StructA {
+				return &testtypes.StructA{Tag: created.Add(1)}
+			},
+				di.Transient,
+				di.WithPrewarm(2),
+			),
+		)
+		require.NoError(t, err)
+
+		assert.Eventually(t, func() bool { return created.Load() == 2 }, time.Second, time.Millisecond)
+
+		a1, err := di.Resolve[*testtypes.StructA](context.Background(), c)
+		require.NoError(t, err)
+		a2, err := di.Resolve[*testtypes.StructA](context.Background(), c)
+		require.NoError(t, err)
+
+		assert.NotSame(t, a1, a2)
+		assert.ElementsMatch(t, []any{int32(1), int32(2)}, []any{a1.Tag, a2.Tag})
+
+		// The pool is replenished in the background
+		assert.Eventually(t, func() bool { return created.Load() == 4 }, time.Second, time.Millisecond)
+	})
+
+	t.Run("constructor error", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func(ctx context.Context) (*testtypes.StructA, error) {
+				if testutils.TestValue(ctx) == nil {
+					return nil, errors.New("missing value")
+				}
+				return &testtypes.StructA{}, nil
+			},
+				di.Transient,
+				di.WithPrewarm(1),
+			),
+		)
+		require.NoError(t, err)
+
+		ctx := testutils.ContextWithTestValue(context.Background(), "value")
+		a, err := di.Resolve[*testtypes.StructA](ctx, c)
+		assert.NoError(t, err)
+		assert.NotNil(t, a)
+	})
+
+	t.Run("closed by resolving scope", func(t *testing.T) {
+		var created, closed atomic.Int32
+
+		c, err := di.NewContainer(
+			di.WithService(func() *testtypes.StructA {
+				created.Add(1)
+				return &testtypes.StructA{}
+			},
+				di.Transient,
+				di.WithPrewarm(3),
+				di.UseCloseFunc(func(context.Context, *testtypes.StructA) error {
+					closed.Add(1)
+					return nil
+				}),
+			),
+		)
+		require.NoError(t, err)
+
+		assert.Eventually(t, func() bool { return created.Load() == 3 }, time.Second, time.Millisecond)
+
+		scope, err := c.NewScope()
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*testtypes.StructA](context.Background(), scope)
+		require.NoError(t, err)
+
+		// The instance is owned by the scope it was resolved from
+		require.NoError(t, scope.Close(context.Background()))
+		assert.Equal(t, int32(1), closed.Load())
+
+		// The instances left in the pool are closed with the Container
+		require.NoError(t, c.Close(context.Background()))
+		assert.GreaterOrEqual(t, closed.Load(), int32(3))
+	})
+
+	t.Run("child scope registers dependency", func(t *testing.T) {
+		type sandbox struct{ Tag any }
+		var created atomic.Int32
+
+		c, err := di.NewContainer(
+			di.WithService(testtypes.StructA{Tag: "parent"}),
+			di.WithService(func(a testtypes.StructA) *sandbox {
+				created.Add(1)
+				return &sandbox{Tag: a.Tag}
+			},
+				di.Transient,
+				di.WithPrewarm(1),
+			),
+		)
+		require.NoError(t, err)
+
+		assert.Eventually(t, func() bool { return created.Load() == 1 }, time.Second, time.Millisecond)
+
+		scope, err := c.NewScope(
+			di.WithService(testtypes.StructA{Tag: "child"}),
+		)
+		require.NoError(t, err)
+
+		// The instance in the pool was created with the parent's dependency
+		b, err := di.Resolve[*sandbox](context.Background(), scope)
+		require.NoError(t, err)
+		assert.Equal(t, "child", b.Tag)
+
+		b, err = di.Resolve[*sandbox](context.Background(), c)
+		require.NoError(t, err)
+		assert.Equal(t, "parent", b.Tag)
+	})
+
+	t.Run("Scoped dependency", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.Scoped),
+			di.WithService(testtypes.NewInterfaceB, di.Transient, di.WithPrewarm(1)),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithPrewarm func(testtypes.InterfaceA) testtypes.InterfaceB: "+
+			"dependency testtypes.InterfaceA is Scoped")
+	})
+
+	t.Run("Singleton", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.WithPrewarm(1)),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func() testtypes.InterfaceA: WithPrewarm 1: service must be Transient")
+	})
+
+	t.Run("invalid count", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.Transient, di.WithPrewarm(0)),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func() testtypes.InterfaceA: WithPrewarm 0: count must be positive")
+	})
+}
@@ -0,0 +1,62 @@
+package di
+
+import (
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// WithPrimary marks a service as the primary service for its types and tags when calling [WithService].
+//
+// By default, when multiple services are registered for the same type and tag, resolving a single service
+// resolves the last one registered, which makes the order of modules matter.
+// With this option, the primary service is resolved instead, regardless of the order it was registered in.
+// Resolving a slice of services still returns all of them, in the usual order.
+// [WithStrictResolve] does not return an error when one of the services is primary.
+//
+// Only one service can be primary for each type and tag registered with a Container.
+// A service registered with a child scope for the same type and tag is still resolved from the child scope,
+// even if the service registered with the parent Container is primary.
+// A service registered with [Chain] is resolved instead of the primary service.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithService(storage.NewDBStore, di.As[storage.Store](), di.WithPrimary()),
+//		cache.Module, // Registers another storage.Store
+//	)
+//
+// [NewContainer] will return an error if more than one service is primary for the same type and tag.
+func WithPrimary() ServiceOption {
+	return serviceOption(func(s *service) error {
+		s.primary = true
+		return nil
+	})
+}
+
+// IsPrimary returns true if the service was registered with [WithPrimary].
+func (s *service) IsPrimary() bool {
+	return s.primary
+}
+
+// checkPrimaries returns an error if more than one service is primary for the same type and tag.
+func (c *Container) checkPrimaries() error {
+	for _, svc := range c.registered {
+		if !svc.primary {
+			continue
+		}
+
+		for _, key := range svc.Keys() {
+			count := 0
+			for _, s := range c.services[key] {
+				if s.primary {
+					count++
+				}
+			}
+
+			if count > 1 {
+				return errors.Errorf("WithPrimary %s: %d services registered as primary", key, count)
+			}
+		}
+	}
+
+	return nil
+}
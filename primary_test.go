@@ -0,0 +1,123 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithPrimary(t *testing.T) {
+	ctx := context.Background()
+
+	newA := func(tag string) *testtypes.StructA {
+		return &testtypes.StructA{Tag: tag}
+	}
+
+	t.Run("registered first", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(newA("primary"), di.As[testtypes.InterfaceA](), di.WithPrimary()),
+			di.WithService(newA("b"), di.As[testtypes.InterfaceA]()),
+			di.WithService(newA("c"), di.As[testtypes.InterfaceA]()),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, newA("primary"), got)
+
+		all, err := di.ResolveAll[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, []testtypes.InterfaceA{newA("primary"), newA("b"), newA("c")}, all)
+	})
+
+	t.Run("dependency", func(t *testing.T) {
+		var got testtypes.InterfaceA
+		c, err := di.NewContainer(
+			di.WithService(newA("primary"), di.As[testtypes.InterfaceA](), di.WithPrimary()),
+			di.WithService(newA("b"), di.As[testtypes.InterfaceA]()),
+			di.WithService(func(a testtypes.InterfaceA) testtypes.InterfaceB {
+				got = a
+				return testtypes.StructB{}
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceB](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, newA("primary"), got)
+	})
+
+	t.Run("strict resolve", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithStrictResolve(),
+			di.WithService(newA("a")),
+			di.WithService(newA("primary"), di.WithPrimary()),
+			di.WithService(newA("c")),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[*testtypes.StructA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, newA("primary"), got)
+	})
+
+	t.Run("child scope", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(newA("primary"), di.WithPrimary()),
+			di.WithService(newA("b")),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope(
+			di.WithService(newA("child")),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[*testtypes.StructA](ctx, scope)
+		require.NoError(t, err)
+		assert.Equal(t, newA("child"), got)
+	})
+
+	t.Run("manifest", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(newA("primary"), di.WithPrimary()),
+			di.WithService(newA("b")),
+		)
+		require.NoError(t, err)
+
+		var primaries []bool
+		for _, s := range c.Manifest().Services {
+			if s.Type == "*testtypes.StructA" {
+				primaries = append(primaries, s.Primary)
+			}
+		}
+		assert.Equal(t, []bool{true, false}, primaries)
+	})
+
+	t.Run("multiple primary", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(newA("a"), di.As[testtypes.InterfaceA](), di.WithPrimary()),
+			di.WithService(newA("b"), di.As[testtypes.InterfaceA](), di.WithPrimary()),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithPrimary testtypes.InterfaceA: 2 services registered as primary")
+	})
+
+	t.Run("primary with different tags", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(newA("a"), di.WithTag("a"), di.WithPrimary()),
+			di.WithService(newA("b"), di.WithTag("b"), di.WithPrimary()),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[*testtypes.StructA](ctx, c, di.WithTag("b"))
+		require.NoError(t, err)
+		assert.Equal(t, newA("b"), got)
+	})
+}
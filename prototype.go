@@ -0,0 +1,99 @@
+package di
+
+import (
+	"reflect"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// Prototype specifies that each resolve of a value service returns a shallow copy of the value
+// when calling [WithService].
+//
+// By default, a value service is a [Singleton], so every consumer shares the same value.
+// With this option, each consumer gets its own copy, which avoids aliasing bugs
+// when consumers modify a struct value, like a default configuration.
+// The value must be a struct or a pointer to a struct. For a pointer, a pointer to a copy of the struct is returned.
+// Fields like pointers, slices, and maps still refer to the same data; use [PrototypeFunc] for a deep copy.
+//
+// Copies are not closed by the [Container]. Only the registered value is closed if [UseCloser] is used.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithService(&http.Transport{MaxIdleConns: 10}, di.Prototype()),
+//	)
+//
+// This option will return an error if the service is not a value service,
+// or the value is not a struct or a pointer to a struct.
+// It cannot be used with a [Lifetime], in any order.
+func Prototype() ServiceOption {
+	return serviceOption(func(s *service) error {
+		if !s.IsValue() {
+			return errors.New("Prototype: only supported for value service")
+		}
+
+		t := s.v.Type()
+		switch {
+		case t.Kind() == reflect.Struct:
+			s.prototype = func(val any) any {
+				return val
+			}
+		case t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Struct:
+			s.prototype = func(val any) any {
+				cp := reflect.New(t.Elem())
+				cp.Elem().Set(reflect.ValueOf(val).Elem())
+				return cp.Interface()
+			}
+		default:
+			return errors.Errorf("Prototype: type %s must be a struct or pointer to struct", t)
+		}
+
+		return nil
+	})
+}
+
+// PrototypeFunc specifies that each resolve of a value service returns a copy of the value created by clone
+// when calling [WithService].
+//
+// This works like [Prototype], but clone is called with the registered value each time the service is resolved,
+// so it can make a deep copy of any type of value.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithService(defaultConfig, di.PrototypeFunc(func(cfg *Config) *Config {
+//			return cfg.Clone()
+//		})),
+//	)
+//
+// This option will return an error if clone is nil, the service is not a value service,
+// or the value is not of type *Service*.
+// It cannot be used with a [Lifetime], in any order.
+func PrototypeFunc[Service any](clone func(Service) Service) ServiceOption {
+	return serviceOption(func(s *service) error {
+		t := reflect.TypeFor[Service]()
+		if clone == nil {
+			return errors.Errorf("PrototypeFunc %s: clone is nil", t)
+		}
+		if !s.IsValue() {
+			return errors.Errorf("PrototypeFunc %s: only supported for value service", t)
+		}
+		if s.v.Type() != t {
+			return errors.Errorf("PrototypeFunc %s: value has type %s", t, s.v.Type())
+		}
+
+		s.prototype = func(val any) any {
+			return clone(val.(Service))
+		}
+		return nil
+	})
+}
+
+// Instance returns the value of a value service, or a copy of the value for a [Prototype].
+func (s *service) Instance() any {
+	if s.prototype != nil {
+		return s.prototype(s.Value())
+	}
+
+	return s.Value()
+}
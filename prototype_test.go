@@ -0,0 +1,165 @@
+package di_test
+
+import (
+	"context"
+	"maps"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type protoConfig struct {
+	Labels map[string]string
+	Name   string
+}
+
+func Test_Prototype(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("pointer to struct", func(t *testing.T) {
+		cfg := &protoConfig{Name: "default"}
+		c, err := di.NewContainer(
+			di.WithService(cfg, di.Prototype()),
+		)
+		require.NoError(t, err)
+
+		got1, err := di.Resolve[*protoConfig](ctx, c)
+		require.NoError(t, err)
+		got1.Name = "changed"
+
+		got2, err := di.Resolve[*protoConfig](ctx, c)
+		require.NoError(t, err)
+
+		assert.NotSame(t, cfg, got1)
+		assert.NotSame(t, got1, got2)
+		assert.Equal(t, "default", got2.Name)
+		assert.Equal(t, "default", cfg.Name)
+	})
+
+	t.Run("struct", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(protoConfig{Name: "default"}, di.Prototype()),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[protoConfig](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, protoConfig{Name: "default"}, got)
+	})
+
+	t.Run("as interface", func(t *testing.T) {
+		a := &testtypes.StructA{Tag: "a"}
+		c, err := di.NewContainer(
+			di.WithService(a, di.As[testtypes.InterfaceA](), di.Prototype()),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, a, got)
+		assert.NotSame(t, a, got)
+	})
+
+	t.Run("function service", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewStructAPtr, di.Prototype()),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func() *testtypes.StructA: "+
+			"Prototype: only supported for value service")
+	})
+
+	t.Run("not a struct", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.CustomMap{}, di.Prototype()),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService testtypes.CustomMap: "+
+			"Prototype: type testtypes.CustomMap must be a struct or pointer to struct")
+	})
+	t.Run("with Singleton", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(&testtypes.StructA{}, di.Prototype(), di.Singleton),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService *testtypes.StructA: "+
+			"Prototype: cannot be used with Singleton")
+	})
+}
+
+func Test_PrototypeFunc(t *testing.T) {
+	ctx := context.Background()
+
+	clone := func(cfg *protoConfig) *protoConfig {
+		cp := *cfg
+		cp.Labels = maps.Clone(cfg.Labels)
+		return &cp
+	}
+
+	t.Run("deep copy", func(t *testing.T) {
+		cfg := &protoConfig{Labels: map[string]string{"env": "prod"}}
+		c, err := di.NewContainer(
+			di.WithService(cfg, di.PrototypeFunc(clone)),
+		)
+		require.NoError(t, err)
+
+		got1, err := di.Resolve[*protoConfig](ctx, c)
+		require.NoError(t, err)
+		got1.Labels["env"] = "dev"
+
+		got2, err := di.Resolve[*protoConfig](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, "prod", got2.Labels["env"])
+		assert.Equal(t, "prod", cfg.Labels["env"])
+	})
+
+	t.Run("manifest lifetime", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(&protoConfig{}, di.PrototypeFunc(clone)),
+		)
+		require.NoError(t, err)
+
+		for _, s := range c.Manifest().Services {
+			if s.Type == "*di_test.protoConfig" {
+				assert.Equal(t, "Transient", s.Lifetime)
+			}
+		}
+	})
+
+	t.Run("clone nil", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(&protoConfig{}, di.PrototypeFunc[*protoConfig](nil)),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService *di_test.protoConfig: "+
+			"PrototypeFunc *di_test.protoConfig: clone is nil")
+	})
+
+	t.Run("wrong type", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(&testtypes.StructA{}, di.PrototypeFunc(clone)),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService *testtypes.StructA: "+
+			"PrototypeFunc *di_test.protoConfig: value has type *testtypes.StructA")
+	})
+
+	t.Run("function service", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() *protoConfig { return nil }, di.PrototypeFunc(clone)),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func() *di_test.protoConfig: "+
+			"PrototypeFunc *di_test.protoConfig: only supported for value service")
+	})
+}
@@ -0,0 +1,35 @@
+package di
+
+import "math/rand/v2"
+
+// Rand provides pseudo-random numbers.
+//
+// A Rand is registered with every [Container] by default, using the top-level functions in [math/rand/v2].
+// Services should depend on Rand instead of using [math/rand/v2] directly,
+// so tests can use a deterministic source. See [ditest.WithRandSeed].
+//
+// This is implemented by [*rand.Rand]. Register another implementation to override the default:
+//
+//	di.WithServiceOverride(rand.New(src), di.As[di.Rand]())
+//
+// [ditest.WithRandSeed]: https://pkg.go.dev/github.com/sectrean/di-kit/ditest#WithRandSeed
+type Rand interface {
+	// Int64 returns a non-negative pseudo-random 63-bit integer as an int64.
+	Int64() int64
+	// IntN returns, as an int, a non-negative pseudo-random number in the half-open interval [0,n).
+	// It panics if n <= 0.
+	IntN(n int) int
+	// Float64 returns, as a float64, a pseudo-random number in the half-open interval [0.0,1.0).
+	Float64() float64
+}
+
+type globalRand struct{}
+
+func (globalRand) Int64() int64     { return rand.Int64() }
+func (globalRand) IntN(n int) int   { return rand.IntN(n) }
+func (globalRand) Float64() float64 { return rand.Float64() }
+
+var (
+	_ Rand = globalRand{}
+	_ Rand = (*rand.Rand)(nil)
+)
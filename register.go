@@ -0,0 +1,188 @@
+package di
+
+import (
+	"reflect"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// Register registers a constructor function for type *Service* when calling [NewContainer]
+// or [Container.NewScope].
+//
+// Unlike [WithService], the signature of the constructor function is checked at compile time,
+// and the function is called directly instead of with reflection when the service is resolved.
+//
+// The function has a single dependency of type *Deps*. Use a struct that embeds [In] for several dependencies,
+// or [context.Context] if the function has no dependencies. See [WithFunc1] for other signatures.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.Register[*sql.DB](func(cfg *Config) (*sql.DB, error) {
+//			return sql.Open("postgres", cfg.DatabaseURL)
+//		}),
+//	)
+//
+// All [ServiceOption]s supported by [WithService] are available.
+//
+// This option will return an error if f is nil, or if *Service* or *Deps* is not a valid type.
+func Register[Service, Deps any](f func(Deps) (Service, error), opts ...ServiceOption) ContainerOption {
+	return registerTyped("Register", f, func(deps []reflect.Value) (Service, error) {
+		return f(depAt[Deps](deps, 0))
+	}, opts)
+}
+
+// WithFunc0 registers a constructor function with no parameters like [WithService],
+// but the signature of the function is checked at compile time.
+//
+// The function may return *Service* or (*Service*, error).
+// It is called directly instead of with reflection when the service is resolved.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithFunc0[*Config](LoadConfig), // LoadConfig() (*Config, error)
+//	)
+func WithFunc0[Service any, F interface {
+	func() Service | func() (Service, error)
+}](f F, opts ...ServiceOption) ContainerOption {
+	var call func([]reflect.Value) (Service, error)
+	switch f := any(f).(type) {
+	case func() Service:
+		call = func([]reflect.Value) (Service, error) {
+			return f(), nil
+		}
+	case func() (Service, error):
+		call = func([]reflect.Value) (Service, error) {
+			return f()
+		}
+	}
+
+	return registerTyped("WithFunc0", f, call, opts)
+}
+
+// WithFunc1 registers a constructor function with one parameter like [WithService],
+// but the signature of the function is checked at compile time.
+//
+// The function may return *Service* or (*Service*, error).
+// It is called directly instead of with reflection when the service is resolved.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithFunc1[*sql.DB, *Config](NewDB), // NewDB(*Config) (*sql.DB, error)
+//	)
+func WithFunc1[Service, D1 any, F interface {
+	func(D1) Service | func(D1) (Service, error)
+}](f F, opts ...ServiceOption) ContainerOption {
+	var call func([]reflect.Value) (Service, error)
+	switch f := any(f).(type) {
+	case func(D1) Service:
+		call = func(deps []reflect.Value) (Service, error) {
+			return f(depAt[D1](deps, 0)), nil
+		}
+	case func(D1) (Service, error):
+		call = func(deps []reflect.Value) (Service, error) {
+			return f(depAt[D1](deps, 0))
+		}
+	}
+
+	return registerTyped("WithFunc1", f, call, opts)
+}
+
+// WithFunc2 registers a constructor function with two parameters like [WithFunc1].
+func WithFunc2[Service, D1, D2 any, F interface {
+	func(D1, D2) Service | func(D1, D2) (Service, error)
+}](f F, opts ...ServiceOption) ContainerOption {
+	var call func([]reflect.Value) (Service, error)
+	switch f := any(f).(type) {
+	case func(D1, D2) Service:
+		call = func(deps []reflect.Value) (Service, error) {
+			return f(depAt[D1](deps, 0), depAt[D2](deps, 1)), nil
+		}
+	case func(D1, D2) (Service, error):
+		call = func(deps []reflect.Value) (Service, error) {
+			return f(depAt[D1](deps, 0), depAt[D2](deps, 1))
+		}
+	}
+
+	return registerTyped("WithFunc2", f, call, opts)
+}
+
+// WithFunc3 registers a constructor function with three parameters like [WithFunc1].
+func WithFunc3[Service, D1, D2, D3 any, F interface {
+	func(D1, D2, D3) Service | func(D1, D2, D3) (Service, error)
+}](f F, opts ...ServiceOption) ContainerOption {
+	var call func([]reflect.Value) (Service, error)
+	switch f := any(f).(type) {
+	case func(D1, D2, D3) Service:
+		call = func(deps []reflect.Value) (Service, error) {
+			return f(depAt[D1](deps, 0), depAt[D2](deps, 1), depAt[D3](deps, 2)), nil
+		}
+	case func(D1, D2, D3) (Service, error):
+		call = func(deps []reflect.Value) (Service, error) {
+			return f(depAt[D1](deps, 0), depAt[D2](deps, 1), depAt[D3](deps, 2))
+		}
+	}
+
+	return registerTyped("WithFunc3", f, call, opts)
+}
+
+// WithFunc4 registers a constructor function with four parameters like [WithFunc1].
+// Use a struct that embeds [In] for more dependencies.
+func WithFunc4[Service, D1, D2, D3, D4 any, F interface {
+	func(D1, D2, D3, D4) Service | func(D1, D2, D3, D4) (Service, error)
+}](f F, opts ...ServiceOption) ContainerOption {
+	var call func([]reflect.Value) (Service, error)
+	switch f := any(f).(type) {
+	case func(D1, D2, D3, D4) Service:
+		call = func(deps []reflect.Value) (Service, error) {
+			return f(depAt[D1](deps, 0), depAt[D2](deps, 1), depAt[D3](deps, 2), depAt[D4](deps, 3)), nil
+		}
+	case func(D1, D2, D3, D4) (Service, error):
+		call = func(deps []reflect.Value) (Service, error) {
+			return f(depAt[D1](deps, 0), depAt[D2](deps, 1), depAt[D3](deps, 2), depAt[D4](deps, 3))
+		}
+	}
+
+	return registerTyped("WithFunc4", f, call, opts)
+}
+
+// registerTyped registers the function f, which is called with call instead of reflection.
+func registerTyped[Service any](
+	name string,
+	f any,
+	call func(deps []reflect.Value) (Service, error),
+	opts []ServiceOption,
+) ContainerOption {
+	return containerOption(func(c *Container) error {
+		v := reflect.ValueOf(f)
+		if v.IsNil() {
+			return errors.Errorf("%s %s: f is nil", name, v.Type())
+		}
+
+		s, err := newService(c, v, false, opts...)
+		if err != nil {
+			return errors.Wrapf(err, "%s %s", name, v.Type())
+		}
+
+		s.typedNew = func(deps []reflect.Value) (any, error) {
+			val, err := call(deps)
+			if isNil(reflect.ValueOf(val)) {
+				return nil, err
+			}
+			return val, err
+		}
+
+		if err := c.registerWithOut(s); err != nil {
+			return errors.Wrapf(err, "%s %s", name, v.Type())
+		}
+		return nil
+	})
+}
+
+// depAt returns the dependency at index i as type D.
+func depAt[D any](deps []reflect.Value, i int) D {
+	dep, _ := deps[i].Interface().(D)
+	return dep
+}
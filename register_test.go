@@ -0,0 +1,220 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type registerParams struct {
+	di.In
+	A testtypes.InterfaceA
+	B testtypes.InterfaceB `di:"optional"`
+}
+
+func Test_Register(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("dependency", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.Register[testtypes.InterfaceB](func(a testtypes.InterfaceA) (testtypes.InterfaceB, error) {
+				assert.NotNil(t, a)
+				return testtypes.StructB{}, nil
+			}),
+		)
+		require.NoError(t, err)
+
+		b, err := di.Resolve[testtypes.InterfaceB](ctx, c)
+		assert.NoError(t, err)
+		assert.Equal(t, testtypes.StructB{}, b)
+	})
+
+	t.Run("context", func(t *testing.T) {
+		valueCtx := testutils.ContextWithTestValue(ctx, "value")
+		c, err := di.NewContainer(
+			di.Register[testtypes.InterfaceA](func(ctx context.Context) (testtypes.InterfaceA, error) {
+				assert.Equal(t, "value", testutils.TestValue(ctx))
+				return testtypes.StructA{}, nil
+			}, di.Transient),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](valueCtx, c)
+		assert.NoError(t, err)
+	})
+
+	t.Run("parameter object", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.Register[testtypes.InterfaceC](func(p registerParams) (testtypes.InterfaceC, error) {
+				assert.NotNil(t, p.A)
+				assert.Nil(t, p.B)
+				return testtypes.StructC{}, nil
+			}),
+			di.WithDependencyValidation(),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceC](ctx, c)
+		assert.NoError(t, err)
+	})
+
+	t.Run("nil dependency", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() testtypes.InterfaceA { return nil }),
+			di.Register[testtypes.InterfaceB](func(a testtypes.InterfaceA) (testtypes.InterfaceB, error) {
+				assert.Nil(t, a)
+				return testtypes.StructB{}, nil
+			}),
+		)
+		require.NoError(t, err)
+
+		b, err := di.Resolve[testtypes.InterfaceB](ctx, c)
+		assert.NoError(t, err)
+		assert.Equal(t, testtypes.StructB{}, b)
+	})
+
+	t.Run("nil pointer", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.Register[*testtypes.StructA](func(context.Context) (*testtypes.StructA, error) {
+				var a *testtypes.StructA
+				return a, nil
+			}),
+		)
+		require.NoError(t, err)
+
+		val, err := c.Resolve(ctx, testtypes.TypeStructAPtr)
+		assert.NoError(t, err)
+		assert.Nil(t, val)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.Register[testtypes.InterfaceA](func(context.Context) (testtypes.InterfaceA, error) {
+				return nil, errors.New("constructor error")
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve testtypes.InterfaceA: constructor error")
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.Register[testtypes.InterfaceA, context.Context](nil),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: Register func(context.Context) (testtypes.InterfaceA, error): f is nil")
+	})
+
+	t.Run("invalid dependency type", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.Register[testtypes.InterfaceA](func(int) (testtypes.InterfaceA, error) {
+				return testtypes.StructA{}, nil
+			}),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: Register func(int) (testtypes.InterfaceA, error): "+
+			"parameter 0: invalid dependency type int; use a named type or a pointer to a named type")
+	})
+}
+
+func Test_WithFunc(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("WithFunc0", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithFunc0[testtypes.InterfaceA](testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+
+		a, err := di.Resolve[testtypes.InterfaceA](ctx, c)
+		assert.NoError(t, err)
+		assert.NotNil(t, a)
+	})
+
+	t.Run("WithFunc0 error", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithFunc0[testtypes.InterfaceA](func() (testtypes.InterfaceA, error) {
+				return nil, errors.New("constructor error")
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve testtypes.InterfaceA: constructor error")
+	})
+
+	t.Run("WithFunc1", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithFunc0[testtypes.InterfaceA](testtypes.NewInterfaceA),
+			di.WithFunc1[testtypes.InterfaceB, testtypes.InterfaceA](testtypes.NewInterfaceB, di.Transient),
+		)
+		require.NoError(t, err)
+
+		b, err := di.Resolve[testtypes.InterfaceB](ctx, c)
+		assert.NoError(t, err)
+		assert.NotNil(t, b)
+	})
+
+	t.Run("WithFunc4", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithFunc0[testtypes.InterfaceA](testtypes.NewInterfaceA),
+			di.WithFunc1[testtypes.InterfaceB, testtypes.InterfaceA](testtypes.NewInterfaceB),
+			di.WithFunc2[testtypes.InterfaceC, testtypes.InterfaceA, testtypes.InterfaceB](testtypes.NewInterfaceC),
+			di.WithFunc3[testtypes.InterfaceD, testtypes.InterfaceA, testtypes.InterfaceB, testtypes.InterfaceC](
+				testtypes.NewInterfaceD,
+			),
+			di.WithFunc4[*testtypes.StructA, testtypes.InterfaceA, testtypes.InterfaceB, testtypes.InterfaceC, testtypes.InterfaceD](
+				func(a testtypes.InterfaceA, b testtypes.InterfaceB, c testtypes.InterfaceC, d testtypes.InterfaceD) (*testtypes.StructA, error) {
+					assert.NotNil(t, a)
+					assert.NotNil(t, b)
+					assert.NotNil(t, c)
+					assert.NotNil(t, d)
+					return &testtypes.StructA{}, nil
+				},
+			),
+			di.WithDependencyValidation(),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*testtypes.StructA](ctx, c)
+		assert.NoError(t, err)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		var f func() testtypes.InterfaceA
+		c, err := di.NewContainer(
+			di.WithFunc0[testtypes.InterfaceA](f),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithFunc0 func() testtypes.InterfaceA: f is nil")
+	})
+
+	t.Run("invalid service type", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithFunc0[int](func() int { return 0 }),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithFunc0 func() int: "+
+			"return type int: invalid service type; use a named type or a pointer to a named type")
+	})
+}
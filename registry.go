@@ -0,0 +1,83 @@
+package di
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// FromRegistry registers each factory function in a registry as a tagged service of type *Service*
+// when calling [NewContainer] or [Container.NewScope].
+//
+// This eases incremental adoption in codebases with a hand-rolled service locator,
+// like a map of factory functions by name. Each function is registered like [WithService]
+// with [As] *Service* and [WithTag] the tag returned by tagFromKey for the key.
+// If tagFromKey is nil, the key is used as the tag.
+// If tagFromKey returns nil, the function is registered without a tag.
+//
+// The functions are registered in order of their keys, and the options are applied to each function.
+//
+// Example:
+//
+//	var codecs = map[string]func(*Config) Codec{
+//		"json":  NewJSONCodec,
+//		"proto": NewProtoCodec,
+//	}
+//
+//	c, err := di.NewContainer(
+//		di.FromRegistry[Codec](codecs, nil),
+//	)
+//
+//	codec, err := di.Resolve[Codec](ctx, c, di.WithTag("json"))
+//
+// This option will return an error if a function is nil, or does not return a type assignable to *Service*.
+func FromRegistry[Service, Factory any](
+	registry map[string]Factory,
+	tagFromKey func(key string) any,
+	opts ...ServiceOption,
+) ContainerOption {
+	t := reflect.TypeFor[Service]()
+
+	return containerOption(func(c *Container) error {
+		if reflect.TypeFor[Factory]().Kind() != reflect.Func {
+			return errors.Errorf("FromRegistry %s: %s is not a function", t, reflect.TypeFor[Factory]())
+		}
+
+		var errs []error
+		for _, key := range slices.Sorted(maps.Keys(registry)) {
+			tag := any(key)
+			if tagFromKey != nil {
+				tag = tagFromKey(key)
+			}
+
+			err := c.registerFactory(registry[key], t, tag, opts)
+			if err != nil {
+				errs = append(errs, errors.Wrapf(err, "key %q", key))
+			}
+		}
+
+		return errors.Wrapf(errors.Join(errs...), "FromRegistry %s", t)
+	})
+}
+
+func (c *Container) registerFactory(factory any, t reflect.Type, tag any, opts []ServiceOption) error {
+	v := reflect.ValueOf(factory)
+	if isNil(v) {
+		return errors.New("function is nil")
+	}
+
+	opts = append([]ServiceOption{asType(t)}, opts...)
+	if tag != nil {
+		opts = append(opts, WithTag(tag))
+	}
+
+	s, err := newService(c, v, false, opts...)
+	if err != nil {
+		return err
+	}
+
+	c.register(s)
+	return nil
+}
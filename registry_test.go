@@ -0,0 +1,150 @@
+package di_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_FromRegistry(t *testing.T) {
+	newStructA := func(tag string) func() *testtypes.StructA {
+		return func() *testtypes.StructA {
+			return &testtypes.StructA{Tag: tag}
+		}
+	}
+
+	t.Run("key as tag", func(t *testing.T) {
+		registry := map[string]func() *testtypes.StructA{
+			"json":  newStructA("json"),
+			"proto": newStructA("proto"),
+		}
+
+		c, err := di.NewContainer(
+			di.FromRegistry[testtypes.InterfaceA](registry, nil),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		got, err := di.Resolve[testtypes.InterfaceA](ctx, c, di.WithTag("json"))
+		require.NoError(t, err)
+		assert.Equal(t, &testtypes.StructA{Tag: "json"}, got)
+
+		got, err = di.Resolve[testtypes.InterfaceA](ctx, c, di.WithTag("proto"))
+		require.NoError(t, err)
+		assert.Equal(t, &testtypes.StructA{Tag: "proto"}, got)
+
+		// Registered as Service only
+		assert.False(t, c.Contains(testtypes.TypeStructAPtr, di.WithTag("json")))
+	})
+
+	t.Run("tagFromKey", func(t *testing.T) {
+		registry := map[string]func() *testtypes.StructA{
+			"JSON": newStructA("json"),
+		}
+
+		c, err := di.NewContainer(
+			di.FromRegistry[*testtypes.StructA](registry, func(key string) any {
+				return strings.ToLower(key)
+			}),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[*testtypes.StructA](context.Background(), c, di.WithTag("json"))
+		require.NoError(t, err)
+		assert.Equal(t, &testtypes.StructA{Tag: "json"}, got)
+	})
+
+	t.Run("nil tag registered without tag", func(t *testing.T) {
+		registry := map[string]func() *testtypes.StructA{
+			"default": newStructA("default"),
+			"other":   newStructA("other"),
+		}
+
+		c, err := di.NewContainer(
+			di.FromRegistry[*testtypes.StructA](registry, func(key string) any {
+				if key == "default" {
+					return nil
+				}
+				return key
+			}),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[*testtypes.StructA](context.Background(), c)
+		require.NoError(t, err)
+		assert.Equal(t, &testtypes.StructA{Tag: "default"}, got)
+	})
+
+	t.Run("functions with dependencies", func(t *testing.T) {
+		calls := 0
+		registry := map[string]func(*testtypes.StructA) *testtypes.StructB{
+			"b": func(a *testtypes.StructA) *testtypes.StructB {
+				calls++
+				return testtypes.NewStructBPtr(a)
+			},
+		}
+
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewStructAPtr),
+			di.FromRegistry[*testtypes.StructB](registry, nil, di.Transient),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		_, err = di.Resolve[*testtypes.StructB](ctx, c, di.WithTag("b"))
+		require.NoError(t, err)
+		_, err = di.Resolve[*testtypes.StructB](ctx, c, di.WithTag("b"))
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("empty registry", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.FromRegistry[*testtypes.StructA](map[string]func() *testtypes.StructA{}, nil),
+		)
+		require.NoError(t, err)
+		assert.False(t, c.Contains(testtypes.TypeStructAPtr))
+	})
+
+	t.Run("nil function", func(t *testing.T) {
+		registry := map[string]func() *testtypes.StructA{
+			"a": nil,
+			"b": newStructA("b"),
+		}
+
+		c, err := di.NewContainer(
+			di.FromRegistry[*testtypes.StructA](registry, nil),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, `di.NewContainer: FromRegistry *testtypes.StructA: key "a": function is nil`)
+	})
+
+	t.Run("not assignable", func(t *testing.T) {
+		registry := map[string]func() *testtypes.StructB{
+			"b": func() *testtypes.StructB { return &testtypes.StructB{} },
+		}
+
+		c, err := di.NewContainer(
+			di.FromRegistry[*testtypes.StructA](registry, nil),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, `di.NewContainer: FromRegistry *testtypes.StructA: key "b": As *testtypes.StructA: type *testtypes.StructB not assignable to *testtypes.StructA`)
+	})
+
+	t.Run("not a function", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.FromRegistry[*testtypes.StructA](map[string]*testtypes.StructA{"a": {}}, nil),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: FromRegistry *testtypes.StructA: *testtypes.StructA is not a function")
+	})
+}
@@ -0,0 +1,107 @@
+package di
+
+import (
+	"reflect"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// RequireCount requires that a slice dependency of type []*Element* resolves to at least n services,
+// when calling [WithService] or [Invoke].
+//
+// By default, a variadic parameter, or a slice dependency with [WithZeroDefault], silently resolves
+// to an empty slice when no services are registered. This hides misconfigured plugin registration.
+// With this option, [WithDependencyValidation] reports a problem when fewer than n services are registered
+// as *Element*, and resolving the service returns an error when fewer than n services are resolved.
+//
+// The option applies to every dependency of type []*Element*, with or without a tag.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithService(NewPluginHost, // NewPluginHost(...Plugin) *PluginHost
+//			di.RequireCount[Plugin](1),
+//		),
+//	)
+//
+// This option will return an error if n is not positive,
+// or the function does not have a dependency of type []*Element*.
+func RequireCount[Element any](n int) DependencyOption {
+	return requireCountOption{
+		t: reflect.SliceOf(reflect.TypeFor[Element]()),
+		n: n,
+	}
+}
+
+type requireCountOption struct {
+	t reflect.Type
+	n int
+}
+
+func (o requireCountOption) applyService(s *service) error {
+	var err error
+	s.minCounts, err = o.apply(s.Dependencies(), s.minCounts)
+	return err
+}
+
+func (o requireCountOption) applyInvokeConfig(c *invokeConfig) error {
+	var err error
+	c.minCounts, err = o.apply(c.deps, c.minCounts)
+	return err
+}
+
+// apply sets the minimum count for each dependency of the slice type in minCounts.
+func (o requireCountOption) apply(deps []serviceKey, minCounts []int) ([]int, error) {
+	if o.n <= 0 {
+		return minCounts, errors.Errorf("RequireCount %d: count must be positive", o.n)
+	}
+
+	found := false
+	for i, dep := range deps {
+		if dep.Type != o.t {
+			continue
+		}
+
+		if minCounts == nil {
+			minCounts = make([]int, len(deps))
+		}
+		minCounts[i] = o.n
+		found = true
+	}
+
+	if !found {
+		return minCounts, errors.Errorf("RequireCount %d: parameter %s not found", o.n, o.t)
+	}
+
+	return minCounts, nil
+}
+
+// minCount returns the minimum number of services required for the dependency at index i, if any.
+func minCount(minCounts []int, i int) int {
+	if i < len(minCounts) {
+		return minCounts[i]
+	}
+	return 0
+}
+
+// checkCount returns an error if the resolved slice has fewer than n elements.
+func checkCount(n int, val any) error {
+	count := 0
+	if v := reflect.ValueOf(val); v.IsValid() {
+		count = v.Len()
+	}
+
+	if count < n {
+		return errors.Errorf("RequireCount %d: found %d services", n, count)
+	}
+	return nil
+}
+
+// countServices returns the number of services that would be resolved for a slice of the element key.
+func (c *Container) countServices(elemKey serviceKey) int {
+	count := 0
+	for range c.sliceServiceSeq(elemKey) {
+		count++
+	}
+	return count
+}
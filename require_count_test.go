@@ -0,0 +1,145 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RequireCount(t *testing.T) {
+	ctx := context.Background()
+
+	newHost := func(as ...testtypes.InterfaceA) *testtypes.StructB {
+		return &testtypes.StructB{}
+	}
+
+	t.Run("enough registered", func(t *testing.T) {
+		var got []testtypes.InterfaceA
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(testtypes.NewInterfaceAStruct),
+			di.WithService(func(as ...testtypes.InterfaceA) *testtypes.StructB {
+				got = as
+				return &testtypes.StructB{}
+			}, di.RequireCount[testtypes.InterfaceA](2)),
+			di.WithDependencyValidation(),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*testtypes.StructB](ctx, c)
+		require.NoError(t, err)
+		assert.Len(t, got, 2)
+	})
+
+	t.Run("too few at resolve", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(newHost, di.RequireCount[testtypes.InterfaceA](2)),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*testtypes.StructB](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve *testtypes.StructB: "+
+			"dependency []testtypes.InterfaceA: RequireCount 2: found 1 services")
+	})
+
+	t.Run("variadic none registered", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(newHost, di.RequireCount[testtypes.InterfaceA](1)),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*testtypes.StructB](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve *testtypes.StructB: "+
+			"dependency []testtypes.InterfaceA: RequireCount 1: found 0 services")
+	})
+
+	t.Run("WithZeroDefault none registered", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func([]testtypes.InterfaceA) *testtypes.StructB {
+				return &testtypes.StructB{}
+			},
+				di.WithZeroDefault[[]testtypes.InterfaceA](),
+				di.RequireCount[testtypes.InterfaceA](1),
+			),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*testtypes.StructB](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve *testtypes.StructB: "+
+			"dependency []testtypes.InterfaceA: RequireCount 1: found 0 services")
+	})
+
+	t.Run("WithDependencyValidation", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(newHost, di.RequireCount[testtypes.InterfaceA](2)),
+			di.WithDependencyValidation(),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithDependencyValidation: "+
+			"service func(...testtypes.InterfaceA) *testtypes.StructB: "+
+			"dependency []testtypes.InterfaceA: RequireCount 2: found 1 services")
+	})
+
+	t.Run("counts parent scope", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope(
+			di.WithService(testtypes.NewInterfaceAStruct),
+			di.WithService(newHost, di.RequireCount[testtypes.InterfaceA](2)),
+			di.WithDependencyValidation(),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*testtypes.StructB](ctx, scope)
+		require.NoError(t, err)
+	})
+
+	t.Run("Invoke", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+
+		err = di.Invoke(ctx, c, func([]testtypes.InterfaceA) {}, di.RequireCount[testtypes.InterfaceA](1))
+		require.NoError(t, err)
+
+		err = di.Invoke(ctx, c, func([]testtypes.InterfaceA) {}, di.RequireCount[testtypes.InterfaceA](2))
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Invoke func([]testtypes.InterfaceA): "+
+			"dependency []testtypes.InterfaceA: RequireCount 2: found 1 services")
+	})
+
+	t.Run("count not positive", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(newHost, di.RequireCount[testtypes.InterfaceA](0)),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func(...testtypes.InterfaceA) *testtypes.StructB: "+
+			"RequireCount 0: count must be positive")
+	})
+
+	t.Run("parameter not found", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceB, di.RequireCount[testtypes.InterfaceA](1)),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func(testtypes.InterfaceA) testtypes.InterfaceB: "+
+			"RequireCount 1: parameter []testtypes.InterfaceA not found")
+	})
+}
@@ -0,0 +1,119 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// ServiceInfo describes a service by the type and tag it is resolved with.
+type ServiceInfo struct {
+	// Type of the service.
+	Type reflect.Type
+	// Tag of the service, or nil if the service is not tagged. See [WithTag].
+	Tag any
+	// Metadata of the service, if any. See [WithMetadata].
+	Metadata map[string]string
+	// ID is a deterministic ID for the service registration, derived from its type, tag, module, and index.
+	// It is stable across process restarts, as long as services are registered in the same order,
+	// so it can be used to correlate the same service in logs and dashboards.
+	// Renaming the constructor function or the name set with [WithName] doesn't change it.
+	// It is empty if the service is not registered.
+	ID string
+	// Name describes the service registration. It is the name set with [WithName], if any.
+	// Otherwise it's the name of the constructor function, or the type of a value service.
+	// It is empty if the service is not registered.
+	Name string
+	// Module is the name of the module the service was registered with, if any. See [NamedModule].
+	Module string
+	// Depth is the scope level of the Container the service is registered with.
+	// It is 0 for the root Container, 1 for its child scopes, and so on.
+	Depth int
+	// Index is the position of the registration among the services registered
+	// for the same type and tag with the Container.
+	Index int
+}
+
+func (i ServiceInfo) String() string {
+	return serviceKey{Type: i.Type, Tag: i.Tag}.String()
+}
+
+// ResolveError is returned by [Container.Resolve] when a service cannot be resolved.
+//
+// [MustResolve] panics with a *ResolveError. Use [RecoverResolve] to get it from a recovered panic.
+type ResolveError struct {
+	// Err is the error that caused the service to fail to resolve.
+	Err error
+	// Trail is the path of dependencies from Service to the service that failed to resolve.
+	// It is empty if the requested service itself failed.
+	Trail []ServiceInfo
+	// Service is the service that was requested.
+	Service ServiceInfo
+}
+
+func newResolveError(c *Container, key serviceKey, err error) *ResolveError {
+	resErr := &ResolveError{
+		Service: c.serviceInfo(key),
+		Err:     err,
+	}
+
+	for depErr := (*dependencyError)(nil); errors.As(err, &depErr); err = depErr.Err {
+		resErr.Trail = append(resErr.Trail, c.serviceInfo(depErr.Key))
+	}
+
+	return resErr
+}
+
+func (e *ResolveError) Error() string {
+	return fmt.Sprintf("di.Container.Resolve %s: %s", e.Service, e.Err)
+}
+
+func (e *ResolveError) Unwrap() error {
+	return e.Err
+}
+
+// RecoverResolve returns the [*ResolveError] from a value recovered from a panic, if any.
+//
+// This can be used in panic recovery middleware to render useful diagnostics
+// when [MustResolve] or [dicontext.MustResolve] panics.
+//
+// Example:
+//
+//	defer func() {
+//		if r := recover(); r != nil {
+//			if resErr, ok := di.RecoverResolve(r); ok {
+//				log.Error("resolve failed", "service", resErr.Service, "trail", resErr.Trail)
+//			}
+//			// ...
+//		}
+//	}()
+//
+// [dicontext.MustResolve]: https://pkg.go.dev/github.com/sectrean/di-kit/dicontext#MustResolve
+func RecoverResolve(recovered any) (*ResolveError, bool) {
+	err, ok := recovered.(error)
+	if !ok {
+		return nil, false
+	}
+
+	var resErr *ResolveError
+	if !errors.As(err, &resErr) {
+		return nil, false
+	}
+
+	return resErr, true
+}
+
+// dependencyError is returned when a dependency of a service cannot be resolved.
+type dependencyError struct {
+	Key serviceKey
+	Err error
+}
+
+func (e *dependencyError) Error() string {
+	return fmt.Sprintf("dependency %s: %s", e.Key, e.Err)
+}
+
+func (e *dependencyError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,134 @@
+package di_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/dicontext"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ResolveError(t *testing.T) {
+	t.Run("service not registered", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		_, err = c.Resolve(context.Background(), reflect.TypeFor[testtypes.InterfaceA](), di.WithTag("tag"))
+		testutils.LogError(t, err)
+
+		var resErr *di.ResolveError
+		require.ErrorAs(t, err, &resErr)
+		assert.Equal(t, di.ServiceInfo{Type: reflect.TypeFor[testtypes.InterfaceA](), Tag: "tag"}, resErr.Service)
+		assert.Empty(t, resErr.Trail)
+		assert.EqualError(t, resErr.Err, "service not registered")
+	})
+
+	t.Run("dependency trail", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceB),
+			di.WithService(testtypes.NewInterfaceC),
+			di.WithService(func() (testtypes.InterfaceA, error) {
+				return nil, errors.New("error A")
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceC](context.Background(), c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve testtypes.InterfaceC: dependency testtypes.InterfaceA: error A")
+
+		var resErr *di.ResolveError
+		require.ErrorAs(t, err, &resErr)
+		assert.Equal(t, "testtypes.InterfaceC", resErr.Service.String())
+		require.Len(t, resErr.Trail, 1)
+		assert.Equal(t, reflect.TypeFor[testtypes.InterfaceA](), resErr.Trail[0].Type)
+		assert.Nil(t, resErr.Trail[0].Tag)
+		assert.NotEmpty(t, resErr.Trail[0].ID)
+		assert.NotEmpty(t, resErr.Service.ID)
+	})
+
+	t.Run("nested dependency trail", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func(*testtypes.StructB) testtypes.InterfaceC {
+				return testtypes.StructC{}
+			}),
+			di.WithService(testtypes.NewStructBPtr),
+			di.WithService(func() (*testtypes.StructA, error) {
+				return nil, errors.New("error A")
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceC](context.Background(), c)
+		testutils.LogError(t, err)
+
+		var resErr *di.ResolveError
+		require.ErrorAs(t, err, &resErr)
+		require.Len(t, resErr.Trail, 2)
+		assert.Equal(t, reflect.TypeFor[*testtypes.StructB](), resErr.Trail[0].Type)
+		assert.Equal(t, reflect.TypeFor[*testtypes.StructA](), resErr.Trail[1].Type)
+		assert.EqualError(t, resErr.Err, "dependency *testtypes.StructB: dependency *testtypes.StructA: error A")
+	})
+}
+
+func Test_RecoverResolve(t *testing.T) {
+	t.Run("MustResolve", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceB),
+		)
+		require.NoError(t, err)
+
+		defer func() {
+			resErr, ok := di.RecoverResolve(recover())
+			require.True(t, ok)
+
+			assert.Equal(t, "testtypes.InterfaceB", resErr.Service.String())
+			assert.Equal(t, []di.ServiceInfo{
+				{Type: reflect.TypeFor[testtypes.InterfaceA]()},
+			}, resErr.Trail)
+			assert.EqualError(t, resErr,
+				"di.Container.Resolve testtypes.InterfaceB: dependency testtypes.InterfaceA: service not registered")
+		}()
+
+		di.MustResolve[testtypes.InterfaceB](context.Background(), c)
+	})
+
+	t.Run("dicontext.MustResolve", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		ctx := dicontext.WithScope(context.Background(), c)
+
+		defer func() {
+			resErr, ok := di.RecoverResolve(recover())
+			require.True(t, ok)
+			assert.Equal(t, "testtypes.InterfaceA", resErr.Service.String())
+		}()
+
+		dicontext.MustResolve[testtypes.InterfaceA](ctx)
+	})
+
+	t.Run("not an error", func(t *testing.T) {
+		resErr, ok := di.RecoverResolve("panic")
+		assert.Nil(t, resErr)
+		assert.False(t, ok)
+	})
+
+	t.Run("other error", func(t *testing.T) {
+		resErr, ok := di.RecoverResolve(errors.New("other error"))
+		assert.Nil(t, resErr)
+		assert.False(t, ok)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		resErr, ok := di.RecoverResolve(nil)
+		assert.Nil(t, resErr)
+		assert.False(t, ok)
+	})
+}
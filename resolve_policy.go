@@ -0,0 +1,76 @@
+package di
+
+import (
+	"context"
+	"fmt"
+)
+
+// ResolvePolicy decides if a service may be resolved with the context.
+//
+// If it returns an error, the service is not resolved, and an [*AccessDeniedError] is returned.
+type ResolvePolicy = func(ctx context.Context, svc ServiceInfo) error
+
+// WithResolvePolicy registers a function that is called before each service is resolved
+// when calling [NewContainer] or [Container.NewScope].
+//
+// This enforces access boundaries at the DI level, like denying sensitive services based on the
+// auth level or tenant in the context. The policy is called for each service resolved from the Container,
+// including dependencies, even if the instance was already created.
+// [Singleton] services are created by the Container they are registered with,
+// so the policies of a child scope are not called for the dependencies of a Singleton registered with a parent.
+//
+// Child scopes inherit the policies of the parent Container.
+// Policies are called in the order they are registered, and may be called concurrently from multiple goroutines.
+//
+// Example:
+//
+//	scope, err := c.NewScope(
+//		di.WithResolvePolicy(func(ctx context.Context, svc di.ServiceInfo) error {
+//			if svc.Type == reflect.TypeFor[*admin.Client]() && !auth.IsAdmin(ctx) {
+//				return errors.New("admin required")
+//			}
+//			return nil
+//		}),
+//	)
+func WithResolvePolicy(p ResolvePolicy) ContainerOption {
+	return containerOption(func(c *Container) error {
+		if p != nil {
+			c.resolvePolicies = append(c.resolvePolicies, p)
+		}
+		return nil
+	})
+}
+
+// AccessDeniedError is returned when a [ResolvePolicy] denies resolving a service.
+//
+// Use [errors.As] to check for it.
+type AccessDeniedError struct {
+	// Err is the error returned by the ResolvePolicy.
+	Err error
+	// Service is the service that was denied.
+	Service ServiceInfo
+}
+
+func (e *AccessDeniedError) Error() string {
+	return fmt.Sprintf("access denied: %s", e.Err)
+}
+
+func (e *AccessDeniedError) Unwrap() error {
+	return e.Err
+}
+
+// checkResolvePolicies returns an [*AccessDeniedError] if a policy denies resolving the service.
+func (c *Container) checkResolvePolicies(ctx context.Context, svc *service, key serviceKey) error {
+	if len(c.resolvePolicies) == 0 {
+		return nil
+	}
+
+	info := svc.Info(key)
+	for _, p := range c.resolvePolicies {
+		if err := p(ctx, info); err != nil {
+			return &AccessDeniedError{Service: info, Err: err}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+package di_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type adminKey struct{}
+
+func requireAdmin(t reflect.Type) di.ResolvePolicy {
+	return func(ctx context.Context, svc di.ServiceInfo) error {
+		if svc.Type == t && ctx.Value(adminKey{}) == nil {
+			return errors.New("admin required")
+		}
+		return nil
+	}
+}
+
+func Test_WithResolvePolicy(t *testing.T) {
+	ctx := context.Background()
+	adminCtx := context.WithValue(ctx, adminKey{}, true)
+
+	t.Run("denied", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithResolvePolicy(requireAdmin(reflect.TypeFor[testtypes.InterfaceA]())),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve testtypes.InterfaceA: access denied: admin required")
+
+		var denied *di.AccessDeniedError
+		require.ErrorAs(t, err, &denied)
+		assert.Equal(t, reflect.TypeFor[testtypes.InterfaceA](), denied.Service.Type)
+	})
+
+	t.Run("allowed", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithResolvePolicy(requireAdmin(reflect.TypeFor[testtypes.InterfaceA]())),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[testtypes.InterfaceA](adminCtx, c)
+		require.NoError(t, err)
+		assert.NotNil(t, got)
+	})
+
+	t.Run("dependency", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(testtypes.NewInterfaceB),
+			di.WithResolvePolicy(requireAdmin(reflect.TypeFor[testtypes.InterfaceA]())),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceB](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve testtypes.InterfaceB: "+
+			"dependency testtypes.InterfaceA: access denied: admin required")
+	})
+
+	t.Run("already resolved", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithResolvePolicy(requireAdmin(reflect.TypeFor[testtypes.InterfaceA]())),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](adminCtx, c)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, c)
+		testutils.LogError(t, err)
+		assert.ErrorAs(t, err, new(*di.AccessDeniedError))
+	})
+
+	t.Run("value service", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(&testtypes.StructA{}),
+			di.WithResolvePolicy(requireAdmin(reflect.TypeFor[*testtypes.StructA]())),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*testtypes.StructA](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve *testtypes.StructA: access denied: admin required")
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope(
+			di.WithResolvePolicy(requireAdmin(reflect.TypeFor[testtypes.InterfaceA]())),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, scope)
+		testutils.LogError(t, err)
+		assert.ErrorAs(t, err, new(*di.AccessDeniedError))
+
+		child, err := scope.NewScope()
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, child)
+		testutils.LogError(t, err)
+		assert.ErrorAs(t, err, new(*di.AccessDeniedError))
+	})
+
+	t.Run("invoke", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithResolvePolicy(requireAdmin(reflect.TypeFor[testtypes.InterfaceA]())),
+		)
+		require.NoError(t, err)
+
+		err = di.Invoke(ctx, c, func(testtypes.InterfaceA) {})
+		testutils.LogError(t, err)
+		assert.ErrorAs(t, err, new(*di.AccessDeniedError))
+	})
+
+	t.Run("info", func(t *testing.T) {
+		var got []string
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA, di.WithTag("a")),
+			di.WithResolvePolicy(func(_ context.Context, svc di.ServiceInfo) error {
+				got = append(got, svc.String())
+				return nil
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, c, di.WithTag("a"))
+		require.NoError(t, err)
+		assert.Len(t, got, 1)
+	})
+}
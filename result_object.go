@@ -0,0 +1,184 @@
+package di
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// Out can be embedded in a struct to use the struct as a result object.
+//
+// When a constructor function registered with [WithService] returns a result object,
+// each exported field is also registered as a service.
+// This allows one constructor function to create several services that work together.
+// The result object itself is also registered, with the [ServiceOption]s passed to WithService.
+//
+// Each field is registered as the declared type of the field.
+// Use the `di` struct tag to configure a field:
+//   - `di:"tag=name"` registers the field with [WithTag]("name").
+//   - `di:"-"` skips the field.
+//
+// The constructor function is called once for each instance of the result object, based on its [Lifetime].
+// When the result object is closed, each field that implements [Closer] is closed in reverse order.
+//
+// Example:
+//
+//	type Clients struct {
+//		di.Out
+//
+//		Reader store.Reader
+//		Writer store.Writer
+//		Admin  *store.Client `di:"tag=admin"`
+//	}
+//
+//	func NewClients(cfg *Config) (Clients, error) {
+//		// ...
+//	}
+type Out struct{}
+
+var typeOut = reflect.TypeFor[Out]()
+
+// isOutType returns true if t is a struct that embeds [Out].
+func isOutType(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if field.Anonymous && field.Type == typeOut {
+			return true
+		}
+	}
+
+	return false
+}
+
+// registerWithOut registers svc, and the fields of the result object if svc creates one.
+func (c *Container) registerWithOut(svc *service) error {
+	var fieldSvcs []*service
+	if !svc.IsValue() && isOutType(svc.Type()) {
+		var err error
+		fieldSvcs, err = c.outServices(svc)
+		if err != nil {
+			return err
+		}
+	}
+
+	c.register(svc)
+	for _, fieldSvc := range fieldSvcs {
+		c.register(fieldSvc)
+	}
+
+	return nil
+}
+
+// outServices returns the services for the fields of the result object created by svc.
+func (c *Container) outServices(svc *service) ([]*service, error) {
+	t := svc.Type()
+	resultKey := svc.Keys()[0]
+
+	svcs := make([]*service, 0, t.NumField())
+	var errs []error
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if field.Anonymous && field.Type == typeOut {
+			continue
+		}
+
+		st, err := parseStructTag(&field, 0)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "field %s", field.Name))
+			continue
+		}
+		if st.skip {
+			continue
+		}
+
+		fieldSvc, err := c.outService(svc, resultKey, &field, i, st.tag)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "field %s", field.Name))
+			continue
+		}
+
+		svcs = append(svcs, fieldSvc)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return svcs, nil
+}
+
+func (c *Container) outService(
+	svc *service,
+	resultKey serviceKey,
+	field *reflect.StructField,
+	index int,
+	tag any,
+) (*service, error) {
+	if !field.IsExported() {
+		return nil, errors.New("unexported field cannot be registered")
+	}
+	if ok := validateServiceType(field.Type); !ok {
+		return nil, errors.Errorf("invalid service type %s; %s", field.Type, invalidServiceTypeHint(field.Type))
+	}
+
+	// The field is resolved from the result object each time.
+	// The result object is cached and closed based on its lifetime.
+	opts := []ServiceOption{Transient, IgnoreCloser()}
+	if tag != nil {
+		opts = append(opts, WithTag(tag))
+	}
+
+	b := NewServiceBuilder(field.Type).
+		Dependency(resultKey.Type, resultKey.Tag).
+		Factory(func(_ context.Context, deps []any) (any, error) {
+			return reflect.ValueOf(deps[0]).Field(index).Interface(), nil
+		}).
+		Options(opts...)
+	b.name = svc.Name() + " " + field.Name
+
+	return b.newService(c)
+}
+
+// closeOutFields returns a Closer that closes each field of a result object that implements [Closer],
+// in reverse order.
+func closeOutFields(val any) Closer {
+	v := reflect.ValueOf(val)
+
+	var closers []Closer
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		// Invalid tags are reported when the result object is registered
+		if st, _ := parseStructTag(&field, 0); st.skip {
+			continue
+		}
+
+		if fieldVal := v.Field(i); !isNil(fieldVal) {
+			if closer := getCloser(fieldVal.Interface()); closer != nil {
+				closers = append(closers, closer)
+			}
+		}
+	}
+
+	if len(closers) == 0 {
+		return nil
+	}
+
+	return closeFunc(func(ctx context.Context) error {
+		var errs []error
+		for i := len(closers) - 1; i >= 0; i-- {
+			if err := closers[i].Close(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		return errors.Join(errs...)
+	})
+}
@@ -0,0 +1,184 @@
+package di_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type serviceResults struct {
+	di.Out
+	A       testtypes.InterfaceA
+	Tagged  testtypes.InterfaceA `di:"tag=tag"`
+	B       *testtypes.StructB
+	Skipped testtypes.InterfaceC `di:"-"`
+}
+
+type closeRecorder struct {
+	closed *[]string
+	name   string
+}
+
+func (r closeRecorder) Close(context.Context) error {
+	*r.closed = append(*r.closed, r.name)
+	return nil
+}
+
+type recorderResults struct {
+	di.Out
+	First  closeRecorder `di:"tag=first"`
+	Second closeRecorder `di:"tag=second"`
+}
+
+func Test_Out(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("fields registered", func(t *testing.T) {
+		calls := 0
+		c, err := di.NewContainer(
+			di.WithService(func() serviceResults {
+				calls++
+				return serviceResults{
+					A:      testtypes.StructA{},
+					Tagged: testtypes.StructA{Tag: "tag"},
+					B:      &testtypes.StructB{},
+				}
+			}),
+		)
+		require.NoError(t, err)
+
+		a, err := di.Resolve[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, testtypes.StructA{}, a)
+
+		tagged, err := di.Resolve[testtypes.InterfaceA](ctx, c, di.WithTag("tag"))
+		require.NoError(t, err)
+		assert.Equal(t, testtypes.StructA{Tag: "tag"}, tagged)
+
+		b1 := di.MustResolve[*testtypes.StructB](ctx, c)
+		b2 := di.MustResolve[*testtypes.StructB](ctx, c)
+		assert.Same(t, b1, b2)
+
+		assert.False(t, c.Contains(testtypes.TypeInterfaceC))
+		assert.True(t, c.Contains(reflect.TypeFor[serviceResults]()))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Transient", func(t *testing.T) {
+		calls := 0
+		c, err := di.NewContainer(
+			di.WithService(func() serviceResults {
+				calls++
+				return serviceResults{B: &testtypes.StructB{}}
+			}, di.Transient),
+		)
+		require.NoError(t, err)
+
+		_ = di.MustResolve[*testtypes.StructB](ctx, c)
+		_ = di.MustResolve[*testtypes.StructB](ctx, c)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("WithTag", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() serviceResults {
+				return serviceResults{A: testtypes.StructA{}}
+			}, di.WithTag("results")),
+		)
+		require.NoError(t, err)
+
+		a, err := di.Resolve[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, testtypes.StructA{}, a)
+	})
+
+	t.Run("constructor error", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() (serviceResults, error) {
+				return serviceResults{}, errors.New("constructor error")
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve testtypes.InterfaceA: "+
+			"dependency di_test.serviceResults: constructor error")
+	})
+
+	t.Run("closed in reverse order", func(t *testing.T) {
+		var closed []string
+		c, err := di.NewContainer(
+			di.WithService(func() recorderResults {
+				return recorderResults{
+					First:  closeRecorder{closed: &closed, name: "first"},
+					Second: closeRecorder{closed: &closed, name: "second"},
+				}
+			}),
+		)
+		require.NoError(t, err)
+
+		_ = di.MustResolve[closeRecorder](ctx, c, di.WithTag("first"))
+
+		err = c.Close(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"second", "first"}, closed)
+	})
+
+	t.Run("IgnoreCloser", func(t *testing.T) {
+		var closed []string
+		c, err := di.NewContainer(
+			di.WithService(func() recorderResults {
+				return recorderResults{
+					First: closeRecorder{closed: &closed, name: "first"},
+				}
+			}, di.IgnoreCloser()),
+		)
+		require.NoError(t, err)
+
+		_ = di.MustResolve[closeRecorder](ctx, c, di.WithTag("first"))
+
+		err = c.Close(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, closed)
+	})
+
+	t.Run("WithDependencyValidation", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() serviceResults { return serviceResults{} }),
+			di.WithService(testtypes.NewInterfaceB),
+			di.WithDependencyValidation(),
+		)
+		require.NoError(t, err)
+		assert.NotNil(t, c)
+	})
+
+	t.Run("invalid fields", func(t *testing.T) {
+		type results struct {
+			di.Out
+
+			a      testtypes.InterfaceA
+			BadTag testtypes.InterfaceA `di:"optional"`
+			Int    int
+		}
+		_ = results{}.a
+
+		c, err := di.NewContainer(
+			di.WithService(func() results { return results{} }),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func() di_test.results: "+
+			"field a: unexported field cannot be registered\n"+
+			"field BadTag: invalid di tag \"optional\": option \"optional\" is not supported\n"+
+			"field Int: invalid service type int; use a named type or a pointer to a named type")
+	})
+}
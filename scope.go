@@ -67,15 +67,21 @@ func Resolve[Service any](ctx context.Context, s Scope, opts ...ResolveOption) (
 // See [Container.Resolve] for more information.
 //
 // This will panic if the service cannot be resolved.
+// The panic value is a [*ResolveError] with details about the resolution failure when available.
+// Use [RecoverResolve] to get it from a recovered panic.
 func MustResolve[Service any](ctx context.Context, s Scope, opts ...ResolveOption) Service {
 	val, err := Resolve[Service](ctx, s, opts...)
 	if err != nil {
+		var resErr *ResolveError
+		if errors.As(err, &resErr) {
+			panic(resErr)
+		}
 		panic(err)
 	}
 	return val
 }
 
-func newInjectedScope(s Scope, key serviceKey) (scope *injectedScope, ready func()) {
+func newInjectedScope(s *Container, key serviceKey) (scope *injectedScope, ready func()) {
 	wrapper := &injectedScope{
 		scope: s,
 		key:   key,
@@ -88,7 +94,7 @@ func newInjectedScope(s Scope, key serviceKey) (scope *injectedScope, ready func
 // This is used to prevent the Scope from being used until the constructor function has returned.
 // Otherwise a dependency cycle is possible.
 type injectedScope struct {
-	scope Scope
+	scope *Container
 
 	// key is the service the Scope is getting injected into
 	key   serviceKey
@@ -108,15 +114,80 @@ func (s *injectedScope) Resolve(
 	t reflect.Type,
 	opts ...ResolveOption,
 ) (any, error) {
+	if err := s.checkReady(t); err != nil {
+		return nil, err
+	}
+
+	return s.scope.Resolve(ctx, t, opts...)
+}
+
+// checkReady returns an error if the constructor function the Scope was injected into has not returned.
+func (s *injectedScope) checkReady(t reflect.Type) error {
 	// Resolve cannot be called until the constructor function has returned.
 	// Otherwise a deadlock is possible.
 	if !s.ready.Load() {
-		return nil, errors.Errorf(
+		return errors.Errorf(
 			"di.Container.Resolve %s: not supported within service constructor function", t,
 		)
 	}
 
-	return s.scope.Resolve(ctx, t, opts...)
+	return nil
+}
+
+// NewScope creates a new child [Container] from the wrapped Container.
+//
+// This allows factory services to create their own child scopes.
+// See [Container.NewScope] for more information.
+func (s *injectedScope) NewScope(opts ...ContainerOption) (*Container, error) {
+	return s.scope.NewScope(opts...)
 }
 
 var _ Scope = (*injectedScope)(nil)
+
+// ReadOnly returns a [Scope] that can only resolve services from s.
+//
+// Use this to pass a Scope to a library without allowing it to create child scopes or close the Container,
+// even with a type assertion to [*Container], [Closer], or [ContainerInterface].
+// [ResolveLast] and [ResolveAll] still work with the returned Scope.
+//
+// Example:
+//
+//	plugin.Init(ctx, di.ReadOnly(c))
+//
+// This will panic if s is nil.
+func ReadOnly(s Scope) Scope {
+	if s == nil {
+		panic("di.ReadOnly: s is nil")
+	}
+	if ro, ok := s.(readOnlyScope); ok {
+		return ro
+	}
+
+	return readOnlyScope{scope: s}
+}
+
+// readOnlyScope wraps a Scope to hide any other methods.
+type readOnlyScope struct {
+	scope Scope
+}
+
+func (s readOnlyScope) Contains(t reflect.Type, opts ...ResolveOption) bool {
+	return s.scope.Contains(t, opts...)
+}
+
+func (s readOnlyScope) Resolve(ctx context.Context, t reflect.Type, opts ...ResolveOption) (any, error) {
+	return s.scope.Resolve(ctx, t, opts...)
+}
+
+func (s readOnlyScope) resolveLast(ctx context.Context, t reflect.Type, opts []ResolveOption) (any, error) {
+	if lr, ok := s.scope.(lastResolver); ok {
+		return lr.resolveLast(ctx, t, opts)
+	}
+
+	return s.scope.Resolve(ctx, t, opts...)
+}
+
+var (
+	_ Scope        = readOnlyScope{}
+	_ lastResolver = readOnlyScope{}
+)
@@ -0,0 +1,180 @@
+package di
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// PerScopeLimiter registers a [*Limiter] that allows up to n concurrent holders
+// as a [Scoped] service when calling [NewContainer] or [Container.NewScope].
+//
+// Each child scope gets its own Limiter, so request handlers can limit concurrent work
+// within a request scope, like calls to a backend for a single request.
+// Use [WithTag] to register more than one Limiter.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.PerScopeLimiter(4),
+//		di.WithService(NewLoader, di.Scoped), // NewLoader(*di.Limiter) *Loader
+//	)
+//
+// This option will return an error if n is not positive, or if opts includes a lifetime other than [Scoped].
+func PerScopeLimiter(n int, opts ...ServiceOption) ContainerOption {
+	return containerOption(func(c *Container) error {
+		if n <= 0 {
+			return errors.Errorf("PerScopeLimiter %d: n must be positive", n)
+		}
+
+		scopedOpts, err := scopedOptions(opts)
+		if err != nil {
+			return errors.Wrapf(err, "PerScopeLimiter %d", n)
+		}
+
+		newLimiter := func() *Limiter {
+			return &Limiter{sem: make(chan struct{}, n)}
+		}
+		return WithService(newLimiter, scopedOpts...).applyContainer(c)
+	})
+}
+
+// PerScopeSingleflight registers a [*Singleflight] as a [Scoped] service
+// when calling [NewContainer] or [Container.NewScope].
+//
+// Each child scope gets its own Singleflight, so request handlers can share the result
+// of duplicate work within a request scope, like loading the same record more than once.
+// Use [WithTag] to register more than one Singleflight.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.PerScopeSingleflight(),
+//		di.WithService(NewLoader, di.Scoped), // NewLoader(*di.Singleflight) *Loader
+//	)
+//
+// This option will return an error if opts includes a lifetime other than [Scoped].
+func PerScopeSingleflight(opts ...ServiceOption) ContainerOption {
+	return containerOption(func(c *Container) error {
+		scopedOpts, err := scopedOptions(opts)
+		if err != nil {
+			return errors.Wrap(err, "PerScopeSingleflight")
+		}
+
+		return WithService(func() *Singleflight {
+			return &Singleflight{}
+		}, scopedOpts...).applyContainer(c)
+	})
+}
+
+// scopedOptions returns a copy of opts with the Scoped lifetime added.
+// It returns an error if opts includes a different lifetime.
+func scopedOptions(opts []ServiceOption) ([]ServiceOption, error) {
+	for _, opt := range opts {
+		if l, ok := opt.(Lifetime); ok && l != Scoped {
+			return nil, errors.Errorf("lifetime %s: service must be Scoped", l)
+		}
+	}
+
+	return append(slices.Clip(opts), Scoped), nil
+}
+
+// Limiter limits the number of concurrent holders. It is registered with [PerScopeLimiter].
+//
+// A Limiter is safe for concurrent use.
+type Limiter struct {
+	sem chan struct{}
+}
+
+var typeLimiter = reflect.TypeFor[Limiter]()
+
+// Acquire waits until the Limiter has room for another holder, or ctx is canceled.
+// Call [Limiter.Release] when done.
+func (l *Limiter) Acquire(ctx context.Context) error {
+	select {
+	case l.sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TryAcquire acquires the Limiter without waiting, and returns true if successful.
+// Call [Limiter.Release] when done.
+func (l *Limiter) TryAcquire() bool {
+	select {
+	case l.sem <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Release releases the Limiter acquired with [Limiter.Acquire] or [Limiter.TryAcquire].
+func (l *Limiter) Release() {
+	<-l.sem
+}
+
+// Singleflight shares the result of concurrent calls with the same key.
+// It is registered with [PerScopeSingleflight].
+//
+// A Singleflight is safe for concurrent use.
+type Singleflight struct {
+	calls map[string]*singleflightCall
+	mu    sync.Mutex
+}
+
+var typeSingleflight = reflect.TypeFor[Singleflight]()
+
+type singleflightCall struct {
+	val  any
+	err  error
+	done chan struct{}
+}
+
+var errSingleflightPanic = errors.New("function panicked")
+
+// Do calls fn and returns its result, unless a call with the same key is already in progress.
+// In that case, Do waits for the call to return and returns the same result.
+// Results are not cached once the call has returned.
+//
+// fn is called with the ctx of the first caller.
+// Other callers return ctx.Err() if their ctx is canceled before the call returns.
+// If fn panics, the panic is passed on to the first caller, and other callers get an error.
+func (g *Singleflight) Do(ctx context.Context, key string, fn func(context.Context) (any, error)) (any, error) {
+	g.mu.Lock()
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+
+		select {
+		case <-call.done:
+			return call.val, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	call := &singleflightCall{
+		err:  errors.Wrapf(errSingleflightPanic, "di.Singleflight.Do %s", key),
+		done: make(chan struct{}),
+	}
+	if g.calls == nil {
+		g.calls = make(map[string]*singleflightCall)
+	}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+
+		close(call.done)
+	}()
+
+	call.val, call.err = fn(ctx)
+	return call.val, call.err
+}
@@ -0,0 +1,250 @@
+package di_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PerScopeLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("per scope", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.PerScopeLimiter(1),
+		)
+		require.NoError(t, err)
+
+		scope1, err := c.NewScope()
+		require.NoError(t, err)
+		scope2, err := c.NewScope()
+		require.NoError(t, err)
+
+		l1 := di.MustResolve[*di.Limiter](ctx, scope1)
+		assert.Same(t, l1, di.MustResolve[*di.Limiter](ctx, scope1))
+
+		l2 := di.MustResolve[*di.Limiter](ctx, scope2)
+		assert.NotSame(t, l1, l2)
+
+		assert.True(t, l1.TryAcquire())
+		assert.False(t, l1.TryAcquire())
+		assert.True(t, l2.TryAcquire())
+
+		l1.Release()
+		assert.True(t, l1.TryAcquire())
+	})
+
+	t.Run("Acquire canceled", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.PerScopeLimiter(1),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope()
+		require.NoError(t, err)
+
+		l := di.MustResolve[*di.Limiter](ctx, scope)
+		require.NoError(t, l.Acquire(ctx))
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		err = l.Acquire(timeoutCtx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("WithTag", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.PerScopeLimiter(1, di.WithTag("db")),
+			di.PerScopeLimiter(2, di.WithTag("http")),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope()
+		require.NoError(t, err)
+
+		db := di.MustResolve[*di.Limiter](ctx, scope, di.WithTag("db"))
+		http := di.MustResolve[*di.Limiter](ctx, scope, di.WithTag("http"))
+		assert.NotSame(t, db, http)
+	})
+
+	t.Run("root Container", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.PerScopeLimiter(1),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*di.Limiter](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve *di.Limiter: scoped service must be resolved from a child scope")
+	})
+
+	t.Run("n not positive", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.PerScopeLimiter(0),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: PerScopeLimiter 0: n must be positive")
+	})
+
+	t.Run("lifetime conflict", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.PerScopeLimiter(1, di.Singleton),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: PerScopeLimiter 1: lifetime Singleton: service must be Scoped")
+	})
+
+	t.Run("options not modified", func(t *testing.T) {
+		all := []di.ServiceOption{di.WithTag("db"), di.Transient}
+		opts := all[:1]
+
+		_, err := di.NewContainer(
+			di.PerScopeLimiter(1, opts...),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, di.Transient, all[1])
+	})
+}
+
+func Test_PerScopeSingleflight(t *testing.T) {
+	ctx := context.Background()
+
+	newSingleflight := func(t *testing.T) *di.Singleflight {
+		c, err := di.NewContainer(
+			di.PerScopeSingleflight(),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope()
+		require.NoError(t, err)
+
+		return di.MustResolve[*di.Singleflight](ctx, scope)
+	}
+
+	t.Run("per scope", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.PerScopeSingleflight(),
+		)
+		require.NoError(t, err)
+
+		scope1, err := c.NewScope()
+		require.NoError(t, err)
+		scope2, err := c.NewScope()
+		require.NoError(t, err)
+
+		g1 := di.MustResolve[*di.Singleflight](ctx, scope1)
+		assert.Same(t, g1, di.MustResolve[*di.Singleflight](ctx, scope1))
+		assert.NotSame(t, g1, di.MustResolve[*di.Singleflight](ctx, scope2))
+	})
+
+	t.Run("shares result", func(t *testing.T) {
+		g := newSingleflight(t)
+
+		var calls atomic.Int32
+		release := make(chan struct{})
+		fn := func(context.Context) (any, error) {
+			calls.Add(1)
+			<-release
+			return "value", nil
+		}
+
+		results := make([]any, 5)
+		var wg sync.WaitGroup
+		for i := range results {
+			wg.Go(func() {
+				results[i], _ = g.Do(ctx, "key", fn)
+			})
+		}
+
+		// Wait for the first call to start, then give the others time to join it
+		require.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), calls.Load())
+		for _, val := range results {
+			assert.Equal(t, "value", val)
+		}
+
+		// Results are not cached
+		_, _ = g.Do(ctx, "key", func(context.Context) (any, error) {
+			calls.Add(1)
+			return "value", nil
+		})
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		g := newSingleflight(t)
+
+		val, err := g.Do(ctx, "key", func(context.Context) (any, error) {
+			return nil, errors.New("load error")
+		})
+		assert.Nil(t, val)
+		assert.EqualError(t, err, "load error")
+	})
+
+	t.Run("waiter canceled", func(t *testing.T) {
+		g := newSingleflight(t)
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		go func() {
+			_, _ = g.Do(ctx, "key", func(context.Context) (any, error) {
+				close(started)
+				<-release
+				return "value", nil
+			})
+		}()
+		<-started
+		defer close(release)
+
+		canceledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := g.Do(canceledCtx, "key", func(context.Context) (any, error) {
+			return "value", nil
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("lifetime conflict", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.PerScopeSingleflight(di.Transient),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: PerScopeSingleflight: lifetime Transient: service must be Scoped")
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		g := newSingleflight(t)
+
+		assert.PanicsWithValue(t, "boom", func() {
+			_, _ = g.Do(ctx, "key", func(context.Context) (any, error) {
+				panic("boom")
+			})
+		})
+
+		val, err := g.Do(ctx, "key", func(context.Context) (any, error) {
+			return "value", nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "value", val)
+	})
+}
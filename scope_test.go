@@ -50,3 +50,94 @@ func Test_MustResolve(t *testing.T) {
 		)
 	})
 }
+
+func Test_ReadOnly(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Resolve", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+
+		s := di.ReadOnly(c)
+		assert.True(t, s.Contains(testtypes.TypeInterfaceA))
+		assert.False(t, s.Contains(testtypes.TypeInterfaceB))
+
+		got, err := di.Resolve[testtypes.InterfaceA](ctx, s)
+		require.NoError(t, err)
+		assert.Same(t, di.MustResolve[testtypes.InterfaceA](ctx, c), got)
+	})
+
+	t.Run("hides Container", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		s := di.ReadOnly(c)
+
+		_, ok := s.(*di.Container)
+		assert.False(t, ok)
+		_, ok = s.(di.Closer)
+		assert.False(t, ok)
+		_, ok = s.(di.ContainerInterface)
+		assert.False(t, ok)
+		_, ok = s.(interface {
+			NewScope(...di.ContainerOption) (*di.Container, error)
+		})
+		assert.False(t, ok)
+	})
+
+	t.Run("injected Scope", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(func(s di.Scope) testtypes.InterfaceB {
+				ro := di.ReadOnly(s)
+				_, ok := ro.(interface {
+					NewScope(...di.ContainerOption) (*di.Container, error)
+				})
+				assert.False(t, ok)
+
+				_, err := di.Resolve[testtypes.InterfaceA](ctx, ro)
+				assert.EqualError(t, err, "di.Container.Resolve testtypes.InterfaceA: not supported within service constructor function")
+
+				return testtypes.StructB{}
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceB](ctx, c)
+		assert.NoError(t, err)
+	})
+
+	t.Run("ResolveLast", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.StructA{Tag: 1}, di.As[testtypes.InterfaceA]()),
+			di.WithService(testtypes.StructA{Tag: 2}, di.As[testtypes.InterfaceA]()),
+			di.WithStrictResolve(),
+		)
+		require.NoError(t, err)
+
+		s := di.ReadOnly(c)
+
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, s)
+		assert.Error(t, err)
+
+		got, err := di.ResolveLast[testtypes.InterfaceA](ctx, s)
+		require.NoError(t, err)
+		assert.Equal(t, testtypes.StructA{Tag: 2}, got)
+	})
+
+	t.Run("ReadOnly twice", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		s := di.ReadOnly(c)
+		assert.Equal(t, s, di.ReadOnly(s))
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		assert.PanicsWithValue(t, "di.ReadOnly: s is nil", func() {
+			di.ReadOnly(nil)
+		})
+	})
+}
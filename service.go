@@ -1,8 +1,10 @@
 package di
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/sectrean/di-kit/internal/errors"
 )
@@ -16,8 +18,7 @@ import (
 // The function may also accept a [context.Context] or [di.Scope].
 //
 // The function must return a service, or the service and an error.
-// The service will be registered as the return type of the function, which must be an interface,
-// a struct, or a pointer to an interface or struct.
+// The service will be registered as the return type of the function.
 //
 // If the function returns an error, this error will be returned when the service is resolved,
 // either directly or as a dependency.
@@ -26,12 +27,20 @@ import (
 // If the resolved service implements [Closer], or a compatible Close method signature,
 // it will be closed when the Container is closed.
 //
+// The function may also return (Service, func(), error), where the second value is a cleanup function.
+// The cleanup function is called when the Container is closed, instead of a Close method of the service,
+// in the reverse order services were created. It is not called if the function returns an error.
+//
 // If a value is provided, it will be returned as the service when resolved.
 // (It will be registered as the actual type even if the variable was declared as an interface.)
 //
-// A service can be almost any named type including structs, interfaces, basic types, functions, or a pointer to a named type.
+// A service can be almost any named type including structs, interfaces, basic types, functions,
+// named slice, map, and channel types, or a pointer to a named type.
 // Some types like [error] and [context.Context] are reserved and cannot be registered as services.
 //
+// A nil value of a named slice or map type is registered as an empty value.
+// Other nil values, including a nil channel or function, return an error since they can't be used.
+//
 // Available options:
 //   - [Lifetime] is used to specify how services are created when resolved.
 //   - [As] overrides the type a service is registered as.
@@ -40,8 +49,18 @@ import (
 //   - [UseCloseFunc] specifies a function to be called when the service is closed.
 //   - [IgnoreCloser] specifies that the service should not be closed by the Container.
 //     Function services are closed by default if they implement [Closer] or a compatible function signature.
+//   - [WithMemo] caches the result of a [Transient] service for a duration.
+//   - [SingletonPer] creates a [Singleton] service once per key derived from the context.
+//   - [WithCacheLimit] sets the maximum number of instances cached for [SingletonPer].
+//   - [WithCircuitBreaker] fails fast when the constructor function keeps returning errors.
+//   - [WithoutCancel] creates the service even if the context passed to Resolve is canceled.
+//   - [WithMaxConcurrentConstructions] limits concurrent calls to the constructor function.
+//   - [WithPrewarm] keeps instances of a [Transient] service created ahead of time.
+//   - [WithCustomLifetime] specifies a [CustomLifetime] for the service.
 //   - [UseCloser] specifies that the service should be closed by the Container if it implements [Closer] or a compatible function signature.
 //     This is the default for function services. Value services will not be closed by default.
+//
+// If the function returns a struct that embeds [Out], each field is also registered as a service.
 func WithService(funcOrValue any, opts ...ServiceOption) ContainerOption {
 	// Use a single WithService function for both function and value services
 	// because it's a better UX.
@@ -55,20 +74,133 @@ func WithService(funcOrValue any, opts ...ServiceOption) ContainerOption {
 
 	return containerOption(func(c *Container) error {
 		v := reflect.ValueOf(funcOrValue)
-		if isNil(v) {
+		if isNilValue(v) {
 			return errors.New("WithService: funcOrValue is nil")
 		}
 
-		s, err := newService(c, v, opts...)
+		s, err := newService(c, v, v.Kind() != reflect.Func, opts...)
 		if err != nil {
 			return errors.Wrapf(err, "WithService %s", v.Type())
 		}
 
+		if err := c.registerWithOut(s); err != nil {
+			return errors.Wrapf(err, "WithService %s", v.Type())
+		}
+		return nil
+	})
+}
+
+// WithDeclaredService registers a value service as type *Service* when calling [NewContainer]
+// or [Container.NewScope].
+//
+// [WithService] registers a value as its actual type, even if the variable was declared as an interface.
+// This registers the value as the declared type instead, which is checked at compile time.
+// The value is always registered as a value service, even if *Service* is a function type.
+//
+// Example:
+//
+//	var store storage.Store = storage.NewMemoryStore()
+//
+//	c, err := di.NewContainer(
+//		di.WithDeclaredService(store), // Registered as storage.Store
+//	)
+//
+// All [ServiceOption]s supported by value services are available.
+// Use [As] to also register the value as other types.
+//
+// This option will return an error if the value is nil, including an interface holding a nil pointer.
+func WithDeclaredService[Service any](value Service, opts ...ServiceOption) ContainerOption {
+	return withDeclaredValue("WithDeclaredService", value, opts)
+}
+
+// WithValue registers a value service as type *Service* when calling [NewContainer]
+// or [Container.NewScope].
+//
+// This is the explicit counterpart of [WithConstructor], and works like [WithDeclaredService].
+// Use WithValue and WithConstructor instead of [WithService] so mixing up
+// WithService(NewService()) and WithService(NewService) is caught when the code is written.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithValue[*Config](cfg),
+//		di.WithConstructor[storage.Store](storage.NewDBStore),
+//	)
+//
+// All [ServiceOption]s supported by value services are available.
+//
+// This option will return an error if the value is nil, including an interface holding a nil pointer.
+func WithValue[Service any](value Service, opts ...ServiceOption) ContainerOption {
+	return withDeclaredValue("WithValue", value, opts)
+}
+
+func withDeclaredValue[Service any](name string, value Service, opts []ServiceOption) ContainerOption {
+	return containerOption(func(c *Container) error {
+		t := reflect.TypeFor[Service]()
+
+		v := reflect.ValueOf(value)
+		if isNilValue(v) {
+			return errors.Errorf("%s %s: value is nil", name, t)
+		}
+
+		s, err := newService(c, v, true, append([]ServiceOption{As[Service]()}, opts...)...)
+		if err != nil {
+			return errors.Wrapf(err, "%s %s", name, t)
+		}
+
 		c.register(s)
 		return nil
 	})
 }
 
+// WithConstructor registers a constructor function for type *Service* when calling [NewContainer]
+// or [Container.NewScope].
+//
+// This is the explicit counterpart of [WithValue]. The function is registered like [WithService]
+// with [As] *Service*, but passing a value instead of a function is an error,
+// instead of registering the value as a service.
+// Since fn has type any, the error is returned by [NewContainer] or [Container.NewScope],
+// not when the code is compiled.
+// The function may have any parameters, and must return a type assignable to *Service*,
+// optionally with a cleanup function and an error. See [Register] to check the signature at compile time.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithConstructor[storage.Store](storage.NewDBStore), // NewDBStore(*sql.DB) *DBStore
+//	)
+//
+// All [ServiceOption]s supported by [WithService] are available.
+//
+// This option will return an error if fn is nil, is not a function,
+// or does not return a type assignable to *Service*.
+func WithConstructor[Service any](fn any, opts ...ServiceOption) ContainerOption {
+	return containerOption(func(c *Container) error {
+		t := reflect.TypeFor[Service]()
+
+		v := reflect.ValueOf(fn)
+		if isNil(v) {
+			return errors.Errorf("WithConstructor %s: function is nil", t)
+		}
+		if v.Kind() != reflect.Func {
+			return errors.Errorf("WithConstructor %s: %s is not a function", t, v.Type())
+		}
+		if fnType := v.Type(); fnType.NumOut() > 0 && !fnType.Out(0).AssignableTo(t) {
+			return errors.Errorf("WithConstructor %s: function returns %s", t, fnType.Out(0))
+		}
+
+		s, err := newService(c, v, false, append([]ServiceOption{As[Service]()}, opts...)...)
+		if err != nil {
+			return errors.Wrapf(err, "WithConstructor %s", t)
+		}
+
+		if err := c.registerWithOut(s); err != nil {
+			return errors.Wrapf(err, "WithConstructor %s", t)
+		}
+		return nil
+	})
+}
+
 // ServiceOption is used to configure service registration when calling [WithService].
 type ServiceOption interface {
 	applyService(*service) error
@@ -101,11 +233,14 @@ func (o serviceOption) applyService(s *service) error {
 //
 // This option will return an error if the service type is not assignable to type *Service*.
 func As[Service any]() ServiceOption {
-	return serviceOption(func(s *service) error {
-		t := reflect.TypeFor[Service]()
+	return asType(reflect.TypeFor[Service]())
+}
 
+// asType works like [As] for a [reflect.Type].
+func asType(t reflect.Type) ServiceOption {
+	return serviceOption(func(s *service) error {
 		if ok := validateServiceType(t); !ok {
-			return errors.Errorf("As %s: invalid service type", t)
+			return errors.Errorf("As %s: invalid service type; %s", t, invalidServiceTypeHint(t))
 		}
 		if !s.Type().AssignableTo(t) {
 			return errors.Errorf("As %s: type %s not assignable to %s", t, s.Type(), t)
@@ -128,19 +263,50 @@ func (k serviceKey) String() string {
 	return fmt.Sprintf("%s: WithTag %v", k.Type, k.Tag)
 }
 
+// resolveOptions returns the options to resolve the service key with [Scope.Resolve].
+func (k serviceKey) resolveOptions() []ResolveOption {
+	if k.Tag == nil {
+		return nil
+	}
+	return []ResolveOption{WithTag(k.Tag)}
+}
+
+// ValidateServiceType returns an error if values of type t can't be registered as a service.
+//
+// This is intended for adapters that register a value of a type parameter with each scope they create,
+// so an invalid type is reported when the adapter is configured, instead of each time a scope is created.
+func ValidateServiceType(t reflect.Type) error {
+	if t == nil {
+		return errors.New("service type is nil")
+	}
+	if !validateServiceType(t) {
+		return errors.Errorf("invalid service type %s; %s", t, invalidServiceTypeHint(t))
+	}
+
+	return nil
+}
+
 func validateServiceType(t reflect.Type) bool {
 	if t.Kind() == reflect.Pointer {
 		t = t.Elem()
 	}
 
-	// TODO: Give more specific error messages for invalid types, e.g. unnamed basic types, reserved types, etc.
-
 	switch t {
 	// These special types cannot be registered as services
 	case typeContext,
 		typeScope,
 		typeError:
 		return false
+
+	// These built-in types can be overridden
+	case typeClock,
+		typeRand:
+		return true
+
+	// These types are registered by PerScopeLimiter and PerScopeSingleflight
+	case typeLimiter,
+		typeSingleflight:
+		return true
 	}
 
 	// We don't want someone to accidentally register a ContainerOption or something.
@@ -161,7 +327,10 @@ func validateDependencyType(t reflect.Type) bool {
 		return true
 	}
 
-	if isUnnamedSliceType(t) {
+	if elemType, ok := optionalElem(t); ok {
+		t = elemType
+	}
+	if isUnnamedSliceType(t) || isStringMapType(t) {
 		t = t.Elem()
 	}
 
@@ -171,27 +340,61 @@ func validateDependencyType(t reflect.Type) bool {
 type closerFactory = func(any) Closer
 
 type service struct {
-	scope         *Container
-	v             reflect.Value
-	t             reflect.Type
-	deps          []serviceKey
-	tags          []any
-	closerFactory closerFactory
-	assignables   []reflect.Type
-	lifetime      Lifetime
+	scope            *Container
+	v                reflect.Value
+	t                reflect.Type
+	deps             []serviceKey
+	tags             []any
+	closerFactory    closerFactory
+	startFuncs       []func(context.Context, any) error
+	typedNew         func(deps []reflect.Value) (any, error)
+	warmCodec        *warmCodec
+	assignables      []reflect.Type
+	zeroDeps         []bool
+	minCounts        []int
+	breaker          *circuitBreaker
+	keyed            *keyedCache
+	regs             map[serviceKey]registration
+	constructions    constructionLimit
+	prewarm          *prewarmPool
+	custom           CustomLifetime
+	name             string
+	module           string
+	replacedKeys     map[serviceKey]struct{}
+	prototype        func(any) any
+	metadata         map[string]string
+	deprecated       string
+	args             []reflect.Value
+	memoTTL          time.Duration
+	coldStartTimeout time.Duration
+	cacheLimit       int
+	cacheTTL         time.Duration
+	order            int
+	lifetime         Lifetime
+	lifetimeSet      bool
+	value            bool
+	builtin          bool
+	withoutCancel    bool
+	replace          bool
+	cleanup          bool
+	chain            bool
+	decorator        bool
+	primary          bool
 }
 
-func newService(c *Container, v reflect.Value, opts ...ServiceOption) (*service, error) {
+func newService(c *Container, v reflect.Value, value bool, opts ...ServiceOption) (*service, error) {
 	s := &service{
 		scope:    c,
 		v:        v,
+		value:    value,
 		lifetime: Singleton,
 	}
 	var err error
+	var invalidDeps []error
 
-	if v.Kind() == reflect.Func {
+	if !value {
 		// Func service
-		err = s.initFuncService(v.Type())
+		invalidDeps, err = s.initFuncService(v.Type())
 	} else {
 		// Value service
 		err = s.initValueService(v.Type())
@@ -207,56 +410,167 @@ func newService(c *Container, v reflect.Value, opts ...ServiceOption) (*service,
 		return nil, err
 	}
 
+	// Parameters with types that can't be resolved must be bound with WithArgs
+	for i := range invalidDeps {
+		if s.arg(i).IsValid() {
+			invalidDeps[i] = nil
+		}
+	}
+	if err := errors.Join(invalidDeps...); err != nil {
+		return nil, err
+	}
+
+	if err := s.applyImpliedLifetime(); err != nil {
+		return nil, err
+	}
+	if err := s.validateCustomLifetime(); err != nil {
+		return nil, err
+	}
+	if s.coldStartTimeout > 0 && s.lifetime == Transient {
+		return nil, errors.Errorf("WithColdStartTimeout %s: service must be Singleton or Scoped", s.coldStartTimeout)
+	}
+	if s.memoTTL > 0 && s.lifetime != Transient {
+		return nil, errors.Errorf("WithMemo %s: service must be Transient", s.memoTTL)
+	}
+	if s.prewarm != nil && s.lifetime != Transient {
+		return nil, errors.Errorf("WithPrewarm %d: service must be Transient", cap(s.prewarm.ready))
+	}
+	if s.constructions != nil && s.lifetime == Singleton {
+		return nil, errors.Errorf("WithMaxConcurrentConstructions %d: service must be Transient or Scoped", cap(s.constructions))
+	}
+	if s.warmCodec != nil && (s.lifetime != Singleton || s.keyed != nil || s.custom != nil) {
+		return nil, errors.Errorf("WithWarmCodec %s: service must be Singleton", s.t)
+	}
+	if s.keyed != nil && s.lifetime != Singleton {
+		return nil, errors.Errorf("SingletonPer: invalid lifetime %s", s.lifetime)
+	}
+	if s.cacheLimit > 0 {
+		if s.keyed == nil {
+			return nil, errors.Errorf("WithCacheLimit %d: service must use SingletonPer", s.cacheLimit)
+		}
+		s.keyed.limit = s.cacheLimit
+	}
+	if s.cacheTTL > 0 {
+		if s.keyed == nil {
+			return nil, errors.Errorf("WithCacheTTL %s: service must use SingletonPer", s.cacheTTL)
+		}
+		s.keyed.ttl = s.cacheTTL
+	}
+
 	return s, nil
 }
 
-func (s *service) initFuncService(funcType reflect.Type) error {
+// initFuncService returns an error for each parameter with a type that can't be resolved,
+// since the parameter may be bound with [WithArgs].
+func (s *service) initFuncService(funcType reflect.Type) ([]error, error) {
 	// Figure out the service type
+	const signatures = "function must return Service, (Service, error), or (Service, func(), error)"
 	switch {
 	case funcType.NumOut() == 1:
 		s.t = funcType.Out(0)
 	case funcType.NumOut() == 2 && funcType.Out(1) == typeError:
 		s.t = funcType.Out(0)
+	case funcType.NumOut() == 3 && funcType.Out(1) == typeCleanup && funcType.Out(2) == typeError:
+		s.t = funcType.Out(0)
+		s.cleanup = true
+	case funcType.NumOut() == 0:
+		return nil, errors.New(signatures + "; function has no return values")
+	case funcType.NumOut() == 2:
+		return nil, errors.Errorf(signatures+"; return 1 has type %s, expected error", funcType.Out(1))
+	case funcType.NumOut() == 3 && funcType.Out(1) != typeCleanup:
+		return nil, errors.Errorf(signatures+"; return 1 has type %s, expected func()", funcType.Out(1))
+	case funcType.NumOut() == 3:
+		return nil, errors.Errorf(signatures+"; return 2 has type %s, expected error", funcType.Out(2))
 	default:
-		return errors.New("function must return Service or (Service, error)")
+		return nil, errors.Errorf(signatures+"; function has %d return values", funcType.NumOut())
 	}
 
 	if ok := validateServiceType(s.t); !ok {
-		return errors.New("invalid service type")
+		return nil, errors.Errorf("return type %s: invalid service type; %s", s.t, invalidServiceTypeHint(s.t))
 	}
 
 	// Get the dependencies and validate dependency types
 	var errs []error
+	var invalidDeps []error
 
 	if funcType.NumIn() > 0 {
 		s.deps = make([]serviceKey, funcType.NumIn())
 		for i := range funcType.NumIn() {
 			depType := funcType.In(i)
 
-			if ok := validateDependencyType(depType); !ok {
-				err := errors.Errorf("invalid dependency type %s", depType)
-				errs = append(errs, err)
-				continue
+			if isInType(depType) {
+				if _, err := inFields(depType); err != nil {
+					errs = append(errs, errors.Wrapf(err, "parameter %d", i))
+					continue
+				}
 			}
 
 			s.deps[i] = serviceKey{
 				Type: depType,
 			}
+
+			if ok := validateDependencyType(depType); !ok {
+				if invalidDeps == nil {
+					invalidDeps = make([]error, funcType.NumIn())
+				}
+				invalidDeps[i] = errors.Errorf("parameter %d: invalid dependency type %s; %s", i, depType, invalidTypeHint(depType))
+			}
 		}
 	}
 
 	if err := errors.Join(errs...); err != nil {
-		return err
+		return nil, err
 	}
 
 	s.closerFactory = getCloser
+	if isOutType(s.t) {
+		s.closerFactory = closeOutFields
+	}
+	if s.cleanup {
+		// The cleanup function is called instead of a Close method
+		s.closerFactory = noCloser
+	}
 
-	return nil
+	return invalidDeps, nil
+}
+
+// invalidTypeHint returns a suggestion for a type that cannot be used as a service or dependency.
+func invalidTypeHint(t reflect.Type) string {
+	if elemType, ok := optionalElem(t); ok {
+		t = elemType
+	}
+	if isUnnamedSliceType(t) || isStringMapType(t) {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == typeContext, t == typeScope, t == typeError:
+		return fmt.Sprintf("%s is reserved and can only be used as a dependency", t)
+	case t.PkgPath() == typeScope.PkgPath():
+		return "types from package di are reserved"
+	default:
+		return "use a named type or a pointer to a named type"
+	}
+}
+
+// invalidServiceTypeHint returns a suggestion for a type that cannot be registered as a service.
+func invalidServiceTypeHint(t reflect.Type) string {
+	if (isUnnamedSliceType(t) || isStringMapType(t)) && validateServiceType(t.Elem()) {
+		if t.Kind() == reflect.Map {
+			return fmt.Sprintf("register each element as %s with a string tag, and depend on %s to resolve all of them", t.Elem(), t)
+		}
+		return fmt.Sprintf("register each element as %s, and depend on %s to resolve all of them", t.Elem(), t)
+	}
+
+	return invalidTypeHint(t)
 }
 
 func (s *service) initValueService(valType reflect.Type) error {
 	if ok := validateServiceType(valType); !ok {
-		return errors.New("invalid service type")
+		return errors.Errorf("invalid service type; %s", invalidServiceTypeHint(valType))
 	}
 
 	s.t = valType
@@ -267,11 +581,40 @@ func (s *service) Scope() *Container { return s.scope }
 
 // Type of the service. This is the return type of the constructor function or the actual type of the value.
 func (s *service) Type() reflect.Type          { return s.t }
-func (s *service) IsValue() bool               { return s.v.Kind() != reflect.Func }
+func (s *service) IsValue() bool               { return s.value }
 func (s *service) Lifetime() Lifetime          { return s.lifetime }
 func (s *service) Dependencies() []serviceKey  { return s.deps }
 func (s *service) Tags() []any                 { return s.tags }
 func (s *service) Assignables() []reflect.Type { return s.assignables }
+func (s *service) MemoTTL() time.Duration      { return s.memoTTL }
+func (s *service) Breaker() *circuitBreaker    { return s.breaker }
+func (s *service) Keyed() *keyedCache          { return s.keyed }
+
+// Keys returns the keys the service is registered with.
+func (s *service) Keys() []serviceKey {
+	types := s.assignables
+	if len(types) == 0 {
+		types = []reflect.Type{s.t}
+	}
+
+	tags := s.tags
+	if len(tags) == 0 {
+		tags = []any{nil}
+	}
+
+	keys := make([]serviceKey, 0, len(types)*len(tags))
+	for _, t := range types {
+		for _, tag := range tags {
+			key := serviceKey{Type: t, Tag: tag}
+			if _, ok := s.replacedKeys[key]; ok {
+				continue
+			}
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
 
 func (s *service) Value() any {
 	return s.v.Interface()
@@ -281,7 +624,13 @@ func (s *service) Func() reflect.Value {
 	return s.v
 }
 
-func (s *service) New(deps []reflect.Value) (val any, err error) {
+func (s *service) New(deps []reflect.Value) (val any, cleanup func(), err error) {
+	// Call the function directly if it was registered with Register
+	if s.typedNew != nil {
+		val, err = s.typedNew(deps)
+		return val, nil, err
+	}
+
 	// Call the function
 	var out []reflect.Value
 	if s.Func().Type().IsVariadic() {
@@ -294,25 +643,45 @@ func (s *service) New(deps []reflect.Value) (val any, err error) {
 	if !isNil(out[0]) {
 		val = out[0].Interface()
 	}
-	if len(out) == 2 && !isNil(out[1]) {
-		err = out[1].Interface().(error)
+	if last := out[len(out)-1]; len(out) > 1 && !isNil(last) {
+		err = last.Interface().(error)
+	}
+	if len(out) == 3 && err == nil && !isNil(out[1]) {
+		cleanup = out[1].Interface().(func())
 	}
 
-	return val, err
+	return val, cleanup, err
 }
 
-func (s *service) CloserFor(val any) Closer {
-	if val == nil {
-		return nil
+// CloserFor returns the Closer for an instance of the service,
+// including the cleanup function returned by the constructor function, if any.
+func (s *service) CloserFor(val any, cleanup func()) Closer {
+	// IgnoreCloser only ignores the Close method of the value, not the cleanup function
+	var closer Closer
+	if val != nil && s.closerFactory != nil {
+		closer = s.closerFactory(val)
 	}
 
-	if s.closerFactory != nil {
-		return s.closerFactory(val)
+	switch {
+	case cleanup == nil:
+		return closer
+	case closer == nil:
+		return closeFunc(func(context.Context) error {
+			cleanup()
+			return nil
+		})
+	default:
+		return closeFunc(func(ctx context.Context) error {
+			err := closer.Close(ctx)
+			cleanup()
+			return err
+		})
 	}
-
-	return nil
 }
 
 func (s *service) String() string {
+	if s.name != "" {
+		return s.v.Type().String() + " (" + s.name + ")"
+	}
 	return s.v.Type().String()
 }
@@ -0,0 +1,216 @@
+package di
+
+import (
+	"context"
+	"reflect"
+	"slices"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// ServiceFactory creates a service registered with a [ServiceBuilder].
+//
+// deps contains the resolved dependencies, in the order they were added with [ServiceBuilder.Dependency].
+type ServiceFactory = func(ctx context.Context, deps []any) (any, error)
+
+// ServiceBuilder registers a service programmatically, without a constructor function.
+//
+// This is intended for adapters and extensions that import registrations from somewhere else,
+// where the service type and dependencies are only known at runtime.
+// Use [WithService] to register a constructor function or value.
+//
+// Example:
+//
+//	opt := di.NewServiceBuilder(reflect.TypeFor[*Service]()).
+//		Dependency(reflect.TypeFor[*slog.Logger](), nil).
+//		Dependency(reflect.TypeFor[storage.Store](), "primary").
+//		Factory(func(ctx context.Context, deps []any) (any, error) {
+//			return NewService(deps[0].(*slog.Logger), deps[1].(storage.Store)), nil
+//		}).
+//		Lifetime(di.Scoped).
+//		Build()
+//
+//	c, err := di.NewContainer(opt)
+type ServiceBuilder struct {
+	t       reflect.Type
+	factory ServiceFactory
+	closer  func(context.Context, any) error
+	name    string
+	deps    []serviceKey
+	opts    []ServiceOption
+}
+
+// NewServiceBuilder returns a [ServiceBuilder] for a service registered as type t.
+func NewServiceBuilder(t reflect.Type) *ServiceBuilder {
+	return &ServiceBuilder{
+		t: t,
+	}
+}
+
+// Dependency adds a dependency of type t with the given tag.
+// Use a nil tag for a dependency without a tag.
+//
+// The resolved dependency is passed to the [ServiceFactory] at the same index it was added.
+func (b *ServiceBuilder) Dependency(t reflect.Type, tag any) *ServiceBuilder {
+	b.deps = append(b.deps, serviceKey{Type: t, Tag: tag})
+	return b
+}
+
+// Factory sets the function called to create the service.
+//
+// The function may return nil for the service, which is not treated as an error.
+func (b *ServiceBuilder) Factory(f ServiceFactory) *ServiceBuilder {
+	b.factory = f
+	return b
+}
+
+// Lifetime sets the [Lifetime] of the service. The default is [Singleton].
+func (b *ServiceBuilder) Lifetime(l Lifetime) *ServiceBuilder {
+	b.opts = append(b.opts, l)
+	return b
+}
+
+// Closer sets a function to call to close the service when the [Container] is closed.
+//
+// By default, the service is closed if it implements [Closer] or a compatible Close method,
+// like a function service.
+func (b *ServiceBuilder) Closer(f func(ctx context.Context, val any) error) *ServiceBuilder {
+	b.closer = f
+	return b
+}
+
+// Options adds [ServiceOption]s, like [WithTag] or [As], used to register the service.
+func (b *ServiceBuilder) Options(opts ...ServiceOption) *ServiceBuilder {
+	b.opts = append(b.opts, opts...)
+	return b
+}
+
+// Build returns a [ContainerOption] that registers the service when calling [NewContainer]
+// or [Container.NewScope].
+//
+// Changes made to the ServiceBuilder after calling Build do not affect the returned option.
+//
+// The option will return an error if the service type or a dependency type is invalid,
+// or if the factory function is nil.
+func (b *ServiceBuilder) Build() ContainerOption {
+	sb := *b
+	sb.deps = slices.Clone(b.deps)
+	sb.opts = slices.Clone(b.opts)
+
+	return containerOption(sb.register)
+}
+
+func (b *ServiceBuilder) register(c *Container) error {
+	if b.t == nil {
+		return errors.New("ServiceBuilder: type is nil")
+	}
+
+	s, err := b.newService(c)
+	if err != nil {
+		return errors.Wrapf(err, "ServiceBuilder %s", b.t)
+	}
+
+	c.register(s)
+	return nil
+}
+
+func (b *ServiceBuilder) newService(c *Container) (*service, error) {
+	err := b.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := make([]ServiceOption, 0, len(b.opts)+1)
+	opts = append(opts, serviceOption(b.applyService))
+	opts = append(opts, b.opts...)
+
+	return newService(c, b.makeFunc(), false, opts...)
+}
+
+func (b *ServiceBuilder) validate() error {
+	var errs []error
+
+	if ok := validateServiceType(b.t); !ok {
+		errs = append(errs, errors.Errorf("invalid service type; %s", invalidServiceTypeHint(b.t)))
+	}
+
+	for i, dep := range b.deps {
+		if dep.Type == nil {
+			errs = append(errs, errors.Errorf("dependency %d: type is nil", i))
+			continue
+		}
+		if ok := validateDependencyType(dep.Type); !ok {
+			errs = append(errs, errors.Errorf("dependency %d: invalid dependency type %s; %s",
+				i, dep.Type, invalidTypeHint(dep.Type)))
+		}
+	}
+
+	if b.factory == nil {
+		errs = append(errs, errors.New("factory is nil"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// applyService sets the name, dependency tags, and closer before other options are applied.
+func (b *ServiceBuilder) applyService(s *service) error {
+	// The first parameter is the context passed to the factory
+	for i, dep := range b.deps {
+		s.deps[i+1].Tag = dep.Tag
+	}
+
+	s.name = b.name
+	if s.name == "" {
+		s.name = "di.ServiceBuilder " + b.t.String()
+	}
+
+	if b.closer != nil {
+		s.closerFactory = func(val any) Closer {
+			return closeFunc(func(ctx context.Context) error {
+				return b.closer(ctx, val)
+			})
+		}
+	}
+
+	return nil
+}
+
+// makeFunc returns a constructor function with the dependencies as parameters,
+// so the service is resolved the same way as any function service.
+func (b *ServiceBuilder) makeFunc() reflect.Value {
+	in := make([]reflect.Type, 0, len(b.deps)+1)
+	in = append(in, typeContext)
+	for _, dep := range b.deps {
+		in = append(in, dep.Type)
+	}
+
+	funcType := reflect.FuncOf(in, []reflect.Type{b.t, typeError}, false)
+
+	return reflect.MakeFunc(funcType, func(args []reflect.Value) []reflect.Value {
+		ctx := args[0].Interface().(context.Context)
+
+		deps := make([]any, len(b.deps))
+		for i, arg := range args[1:] {
+			deps[i] = arg.Interface()
+		}
+
+		val, err := b.factory(ctx, deps)
+
+		out := reflect.New(b.t).Elem()
+		if val != nil {
+			v := reflect.ValueOf(val)
+			if v.Type().AssignableTo(b.t) {
+				out.Set(v)
+			} else {
+				err = errors.Join(err, errors.Errorf("factory returned %s, expected %s", v.Type(), b.t))
+			}
+		}
+
+		errOut := reflect.Zero(typeError)
+		if err != nil {
+			errOut = reflect.ValueOf(&err).Elem()
+		}
+
+		return []reflect.Value{out, errOut}
+	})
+}
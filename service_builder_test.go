@@ -0,0 +1,212 @@
+package di_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ServiceBuilder(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("dependencies", func(t *testing.T) {
+		a := testtypes.StructA{Tag: "tag"}
+
+		c, err := di.NewContainer(
+			di.WithService(a, di.As[testtypes.InterfaceA](), di.WithTag("tag")),
+			di.NewServiceBuilder(testtypes.TypeInterfaceB).
+				Dependency(testtypes.TypeInterfaceA, "tag").
+				Factory(func(_ context.Context, deps []any) (any, error) {
+					assert.Equal(t, []any{a}, deps)
+					return testtypes.StructB{}, nil
+				}).
+				Build(),
+		)
+		require.NoError(t, err)
+
+		b, err := di.Resolve[testtypes.InterfaceB](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, testtypes.StructB{}, b)
+	})
+
+	t.Run("context", func(t *testing.T) {
+		valueCtx := testutils.ContextWithTestValue(ctx, "value")
+
+		c, err := di.NewContainer(
+			di.NewServiceBuilder(testtypes.TypeInterfaceA).
+				Factory(func(ctx context.Context, deps []any) (any, error) {
+					assert.Empty(t, deps)
+					return testtypes.StructA{Tag: testutils.TestValue(ctx)}, nil
+				}).
+				Build(),
+		)
+		require.NoError(t, err)
+
+		a, err := di.Resolve[testtypes.InterfaceA](valueCtx, c)
+		require.NoError(t, err)
+		assert.Equal(t, testtypes.StructA{Tag: "value"}, a)
+	})
+
+	t.Run("Lifetime", func(t *testing.T) {
+		calls := 0
+		c, err := di.NewContainer(
+			di.NewServiceBuilder(testtypes.TypeInterfaceA).
+				Factory(func(context.Context, []any) (any, error) {
+					calls++
+					return &testtypes.StructA{}, nil
+				}).
+				Lifetime(di.Transient).
+				Build(),
+		)
+		require.NoError(t, err)
+
+		a1 := di.MustResolve[testtypes.InterfaceA](ctx, c)
+		a2 := di.MustResolve[testtypes.InterfaceA](ctx, c)
+		assert.NotSame(t, a1, a2)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Closer", func(t *testing.T) {
+		var closed any
+		c, err := di.NewContainer(
+			di.NewServiceBuilder(testtypes.TypeInterfaceA).
+				Factory(func(context.Context, []any) (any, error) {
+					return testtypes.StructA{Tag: "closed"}, nil
+				}).
+				Closer(func(_ context.Context, val any) error {
+					closed = val
+					return nil
+				}).
+				Build(),
+		)
+		require.NoError(t, err)
+
+		_ = di.MustResolve[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, c.Close(ctx))
+		assert.Equal(t, testtypes.StructA{Tag: "closed"}, closed)
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.NewServiceBuilder(reflect.TypeFor[*testtypes.StructA]()).
+				Factory(func(context.Context, []any) (any, error) {
+					return &testtypes.StructA{}, nil
+				}).
+				Options(di.As[testtypes.InterfaceA](), di.WithTag("tag")).
+				Build(),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, c, di.WithTag("tag"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Build copies builder", func(t *testing.T) {
+		b := di.NewServiceBuilder(testtypes.TypeInterfaceA).
+			Factory(func(context.Context, []any) (any, error) {
+				return testtypes.StructA{}, nil
+			})
+		opt := b.Build()
+		b.Dependency(testtypes.TypeInterfaceB, nil)
+
+		c, err := di.NewContainer(opt, di.WithDependencyValidation())
+		require.NoError(t, err)
+		assert.True(t, c.Contains(testtypes.TypeInterfaceA))
+	})
+
+	t.Run("nil service", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.NewServiceBuilder(testtypes.TypeInterfaceA).
+				Factory(func(context.Context, []any) (any, error) {
+					var a testtypes.InterfaceA
+					return a, nil
+				}).
+				Build(),
+		)
+		require.NoError(t, err)
+
+		a, err := di.Resolve[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Nil(t, a)
+	})
+
+	t.Run("factory error", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.NewServiceBuilder(testtypes.TypeInterfaceA).
+				Factory(func(context.Context, []any) (any, error) {
+					return nil, errors.New("factory error")
+				}).
+				Build(),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve testtypes.InterfaceA: service di.ServiceBuilder testtypes.InterfaceA: factory error")
+	})
+
+	t.Run("factory wrong type", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.NewServiceBuilder(testtypes.TypeInterfaceA).
+				Factory(func(context.Context, []any) (any, error) {
+					return testtypes.StructB{}, nil
+				}).
+				Build(),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve testtypes.InterfaceA: service di.ServiceBuilder testtypes.InterfaceA: factory returned testtypes.StructB, expected testtypes.InterfaceA")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.NewServiceBuilder(reflect.TypeFor[int]()).
+				Dependency(reflect.TypeFor[string](), nil).
+				Dependency(nil, nil).
+				Build(),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: ServiceBuilder int: "+
+			"invalid service type; use a named type or a pointer to a named type\n"+
+			"dependency 0: invalid dependency type string; use a named type or a pointer to a named type\n"+
+			"dependency 1: type is nil\n"+
+			"factory is nil")
+	})
+
+	t.Run("nil type", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.NewServiceBuilder(nil).Build(),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: ServiceBuilder: type is nil")
+	})
+
+	t.Run("invalid option", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.NewServiceBuilder(testtypes.TypeInterfaceA).
+				Factory(func(context.Context, []any) (any, error) {
+					return testtypes.StructA{}, nil
+				}).
+				Options(di.As[testtypes.InterfaceB]()).
+				Build(),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: ServiceBuilder testtypes.InterfaceA: "+
+			"As testtypes.InterfaceB: type testtypes.InterfaceA not assignable to testtypes.InterfaceB")
+	})
+}
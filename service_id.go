@@ -0,0 +1,94 @@
+package di
+
+import (
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"runtime"
+)
+
+// newServiceID returns a deterministic ID for a service registered with key.
+//
+// The ID is a hash of the service key, the module the service is registered with (see NamedModule),
+// the depth of the scope the service is registered with, and the index of the registration for the key.
+// This is stable across process restarts as long as services are registered in the same order.
+// The constructor function and the name set with WithName are not included,
+// since the names of function literals change when unrelated code is moved.
+func newServiceID(s *service, key serviceKey, depth, index int) string {
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%s\x00%v\x00%s\x00%d\x00%d", key.Type, key.Tag, s.module, depth, index)
+
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// Name returns the name of the service, which describes it in errors, events, and the Manifest.
+// This is the name set with WithName, or by a ServiceBuilder, if any.
+// Otherwise it's the constructor function name for function services, or the type for value services.
+func (s *service) Name() string {
+	if s.name != "" {
+		return s.name
+	}
+	if s.IsValue() {
+		return s.v.Type().String()
+	}
+
+	if fn := runtime.FuncForPC(s.v.Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return s.String()
+}
+
+// registration is where a service is registered for a key.
+type registration struct {
+	ID    string
+	Depth int
+	Index int
+}
+
+// ID returns the deterministic ID of the service registered with key.
+func (s *service) ID(key serviceKey) string {
+	return s.regs[key].ID
+}
+
+// Info returns the [ServiceInfo] for the service registered with key.
+func (s *service) Info(key serviceKey) ServiceInfo {
+	reg := s.regs[key]
+
+	return ServiceInfo{
+		Type:     key.Type,
+		Tag:      key.Tag,
+		ID:       reg.ID,
+		Name:     s.Name(),
+		Module:   s.module,
+		Metadata: maps.Clone(s.metadata),
+		Depth:    reg.Depth,
+		Index:    reg.Index,
+	}
+}
+
+// depth returns the number of parents of the Container.
+func (c *Container) depth() int {
+	depth := 0
+	for scope := c.parent; scope != nil; scope = scope.parent {
+		depth++
+	}
+
+	return depth
+}
+
+// serviceInfo returns the [ServiceInfo] for the key, including the ID of the registered service, if any.
+func (c *Container) serviceInfo(key serviceKey) ServiceInfo {
+	info := ServiceInfo{
+		Type: key.Type,
+		Tag:  key.Tag,
+	}
+
+	if isUnnamedSliceType(key.Type) || isStringMapType(key.Type) {
+		return info
+	}
+	if svc := c.lookupService(key); svc != nil {
+		return svc.Info(key)
+	}
+
+	return info
+}
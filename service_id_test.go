@@ -0,0 +1,115 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// registeredIDs returns the IDs of the services registered with the container, by service string.
+func registeredIDs(t *testing.T, opts ...di.ContainerOption) map[string][]string {
+	t.Helper()
+
+	rec := &eventRecorder{}
+	_, err := di.NewContainer(append(opts, di.WithEventHandler(rec.Handler()))...)
+	require.NoError(t, err)
+
+	ids := make(map[string][]string)
+	for i := range rec.events {
+		if e := &rec.events[i]; e.Kind == di.Registered {
+			ids[e.Service.String()] = append(ids[e.Service.String()], e.Service.ID)
+		}
+	}
+
+	return ids
+}
+
+func Test_ServiceID(t *testing.T) {
+	t.Run("stable", func(t *testing.T) {
+		opts := func() []di.ContainerOption {
+			return []di.ContainerOption{
+				di.WithService(testtypes.NewInterfaceA),
+				di.WithService(testtypes.NewInterfaceB, di.WithTag("tag")),
+			}
+		}
+
+		ids1 := registeredIDs(t, opts()...)
+		ids2 := registeredIDs(t, opts()...)
+
+		assert.Equal(t, ids1, ids2)
+		assert.NotEmpty(t, ids1["testtypes.InterfaceA"][0])
+		assert.NotEmpty(t, ids1["testtypes.InterfaceB: WithTag tag"][0])
+	})
+
+	t.Run("different tags", func(t *testing.T) {
+		ids := registeredIDs(t,
+			di.WithService(testtypes.NewInterfaceA, di.WithTag(1), di.WithTag(2)),
+		)
+
+		assert.NotEqual(t, ids["testtypes.InterfaceA: WithTag 1"], ids["testtypes.InterfaceA: WithTag 2"])
+	})
+
+	t.Run("same constructor registered twice", func(t *testing.T) {
+		ids := registeredIDs(t,
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(testtypes.NewInterfaceA),
+		)
+
+		require.Len(t, ids["testtypes.InterfaceA"], 2)
+		assert.NotEqual(t, ids["testtypes.InterfaceA"][0], ids["testtypes.InterfaceA"][1])
+	})
+
+	t.Run("resolve error", func(t *testing.T) {
+		ids := registeredIDs(t,
+			di.WithService(func() (testtypes.InterfaceA, error) {
+				return nil, errors.New("error A")
+			}),
+		)
+
+		c, err := di.NewContainer(
+			di.WithService(func() (testtypes.InterfaceA, error) {
+				return nil, errors.New("error A")
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceA](context.Background(), c)
+
+		var resErr *di.ResolveError
+		require.ErrorAs(t, err, &resErr)
+		assert.NotEmpty(t, resErr.Service.ID)
+		assert.Equal(t, ids["testtypes.InterfaceA"][0], resErr.Service.ID)
+	})
+
+	t.Run("closures reordered", func(t *testing.T) {
+		newA := func() testtypes.InterfaceA { return &testtypes.StructA{} }
+		newB := func() testtypes.InterfaceB { return &testtypes.StructB{} }
+
+		ids1 := registeredIDs(t, di.WithService(newA), di.WithService(newB))
+		ids2 := registeredIDs(t,
+			di.WithService(func() testtypes.InterfaceA { return &testtypes.StructA{} }),
+			di.WithService(newB),
+		)
+
+		assert.Equal(t, ids1, ids2)
+	})
+
+	t.Run("WithName", func(t *testing.T) {
+		ids1 := registeredIDs(t, di.WithService(testtypes.NewInterfaceA))
+		ids2 := registeredIDs(t, di.WithService(testtypes.NewInterfaceA, di.WithName("a")))
+
+		assert.Equal(t, ids1["testtypes.InterfaceA"], ids2["testtypes.InterfaceA"])
+	})
+
+	t.Run("different modules", func(t *testing.T) {
+		ids1 := registeredIDs(t, di.NamedModule("a", di.WithService(testtypes.NewInterfaceA)))
+		ids2 := registeredIDs(t, di.NamedModule("b", di.WithService(testtypes.NewInterfaceA)))
+
+		assert.NotEqual(t, ids1["testtypes.InterfaceA"], ids2["testtypes.InterfaceA"])
+	})
+}
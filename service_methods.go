@@ -0,0 +1,159 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// WithServiceMethods registers each exported method of a factory as a constructor function
+// when calling [NewContainer] or [Container.NewScope].
+//
+// This avoids calling [WithService] for each method of a provider struct.
+// If factory is a value, each method is registered like [WithService] with the factory as the receiver,
+// so all of the services share the same factory.
+// If factory is a constructor function, it is registered as a Singleton service for the receiver,
+// and each method of the returned type resolves the receiver from the [Container] before it's called.
+//
+// Methods are not registered if they return no values, or only an error, like a Close method,
+// or if the first return type is not a valid service type, like a String method.
+// Use [WithMethodFilter] to choose which methods are registered.
+//
+// The other options are applied to each method.
+//
+// Example:
+//
+//	type Providers struct {
+//		Config *Config
+//	}
+//
+//	func (p *Providers) NewDB(ctx context.Context) (*sql.DB, error) { ... }
+//	func (p *Providers) NewStore(db *sql.DB) storage.Store { ... }
+//
+//	c, err := di.NewContainer(
+//		di.WithServiceMethods(&Providers{Config: cfg}),
+//	)
+//
+// This option will return an error if the factory is nil, has no methods to register,
+// or a method is not a valid constructor function.
+func WithServiceMethods(factory any, opts ...ServiceOption) ContainerOption {
+	return containerOption(func(c *Container) error {
+		v := reflect.ValueOf(factory)
+		if isNil(v) {
+			return errors.New("WithServiceMethods: factory is nil")
+		}
+
+		var filters []methodFilter
+		var methodOpts []ServiceOption
+		for _, opt := range opts {
+			if f, ok := opt.(methodFilter); ok {
+				if f == nil {
+					return errors.Errorf("WithServiceMethods %T: WithMethodFilter: f is nil", factory)
+				}
+				filters = append(filters, f)
+				continue
+			}
+			methodOpts = append(methodOpts, opt)
+		}
+
+		methods := v
+		receiverType := v.Type()
+		if v.Kind() == reflect.Func {
+			if v.Type().NumOut() == 0 {
+				return errors.Errorf("WithServiceMethods %T: constructor function has no return values", factory)
+			}
+
+			receiverType = v.Type().Out(0)
+			if receiverType.Kind() == reflect.Interface {
+				return errors.Errorf("WithServiceMethods %T: receiver type %s is an interface", factory, receiverType)
+			}
+
+			s, err := newService(c, v, false)
+			if err != nil {
+				return errors.Wrapf(err, "WithServiceMethods %T", factory)
+			}
+			if err := c.registerWithOut(s); err != nil {
+				return errors.Wrapf(err, "WithServiceMethods %T", factory)
+			}
+			methods = reflect.Value{}
+		}
+
+		var errs []error
+		registered := 0
+		for i := range receiverType.NumMethod() {
+			method := receiverType.Method(i)
+			if !isConstructorMethod(method.Type) || !includeMethod(filters, method.Name) {
+				continue
+			}
+
+			// A method expression takes the receiver as its first parameter,
+			// so the receiver is resolved like any other dependency.
+			fn := method.Func
+			if methods.IsValid() {
+				fn = methods.Method(i)
+			}
+
+			s, err := newService(c, fn, false, methodOpts...)
+			if err != nil {
+				errs = append(errs, errors.Wrapf(err, "method %s", method.Name))
+				continue
+			}
+
+			s.name = fmt.Sprintf("(%s).%s", receiverType, method.Name)
+			if err := c.registerWithOut(s); err != nil {
+				errs = append(errs, errors.Wrapf(err, "method %s", method.Name))
+				continue
+			}
+			registered++
+		}
+
+		if len(errs) == 0 && registered == 0 {
+			return errors.Errorf("WithServiceMethods %T: no methods found", factory)
+		}
+		return errors.Wrapf(errors.Join(errs...), "WithServiceMethods %T", factory)
+	})
+}
+
+// WithMethodFilter configures [WithServiceMethods] to only register methods where f returns true
+// for the method name.
+//
+// Use this option to skip getters or other methods that return a value, but should not be services.
+// If this option is used more than once, a method must match all of the filters.
+//
+// Example:
+//
+//	di.WithServiceMethods(providers,
+//		di.WithMethodFilter(func(name string) bool {
+//			return strings.HasPrefix(name, "New")
+//		}),
+//	)
+//
+// This option will return an error if f is nil, or if it is used with any other option than [WithServiceMethods].
+func WithMethodFilter(f func(name string) bool) ServiceOption {
+	return methodFilter(f)
+}
+
+type methodFilter func(name string) bool
+
+func (f methodFilter) applyService(*service) error {
+	if f == nil {
+		return errors.New("WithMethodFilter: f is nil")
+	}
+	return errors.New("WithMethodFilter: only supported by WithServiceMethods")
+}
+
+// includeMethod returns true if the method name matches all of the filters.
+func includeMethod(filters []methodFilter, name string) bool {
+	for _, f := range filters {
+		if !f(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// isConstructorMethod returns true if the method returns a valid service type other than an error.
+func isConstructorMethod(t reflect.Type) bool {
+	return t.NumOut() > 0 && t.Out(0) != typeError && validateServiceType(t.Out(0))
+}
@@ -0,0 +1,199 @@
+package di_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type providers struct {
+	tag    string
+	closed bool
+}
+
+func (p *providers) NewStructA() *testtypes.StructA {
+	return &testtypes.StructA{Tag: p.tag}
+}
+
+func (p *providers) NewStructB(*testtypes.StructA) (*testtypes.StructB, error) {
+	return &testtypes.StructB{}, nil
+}
+
+func (p *providers) Close() error {
+	p.closed = true
+	return nil
+}
+
+func (p *providers) String() string {
+	return p.tag
+}
+
+func newProviders() *providers {
+	return &providers{tag: "resolved"}
+}
+
+type badProviders struct{}
+
+func (badProviders) NewContext() context.Context {
+	return context.Background()
+}
+
+func (badProviders) NewStructA(func()) *testtypes.StructA {
+	return &testtypes.StructA{}
+}
+
+func Test_WithServiceMethods(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("registers methods", func(t *testing.T) {
+		p := &providers{tag: "p"}
+		c, err := di.NewContainer(
+			di.WithServiceMethods(p),
+			di.WithDependencyValidation(),
+		)
+		require.NoError(t, err)
+
+		a, err := di.Resolve[*testtypes.StructA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, &testtypes.StructA{Tag: "p"}, a)
+
+		_, err = di.Resolve[*testtypes.StructB](ctx, c)
+		require.NoError(t, err)
+
+		// Close is not registered
+		err = c.Close(ctx)
+		require.NoError(t, err)
+		assert.False(t, p.closed)
+	})
+
+	t.Run("shared factory", func(t *testing.T) {
+		f := &testtypes.Factory{}
+		c, err := di.NewContainer(
+			di.WithServiceMethods(f, di.Transient),
+		)
+		require.NoError(t, err)
+
+		a, err := di.Resolve[*testtypes.StructA](ctx, c)
+		require.NoError(t, err)
+		i, err := di.Resolve[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+
+		assert.Equal(t, &testtypes.StructA{Tag: 0}, a)
+		assert.Equal(t, &testtypes.StructA{Tag: 1}, i)
+	})
+
+	t.Run("receiver constructor", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithServiceMethods(newProviders),
+			di.WithDependencyValidation(),
+		)
+		require.NoError(t, err)
+
+		p, err := di.Resolve[*providers](ctx, c)
+		require.NoError(t, err)
+
+		a, err := di.Resolve[*testtypes.StructA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, &testtypes.StructA{Tag: "resolved"}, a)
+
+		// The receiver is a Singleton closed by the Container
+		err = c.Close(ctx)
+		require.NoError(t, err)
+		assert.True(t, p.closed)
+	})
+
+	t.Run("method filter", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithServiceMethods(&providers{},
+				di.WithMethodFilter(func(name string) bool {
+					return name != "NewStructB"
+				}),
+			),
+		)
+		require.NoError(t, err)
+
+		assert.True(t, c.Contains(testtypes.TypeStructAPtr))
+		assert.False(t, c.Contains(reflect.TypeFor[*testtypes.StructB]()))
+	})
+
+	t.Run("method filter nil", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithServiceMethods(&providers{}, di.WithMethodFilter(nil)),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithServiceMethods *di_test.providers: WithMethodFilter: f is nil")
+	})
+
+	t.Run("method filter with service", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewStructAPtr,
+				di.WithMethodFilter(func(string) bool { return true }),
+			),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func() *testtypes.StructA: "+
+			"WithMethodFilter: only supported by WithServiceMethods")
+	})
+
+	t.Run("interface receiver", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithServiceMethods(testtypes.NewInterfaceA),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithServiceMethods func() testtypes.InterfaceA: "+
+			"receiver type testtypes.InterfaceA is an interface")
+	})
+
+	t.Run("manifest name", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithServiceMethods(&providers{}),
+		)
+		require.NoError(t, err)
+
+		var names []string
+		for _, s := range c.Manifest().Services {
+			if s.Type == "*testtypes.StructA" {
+				names = append(names, s.Constructor)
+			}
+		}
+		assert.Equal(t, []string{"(*di_test.providers).NewStructA"}, names)
+	})
+
+	t.Run("factory nil", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithServiceMethods(nil),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithServiceMethods: factory is nil")
+	})
+
+	t.Run("no methods", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithServiceMethods(providers{}),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithServiceMethods di_test.providers: no methods found")
+	})
+
+	t.Run("invalid method", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithServiceMethods(badProviders{}),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithServiceMethods di_test.badProviders: "+
+			"method NewStructA: parameter 0: invalid dependency type func(); "+
+			"use a named type or a pointer to a named type")
+	})
+}
@@ -0,0 +1,48 @@
+package di
+
+import "reflect"
+
+// WithSliceDedup removes duplicate instances when resolving a slice of services,
+// when calling [NewContainer] or [Container.NewScope].
+//
+// By default, a slice includes one element for each registered service, even if several of them
+// resolve to the same instance, like a value registered under several types or a service
+// that returns another service. With this option, an instance is only included the first time
+// it is resolved, so consumers like middleware chains don't run the same component twice.
+//
+// Instances are compared with ==, so pointers are compared by identity.
+// Nil values and values that are not comparable, like functions, are never removed.
+// This applies to slice dependencies, [ResolveAll], and resolving a slice type directly.
+//
+// Child scopes inherit this option from the parent Container.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithService(auth, di.As[Middleware](), di.As[AdminMiddleware]()),
+//		di.WithService(func(m AdminMiddleware) Middleware { return m }),
+//		di.WithSliceDedup(), // []Middleware includes auth once
+//	)
+func WithSliceDedup() ContainerOption {
+	return containerOption(func(c *Container) error {
+		c.sliceDedup = true
+		return nil
+	})
+}
+
+// sliceDedup tracks the instances already added to a slice.
+type sliceDedup map[any]struct{}
+
+// Seen returns true if the instance was already added, and marks it as added otherwise.
+func (d sliceDedup) Seen(val any) bool {
+	if d == nil || val == nil || !reflect.ValueOf(val).Comparable() {
+		return false
+	}
+
+	if _, ok := d[val]; ok {
+		return true
+	}
+
+	d[val] = struct{}{}
+	return false
+}
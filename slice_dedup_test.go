@@ -0,0 +1,107 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithSliceDedup(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("same value registered twice", func(t *testing.T) {
+		a := &testtypes.StructA{Tag: "a"}
+		c, err := di.NewContainer(
+			di.WithService(a, di.As[testtypes.InterfaceA]()),
+			di.WithService(a, di.As[testtypes.InterfaceA]()),
+			di.WithService(&testtypes.StructA{Tag: "b"}, di.As[testtypes.InterfaceA]()),
+			di.WithSliceDedup(),
+		)
+		require.NoError(t, err)
+
+		got, err := di.ResolveAll[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, []testtypes.InterfaceA{a, &testtypes.StructA{Tag: "b"}}, got)
+	})
+
+	t.Run("service returns another service", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() *testtypes.StructA {
+				return &testtypes.StructA{Tag: "a"}
+			}, di.As[*testtypes.StructA](), di.As[testtypes.InterfaceA]()),
+			di.WithService(func(a *testtypes.StructA) testtypes.InterfaceA { return a }),
+			di.WithSliceDedup(),
+		)
+		require.NoError(t, err)
+
+		var got []testtypes.InterfaceA
+		err = di.Invoke(ctx, c, func(as []testtypes.InterfaceA) {
+			got = as
+		})
+		require.NoError(t, err)
+		assert.Len(t, got, 1)
+	})
+
+	t.Run("without option", func(t *testing.T) {
+		a := &testtypes.StructA{}
+		c, err := di.NewContainer(
+			di.WithService(a, di.As[testtypes.InterfaceA]()),
+			di.WithService(a, di.As[testtypes.InterfaceA]()),
+		)
+		require.NoError(t, err)
+
+		got, err := di.ResolveAll[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Len(t, got, 2)
+	})
+
+	t.Run("values compared with ==", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.StructA{Tag: "a"}, di.As[testtypes.InterfaceA]()),
+			di.WithService(testtypes.StructA{Tag: "a"}, di.As[testtypes.InterfaceA]()),
+			di.WithService(testtypes.StructA{Tag: "b"}, di.As[testtypes.InterfaceA]()),
+			di.WithSliceDedup(),
+		)
+		require.NoError(t, err)
+
+		got, err := di.ResolveAll[testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, []testtypes.InterfaceA{testtypes.StructA{Tag: "a"}, testtypes.StructA{Tag: "b"}}, got)
+	})
+
+	t.Run("not comparable", func(t *testing.T) {
+		mw := testtypes.NewMiddleware()
+		c, err := di.NewContainer(
+			di.WithDeclaredService(mw),
+			di.WithDeclaredService(mw),
+			di.WithSliceDedup(),
+		)
+		require.NoError(t, err)
+
+		got, err := di.ResolveAll[testtypes.HTTPMiddleware](ctx, c)
+		require.NoError(t, err)
+		assert.Len(t, got, 2)
+	})
+
+	t.Run("inherited by scope", func(t *testing.T) {
+		a := &testtypes.StructA{}
+		c, err := di.NewContainer(
+			di.WithService(a, di.As[testtypes.InterfaceA]()),
+			di.WithSliceDedup(),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope(
+			di.WithService(a, di.As[testtypes.InterfaceA]()),
+		)
+		require.NoError(t, err)
+
+		got, err := di.ResolveAll[testtypes.InterfaceA](ctx, scope)
+		require.NoError(t, err)
+		assert.Equal(t, []testtypes.InterfaceA{a}, got)
+	})
+}
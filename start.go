@@ -0,0 +1,62 @@
+package di
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// UseStartFunc configures a function to call after the constructor function creates the service.
+//
+// This is useful for services that need to be started before they are used, like a test container
+// or a background worker. The context passed to Resolve is passed to the function.
+//
+// If the function returns an error, the instance is closed, and the error is returned as if the
+// constructor function returned it. Errors from closing the instance are joined with the error.
+//
+// Example:
+//
+//	di.UseStartFunc(func(ctx context.Context, w *worker.Worker) error {
+//		return w.Start(ctx)
+//	})
+//
+// The option can be used more than once. The functions are called in the order they are provided.
+//
+// This option will return an error if the service type is not assignable to type *Service*,
+// or the service is a value service.
+func UseStartFunc[Service any](f func(context.Context, Service) error) ServiceOption {
+	return serviceOption(func(s *service) error {
+		if !s.Type().AssignableTo(reflect.TypeFor[Service]()) {
+			return errors.Errorf("UseStartFunc: service type %s is not assignable to %s",
+				s.Type(), reflect.TypeFor[Service]())
+		}
+		if s.IsValue() {
+			return errors.New("UseStartFunc: not supported for value service")
+		}
+
+		s.startFuncs = append(s.startFuncs, func(ctx context.Context, val any) error {
+			svc, _ := val.(Service)
+			return f(ctx, svc)
+		})
+		return nil
+	})
+}
+
+// start calls the start functions for an instance of the service.
+// If one returns an error, the instance is closed.
+func (s *service) start(ctx context.Context, val any, cleanup func()) error {
+	for _, f := range s.startFuncs {
+		err := f(ctx, val)
+		if err == nil {
+			continue
+		}
+
+		if closer := s.CloserFor(val, cleanup); closer != nil {
+			err = errors.Join(err, closer.Close(ctx))
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_UseStartFunc(t *testing.T) {
+	t.Run("called in order", func(t *testing.T) {
+		var calls []string
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA,
+				di.UseStartFunc(func(ctx context.Context, a testtypes.InterfaceA) error {
+					assert.Equal(t, "value", testutils.TestValue(ctx))
+					assert.NotNil(t, a)
+					calls = append(calls, "first")
+					return nil
+				}),
+				di.UseStartFunc(func(context.Context, any) error {
+					calls = append(calls, "second")
+					return nil
+				}),
+			),
+		)
+		require.NoError(t, err)
+
+		ctx := testutils.ContextWithTestValue(context.Background(), "value")
+		_ = di.MustResolve[testtypes.InterfaceA](ctx, c)
+		_ = di.MustResolve[testtypes.InterfaceA](ctx, c)
+
+		assert.Equal(t, []string{"first", "second"}, calls)
+	})
+
+	t.Run("error closes instance", func(t *testing.T) {
+		closed := 0
+		cleanups := 0
+		c, err := di.NewContainer(
+			di.WithService(func() (*testtypes.StructA, func(), error) {
+				return &testtypes.StructA{}, func() { cleanups++ }, nil
+			},
+				di.UseCloseFunc(func(context.Context, *testtypes.StructA) error {
+					closed++
+					return errors.New("close error")
+				}),
+				di.UseStartFunc(func(context.Context, *testtypes.StructA) error {
+					return errors.New("start error")
+				}),
+			),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		got, err := di.Resolve[*testtypes.StructA](ctx, c)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, got)
+		assert.EqualError(t, err, "di.Container.Resolve *testtypes.StructA: start error\nclose error")
+		assert.Equal(t, 1, closed)
+		assert.Equal(t, 1, cleanups)
+
+		// The instance is not closed again with the Container
+		err = c.Close(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, closed)
+	})
+
+	t.Run("not assignable", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA,
+				di.UseStartFunc(func(context.Context, testtypes.InterfaceB) error { return nil }),
+			),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func() testtypes.InterfaceA: "+
+			"UseStartFunc: service type testtypes.InterfaceA is not assignable to testtypes.InterfaceB")
+	})
+
+	t.Run("value service", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(&testtypes.StructA{},
+				di.UseStartFunc(func(context.Context, *testtypes.StructA) error { return nil }),
+			),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService *testtypes.StructA: "+
+			"UseStartFunc: not supported for value service")
+	})
+}
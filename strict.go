@@ -0,0 +1,134 @@
+package di
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// WithStrictResolve makes resolving a single service an error when multiple services are registered
+// for the type and tag, when calling [NewContainer] or [Container.NewScope].
+//
+// By default, the last service registered is resolved, unless one is registered with [WithPrimary].
+// With this option, use [ResolveLast] to resolve the last service registered or [ResolveAll] to resolve all of them.
+// This applies to dependencies of services too, since they are also resolved as a single service.
+// The error lists each registration with its index and constructor function or value type,
+// and marks the last one, which would be resolved without this option.
+//
+// Registering a service with a child scope for the same type as a parent Container is not ambiguous.
+// Overriding the built-in [Clock] and [Rand] services is not ambiguous either.
+//
+// Child scopes inherit this option from the parent Container.
+func WithStrictResolve() ContainerOption {
+	return containerOption(func(c *Container) error {
+		c.strictResolve = true
+		return nil
+	})
+}
+
+// ResolveLast resolves the last service registered as type *Service*.
+//
+// This is the same as [Resolve], except [WithStrictResolve] does not apply.
+// With [DuplicateFirstWins], the first service registered is resolved instead.
+// If the Scope does not support this, it falls back to [Scope.Resolve].
+//
+// See [Container.Resolve] for more information.
+func ResolveLast[Service any](ctx context.Context, s Scope, opts ...ResolveOption) (Service, error) {
+	t := reflect.TypeFor[Service]()
+
+	var anyVal any
+	var err error
+	if lr, ok := s.(lastResolver); ok {
+		anyVal, err = lr.resolveLast(ctx, t, opts)
+	} else {
+		anyVal, err = s.Resolve(ctx, t, opts...)
+	}
+
+	var val Service
+	if anyVal != nil {
+		val = anyVal.(Service)
+	}
+
+	return val, err
+}
+
+// ResolveAll resolves all services registered as type *Service*.
+//
+// This is the same as calling [Resolve] with []*Service*.
+// Services registered with parent scopes are included.
+//
+// See [Container.Resolve] for more information.
+func ResolveAll[Service any](ctx context.Context, s Scope, opts ...ResolveOption) ([]Service, error) {
+	return Resolve[[]Service](ctx, s, opts...)
+}
+
+// lastResolver is implemented by scopes that support [ResolveLast].
+type lastResolver interface {
+	resolveLast(ctx context.Context, t reflect.Type, opts []ResolveOption) (any, error)
+}
+
+var (
+	_ lastResolver = (*Container)(nil)
+	_ lastResolver = (*injectedScope)(nil)
+)
+
+func (c *Container) resolveLast(ctx context.Context, t reflect.Type, opts []ResolveOption) (any, error) {
+	key := c.serviceKeyFor(t, opts)
+
+	if c.requireScope {
+		return nil, newResolveError(c, key, errScopeRequired)
+	}
+
+	return c.resolveWith(ctx, key, true)
+}
+
+func (s *injectedScope) resolveLast(ctx context.Context, t reflect.Type, opts []ResolveOption) (any, error) {
+	if err := s.checkReady(t); err != nil {
+		return nil, err
+	}
+
+	return s.scope.resolveLast(ctx, t, opts)
+}
+
+// checkAmbiguous returns an error if more than one of the services was registered by the user.
+// The error lists each candidate with its registration index, and which one would be resolved by default.
+func (c *Container) checkAmbiguous(svcs []*service) error {
+	if slices.ContainsFunc(svcs, (*service).IsChain) {
+		// The Chain composes the other services
+		return nil
+	}
+	if slices.ContainsFunc(svcs, (*service).IsPrimary) {
+		// The primary service is resolved on purpose
+		return nil
+	}
+
+	resolved := c.lastService(svcs)
+	marker := "last"
+	if c.duplicatePolicy == DuplicateFirstWins {
+		marker = "first"
+	}
+
+	candidates := make([]string, 0, len(svcs))
+	for i, svc := range svcs {
+		if svc.builtin {
+			continue
+		}
+
+		candidate := fmt.Sprintf("#%d %s", i, svc.Name())
+		if svc == resolved {
+			candidate += " (" + marker + ")"
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	if len(candidates) > 1 {
+		return errors.Errorf("%d services registered; use di.ResolveLast or di.ResolveAll; candidates: %s",
+			len(candidates), strings.Join(candidates, ", "))
+	}
+
+	return nil
+}
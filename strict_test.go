@@ -0,0 +1,154 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithStrictResolve(t *testing.T) {
+	a1 := &testtypes.StructA{Tag: 1}
+	a2 := &testtypes.StructA{Tag: 2}
+
+	t.Run("default resolves last", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(a1),
+			di.WithService(a2),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[*testtypes.StructA](context.Background(), c)
+		assert.NoError(t, err)
+		assert.Same(t, a2, got)
+	})
+
+	t.Run("ambiguous", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithStrictResolve(),
+			di.WithService(a1),
+			di.WithService(a2),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[*testtypes.StructA](context.Background(), c)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, got)
+		assert.EqualError(t, err, "di.Container.Resolve *testtypes.StructA: "+
+			"2 services registered; use di.ResolveLast or di.ResolveAll; "+
+			"candidates: #0 *testtypes.StructA, #1 *testtypes.StructA (last)")
+	})
+
+	t.Run("ambiguous dependency", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithStrictResolve(),
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(testtypes.NewInterfaceB),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[testtypes.InterfaceB](context.Background(), c)
+		testutils.LogError(t, err)
+
+		assert.EqualError(t, err, "di.Container.Resolve testtypes.InterfaceB: "+
+			"dependency testtypes.InterfaceA: 2 services registered; use di.ResolveLast or di.ResolveAll; "+
+			"candidates: #0 github.com/sectrean/di-kit/internal/testtypes.NewInterfaceA, "+
+			"#1 github.com/sectrean/di-kit/internal/testtypes.NewInterfaceA (last)")
+	})
+
+	t.Run("ResolveLast", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithStrictResolve(),
+			di.WithService(a1),
+			di.WithService(a2),
+		)
+		require.NoError(t, err)
+
+		got, err := di.ResolveLast[*testtypes.StructA](context.Background(), c)
+		assert.NoError(t, err)
+		assert.Same(t, a2, got)
+	})
+
+	t.Run("ResolveAll", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithStrictResolve(),
+			di.WithService(a1),
+			di.WithService(a2),
+		)
+		require.NoError(t, err)
+
+		got, err := di.ResolveAll[*testtypes.StructA](context.Background(), c)
+		assert.NoError(t, err)
+		assert.Equal(t, []*testtypes.StructA{a1, a2}, got)
+	})
+
+	t.Run("child scope override", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithStrictResolve(),
+			di.WithService(a1),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope(
+			di.WithService(a2),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[*testtypes.StructA](context.Background(), scope)
+		assert.NoError(t, err)
+		assert.Same(t, a2, got)
+	})
+
+	t.Run("child scope inherits", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithStrictResolve(),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope(
+			di.WithService(a1),
+			di.WithService(a2),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*testtypes.StructA](context.Background(), scope)
+		testutils.LogError(t, err)
+
+		assert.EqualError(t, err, "di.Container.Resolve *testtypes.StructA: "+
+			"2 services registered; use di.ResolveLast or di.ResolveAll; "+
+			"candidates: #0 *testtypes.StructA, #1 *testtypes.StructA (last)")
+	})
+
+	t.Run("ambiguous with built-in override", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithStrictResolve(),
+			di.WithService(fixedClock{}, di.As[di.Clock]()),
+			di.WithService(fixedClock{}, di.As[di.Clock]()),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[di.Clock](context.Background(), c)
+		testutils.LogError(t, err)
+
+		assert.EqualError(t, err, "di.Container.Resolve di.Clock: "+
+			"2 services registered; use di.ResolveLast or di.ResolveAll; "+
+			"candidates: #1 di_test.fixedClock, #2 di_test.fixedClock (last)")
+	})
+
+	t.Run("override built-in", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithStrictResolve(),
+			di.WithService(fixedClock{}, di.As[di.Clock]()),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[di.Clock](context.Background(), c)
+		assert.NoError(t, err)
+	})
+}
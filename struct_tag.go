@@ -0,0 +1,71 @@
+package di
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// structTag is a parsed `di` struct tag.
+//
+// The same grammar is used by [WithValuesFrom], [WithStruct], [In], and [Out].
+// The tag is a comma-separated list of options:
+//   - "-" skips the field, and cannot be combined with other options.
+//   - "tag=name" uses [WithTag]("name") for the field.
+//   - "optional" leaves the field as the zero value if the service is not registered.
+//   - "inject" marks a field to be resolved by [WithStruct].
+//
+// Each use of the tag supports "-" and "tag=name". Other options must be allowed.
+type structTag struct {
+	tag      any
+	skip     bool
+	optional bool
+	inject   bool
+}
+
+// structTagOption is a set of struct tag options allowed by parseStructTag,
+// in addition to "-" and "tag=name".
+type structTagOption uint8
+
+const (
+	tagOptional structTagOption = 1 << iota
+	tagInject
+)
+
+// parseStructTag parses the `di` struct tag of field.
+// It returns an error if the tag is invalid, or uses an option that is not allowed.
+func parseStructTag(field *reflect.StructField, allowed structTagOption) (structTag, error) {
+	var st structTag
+
+	tag := field.Tag.Get("di")
+	switch tag {
+	case "":
+		return st, nil
+	case "-":
+		st.skip = true
+		return st, nil
+	}
+
+	for opt := range strings.SplitSeq(tag, ",") {
+		var option structTagOption
+		switch {
+		case opt == "optional" && !st.optional:
+			st.optional = true
+			option = tagOptional
+		case opt == "inject" && !st.inject:
+			st.inject = true
+			option = tagInject
+		case strings.HasPrefix(opt, "tag=") && len(opt) > len("tag=") && st.tag == nil:
+			st.tag = strings.TrimPrefix(opt, "tag=")
+		default:
+			return st, errors.Errorf("invalid di tag %q", tag)
+		}
+
+		if option != 0 && allowed&option == 0 {
+			return st, errors.Errorf("invalid di tag %q: option %q is not supported", tag, opt)
+		}
+	}
+
+	return st, nil
+}
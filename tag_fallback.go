@@ -0,0 +1,41 @@
+package di
+
+// WithTagFallback makes resolving a tagged service fall back to the untagged service of the same type
+// when no service is registered with the tag, when calling [NewContainer] or [Container.NewScope].
+//
+// This applies to [Resolve] with [WithTag], dependencies declared with [WithTagged],
+// [Container.Contains], [Container.Lookup], and [WithDependencyValidation].
+// It allows a tagged registration to override a default, without registering the default for every tag.
+// Slices of tagged services do not fall back.
+//
+// Child scopes inherit this option from the parent Container.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithService(NewHTTPClient),                        // Used for any tag without its own client
+//		di.WithService(NewBillingClient, di.WithTag(billing)), // Used for the billing tag
+//		di.WithService(NewInvoiceService,                      // Gets the billing client
+//			di.WithTagged[*http.Client](billing),
+//		),
+//		di.WithService(NewReportService,                       // Gets the default client
+//			di.WithTagged[*http.Client](reports),
+//		),
+//		di.WithTagFallback(),
+//	)
+func WithTagFallback() ContainerOption {
+	return containerOption(func(c *Container) error {
+		c.tagFallback = true
+		return nil
+	})
+}
+
+// resolvableKey returns the key of the services that would be resolved for key.
+// With [WithTagFallback], this is the untagged key if no services are registered with the tag.
+func (c *Container) resolvableKey(key serviceKey) serviceKey {
+	if key.Tag != nil && c.tagFallback && len(c.lookupServices(key)) == 0 {
+		return serviceKey{Type: key.Type}
+	}
+
+	return key
+}
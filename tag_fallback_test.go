@@ -0,0 +1,105 @@
+package di_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithTagFallback(t *testing.T) {
+	ctx := context.Background()
+
+	newContainer := func(t *testing.T, opts ...di.ContainerOption) *di.Container {
+		c, err := di.NewContainer(append([]di.ContainerOption{
+			di.WithService(testtypes.StructA{Tag: "default"}, di.As[testtypes.InterfaceA]()),
+			di.WithService(testtypes.StructA{Tag: "override"}, di.As[testtypes.InterfaceA](), di.WithTag("override")),
+		}, opts...)...)
+		require.NoError(t, err)
+
+		return c
+	}
+
+	t.Run("Resolve WithTag", func(t *testing.T) {
+		c := newContainer(t, di.WithTagFallback())
+
+		a, err := di.Resolve[testtypes.InterfaceA](ctx, c, di.WithTag("override"))
+		require.NoError(t, err)
+		assert.Equal(t, testtypes.StructA{Tag: "override"}, a)
+
+		a, err = di.Resolve[testtypes.InterfaceA](ctx, c, di.WithTag("missing"))
+		require.NoError(t, err)
+		assert.Equal(t, testtypes.StructA{Tag: "default"}, a)
+
+		assert.True(t, c.Contains(testtypes.TypeInterfaceA, di.WithTag("missing")))
+	})
+
+	t.Run("WithTagged", func(t *testing.T) {
+		c := newContainer(t,
+			di.WithService(func(a testtypes.InterfaceA) *testtypes.StructB {
+				return &testtypes.StructB{}
+			}, di.WithTagged[testtypes.InterfaceA]("missing")),
+			di.WithTagFallback(),
+			di.WithDependencyValidation(),
+		)
+
+		_, err := di.Resolve[*testtypes.StructB](ctx, c)
+		require.NoError(t, err)
+	})
+
+	t.Run("Lookup", func(t *testing.T) {
+		c := newContainer(t, di.WithTagFallback())
+
+		info, ok := c.Lookup(testtypes.TypeInterfaceA, di.WithTag("missing"))
+		require.True(t, ok)
+		assert.Nil(t, info.Tag)
+		assert.Equal(t, 0, info.Index)
+	})
+
+	t.Run("child scope inherits", func(t *testing.T) {
+		c := newContainer(t, di.WithTagFallback())
+
+		scope, err := c.NewScope()
+		require.NoError(t, err)
+
+		a, err := di.Resolve[testtypes.InterfaceA](ctx, scope, di.WithTag("missing"))
+		require.NoError(t, err)
+		assert.Equal(t, testtypes.StructA{Tag: "default"}, a)
+	})
+
+	t.Run("tagged in parent preferred", func(t *testing.T) {
+		c := newContainer(t, di.WithTagFallback())
+
+		scope, err := c.NewScope(
+			di.WithService(testtypes.StructA{Tag: "child default"}, di.As[testtypes.InterfaceA]()),
+		)
+		require.NoError(t, err)
+
+		a, err := di.Resolve[testtypes.InterfaceA](ctx, scope, di.WithTag("override"))
+		require.NoError(t, err)
+		assert.Equal(t, testtypes.StructA{Tag: "override"}, a)
+	})
+
+	t.Run("slice does not fall back", func(t *testing.T) {
+		c := newContainer(t, di.WithTagFallback())
+
+		assert.False(t, c.Contains(reflect.TypeFor[[]testtypes.InterfaceA](), di.WithTag("missing")))
+
+		_, err := di.Resolve[[]testtypes.InterfaceA](ctx, c, di.WithTag("missing"))
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve []testtypes.InterfaceA: WithTag missing: service not registered")
+	})
+
+	t.Run("without option", func(t *testing.T) {
+		c := newContainer(t)
+
+		_, err := di.Resolve[testtypes.InterfaceA](ctx, c, di.WithTag("missing"))
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve testtypes.InterfaceA: WithTag missing: service not registered")
+	})
+}
@@ -0,0 +1,189 @@
+package di
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// ResolveMap resolves all services registered as type *Service* with a tag of type *K*,
+// keyed by their tag.
+//
+// Services registered without a tag, or with a tag of another type, are not included.
+// Use any for *K* to include services with any tag.
+// If multiple services are registered with the same tag, the last one is resolved,
+// the same as calling [Resolve] with [WithTag].
+// Services registered with parent scopes are included, unless a service is registered with
+// the same tag in the child scope.
+//
+// A dependency of type map[string]*Service* is resolved the same way, with the services registered
+// with a string tag. This is useful for registries of strategies, like payment providers,
+// without writing a constructor function to build the map.
+//
+// Example:
+//
+//	dbs, err := di.ResolveMap[dbTag, *sql.DB](ctx, c)
+//	...
+//	primary := dbs[dbPrimary]
+//
+// This will return an error if no services are registered, or if any of the services fail to resolve.
+// If the Scope does not support this, an error is returned.
+func ResolveMap[K comparable, Service any](ctx context.Context, s Scope) (map[K]Service, error) {
+	mapType := reflect.TypeFor[map[K]Service]()
+
+	mr, ok := s.(mapResolver)
+	if !ok {
+		return nil, errors.Errorf("di.ResolveMap %s: not supported by %T", mapType, s)
+	}
+
+	vals, err := mr.resolveMap(ctx, mapType, func(tag any) bool {
+		_, ok := tag.(K)
+		return ok
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[K]Service, len(vals))
+	for tag, val := range vals {
+		var svc Service
+		if val != nil {
+			svc = val.(Service)
+		}
+		m[tag.(K)] = svc
+	}
+
+	return m, nil
+}
+
+// mapResolver is implemented by scopes that support [ResolveMap].
+type mapResolver interface {
+	resolveMap(ctx context.Context, mapType reflect.Type, match func(tag any) bool) (map[any]any, error)
+}
+
+var (
+	_ mapResolver = (*Container)(nil)
+	_ mapResolver = (*injectedScope)(nil)
+)
+
+func (c *Container) resolveMap(
+	ctx context.Context,
+	mapType reflect.Type,
+	match func(tag any) bool,
+) (map[any]any, error) {
+	mapKey := serviceKey{Type: mapType}
+
+	if c.requireScope {
+		return nil, newResolveError(c, mapKey, errScopeRequired)
+	}
+
+	// Don't wait for the Container to finish closing
+	if c.closing.Load() {
+		return nil, newResolveError(c, mapKey, ErrContainerClosing)
+	}
+
+	c.closedMu.RLock()
+	defer c.closedMu.RUnlock()
+
+	if c.closed {
+		return nil, newResolveError(c, mapKey, errContainerClosed)
+	}
+
+	keys := c.taggedKeys(mapType.Elem(), match)
+	if len(keys) == 0 {
+		return nil, newResolveError(c, mapKey, errServiceNotRegistered)
+	}
+
+	vals := make(map[any]any, len(keys))
+	visitor := make(resolveVisitor)
+	for _, key := range keys {
+		val, err := resolveKey(ctx, c, key, visitor, false)
+		if err != nil {
+			return nil, newResolveError(c, mapKey, err)
+		}
+
+		vals[key.Tag] = val
+	}
+
+	return vals, nil
+}
+
+func (s *injectedScope) resolveMap(
+	ctx context.Context,
+	mapType reflect.Type,
+	match func(tag any) bool,
+) (map[any]any, error) {
+	if err := s.checkReady(mapType); err != nil {
+		return nil, err
+	}
+
+	return s.scope.resolveMap(ctx, mapType, match)
+}
+
+// taggedKeys returns the keys of services registered as type t with a tag that matches,
+// in registration order, starting with the current scope.
+func (c *Container) taggedKeys(t reflect.Type, match func(tag any) bool) []serviceKey {
+	var keys []serviceKey
+	seen := make(map[serviceKey]struct{})
+
+	for scope := c; scope != nil; scope = scope.parent {
+		for _, svc := range scope.registered {
+			for _, key := range svc.Keys() {
+				if key.Type != t || key.Tag == nil || !match(key.Tag) || !c.visible(scope, key) {
+					continue
+				}
+				if _, ok := seen[key]; ok {
+					continue
+				}
+
+				seen[key] = struct{}{}
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	return keys
+}
+
+// isStringMapType returns true if t is an unnamed map type keyed by string, like map[string]T.
+// A dependency of this type is resolved as the services registered with a string tag, keyed by their tag.
+func isStringMapType(t reflect.Type) bool {
+	return t.Kind() == reflect.Map && t.PkgPath() == "" && t.Name() == "" && t.Key() == typeString
+}
+
+func isStringTag(tag any) bool {
+	_, ok := tag.(string)
+	return ok
+}
+
+// stringMapKeys returns the keys of the services resolved for a map type keyed by string.
+func (c *Container) stringMapKeys(mapType reflect.Type) []serviceKey {
+	return c.taggedKeys(mapType.Elem(), isStringTag)
+}
+
+func resolveStringMapKey(
+	ctx context.Context,
+	scope *Container,
+	key serviceKey,
+	visitor resolveVisitor,
+	optional bool,
+) (any, error) {
+	keys := scope.stringMapKeys(key.Type)
+	if len(keys) == 0 && !optional {
+		return nil, errServiceNotRegistered
+	}
+
+	elemType := key.Type.Elem()
+	mapVal := reflect.MakeMapWithSize(key.Type, len(keys))
+	for _, elemKey := range keys {
+		val, err := resolveKey(ctx, scope, elemKey, visitor, false)
+		if err != nil {
+			return nil, err
+		}
+
+		mapVal.SetMapIndex(reflect.ValueOf(elemKey.Tag), safeReflectValue(elemType, val))
+	}
+
+	return mapVal.Interface(), nil
+}
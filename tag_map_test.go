@@ -0,0 +1,268 @@
+package di_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type regionTag string
+
+func Test_ResolveMap(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("keyed by tag", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.StructA{Tag: "us"}, di.As[testtypes.InterfaceA](), di.WithTag(regionTag("us"))),
+			di.WithService(testtypes.StructA{Tag: "eu"}, di.As[testtypes.InterfaceA](), di.WithTag(regionTag("eu"))),
+			di.WithService(testtypes.StructA{Tag: "other"}, di.As[testtypes.InterfaceA](), di.WithTag("other")),
+			di.WithService(testtypes.StructA{}, di.As[testtypes.InterfaceA]()),
+		)
+		require.NoError(t, err)
+
+		m, err := di.ResolveMap[regionTag, testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, map[regionTag]testtypes.InterfaceA{
+			"us": testtypes.StructA{Tag: "us"},
+			"eu": testtypes.StructA{Tag: "eu"},
+		}, m)
+	})
+
+	t.Run("any tag", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.StructA{Tag: "us"}, di.As[testtypes.InterfaceA](), di.WithTag(regionTag("us"))),
+			di.WithService(testtypes.StructA{Tag: "other"}, di.As[testtypes.InterfaceA](), di.WithTag("other")),
+			di.WithService(testtypes.StructA{}, di.As[testtypes.InterfaceA]()),
+		)
+		require.NoError(t, err)
+
+		m, err := di.ResolveMap[any, testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, map[any]testtypes.InterfaceA{
+			regionTag("us"): testtypes.StructA{Tag: "us"},
+			"other":         testtypes.StructA{Tag: "other"},
+		}, m)
+	})
+
+	t.Run("last registered", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.StructA{Tag: "first"}, di.As[testtypes.InterfaceA](), di.WithTag(regionTag("us"))),
+			di.WithService(testtypes.StructA{Tag: "last"}, di.As[testtypes.InterfaceA](), di.WithTag(regionTag("us"))),
+		)
+		require.NoError(t, err)
+
+		m, err := di.ResolveMap[regionTag, testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, map[regionTag]testtypes.InterfaceA{
+			"us": testtypes.StructA{Tag: "last"},
+		}, m)
+	})
+
+	t.Run("child scope", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.StructA{Tag: "parent us"}, di.As[testtypes.InterfaceA](), di.WithTag(regionTag("us"))),
+			di.WithService(testtypes.StructA{Tag: "parent eu"}, di.As[testtypes.InterfaceA](), di.WithTag(regionTag("eu"))),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope(
+			di.WithService(testtypes.StructA{Tag: "child us"}, di.As[testtypes.InterfaceA](), di.WithTag(regionTag("us"))),
+		)
+		require.NoError(t, err)
+
+		m, err := di.ResolveMap[regionTag, testtypes.InterfaceA](ctx, scope)
+		require.NoError(t, err)
+		assert.Equal(t, map[regionTag]testtypes.InterfaceA{
+			"us": testtypes.StructA{Tag: "child us"},
+			"eu": testtypes.StructA{Tag: "parent eu"},
+		}, m)
+	})
+
+	t.Run("not registered", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.StructA{}, di.As[testtypes.InterfaceA]()),
+		)
+		require.NoError(t, err)
+
+		m, err := di.ResolveMap[regionTag, testtypes.InterfaceA](ctx, c)
+		testutils.LogError(t, err)
+		assert.Nil(t, m)
+		assert.EqualError(t, err, "di.Container.Resolve map[di_test.regionTag]testtypes.InterfaceA: "+
+			"service not registered")
+	})
+
+	t.Run("constructor error", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() (testtypes.InterfaceA, error) {
+				return nil, errors.New("constructor error")
+			}, di.WithTag(regionTag("us"))),
+		)
+		require.NoError(t, err)
+
+		m, err := di.ResolveMap[regionTag, testtypes.InterfaceA](ctx, c)
+		testutils.LogError(t, err)
+		assert.Nil(t, m)
+		assert.EqualError(t, err, "di.Container.Resolve map[di_test.regionTag]testtypes.InterfaceA: "+
+			"constructor error")
+	})
+
+	t.Run("injected scope", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.StructA{Tag: "us"}, di.As[testtypes.InterfaceA](), di.WithTag(regionTag("us"))),
+		)
+		require.NoError(t, err)
+
+		var m map[regionTag]testtypes.InterfaceA
+		err = di.Invoke(ctx, c, func(s di.Scope) error {
+			var resolveErr error
+			m, resolveErr = di.ResolveMap[regionTag, testtypes.InterfaceA](ctx, s)
+			return resolveErr
+		})
+		require.NoError(t, err)
+		assert.Len(t, m, 1)
+	})
+}
+
+type providerRegistry struct {
+	providers map[string]testtypes.InterfaceA
+}
+
+func Test_StringMapDependency(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("dependency", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.StructA{Tag: "stripe"}, di.As[testtypes.InterfaceA](), di.WithTag("stripe")),
+			di.WithService(testtypes.StructA{Tag: "paypal"}, di.As[testtypes.InterfaceA](), di.WithTag("paypal")),
+			di.WithService(func(providers map[string]testtypes.InterfaceA) *providerRegistry {
+				return &providerRegistry{providers: providers}
+			}),
+			di.WithDependencyValidation(),
+		)
+		require.NoError(t, err)
+
+		r, err := di.Resolve[*providerRegistry](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]testtypes.InterfaceA{
+			"stripe": testtypes.StructA{Tag: "stripe"},
+			"paypal": testtypes.StructA{Tag: "paypal"},
+		}, r.providers)
+	})
+
+	t.Run("resolve directly", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.StructA{Tag: "stripe"}, di.As[testtypes.InterfaceA](), di.WithTag("stripe")),
+		)
+		require.NoError(t, err)
+
+		m, err := di.Resolve[map[string]testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]testtypes.InterfaceA{
+			"stripe": testtypes.StructA{Tag: "stripe"},
+		}, m)
+		assert.True(t, c.Contains(reflect.TypeFor[map[string]testtypes.InterfaceA]()))
+	})
+
+	t.Run("string tags only", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.StructA{Tag: "stripe"}, di.As[testtypes.InterfaceA](), di.WithTag("stripe")),
+			di.WithService(testtypes.StructA{Tag: "us"}, di.As[testtypes.InterfaceA](), di.WithTag(regionTag("us"))),
+			di.WithService(testtypes.StructA{}, di.As[testtypes.InterfaceA]()),
+		)
+		require.NoError(t, err)
+
+		m, err := di.Resolve[map[string]testtypes.InterfaceA](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]testtypes.InterfaceA{
+			"stripe": testtypes.StructA{Tag: "stripe"},
+		}, m)
+	})
+
+	t.Run("child scope", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.StructA{Tag: "parent stripe"}, di.As[testtypes.InterfaceA](), di.WithTag("stripe")),
+			di.WithService(testtypes.StructA{Tag: "parent paypal"}, di.As[testtypes.InterfaceA](), di.WithTag("paypal")),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope(
+			di.WithService(testtypes.StructA{Tag: "child stripe"}, di.As[testtypes.InterfaceA](), di.WithTag("stripe")),
+		)
+		require.NoError(t, err)
+
+		m, err := di.Resolve[map[string]testtypes.InterfaceA](ctx, scope)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]testtypes.InterfaceA{
+			"stripe": testtypes.StructA{Tag: "child stripe"},
+			"paypal": testtypes.StructA{Tag: "parent paypal"},
+		}, m)
+	})
+
+	t.Run("zero default", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.StructA{}, di.As[testtypes.InterfaceA]()),
+			di.WithService(func(providers map[string]testtypes.InterfaceA) *providerRegistry {
+				return &providerRegistry{providers: providers}
+			}, di.WithZeroDefault[map[string]testtypes.InterfaceA]()),
+		)
+		require.NoError(t, err)
+
+		r, err := di.Resolve[*providerRegistry](ctx, c)
+		require.NoError(t, err)
+		assert.Empty(t, r.providers)
+	})
+
+	t.Run("not registered", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.StructA{}, di.As[testtypes.InterfaceA]()),
+			di.WithService(func(providers map[string]testtypes.InterfaceA) *providerRegistry {
+				return &providerRegistry{providers: providers}
+			}),
+			di.WithDependencyValidation(),
+		)
+		testutils.LogError(t, err)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithDependencyValidation: "+
+			"service func(map[string]testtypes.InterfaceA) *di_test.providerRegistry: "+
+			"dependency map[string]testtypes.InterfaceA: service not registered")
+	})
+
+	t.Run("resolve not registered", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.StructA{}, di.As[testtypes.InterfaceA]()),
+		)
+		require.NoError(t, err)
+
+		m, err := di.Resolve[map[string]testtypes.InterfaceA](ctx, c)
+		testutils.LogError(t, err)
+		assert.Nil(t, m)
+		assert.EqualError(t, err, "di.Container.Resolve map[string]testtypes.InterfaceA: "+
+			"service not registered")
+		assert.False(t, c.Contains(reflect.TypeFor[map[string]testtypes.InterfaceA]()))
+	})
+
+	t.Run("constructor error", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() (testtypes.InterfaceA, error) {
+				return nil, errors.New("constructor error")
+			}, di.WithTag("stripe")),
+			di.WithService(func(providers map[string]testtypes.InterfaceA) *providerRegistry {
+				return &providerRegistry{providers: providers}
+			}),
+		)
+		require.NoError(t, err)
+
+		r, err := di.Resolve[*providerRegistry](ctx, c)
+		testutils.LogError(t, err)
+		assert.Nil(t, r)
+		assert.EqualError(t, err, "di.Container.Resolve *di_test.providerRegistry: "+
+			"dependency map[string]testtypes.InterfaceA: constructor error")
+	})
+}
@@ -22,6 +22,65 @@ func WithTag(tag any) ServiceTagOption {
 	return tagOption{Tag: tag}
 }
 
+// Tag is a tag for services of type *Service*.
+//
+// Use a Tag with [WithTagT] and [WithTaggedT] so the compiler checks that the tag is used
+// with the right service type.
+//
+// Example:
+//
+//	var (
+//		Primary = di.Tag[*DB]{Name: "primary"}
+//		Replica = di.Tag[*DB]{Name: "replica"}
+//	)
+type Tag[Service any] struct {
+	Name string
+}
+
+func (t Tag[Service]) String() string {
+	return t.Name
+}
+
+// WithTagT is used to specify a [Tag] associated with a service of type *Service*.
+//
+// This works like [WithTag], except the tag is tied to the service type.
+// When resolving a service, a Tag for another service type will not match.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithService(db.NewPrimaryDB, di.WithTagT(db.Primary)),
+//		di.WithService(db.NewReplicaDB, di.WithTagT(db.Replica)),
+//		...
+//	)
+//
+//	replica, err := di.Resolve[*db.DB](ctx, c, di.WithTagT(db.Replica))
+//
+// When registering a service, this option will return an error if the service type
+// is not assignable to type *Service*.
+func WithTagT[Service any](tag Tag[Service]) ServiceTagOption {
+	return typedTagOption[Service]{tagOption{Tag: tag}}
+}
+
+// WithTaggedT is used to specify a [Tag] for a service dependency of type *Dependency* when calling
+// [WithService] or [Invoke].
+//
+// This works like [WithTagged], except the dependency type is inferred from the tag.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		// ...
+//		di.WithService(storage.NewReadOnlyStore,
+//			di.WithTaggedT(db.Replica),
+//		),
+//	)
+//
+// This option will return an error if the service does not have a dependency of type *Dependency*.
+func WithTaggedT[Dependency any](tag Tag[Dependency]) DependencyOption {
+	return WithTagged[Dependency](tag)
+}
+
 // WithDefaultTag is used to associate the default tag with a service.
 //
 // This is useful when you register a service with a tag, but you also want the service to
@@ -114,6 +173,21 @@ func (o tagOption) applyServiceKey(key serviceKey) serviceKey {
 
 var _ ServiceTagOption = tagOption{}
 
+type typedTagOption[Service any] struct {
+	tagOption
+}
+
+func (o typedTagOption[Service]) applyService(s *service) error {
+	t := reflect.TypeFor[Service]()
+	if !s.Type().AssignableTo(t) {
+		return errors.Errorf("WithTagT %v: type %s not assignable to %s", o.Tag, s.Type(), t)
+	}
+
+	return o.tagOption.applyService(s)
+}
+
+var _ ServiceTagOption = typedTagOption[any]{}
+
 type dependencyOption func(deps []serviceKey) error
 
 func (o dependencyOption) applyService(s *service) error {
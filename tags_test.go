@@ -0,0 +1,111 @@
+package di_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	tagA1 = di.Tag[*testtypes.StructA]{Name: "a1"}
+	tagA2 = di.Tag[*testtypes.StructA]{Name: "a2"}
+	tagB1 = di.Tag[*testtypes.StructB]{Name: "a1"}
+)
+
+func Test_WithTagT(t *testing.T) {
+	t.Run("resolve", func(t *testing.T) {
+		a1 := &testtypes.StructA{Tag: "a1"}
+		a2 := &testtypes.StructA{Tag: "a2"}
+
+		c, err := di.NewContainer(
+			di.WithService(a1, di.WithTagT(tagA1)),
+			di.WithService(a2, di.WithTagT(tagA2)),
+		)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		got1, err := di.Resolve[*testtypes.StructA](ctx, c, di.WithTagT(tagA1))
+		assert.Same(t, a1, got1)
+		assert.NoError(t, err)
+
+		got2, err := di.Resolve[*testtypes.StructA](ctx, c, di.WithTagT(tagA2))
+		assert.Same(t, a2, got2)
+		assert.NoError(t, err)
+	})
+
+	t.Run("tag with same name for another type", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(&testtypes.StructA{}, di.WithTagT(tagA1)),
+		)
+		require.NoError(t, err)
+
+		assert.False(t, c.Contains(reflect.TypeFor[*testtypes.StructA](), di.WithTag("a1")))
+		assert.False(t, c.Contains(reflect.TypeFor[*testtypes.StructA](), di.WithTagT(tagB1)))
+		assert.True(t, c.Contains(reflect.TypeFor[*testtypes.StructA](), di.WithTagT(tagA1)))
+	})
+
+	t.Run("not registered", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*testtypes.StructA](context.Background(), c, di.WithTagT(tagA1))
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve *testtypes.StructA: WithTag a1: service not registered")
+	})
+
+	t.Run("type not assignable", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(&testtypes.StructB{}, di.WithTagT(tagA1)),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService *testtypes.StructB: "+
+			"WithTagT a1: type *testtypes.StructB not assignable to *testtypes.StructA")
+	})
+}
+
+func Test_WithTaggedT(t *testing.T) {
+	t.Run("WithService", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(&testtypes.StructA{Tag: "a1"}, di.WithTagT(tagA1)),
+			di.WithService(&testtypes.StructA{Tag: "a2"}, di.WithTagT(tagA2)),
+			di.WithService(testtypes.NewStructBPtr, di.WithTaggedT(tagA2)),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*testtypes.StructB](context.Background(), c)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Invoke", func(t *testing.T) {
+		a2 := &testtypes.StructA{Tag: "a2"}
+		c, err := di.NewContainer(
+			di.WithService(&testtypes.StructA{Tag: "a1"}, di.WithTagT(tagA1)),
+			di.WithService(a2, di.WithTagT(tagA2)),
+		)
+		require.NoError(t, err)
+
+		err = di.Invoke(context.Background(), c, func(a *testtypes.StructA) {
+			assert.Same(t, a2, a)
+		}, di.WithTaggedT(tagA2))
+		assert.NoError(t, err)
+	})
+
+	t.Run("parameter not found", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewStructBPtr, di.WithTaggedT(tagB1)),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func(*testtypes.StructA) *testtypes.StructB: "+
+			"WithTagged *testtypes.StructB: parameter not found")
+	})
+}
@@ -0,0 +1,179 @@
+package di
+
+import (
+	"container/list"
+	"maps"
+	"slices"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// Template is used to create many [Container]s with the same options.
+//
+// The options are applied and checked once when the Template is created with [NewTemplate],
+// including [WithDependencyValidation], which is the most expensive part of creating a Container.
+// Each call to [Template.New] copies the registered services to create an independent Container,
+// without applying the options or validating dependencies again.
+// Each Container has its own instances, and its own state for options like [SingletonPer] and [WithCircuitBreaker].
+//
+// Value services are shared by every Container created from the Template,
+// and each Container closes them when it is closed. Use [IgnoreCloser] for value services
+// that should only be closed once.
+//
+// A Template is safe for concurrent use.
+type Template struct {
+	plan *Container
+}
+
+// NewTemplate creates a new [Template] with the provided options.
+//
+// All options supported by [NewContainer] are available.
+// Services are not created and events are not emitted until a Container is created with [Template.New].
+//
+// Example:
+//
+//	tmpl, err := di.NewTemplate(
+//		app.Dependencies,
+//		di.WithDependencyValidation(),
+//	)
+//	...
+//
+//	c, err := tmpl.New()
+//
+// This will return an error if any of the options return an error.
+func NewTemplate(opts ...ContainerOption) (*Template, error) {
+	c := newUnsealedContainer()
+
+	err := c.applyOptions(opts, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "di.NewTemplate")
+	}
+
+	return &Template{
+		plan: c,
+	}, nil
+}
+
+// New creates a new [Container] with the options of the Template.
+//
+// Additional options can be provided, such as services specific to the new Container.
+// They are applied after the options of the Template. If additional options are provided,
+// dependencies are validated again if the Template uses [WithDependencyValidation].
+func (t *Template) New(opts ...ContainerOption) (*Container, error) {
+	c := t.plan.clone()
+
+	if len(opts) > 0 {
+		err := c.applyOptions(opts, true)
+		if err != nil {
+			return nil, errors.Wrap(err, "di.Template.New")
+		}
+	}
+
+	c.seal()
+	c.emitCreated()
+	c.startPrewarm()
+
+	return c, nil
+}
+
+// clone returns a copy of a root Container that has options applied, but has not been sealed.
+// Each service is copied, so the copy has its own instances and caches.
+func (c *Container) clone() *Container {
+	clone := &Container{
+		resolved:           make(map[*service]resolveResult),
+		replaced:           maps.Clone(c.replaced),
+		resolveOpts:        slices.Clip(c.resolveOpts),
+		eventHandlers:      slices.Clip(c.eventHandlers),
+		constructorHooks:   slices.Clip(c.constructorHooks),
+		closerCtx:          c.closerCtx,
+		module:             c.module,
+		modules:            slices.Clip(c.modules),
+		newInstanceStore:   c.newInstanceStore,
+		closeRand:          c.closeRand,
+		validate:           c.validate,
+		requireScope:       c.requireScope,
+		strictResolve:      c.strictResolve,
+		tagFallback:        c.tagFallback,
+		sliceDedup:         c.sliceDedup,
+		constructorDedup:   c.constructorDedup,
+		decoratorsDisabled: c.decoratorsDisabled,
+		inheritFilters:     slices.Clip(c.inheritFilters),
+		inheritFiltered:    c.inheritFiltered,
+		resolvePolicies:    slices.Clip(c.resolvePolicies),
+		experiments:        slices.Clip(c.experiments),
+		optionOrder:        c.optionOrder,
+		duplicatePolicy:    c.duplicatePolicy,
+		warmCache:          c.warmCache,
+	}
+	if c.lockStats != nil {
+		clone.lockStats = &lockStats{}
+	}
+	if c.memoryStats != nil {
+		clone.memoryStats = &memoryStats{sizer: c.memoryStats.sizer}
+	}
+	if c.newInstanceStore != nil {
+		clone.instanceStore = c.newInstanceStore()
+	}
+
+	clones := make(map[*service]*service, len(c.registered))
+	clone.registered = make([]*service, len(c.registered))
+	for i, svc := range c.registered {
+		clones[svc] = svc.clone(clone)
+		clone.registered[i] = clones[svc]
+	}
+
+	clone.services = make(map[serviceKey][]*service, len(c.services))
+	for key, svcs := range c.services {
+		cloned := make([]*service, len(svcs))
+		for i, svc := range svcs {
+			cloned[i] = clones[svc]
+		}
+		clone.services[key] = cloned
+	}
+
+	// Add closers for value services
+	for _, svc := range clone.registered {
+		if svc.IsValue() {
+			if closer := svc.CloserFor(svc.Value(), nil); closer != nil {
+				clone.appendCloser(closer, svc)
+			}
+		}
+	}
+
+	return clone
+}
+
+// clone returns a copy of the service registered with c, without any cached instances.
+func (s *service) clone(c *Container) *service {
+	clone := *s
+	clone.scope = c
+	clone.replacedKeys = maps.Clone(s.replacedKeys)
+
+	if s.breaker != nil {
+		clone.breaker = &circuitBreaker{
+			threshold: s.breaker.threshold,
+			cooldown:  s.breaker.cooldown,
+		}
+	}
+	if s.keyed != nil {
+		clone.keyed = &keyedCache{
+			key:      s.keyed.key,
+			limit:    s.keyed.limit,
+			ttl:      s.keyed.ttl,
+			entries:  make(map[any]*list.Element),
+			inflight: make(map[any]*keyedCall),
+			order:    list.New(),
+		}
+	}
+	if s.constructions != nil {
+		clone.constructions = make(constructionLimit, cap(s.constructions))
+	}
+	if s.prewarm != nil {
+		clone.prewarm = &prewarmPool{
+			ready: make(chan *prewarmed, cap(s.prewarm.ready)),
+			deps:  s.prewarm.deps,
+		}
+	}
+
+	return &clone
+}
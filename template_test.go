@@ -0,0 +1,143 @@
+package di_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Template(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("New", func(t *testing.T) {
+		calls := 0
+		tmpl, err := di.NewTemplate(
+			di.WithService(func() testtypes.InterfaceA {
+				calls++
+				return testtypes.StructA{}
+			}),
+			di.WithService(testtypes.NewInterfaceB),
+			di.WithDependencyValidation(),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, 0, calls)
+
+		c1, err := tmpl.New()
+		require.NoError(t, err)
+		c2, err := tmpl.New()
+		require.NoError(t, err)
+		assert.NotSame(t, c1, c2)
+
+		_ = di.MustResolve[testtypes.InterfaceB](ctx, c1)
+		_ = di.MustResolve[testtypes.InterfaceB](ctx, c2)
+		assert.Equal(t, 2, calls)
+
+		require.NoError(t, c1.Close(ctx))
+		require.NoError(t, c2.Close(ctx))
+	})
+
+	t.Run("New with options", func(t *testing.T) {
+		tmpl, err := di.NewTemplate(
+			di.WithService(testtypes.NewInterfaceB),
+		)
+		require.NoError(t, err)
+
+		c, err := tmpl.New(
+			di.WithService(testtypes.StructA{Tag: "a"}, di.As[testtypes.InterfaceA]()),
+		)
+		require.NoError(t, err)
+
+		a := di.MustResolve[testtypes.InterfaceA](ctx, c)
+		assert.Equal(t, testtypes.StructA{Tag: "a"}, a)
+
+		// Options passed to New are not added to the Template
+		c, err = tmpl.New()
+		require.NoError(t, err)
+		assert.False(t, c.Contains(testtypes.TypeInterfaceA))
+	})
+
+	t.Run("New with options WithDependencyValidation", func(t *testing.T) {
+		tmpl, err := di.NewTemplate(
+			di.WithService(testtypes.StructA{}, di.As[testtypes.InterfaceA]()),
+			di.WithDependencyValidation(),
+		)
+		require.NoError(t, err)
+
+		c, err := tmpl.New(
+			di.WithService(testtypes.NewInterfaceC),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.Template.New: WithDependencyValidation: "+
+			"service func(testtypes.InterfaceA, testtypes.InterfaceB) testtypes.InterfaceC: dependency testtypes.InterfaceB: service not registered")
+	})
+
+	t.Run("options applied once", func(t *testing.T) {
+		conds := 0
+		tmpl, err := di.NewTemplate(
+			di.WithServiceWhen(func(context.Context) bool {
+				conds++
+				return true
+			}, testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+
+		for range 3 {
+			c, err := tmpl.New()
+			require.NoError(t, err)
+			assert.True(t, c.Contains(testtypes.TypeInterfaceA))
+		}
+		assert.Equal(t, 1, conds)
+	})
+
+	t.Run("independent state", func(t *testing.T) {
+		tmpl, err := di.NewTemplate(
+			di.Experimental(di.ExperimentKeyedSingletons),
+			di.WithService(testtypes.NewStructAPtr, di.SingletonPer(testutils.TestValue)),
+		)
+		require.NoError(t, err)
+
+		c1, err := tmpl.New()
+		require.NoError(t, err)
+		c2, err := tmpl.New()
+		require.NoError(t, err)
+
+		valueCtx := testutils.ContextWithTestValue(ctx, "tenant")
+		a1 := di.MustResolve[*testtypes.StructA](valueCtx, c1)
+		a2 := di.MustResolve[*testtypes.StructA](valueCtx, c2)
+		assert.NotSame(t, a1, a2)
+
+		stats, ok := c2.CacheStats(reflect.TypeFor[*testtypes.StructA]())
+		assert.True(t, ok)
+		assert.Equal(t, 1, stats.Size)
+	})
+
+	t.Run("WithDependencyValidation error", func(t *testing.T) {
+		tmpl, err := di.NewTemplate(
+			di.WithService(testtypes.NewInterfaceB),
+			di.WithDependencyValidation(),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, tmpl)
+		assert.EqualError(t, err, "di.NewTemplate: WithDependencyValidation: "+
+			"service func(testtypes.InterfaceA) testtypes.InterfaceB: dependency testtypes.InterfaceA: service not registered")
+	})
+
+	t.Run("option error", func(t *testing.T) {
+		tmpl, err := di.NewTemplate(
+			di.WithService(nil),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, tmpl)
+		assert.EqualError(t, err, "di.NewTemplate: WithService: funcOrValue is nil")
+	})
+}
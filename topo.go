@@ -0,0 +1,170 @@
+package di
+
+import (
+	"strings"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// TopologicalOrder returns the services registered with the [Container] and its parent Containers,
+// sorted so each service comes after the services it depends on.
+//
+// This can be used to create or start services in dependency order, or by external tooling.
+// Reverse the order to close services after the services that depend on them.
+//
+// Each service is included once, described by the first type and tag it is registered with.
+// Services registered with parent Containers come first when there are no dependencies between them.
+// Dependencies that are not registered are ignored. Use [WithDependencyValidation] to check for them.
+//
+// This will return an error that lists the services in a dependency cycle, if one is found.
+func (c *Container) TopologicalOrder() ([]ServiceInfo, error) {
+	scopes := make([]*Container, 0, c.depth()+1)
+	for scope := c; scope != nil; scope = scope.parent {
+		scopes = append(scopes, scope)
+	}
+
+	sorter := newTopoSorter()
+
+	// Start with the root Container
+	for i := len(scopes) - 1; i >= 0; i-- {
+		for _, svc := range scopes[i].registered {
+			if err := sorter.Visit(c, svc); err != nil {
+				return nil, errors.Wrap(err, "di.Container.TopologicalOrder")
+			}
+		}
+	}
+
+	infos := make([]ServiceInfo, len(sorter.order))
+	for i, svc := range sorter.order {
+		infos[i] = svc.Info(svc.Keys()[0])
+	}
+	return infos, nil
+}
+
+type topoState uint8
+
+const (
+	topoUnvisited topoState = iota
+	topoVisiting
+	topoVisited
+)
+
+type topoSorter struct {
+	state map[*service]topoState
+	path  []*service
+	order []*service
+}
+
+func newTopoSorter() *topoSorter {
+	return &topoSorter{
+		state: make(map[*service]topoState),
+	}
+}
+
+// Visit the service resolved from a Container after its dependencies, depth first.
+func (s *topoSorter) Visit(from *Container, svc *service) error {
+	switch s.state[svc] {
+	case topoVisited:
+		return nil
+	case topoVisiting:
+		return s.cycleError(svc)
+	}
+
+	s.state[svc] = topoVisiting
+	s.path = append(s.path, svc)
+
+	from = svc.resolvedFrom(from)
+	for _, depSvc := range from.serviceDependencies(svc) {
+		if err := s.Visit(from, depSvc); err != nil {
+			return err
+		}
+	}
+
+	s.path = s.path[:len(s.path)-1]
+	s.state[svc] = topoVisited
+	s.order = append(s.order, svc)
+
+	return nil
+}
+
+func (s *topoSorter) cycleError(svc *service) error {
+	names := make([]string, 0, len(s.path))
+	for i := len(s.path) - 1; i >= 0; i-- {
+		names = append(names, s.path[i].Keys()[0].String())
+		if s.path[i] == svc {
+			break
+		}
+	}
+
+	// Reverse so the cycle reads in dependency order
+	for i, j := 0, len(names)-1; i < j; i, j = i+1, j-1 {
+		names[i], names[j] = names[j], names[i]
+	}
+	names = append(names, svc.Keys()[0].String())
+
+	return errors.Wrap(errDependencyCycle, strings.Join(names, " -> "))
+}
+
+// resolvedFrom returns the Container the dependencies of the service are resolved from,
+// when the service is resolved from c.
+func (s *service) resolvedFrom(c *Container) *Container {
+	// Singleton dependencies are resolved from the Container the service is registered with
+	if s.Lifetime() == Singleton {
+		return s.Scope()
+	}
+	return c
+}
+
+// serviceDependencies returns the services that would be resolved for the dependencies of svc.
+// Dependencies bound with [WithArgs] are skipped.
+func (c *Container) serviceDependencies(svc *service) []*service {
+	var svcs []*service
+	for i, dep := range svc.Dependencies() {
+		if svc.arg(i).IsValid() {
+			continue
+		}
+		svcs = append(svcs, c.dependencyServices(dep)...)
+	}
+	return svcs
+}
+
+// dependencyServices returns the services that would be resolved for a dependency.
+func (c *Container) dependencyServices(dep serviceKey) []*service {
+	if isInType(dep.Type) {
+		fields, _ := inFields(dep.Type)
+
+		var svcs []*service
+		for _, f := range fields {
+			svcs = append(svcs, c.dependencyServices(f.key)...)
+		}
+		return svcs
+	}
+
+	if elemType, ok := optionalElem(dep.Type); ok {
+		dep.Type = elemType
+	}
+
+	switch {
+	case dep.Type == typeContext, dep.Type == typeScope:
+		return nil
+
+	case isUnnamedSliceType(dep.Type):
+		elemKey := serviceKey{Type: dep.Type.Elem(), Tag: dep.Tag}
+		return c.sliceServices(elemKey)
+
+	case isStringMapType(dep.Type):
+		var svcs []*service
+		for _, key := range c.stringMapKeys(dep.Type) {
+			if svc := c.lookupService(c.resolvableKey(key)); svc != nil {
+				svcs = append(svcs, svc)
+			}
+		}
+		return svcs
+
+	default:
+		if svc := c.lookupService(c.resolvableKey(dep)); svc != nil {
+			return []*service{svc}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,108 @@
+package di_test
+
+import (
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serviceNames returns the names of the services, ignoring the built-in Clock and Rand services.
+func serviceNames(infos []di.ServiceInfo) []string {
+	names := make([]string, 0, len(infos))
+	for _, info := range infos {
+		name := info.String()
+		if name == "di.Clock" || name == "di.Rand" {
+			continue
+		}
+		names = append(names, name)
+	}
+
+	return names
+}
+
+func Test_Container_TopologicalOrder(t *testing.T) {
+	t.Run("dependency order", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceC), // Depends on InterfaceA and InterfaceB
+			di.WithService(testtypes.NewInterfaceB), // Depends on InterfaceA
+			di.WithService(testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+
+		order, err := c.TopologicalOrder()
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"testtypes.InterfaceA",
+			"testtypes.InterfaceB",
+			"testtypes.InterfaceC",
+		}, serviceNames(order))
+	})
+
+	t.Run("child scope", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+
+		scope, err := c.NewScope(
+			di.WithService(testtypes.NewInterfaceC),
+			di.WithService(testtypes.NewInterfaceB),
+		)
+		require.NoError(t, err)
+
+		order, err := scope.TopologicalOrder()
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"testtypes.InterfaceA",
+			"testtypes.InterfaceB",
+			"testtypes.InterfaceC",
+		}, serviceNames(order))
+		assert.Equal(t, 0, order[len(order)-3].Depth)
+		assert.Equal(t, 1, order[len(order)-1].Depth)
+	})
+
+	t.Run("slice and tagged dependencies", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func([]testtypes.InterfaceA, testtypes.InterfaceB) *testtypes.StructC {
+				return &testtypes.StructC{}
+			}, di.WithTagged[testtypes.InterfaceB]("tag")),
+			di.WithService(testtypes.NewInterfaceB, di.WithTag("tag")),
+			di.WithService(testtypes.NewInterfaceA, di.WithTag(1)),
+			di.WithService(testtypes.NewInterfaceA),
+		)
+		require.NoError(t, err)
+
+		order, err := c.TopologicalOrder()
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"testtypes.InterfaceA",
+			"testtypes.InterfaceB: WithTag tag",
+			"*testtypes.StructC",
+			"testtypes.InterfaceA: WithTag 1",
+		}, serviceNames(order))
+	})
+
+	t.Run("dependency cycle", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceB), // Depends on InterfaceA
+			di.WithService(func(testtypes.InterfaceC) testtypes.InterfaceA { return nil }),
+			di.WithService(testtypes.NewInterfaceC), // Depends on InterfaceA and InterfaceB
+		)
+		require.NoError(t, err)
+
+		order, err := c.TopologicalOrder()
+		testutils.LogError(t, err)
+
+		assert.Nil(t, order)
+		assert.EqualError(t, err, "di.Container.TopologicalOrder: "+
+			"testtypes.InterfaceA -> testtypes.InterfaceC -> testtypes.InterfaceA: "+
+			"dependency cycle detected")
+	})
+}
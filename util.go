@@ -11,7 +11,11 @@ import (
 var (
 	typeError   = reflect.TypeFor[error]()
 	typeContext = reflect.TypeFor[context.Context]()
+	typeString  = reflect.TypeFor[string]()
 	typeScope   = reflect.TypeFor[Scope]()
+	typeClock   = reflect.TypeFor[Clock]()
+	typeRand    = reflect.TypeFor[Rand]()
+	typeCleanup = reflect.TypeFor[func()]()
 )
 
 func safeReflectValue(t reflect.Type, val any) reflect.Value {
@@ -61,6 +65,17 @@ func applyOptions[O any](opts []O, f func(O) error) error {
 	return errors.Join(errs...)
 }
 
+// isNilValue returns true if v is nil and cannot be registered as a service.
+// A nil slice or map is a valid empty value for a named slice or map type.
+// A nil channel blocks forever and a nil function panics when called, so they are not allowed.
+func isNilValue(v reflect.Value) bool {
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Map {
+		return false
+	}
+
+	return isNil(v)
+}
+
 func isUnnamedSliceType(t reflect.Type) bool {
 	return t.Kind() == reflect.Slice && t.PkgPath() == "" && t.Name() == ""
 }
@@ -0,0 +1,90 @@
+package di
+
+import (
+	"reflect"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// WithValuesFrom registers each exported field of a struct as a value service when calling
+// [NewContainer] or [Container.NewScope].
+//
+// This is convenient for passing a collection of services that are already created,
+// like a logger, tracer, and config, into the Container.
+//
+// Each field is registered as the declared type of the field, like [WithDeclaredService].
+// Use the `di` struct tag to configure a field:
+//   - `di:"tag=name"` registers the field with [WithTag]("name").
+//   - `di:"-"` skips the field.
+//
+// Example:
+//
+//	type Infra struct {
+//		Logger  *slog.Logger
+//		Tracer  trace.Tracer
+//		Primary *sql.DB `di:"tag=primary"` // Registered with di.WithTag("primary")
+//		Debug   bool    `di:"-"`           // Not registered
+//	}
+//
+//	c, err := di.NewContainer(
+//		di.WithValuesFrom(infra),
+//	)
+//
+// This option will return an error if v is not a struct or a pointer to a struct,
+// or if an exported field is nil, has an invalid tag, or is not a valid service type.
+// A nil slice or map of a named type is registered as an empty value, like [WithDeclaredService].
+func WithValuesFrom(v any) ContainerOption {
+	return containerOption(func(c *Container) error {
+		structVal := reflect.ValueOf(v)
+		if structVal.Kind() == reflect.Pointer && !structVal.IsNil() {
+			structVal = structVal.Elem()
+		}
+		if structVal.Kind() != reflect.Struct {
+			return errors.Errorf("WithValuesFrom %T: must be a struct or pointer to struct", v)
+		}
+
+		var errs []error
+		for i := range structVal.NumField() {
+			field := structVal.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			st, err := parseStructTag(&field, 0)
+			if err == nil && st.skip {
+				continue
+			}
+			if err == nil {
+				err = c.registerField(structVal.Field(i), &field, st.tag)
+			}
+			if err != nil {
+				errs = append(errs, errors.Wrapf(err, "field %s", field.Name))
+			}
+		}
+
+		return errors.Wrapf(errors.Join(errs...), "WithValuesFrom %T", v)
+	})
+}
+
+func (c *Container) registerField(v reflect.Value, field *reflect.StructField, tag any) error {
+	if isNilValue(v) {
+		return errors.New("value is nil")
+	}
+	if v.Kind() == reflect.Interface {
+		// Register the actual value, but as the declared type
+		v = v.Elem()
+	}
+
+	opts := []ServiceOption{asType(field.Type)}
+	if tag != nil {
+		opts = append(opts, WithTag(tag))
+	}
+
+	s, err := newService(c, v, true, opts...)
+	if err != nil {
+		return err
+	}
+
+	c.register(s)
+	return nil
+}
@@ -0,0 +1,146 @@
+package di_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type infra struct {
+	A       testtypes.InterfaceA
+	B       *testtypes.StructB
+	Tagged  *testtypes.StructA `di:"tag=tag"`
+	Skipped *testtypes.StructC `di:"-"`
+}
+
+func Test_WithValuesFrom(t *testing.T) {
+	a := &testtypes.StructA{}
+	b := &testtypes.StructB{}
+	tagged := &testtypes.StructA{Tag: 1}
+
+	t.Run("struct", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithValuesFrom(infra{A: a, B: b, Tagged: tagged}),
+		)
+		require.NoError(t, err)
+
+		gotA, err := di.Resolve[testtypes.InterfaceA](context.Background(), c)
+		assert.NoError(t, err)
+		assert.Same(t, a, gotA)
+
+		gotB, err := di.Resolve[*testtypes.StructB](context.Background(), c)
+		assert.NoError(t, err)
+		assert.Same(t, b, gotB)
+
+		gotTagged, err := di.Resolve[*testtypes.StructA](context.Background(), c, di.WithTag("tag"))
+		assert.NoError(t, err)
+		assert.Same(t, tagged, gotTagged)
+
+		assert.False(t, c.Contains(reflect.TypeFor[*testtypes.StructA]()))
+		assert.False(t, c.Contains(reflect.TypeFor[*testtypes.StructC]()))
+	})
+
+	t.Run("pointer to struct", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithValuesFrom(&infra{A: a, B: b, Tagged: tagged}),
+		)
+		require.NoError(t, err)
+
+		assert.True(t, c.Contains(reflect.TypeFor[testtypes.InterfaceA]()))
+	})
+
+	t.Run("nil fields", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithValuesFrom(infra{B: b}),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithValuesFrom di_test.infra: field A: value is nil\n"+
+			"field Tagged: value is nil")
+	})
+
+	t.Run("typed nil interface field", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithValuesFrom(struct {
+				A testtypes.InterfaceA
+			}{A: (*testtypes.StructA)(nil)}),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithValuesFrom struct { A testtypes.InterfaceA }: field A: value is nil")
+	})
+
+	t.Run("nil map and slice fields", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithValuesFrom(struct {
+				Map   testtypes.CustomMap
+				Slice testtypes.CustomStringCollection
+			}{}),
+		)
+		require.NoError(t, err)
+
+		gotMap, err := di.Resolve[testtypes.CustomMap](context.Background(), c)
+		assert.NoError(t, err)
+		assert.Nil(t, gotMap)
+
+		gotSlice, err := di.Resolve[testtypes.CustomStringCollection](context.Background(), c)
+		assert.NoError(t, err)
+		assert.Nil(t, gotSlice)
+	})
+
+	t.Run("invalid field type", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithValuesFrom(struct{ Port int }{Port: 8080}),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithValuesFrom struct { Port int }: field Port: invalid service type; "+
+			"use a named type or a pointer to a named type")
+	})
+
+	t.Run("invalid tag", func(t *testing.T) {
+		type values struct {
+			A testtypes.InterfaceA `di:"primary"`
+			B testtypes.InterfaceB `di:"optional"`
+		}
+
+		c, err := di.NewContainer(
+			di.WithValuesFrom(values{A: a, B: &testtypes.StructB{}}),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithValuesFrom di_test.values: "+
+			"field A: invalid di tag \"primary\"\n"+
+			"field B: invalid di tag \"optional\": option \"optional\" is not supported")
+	})
+
+	t.Run("not a struct", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithValuesFrom(1234),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithValuesFrom int: must be a struct or pointer to struct")
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithValuesFrom(nil),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithValuesFrom <nil>: must be a struct or pointer to struct")
+	})
+}
@@ -0,0 +1,222 @@
+package di
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// WarmCache holds the encoded instances of [Singleton] services, by [ServiceInfo.ID].
+//
+// Use [Container.ExportWarmCache] to create it, and [WithWarmCache] to import it in another process.
+// It can be serialized with any encoding that supports a map of byte slices, like encoding/gob or encoding/json.
+type WarmCache map[string][]byte
+
+// WithWarmCodec registers functions to encode and decode instances of a [Singleton] service
+// for a [WarmCache] when calling [WithService].
+//
+// This is an advanced startup optimization for prefork servers and process managers.
+// The parent process creates the services that are expensive to construct, like a parsed rules engine,
+// and exports them with [Container.ExportWarmCache]. Worker processes create a Container with [WithWarmCache],
+// and decode the instances instead of calling the constructor functions.
+//
+// A service imported from a WarmCache is treated like it was created by its constructor function:
+// constructor hooks and start functions are called, and it's closed when the Container is closed,
+// but its dependencies are not resolved. If the constructor function returns a cleanup function,
+// the imported instance is closed with its Close method instead, unless [IgnoreCloser] is used.
+// Each instance is decoded once, when the service is first resolved.
+// If decoding fails, a [ConstructorFailed] event is emitted with the error, and the service is created as usual.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithService(rules.NewEngine, // NewEngine(*rules.Config) (*rules.Engine, error)
+//			di.WithWarmCodec((*rules.Engine).MarshalBinary, rules.UnmarshalEngine),
+//		),
+//	)
+//
+// This option will return an error if encode or decode is nil, *Service* is not the type returned by
+// the constructor function, or the service is not a Singleton.
+func WithWarmCodec[Service any](
+	encode func(Service) ([]byte, error),
+	decode func([]byte) (Service, error),
+) ServiceOption {
+	t := reflect.TypeFor[Service]()
+
+	return serviceOption(func(s *service) error {
+		if encode == nil || decode == nil {
+			return errors.Errorf("WithWarmCodec %s: encode and decode must not be nil", t)
+		}
+		if s.IsValue() {
+			return errors.Errorf("WithWarmCodec %s: not supported for value service", t)
+		}
+		if s.t != t {
+			return errors.Errorf("WithWarmCodec %s: service type is %s", t, s.t)
+		}
+
+		s.warmCodec = &warmCodec{
+			Encode: func(val any) ([]byte, error) {
+				return encode(val.(Service))
+			},
+			Decode: func(data []byte) (any, error) {
+				return decode(data)
+			},
+		}
+		return nil
+	})
+}
+
+type warmCodec struct {
+	Encode func(any) ([]byte, error)
+	Decode func([]byte) (any, error)
+}
+
+// WithWarmCache imports instances of [Singleton] services exported by [Container.ExportWarmCache]
+// when calling [NewContainer] or [Container.NewScope].
+//
+// Services registered with [WithWarmCodec] are decoded from the cache when they are first resolved,
+// instead of calling the constructor function. Services are matched by [ServiceInfo.ID],
+// so the Container must register the same services in the same order as the Container that exported the cache.
+// Entries for other services are ignored.
+//
+// Example:
+//
+//	var cache di.WarmCache
+//	if err := gob.NewDecoder(os.Stdin).Decode(&cache); err != nil {
+//		return err
+//	}
+//
+//	c, err := di.NewContainer(
+//		app.Dependencies,
+//		di.WithWarmCache(cache),
+//	)
+func WithWarmCache(cache WarmCache) ContainerOption {
+	return containerOption(func(c *Container) error {
+		c.warmCache = cache
+		return nil
+	})
+}
+
+// ExportWarmCache resolves each [Singleton] service registered with the Container with [WithWarmCodec],
+// and returns the encoded instances.
+//
+// Services registered with parent Containers are not included.
+// See [WithWarmCodec] for more information.
+func (c *Container) ExportWarmCache(ctx context.Context) (WarmCache, error) {
+	c.closedMu.RLock()
+	defer c.closedMu.RUnlock()
+
+	if c.closed {
+		return nil, errors.Wrap(errContainerClosed, "di.Container.ExportWarmCache")
+	}
+
+	cache := make(WarmCache)
+	for _, svc := range c.registered {
+		if svc.warmCodec == nil || svc.Lifetime() != Singleton || svc.keyed != nil {
+			continue
+		}
+
+		key := svc.Keys()[0]
+		val, err := resolveService(ctx, c, key, svc, make(resolveVisitor))
+		if err != nil {
+			return nil, errors.Wrapf(err, "di.Container.ExportWarmCache: service %s", svc)
+		}
+
+		data, err := svc.warmCodec.Encode(val)
+		if err != nil {
+			return nil, errors.Wrapf(err, "di.Container.ExportWarmCache: service %s: encode", svc)
+		}
+
+		cache[svc.Info(key).ID] = data
+	}
+
+	return cache, nil
+}
+
+// resolveWarm decodes the instance of the service from the imported [WarmCache], if any,
+// and stores it like an instance created by the constructor function: the constructor hooks
+// and start functions are called, and the instance is closed with the Container.
+//
+// It returns false if the service is not in the cache or can't be decoded, so the service is created as usual.
+// A decode error is reported with a [ConstructorFailed] event.
+func (c *Container) resolveWarm(ctx context.Context, svc *service, key serviceKey) (resolveResult, bool) {
+	if svc.warmCodec == nil || len(c.warmCache) == 0 {
+		return resolveResult{}, false
+	}
+
+	data, ok := c.warmCache[svc.Info(svc.Keys()[0]).ID]
+	if !ok {
+		return resolveResult{}, false
+	}
+
+	// Decode under the lock so concurrent callers don't decode the same instance twice
+	c.lockResolved()
+
+	// Check if another goroutine resolved the service since the last check
+	if res, exists := c.loadResolved(svc); exists {
+		c.resolvedMu.Unlock()
+		return res, true
+	}
+
+	start := time.Now()
+	res, ok := c.importWarm(ctx, svc, key, data)
+	c.resolvedMu.Unlock()
+
+	// Emit the event after the lock is released
+	c.emitConstructed(svc, key, time.Since(start), res.Err)
+	if !ok {
+		return resolveResult{}, false
+	}
+
+	return res, true
+}
+
+// importWarm decodes the instance of the service and stores it like resolveService.
+// It returns false with the decode error if the instance can't be decoded.
+// The caller must hold a write lock on resolvedMu.
+func (c *Container) importWarm(ctx context.Context, svc *service, key serviceKey, data []byte) (resolveResult, bool) {
+	err := c.callConstructorHooks(ctx, svc, key)
+	if err != nil {
+		// Hook errors are not stored, like errors from hooks called before the constructor function
+		return resolveResult{Err: err}, true
+	}
+
+	val, err := svc.warmCodec.Decode(data)
+	if err != nil {
+		return resolveResult{Err: errors.Wrap(err, "WithWarmCodec: decode")}, false
+	}
+
+	err = c.initInstance(ctx, svc, val, nil)
+	if err != nil {
+		res := resolveResult{Err: svc.wrapNamed(err)}
+		if ctx.Err() == nil {
+			c.storeResolved(svc, res)
+		}
+		return res, true
+	}
+
+	res := resolveResult{Val: val}
+	c.storeResolved(svc, res)
+
+	if closer := svc.warmCloser(val); closer != nil {
+		c.lockClosers()
+		c.appendCloser(closer, svc)
+		c.closersMu.Unlock()
+	}
+
+	return res, true
+}
+
+// warmCloser returns the Closer for an instance of the service imported from a [WarmCache].
+// An imported instance has no cleanup function, so a service that is closed by the cleanup function
+// returned by its constructor function is closed with its Close method instead, unless [IgnoreCloser] is used.
+func (s *service) warmCloser(val any) Closer {
+	closer := s.CloserFor(val, nil)
+	if closer == nil && s.cleanup && s.closerFactory != nil {
+		closer = getCloser(val)
+	}
+
+	return closer
+}
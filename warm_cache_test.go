@@ -0,0 +1,369 @@
+package di_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type rulesEngine struct {
+	Rules  []string
+	closed bool
+}
+
+func (e *rulesEngine) Close() {
+	e.closed = true
+}
+
+func encodeRules(e *rulesEngine) ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func decodeRules(data []byte) (*rulesEngine, error) {
+	e := &rulesEngine{}
+	err := json.Unmarshal(data, e)
+	return e, err
+}
+
+type warmConn struct {
+	Addr   string
+	closed bool
+}
+
+func (c *warmConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+var _ io.Closer = (*warmConn)(nil)
+
+func encodeConn(c *warmConn) ([]byte, error) {
+	return json.Marshal(c)
+}
+
+func decodeConn(data []byte) (*warmConn, error) {
+	c := &warmConn{}
+	err := json.Unmarshal(data, c)
+	return c, err
+}
+
+func Test_WithWarmCache(t *testing.T) {
+	ctx := context.Background()
+
+	newDeps := func(calls *int) di.Module {
+		return di.Module{
+			di.WithService(testtypes.NewInterfaceA),
+			di.WithService(func(testtypes.InterfaceA) *rulesEngine {
+				*calls++
+				return &rulesEngine{Rules: []string{"allow", "deny"}}
+			}, di.WithWarmCodec(encodeRules, decodeRules)),
+		}
+	}
+
+	t.Run("export and import", func(t *testing.T) {
+		parentCalls := 0
+		parent, err := di.NewContainer(newDeps(&parentCalls))
+		require.NoError(t, err)
+
+		cache, err := parent.ExportWarmCache(ctx)
+		require.NoError(t, err)
+		assert.Len(t, cache, 1)
+		assert.Equal(t, 1, parentCalls)
+
+		workerCalls := 0
+		aCalls := 0
+		worker, err := di.NewContainer(
+			newDeps(&workerCalls),
+			di.WithService(func() testtypes.InterfaceA {
+				aCalls++
+				return testtypes.StructA{}
+			}),
+			di.WithWarmCache(cache),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[*rulesEngine](ctx, worker)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"allow", "deny"}, got.Rules)
+		assert.Equal(t, 0, workerCalls)
+		assert.Equal(t, 0, aCalls)
+
+		again, err := di.Resolve[*rulesEngine](ctx, worker)
+		require.NoError(t, err)
+		assert.Same(t, got, again)
+
+		require.NoError(t, worker.Close(ctx))
+		assert.True(t, got.closed)
+	})
+
+	t.Run("import closes io.Closer with cleanup", func(t *testing.T) {
+		cleanups := 0
+		deps := di.WithService(func() (*warmConn, func(), error) {
+			return &warmConn{Addr: "db:5432"}, func() { cleanups++ }, nil
+		}, di.WithWarmCodec(encodeConn, decodeConn))
+
+		parent, err := di.NewContainer(deps)
+		require.NoError(t, err)
+
+		cache, err := parent.ExportWarmCache(ctx)
+		require.NoError(t, err)
+		require.NoError(t, parent.Close(ctx))
+		assert.Equal(t, 1, cleanups)
+
+		worker, err := di.NewContainer(deps, di.WithWarmCache(cache))
+		require.NoError(t, err)
+
+		got, err := di.Resolve[*warmConn](ctx, worker)
+		require.NoError(t, err)
+		assert.Equal(t, "db:5432", got.Addr)
+
+		require.NoError(t, worker.Close(ctx))
+		assert.True(t, got.closed)
+		assert.Equal(t, 1, cleanups)
+	})
+
+	t.Run("import calls constructor hooks", func(t *testing.T) {
+		parentCalls := 0
+		parent, err := di.NewContainer(newDeps(&parentCalls))
+		require.NoError(t, err)
+
+		cache, err := parent.ExportWarmCache(ctx)
+		require.NoError(t, err)
+
+		var hooked []string
+		workerCalls := 0
+		worker, err := di.NewContainer(
+			newDeps(&workerCalls),
+			di.WithWarmCache(cache),
+			di.WithConstructorHook(func(_ context.Context, svc di.ServiceInfo) error {
+				hooked = append(hooked, svc.String())
+				return nil
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*rulesEngine](ctx, worker)
+		require.NoError(t, err)
+		assert.Equal(t, 0, workerCalls)
+		assert.Equal(t, []string{"*di_test.rulesEngine"}, hooked)
+	})
+
+	t.Run("import hook error", func(t *testing.T) {
+		parentCalls := 0
+		parent, err := di.NewContainer(newDeps(&parentCalls))
+		require.NoError(t, err)
+
+		cache, err := parent.ExportWarmCache(ctx)
+		require.NoError(t, err)
+
+		fail := true
+		worker, err := di.NewContainer(
+			newDeps(new(int)),
+			di.WithWarmCache(cache),
+			di.WithConstructorHook(func(context.Context, di.ServiceInfo) error {
+				if fail {
+					return errors.New("hook error")
+				}
+				return nil
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*rulesEngine](ctx, worker)
+		assert.ErrorContains(t, err, "hook error")
+
+		// Hook errors are not stored
+		fail = false
+		got, err := di.Resolve[*rulesEngine](ctx, worker)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"allow", "deny"}, got.Rules)
+	})
+
+	t.Run("different registrations", func(t *testing.T) {
+		parentCalls := 0
+		parent, err := di.NewContainer(newDeps(&parentCalls))
+		require.NoError(t, err)
+
+		cache, err := parent.ExportWarmCache(ctx)
+		require.NoError(t, err)
+
+		workerCalls := 0
+		worker, err := di.NewContainer(
+			di.NamedModule("worker",
+				di.WithService(func() *rulesEngine {
+					workerCalls++
+					return &rulesEngine{}
+				}, di.WithWarmCodec(encodeRules, decodeRules)),
+			),
+			di.WithWarmCache(cache),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*rulesEngine](ctx, worker)
+		require.NoError(t, err)
+		assert.Equal(t, 1, workerCalls)
+	})
+
+	t.Run("decode error", func(t *testing.T) {
+		calls := 0
+		c, err := di.NewContainer(newDeps(&calls))
+		require.NoError(t, err)
+
+		cache, err := c.ExportWarmCache(ctx)
+		require.NoError(t, err)
+		for id := range cache {
+			cache[id] = []byte("not json")
+		}
+
+		rec := &eventRecorder{}
+		workerCalls := 0
+		worker, err := di.NewContainer(
+			newDeps(&workerCalls),
+			di.WithWarmCache(cache),
+			di.WithEventHandler(rec.Handler()),
+		)
+		require.NoError(t, err)
+
+		got, err := di.Resolve[*rulesEngine](ctx, worker)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"allow", "deny"}, got.Rules)
+		assert.Equal(t, 1, workerCalls)
+
+		var decodeErr error
+		for _, e := range rec.events {
+			if e.Kind == di.ConstructorFailed {
+				decodeErr = e.Err
+			}
+		}
+		assert.ErrorContains(t, decodeErr, "WithWarmCodec: decode: ")
+
+		again, err := di.Resolve[*rulesEngine](ctx, worker)
+		require.NoError(t, err)
+		assert.Same(t, got, again)
+	})
+
+	t.Run("concurrent decode", func(t *testing.T) {
+		decodes := atomic.Int32{}
+		newEngine := func() di.ContainerOption {
+			return di.WithService(func() *rulesEngine {
+				return &rulesEngine{}
+			}, di.WithWarmCodec(encodeRules, func(data []byte) (*rulesEngine, error) {
+				decodes.Add(1)
+				return decodeRules(data)
+			}))
+		}
+
+		c, err := di.NewContainer(newEngine())
+		require.NoError(t, err)
+
+		cache, err := c.ExportWarmCache(ctx)
+		require.NoError(t, err)
+		require.Len(t, cache, 1)
+
+		worker, err := di.NewContainer(
+			newEngine(),
+			di.WithWarmCache(cache),
+		)
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := di.Resolve[*rulesEngine](ctx, worker)
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), decodes.Load())
+	})
+
+	t.Run("encode error", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() *rulesEngine { return &rulesEngine{} },
+				di.WithWarmCodec(func(*rulesEngine) ([]byte, error) {
+					return nil, errors.New("encode error")
+				}, decodeRules),
+			),
+		)
+		require.NoError(t, err)
+
+		_, err = c.ExportWarmCache(ctx)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.ExportWarmCache: service func() *di_test.rulesEngine: encode: encode error")
+	})
+
+	t.Run("constructor error", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() (*rulesEngine, error) { return nil, errors.New("constructor error") },
+				di.WithWarmCodec(encodeRules, decodeRules),
+			),
+		)
+		require.NoError(t, err)
+
+		_, err = c.ExportWarmCache(ctx)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.ExportWarmCache: service func() (*di_test.rulesEngine, error): constructor error")
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+		require.NoError(t, c.Close(ctx))
+
+		_, err = c.ExportWarmCache(ctx)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.ExportWarmCache: container closed")
+	})
+
+	t.Run("option errors", func(t *testing.T) {
+		tests := []struct {
+			name string
+			opt  di.ContainerOption
+			want string
+		}{
+			{
+				name: "nil",
+				opt:  di.WithService(func() *rulesEngine { return nil }, di.WithWarmCodec[*rulesEngine](nil, nil)),
+				want: "WithService func() *di_test.rulesEngine: WithWarmCodec *di_test.rulesEngine: encode and decode must not be nil",
+			},
+			{
+				name: "value service",
+				opt:  di.WithService(&rulesEngine{}, di.WithWarmCodec(encodeRules, decodeRules)),
+				want: "WithService *di_test.rulesEngine: WithWarmCodec *di_test.rulesEngine: not supported for value service",
+			},
+			{
+				name: "wrong type",
+				opt:  di.WithService(testtypes.NewInterfaceA, di.WithWarmCodec(encodeRules, decodeRules)),
+				want: "WithService func() testtypes.InterfaceA: WithWarmCodec *di_test.rulesEngine: service type is testtypes.InterfaceA",
+			},
+			{
+				name: "transient",
+				opt: di.WithService(func() *rulesEngine { return nil },
+					di.WithWarmCodec(encodeRules, decodeRules), di.Transient),
+				want: "WithService func() *di_test.rulesEngine: WithWarmCodec *di_test.rulesEngine: service must be Singleton",
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				c, err := di.NewContainer(tt.opt)
+				testutils.LogError(t, err)
+				assert.Nil(t, c)
+				assert.EqualError(t, err, "di.NewContainer: "+tt.want)
+			})
+		}
+	})
+}
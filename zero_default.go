@@ -0,0 +1,88 @@
+package di
+
+import (
+	"reflect"
+
+	"github.com/sectrean/di-kit/internal/errors"
+)
+
+// WithZeroDefault injects the zero value for dependencies of type *Dependency* that are not registered,
+// when calling [WithService] or [Invoke].
+//
+// By default, resolving a service with a dependency that is not registered returns an error.
+// With this option, the zero value is injected instead, like nil for an interface or pointer,
+// or an empty slice or map for a slice or map dependency.
+// This is useful for optional collaborators, like a metrics sink, that the service checks for nil.
+// Errors from resolving a registered service are still returned.
+//
+// The option applies to every dependency of type *Dependency*, with or without a tag.
+// Use [Optional] instead to tell the difference between a zero value and a service that is not registered.
+//
+// Example:
+//
+//	c, err := di.NewContainer(
+//		di.WithService(NewService, // NewService(metrics.Sink) *Service
+//			di.WithZeroDefault[metrics.Sink](),
+//		),
+//	)
+//
+// This option will return an error if the function does not have a dependency of type *Dependency*.
+func WithZeroDefault[Dependency any]() DependencyOption {
+	return zeroDefaultOption{t: reflect.TypeFor[Dependency]()}
+}
+
+type zeroDefaultOption struct {
+	t reflect.Type
+}
+
+func (o zeroDefaultOption) applyService(s *service) error {
+	var err error
+	s.zeroDeps, err = o.apply(s.Dependencies(), s.zeroDeps)
+	return err
+}
+
+func (o zeroDefaultOption) applyInvokeConfig(c *invokeConfig) error {
+	var err error
+	c.zeroDeps, err = o.apply(c.deps, c.zeroDeps)
+	return err
+}
+
+// apply marks each dependency of the type in zeroDeps.
+func (o zeroDefaultOption) apply(deps []serviceKey, zeroDeps []bool) ([]bool, error) {
+	found := false
+	for i, dep := range deps {
+		if dep.Type != o.t {
+			continue
+		}
+
+		if zeroDeps == nil {
+			zeroDeps = make([]bool, len(deps))
+		}
+		zeroDeps[i] = true
+		found = true
+	}
+
+	if !found {
+		return zeroDeps, errors.Errorf("WithZeroDefault %s: parameter not found", o.t)
+	}
+
+	return zeroDeps, nil
+}
+
+// isZeroDefault returns true if the dependency at index i is injected as the zero value when it is not registered.
+func isZeroDefault(zeroDeps []bool, i int) bool {
+	return i < len(zeroDeps) && zeroDeps[i]
+}
+
+// isRegistered returns true if a service would be resolved for the dependency key.
+func (c *Container) isRegistered(key serviceKey) bool {
+	if isUnnamedSliceType(key.Type) {
+		elemKey := serviceKey{Type: key.Type.Elem(), Tag: key.Tag}
+		return c.hasSliceServices(elemKey)
+	}
+	if isStringMapType(key.Type) {
+		return len(c.stringMapKeys(key.Type)) > 0
+	}
+
+	return len(c.lookupServices(c.resolvableKey(key))) > 0
+}
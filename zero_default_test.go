@@ -0,0 +1,132 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sectrean/di-kit"
+	"github.com/sectrean/di-kit/internal/errors"
+	"github.com/sectrean/di-kit/internal/testtypes"
+	"github.com/sectrean/di-kit/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithZeroDefault(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("not registered", func(t *testing.T) {
+		var got testtypes.InterfaceA = testtypes.StructA{}
+		c, err := di.NewContainer(
+			di.WithService(func(a testtypes.InterfaceA) *testtypes.StructB {
+				got = a
+				return &testtypes.StructB{}
+			}, di.WithZeroDefault[testtypes.InterfaceA]()),
+			di.WithDependencyValidation(),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*testtypes.StructB](ctx, c)
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("registered", func(t *testing.T) {
+		var got testtypes.InterfaceA
+		c, err := di.NewContainer(
+			di.WithService(testtypes.StructA{Tag: "a"}, di.As[testtypes.InterfaceA]()),
+			di.WithService(func(a testtypes.InterfaceA) *testtypes.StructB {
+				got = a
+				return &testtypes.StructB{}
+			}, di.WithZeroDefault[testtypes.InterfaceA]()),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*testtypes.StructB](ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, testtypes.StructA{Tag: "a"}, got)
+	})
+
+	t.Run("registered error", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func() (testtypes.InterfaceA, error) {
+				return nil, errors.New("constructor error")
+			}),
+			di.WithService(func(testtypes.InterfaceA) *testtypes.StructB {
+				return &testtypes.StructB{}
+			}, di.WithZeroDefault[testtypes.InterfaceA]()),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*testtypes.StructB](ctx, c)
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Container.Resolve *testtypes.StructB: "+
+			"dependency testtypes.InterfaceA: constructor error")
+	})
+
+	t.Run("WithTagged", func(t *testing.T) {
+		var got testtypes.InterfaceA = testtypes.StructA{}
+		c, err := di.NewContainer(
+			di.WithService(testtypes.StructA{}, di.As[testtypes.InterfaceA]()),
+			di.WithService(func(a testtypes.InterfaceA) *testtypes.StructB {
+				got = a
+				return &testtypes.StructB{}
+			},
+				di.WithTagged[testtypes.InterfaceA]("missing"),
+				di.WithZeroDefault[testtypes.InterfaceA](),
+			),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*testtypes.StructB](ctx, c)
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("slice", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(func(as []testtypes.InterfaceA) *testtypes.StructB {
+				assert.Empty(t, as)
+				return &testtypes.StructB{}
+			}, di.WithZeroDefault[[]testtypes.InterfaceA]()),
+			di.WithDependencyValidation(),
+		)
+		require.NoError(t, err)
+
+		_, err = di.Resolve[*testtypes.StructB](ctx, c)
+		require.NoError(t, err)
+	})
+
+	t.Run("Invoke", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		called := false
+		err = di.Invoke(ctx, c, func(a testtypes.InterfaceA) {
+			called = true
+			assert.Nil(t, a)
+		}, di.WithZeroDefault[testtypes.InterfaceA]())
+		require.NoError(t, err)
+		assert.True(t, called)
+	})
+
+	t.Run("parameter not found", func(t *testing.T) {
+		c, err := di.NewContainer(
+			di.WithService(testtypes.NewInterfaceB, di.WithZeroDefault[testtypes.InterfaceC]()),
+		)
+		testutils.LogError(t, err)
+
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "di.NewContainer: WithService func(testtypes.InterfaceA) testtypes.InterfaceB: "+
+			"WithZeroDefault testtypes.InterfaceC: parameter not found")
+	})
+
+	t.Run("Invoke parameter not found", func(t *testing.T) {
+		c, err := di.NewContainer()
+		require.NoError(t, err)
+
+		err = di.Invoke(ctx, c, func() {}, di.WithZeroDefault[testtypes.InterfaceA]())
+		testutils.LogError(t, err)
+		assert.EqualError(t, err, "di.Invoke func(): WithZeroDefault testtypes.InterfaceA: parameter not found")
+	})
+}